@@ -20,6 +20,15 @@ import (
 	"github.com/tomasohchom/motion/services/workspace/internal/store"
 )
 
+// allowedOrigins are the web app origins allowed to call the API from a
+// browser
+var allowedOrigins = []string{
+	"http://localhost:3000",
+	"http://127.0.0.1:3000",
+	"http://localhost:5173",
+	"http://127.0.0.1:5173",
+} // modify for production
+
 type Route struct {
 	Method  string
 	Path    string
@@ -115,17 +124,66 @@ func main() {
 		})
 		log.Println("Workspace handler routes registered")
 
-		noteService := services.NewNoteService(store)
+		automationEngine := services.NewAutomationEngine(store)
+		go automationEngine.Start(ctx)
+
+		noteService := services.NewNoteService(store, automationEngine)
 		noteHandler := handlers.NewNoteHandler(noteService)
 		registerRoutes(mux, []Route{
 			{"POST", "/workspaces/{workspace_id}/notes", noteHandler.CreateNote},
 			{"GET", "/workspaces/{workspace_id}/notes", noteHandler.ListNotes},
+			{"GET", "/workspaces/{workspace_id}/notes/search", noteHandler.SearchNotes},
+			{"PUT", "/workspaces/{workspace_id}/notes/order", noteHandler.ReorderNotes},
+			{"GET", "/workspaces/{workspace_id}/notes/graph", noteHandler.GetNoteGraph},
+			{"GET", "/workspaces/{workspace_id}/notes/broken-links", noteHandler.ListBrokenLinks},
+			{"GET", "/workspaces/{workspace_id}/notes/export", noteHandler.ExportNotes},
+			{"POST", "/workspaces/{workspace_id}/notes/import", noteHandler.ImportNotes},
+			{"GET", "/workspaces/{workspace_id}/tags", noteHandler.ListTags},
+			{"POST", "/workspaces/{workspace_id}/tags/rename", noteHandler.RenameTag},
+			{"POST", "/workspaces/{workspace_id}/tags/merge", noteHandler.MergeTags},
 			{"GET", "/workspaces/{workspace_id}/notes/{note_id}", noteHandler.GetNote},
 			{"PATCH", "/workspaces/{workspace_id}/notes/{note_id}", noteHandler.UpdateNote},
 			{"DELETE", "/workspaces/{workspace_id}/notes/{note_id}", noteHandler.DeleteNote},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/access", noteHandler.GetNoteAccess},
+			{"PUT", "/workspaces/{workspace_id}/notes/{note_id}/access", noteHandler.UpdateNoteAccess},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/children", noteHandler.ListChildren},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/breadcrumbs", noteHandler.GetBreadcrumbs},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/move", noteHandler.MoveNote},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/backlinks", noteHandler.ListBacklinks},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/render", noteHandler.RenderNote},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/revisions", noteHandler.ListRevisions},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/revisions/diff", noteHandler.DiffRevisions},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/revisions/{revision}", noteHandler.GetRevision},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/revisions/{revision}/restore", noteHandler.RestoreRevision},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/comments", noteHandler.ListCommentThreads},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/comments", noteHandler.CreateCommentThread},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/comments/{thread_id}/replies", noteHandler.ReplyToCommentThread},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/comments/{thread_id}/resolve", noteHandler.ResolveCommentThread},
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/comments/{thread_id}/unresolve", noteHandler.UnresolveCommentThread},
 		})
 		log.Println("Note handler routes registered")
 
+		noteCollabService := services.NewNoteCollabService(noteService)
+		go noteCollabService.Start(ctx)
+		collabHandler := handlers.NewCollabHandler(noteCollabService, allowedOrigins)
+		registerRoutes(mux, []Route{
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/collab", collabHandler.CollabNote},
+		})
+		log.Println("Collab handler routes registered")
+
+		noteShareService := services.NewNoteShareService(store, noteService)
+		noteShareHandler := handlers.NewNoteShareHandler(noteShareService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/notes/{note_id}/shares", noteShareHandler.CreateShare},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/shares", noteShareHandler.ListShares},
+			{"DELETE", "/workspaces/{workspace_id}/notes/{note_id}/shares/{share_id}", noteShareHandler.RevokeShare},
+			{"GET", "/workspaces/{workspace_id}/notes/{note_id}/shares/{share_id}/accesses", noteShareHandler.ListShareAccesses},
+		})
+		// Share links are opened by people without an account, so this
+		// route is registered without AuthMiddleware
+		mux.HandleFunc("GET /shared/notes/{token}", noteShareHandler.GetSharedNote)
+		log.Println("Note share handler routes registered")
+
 		inviteSerive := services.NewInviteService(store)
 		inviteHandler := handlers.NewInviteHandler(inviteSerive)
 
@@ -138,7 +196,7 @@ func main() {
 		})
 		log.Println("Invite handler routes registered")
 
-		taskService := services.NewTaskService(store)
+		taskService := services.NewTaskService(store, automationEngine)
 		taskHandler := handlers.NewTaskHandler(taskService)
 		registerRoutes(mux, []Route{
 			{"POST", "/workspaces/{workspaceId}/tasks", taskHandler.CreateNewTask},
@@ -146,29 +204,124 @@ func main() {
 			{"GET", "/tasks/{task_id}", taskHandler.GetTask},
 			{"PATCH", "/tasks/{task_id}", taskHandler.UpdateTask},
 			{"DELETE", "/tasks/{task_id}", taskHandler.DeleteTask},
+			{"GET", "/tasks/{task_id}/comments", taskHandler.ListTaskComments},
+			{"POST", "/tasks/{task_id}/comments", taskHandler.AddTaskComment},
+			{"GET", "/tasks/{task_id}/dependencies", taskHandler.ListTaskDependencies},
+			{"POST", "/tasks/{task_id}/dependencies", taskHandler.AddTaskDependency},
+			{"DELETE", "/tasks/{task_id}/dependencies/{depends_on_id}", taskHandler.RemoveTaskDependency},
 		})
 		log.Println("Task handler routes registered")
 
+		templateService := services.NewTemplateService(store, noteService, taskService)
+		templateHandler := handlers.NewTemplateHandler(templateService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/templates", templateHandler.CreateTemplate},
+			{"GET", "/workspaces/{workspace_id}/templates", templateHandler.ListTemplates},
+			{"GET", "/workspaces/{workspace_id}/templates/{template_id}", templateHandler.GetTemplate},
+			{"PATCH", "/workspaces/{workspace_id}/templates/{template_id}", templateHandler.UpdateTemplate},
+			{"DELETE", "/workspaces/{workspace_id}/templates/{template_id}", templateHandler.DeleteTemplate},
+			{"POST", "/workspaces/{workspace_id}/templates/{template_id}/instantiate", templateHandler.InstantiateTemplate},
+		})
+		log.Println("Template handler routes registered")
+
+		taskViewService := services.NewTaskViewService(store)
+		taskViewHandler := handlers.NewTaskViewHandler(taskViewService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspaceId}/task-views", taskViewHandler.CreateTaskView},
+			{"GET", "/workspaces/{workspaceId}/task-views", taskViewHandler.ListTaskViews},
+			{"GET", "/workspaces/{workspaceId}/task-views/{view_id}", taskViewHandler.GetTaskView},
+			{"PATCH", "/workspaces/{workspaceId}/task-views/{view_id}", taskViewHandler.UpdateTaskView},
+			{"DELETE", "/workspaces/{workspaceId}/task-views/{view_id}", taskViewHandler.DeleteTaskView},
+			{"GET", "/workspaces/{workspaceId}/task-views/{view_id}/tasks", taskViewHandler.ExecuteTaskView},
+		})
+		log.Println("Task view handler routes registered")
+
+		automationService := services.NewAutomationService(store)
+		automationHandler := handlers.NewAutomationHandler(automationService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/automations", automationHandler.CreateRule},
+			{"GET", "/workspaces/{workspace_id}/automations", automationHandler.ListRules},
+			{"GET", "/workspaces/{workspace_id}/automations/{rule_id}", automationHandler.GetRule},
+			{"PATCH", "/workspaces/{workspace_id}/automations/{rule_id}", automationHandler.UpdateRule},
+			{"DELETE", "/workspaces/{workspace_id}/automations/{rule_id}", automationHandler.DeleteRule},
+			{"GET", "/workspaces/{workspace_id}/automations/{rule_id}/runs", automationHandler.ListRuns},
+		})
+		log.Println("Automation handler routes registered")
+
+		projectService := services.NewProjectService(store)
+		projectHandler := handlers.NewProjectHandler(projectService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/projects", projectHandler.CreateProject},
+			{"GET", "/workspaces/{workspace_id}/projects", projectHandler.ListProjects},
+			{"GET", "/workspaces/{workspace_id}/projects/{project_id}", projectHandler.GetProject},
+			{"PATCH", "/workspaces/{workspace_id}/projects/{project_id}", projectHandler.UpdateProject},
+			{"DELETE", "/workspaces/{workspace_id}/projects/{project_id}", projectHandler.DeleteProject},
+			{"GET", "/workspaces/{workspace_id}/projects/{project_id}/tasks", projectHandler.ListProjectTasks},
+			{"POST", "/workspaces/{workspace_id}/projects/{project_id}/milestones", projectHandler.CreateMilestone},
+			{"PATCH", "/workspaces/{workspace_id}/projects/{project_id}/milestones/{milestone_id}", projectHandler.UpdateMilestone},
+			{"DELETE", "/workspaces/{workspace_id}/projects/{project_id}/milestones/{milestone_id}", projectHandler.DeleteMilestone},
+			{"PUT", "/tasks/{task_id}/project", projectHandler.AssignTask},
+		})
+		log.Println("Project handler routes registered")
+
+		cycleService := services.NewCycleService(store)
+		go cycleService.Start(ctx)
+		cycleHandler := handlers.NewCycleHandler(cycleService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/cycles", cycleHandler.CreateCycle},
+			{"GET", "/workspaces/{workspace_id}/cycles", cycleHandler.ListCycles},
+			{"GET", "/workspaces/{workspace_id}/cycles/{cycle_id}", cycleHandler.GetCycle},
+			{"PATCH", "/workspaces/{workspace_id}/cycles/{cycle_id}", cycleHandler.UpdateCycle},
+			{"DELETE", "/workspaces/{workspace_id}/cycles/{cycle_id}", cycleHandler.DeleteCycle},
+			{"POST", "/workspaces/{workspace_id}/cycles/{cycle_id}/close", cycleHandler.CloseCycle},
+			{"GET", "/workspaces/{workspace_id}/cycles/{cycle_id}/summary", cycleHandler.GetCycleSummary},
+			{"PUT", "/tasks/{task_id}/cycle", cycleHandler.AssignTask},
+		})
+		log.Println("Cycle handler routes registered")
+
+		timelineService := services.NewTimelineService(store)
+		timelineHandler := handlers.NewTimelineHandler(timelineService)
+		registerRoutes(mux, []Route{
+			{"GET", "/workspaces/{workspace_id}/timeline", timelineHandler.GetTimeline},
+			{"GET", "/workspaces/{workspace_id}/projects/{project_id}/timeline", timelineHandler.GetTimeline},
+		})
+		log.Println("Timeline handler routes registered")
+
 		eventService := services.NewEventService(store)
 		eventHandler := handlers.NewEventHandler(eventService)
 		registerRoutes(mux, []Route{
 			{"POST", "/workspaces/{workspace_id}/events", eventHandler.CreateEvent},
+			{"POST", "/workspaces/{workspace_id}/events/import", eventHandler.ImportEvents},
 			{"GET", "/workspaces/{workspace_id}/events", eventHandler.ListWorkspaceEvents},
 			{"GET", "/workspaces/{workspace_id}/events/{event_id}", eventHandler.GetEvent},
 			{"PUT", "/workspaces/{workspace_id}/events/{event_id}", eventHandler.UpdateEvent},
 			{"DELETE", "/workspaces/{workspace_id}/events/{event_id}", eventHandler.DeleteEvent},
+			{"PUT", "/workspaces/{workspace_id}/events/{event_id}/rsvp", eventHandler.RespondToEvent},
+			{"POST", "/workspaces/{workspace_id}/events/{event_id}/attendees/{attendee_id}/rsvp-link", eventHandler.CreateAttendeeLink},
 			{"GET", "/events/color", eventHandler.GetEventColor},
 		})
+		// Invitees outside the workspace answer through RSVP links, which
+		// authenticate them instead of AuthMiddleware
+		mux.HandleFunc("GET /event-invitations/{token}", eventHandler.GetInvitation)
+		mux.HandleFunc("PUT /event-invitations/{token}", eventHandler.RespondToInvitation)
 		log.Println("Event handler routes registered")
+
+		calendarFeedService := services.NewCalendarFeedService(store)
+		calendarFeedHandler := handlers.NewCalendarFeedHandler(calendarFeedService)
+		registerRoutes(mux, []Route{
+			{"POST", "/workspaces/{workspace_id}/calendar-feed", calendarFeedHandler.CreateFeed},
+			{"GET", "/workspaces/{workspace_id}/calendar-feed", calendarFeedHandler.GetFeed},
+			{"PATCH", "/workspaces/{workspace_id}/calendar-feed", calendarFeedHandler.UpdateFeed},
+			{"DELETE", "/workspaces/{workspace_id}/calendar-feed", calendarFeedHandler.DeleteFeed},
+		})
+		// Calendar apps cannot sign in; the token in the URL authenticates
+		// them instead of AuthMiddleware
+		mux.HandleFunc("GET /calendar-feeds/{token}", calendarFeedHandler.GetFeedCalendar)
+		log.Println("Calendar feed handler routes registered")
 	}()
 
 	c := cors.New(cors.Options{
-		AllowedOrigins: []string{
-			"http://localhost:3000",
-			"http://127.0.0.1:3000",
-			"http://localhost:5173",
-			"http://127.0.0.1:5173",
-		}, // modify for production
+		AllowedOrigins: allowedOrigins,
 		AllowOriginRequestFunc: func(_ *http.Request, origin string) bool {
 			return true // allow any origin in dev; tighten for prod
 		},
@@ -186,6 +339,9 @@ func main() {
 			"Authorization",
 			"X-Dev-UserID",
 		},
+		ExposedHeaders: []string{
+			"ETag",
+		},
 		AllowCredentials:   false, // enable in production
 		MaxAge:             300,   // preflight cache duration in seconds
 		Debug:              true,  // disable in production
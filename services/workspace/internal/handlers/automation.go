@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type AutomationHandler struct {
+	s services.AutomationServicer
+}
+
+func NewAutomationHandler(service services.AutomationServicer) *AutomationHandler {
+	return &AutomationHandler{s: service}
+}
+
+type createAutomationRuleRequest struct {
+	Name          string                           `json:"name"`
+	Enabled       *bool                            `json:"enabled"`
+	TriggerType   string                           `json:"trigger_type"`
+	TriggerConfig services.AutomationTriggerConfig `json:"trigger_config"`
+	Conditions    []services.AutomationCondition   `json:"conditions"`
+	Actions       []services.AutomationAction      `json:"actions"`
+}
+
+type updateAutomationRuleRequest struct {
+	Name          *string                           `json:"name"`
+	Enabled       *bool                             `json:"enabled"`
+	TriggerType   *string                           `json:"trigger_type"`
+	TriggerConfig *services.AutomationTriggerConfig `json:"trigger_config"`
+	Conditions    *[]services.AutomationCondition   `json:"conditions"`
+	Actions       *[]services.AutomationAction      `json:"actions"`
+}
+
+func (h *AutomationHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req createAutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.s.CreateRule(r.Context(), workspaceID, userId, services.AutomationRuleInput{
+		Name:          req.Name,
+		Enabled:       req.Enabled,
+		TriggerType:   req.TriggerType,
+		TriggerConfig: req.TriggerConfig,
+		Conditions:    req.Conditions,
+		Actions:       req.Actions,
+	})
+	if err != nil {
+		handleAutomationError(w, "create automation rule", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *AutomationHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	rules, err := h.s.ListRules(r.Context(), workspaceID)
+	if err != nil {
+		handleAutomationError(w, "list automation rules", err)
+		return
+	}
+
+	writeJSON(w, rules)
+}
+
+func (h *AutomationHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	ruleID := r.PathValue("rule_id")
+	if workspaceID == "" || ruleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.s.GetRule(r.Context(), workspaceID, ruleID)
+	if err != nil {
+		handleAutomationError(w, "get automation rule", err)
+		return
+	}
+
+	writeJSON(w, rule)
+}
+
+func (h *AutomationHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	ruleID := r.PathValue("rule_id")
+	if workspaceID == "" || ruleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req updateAutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.s.UpdateRule(r.Context(), workspaceID, ruleID, services.UpdateAutomationRuleInput{
+		Name:          req.Name,
+		Enabled:       req.Enabled,
+		TriggerType:   req.TriggerType,
+		TriggerConfig: req.TriggerConfig,
+		Conditions:    req.Conditions,
+		Actions:       req.Actions,
+	})
+	if err != nil {
+		handleAutomationError(w, "update automation rule", err)
+		return
+	}
+
+	writeJSON(w, rule)
+}
+
+func (h *AutomationHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	ruleID := r.PathValue("rule_id")
+	if workspaceID == "" || ruleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteRule(r.Context(), workspaceID, ruleID); err != nil {
+		handleAutomationError(w, "delete automation rule", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AutomationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	ruleID := r.PathValue("rule_id")
+	if workspaceID == "" || ruleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	runs, err := h.s.ListRuns(r.Context(), workspaceID, ruleID)
+	if err != nil {
+		handleAutomationError(w, "list automation runs", err)
+		return
+	}
+
+	writeJSON(w, runs)
+}
+
+func handleAutomationError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAutomationData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrAutomationRuleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type CalendarFeedHandler struct {
+	s services.CalendarFeedServicer
+}
+
+func NewCalendarFeedHandler(service services.CalendarFeedServicer) *CalendarFeedHandler {
+	return &CalendarFeedHandler{s: service}
+}
+
+type calendarFeedRequest struct {
+	// Tasks is none, todo or event
+	Tasks string `json:"tasks"`
+}
+
+// createdCalendarFeedResponse adds the URL to subscribe to
+type createdCalendarFeedResponse struct {
+	services.CreatedCalendarFeed
+	URL string `json:"url"`
+}
+
+// CreateFeed gives the signed-in member a calendar feed of the workspace,
+// replacing the URL of any previous one
+func (h *CalendarFeedHandler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var req calendarFeedRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	feed, err := h.s.CreateFeed(r.Context(), workspaceID, userID, req.Tasks)
+	if err != nil {
+		handleCalendarFeedError(w, "create calendar feed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdCalendarFeedResponse{
+		CreatedCalendarFeed: feed,
+		URL:                 calendarFeedURL(r, feed.Token),
+	})
+}
+
+func (h *CalendarFeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	feed, err := h.s.GetFeed(r.Context(), workspaceID, userID)
+	if err != nil {
+		handleCalendarFeedError(w, "get calendar feed", err)
+		return
+	}
+
+	writeJSON(w, feed)
+}
+
+func (h *CalendarFeedHandler) UpdateFeed(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req calendarFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	feed, err := h.s.UpdateFeed(r.Context(), workspaceID, userID, req.Tasks)
+	if err != nil {
+		handleCalendarFeedError(w, "update calendar feed", err)
+		return
+	}
+
+	writeJSON(w, feed)
+}
+
+func (h *CalendarFeedHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	if err := h.s.DeleteFeed(r.Context(), workspaceID, userID); err != nil {
+		handleCalendarFeedError(w, "delete calendar feed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFeedCalendar serves the iCalendar document of a feed to calendar
+// apps, which authenticate with the token in the URL alone. The token may
+// be followed by ".ics", which some apps expect.
+func (h *CalendarFeedHandler) GetFeedCalendar(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(r.PathValue("token"), ".ics")
+
+	calendar, err := h.s.RenderFeed(r.Context(), token)
+
+	// Feeds can be replaced at any time, and the token is in the URL
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err != nil {
+		handleCalendarFeedError(w, "render calendar feed", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.Write([]byte(calendar))
+}
+
+// calendarFeedURL returns the URL calendar apps subscribe to
+func calendarFeedURL(r *http.Request, token string) string {
+	return requestBaseURL(r) + "/calendar-feeds/" + token + ".ics"
+}
+
+// requestBaseURL returns the scheme and host of the service as seen by the
+// client, behind a proxy too
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return scheme + "://" + host
+}
+
+func handleCalendarFeedError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCalendarFeedData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrWorkspaceAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrCalendarFeedNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+const (
+	collabWriteWait  = 10 * time.Second
+	collabPongWait   = 60 * time.Second
+	collabPingPeriod = collabPongWait * 9 / 10
+	collabMaxMessage = 1 << 20
+)
+
+type CollabHandler struct {
+	s        services.NoteCollabServicer
+	upgrader websocket.Upgrader
+}
+
+// NewCollabHandler accepts WebSockets from the same origin and from
+// allowedOrigins. Browsers send cookies along with cross-site WebSockets,
+// so any other origin could act as the signed-in member.
+func NewCollabHandler(service services.NoteCollabServicer, allowedOrigins []string) *CollabHandler {
+	return &CollabHandler{
+		s: service,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					// Not a browser
+					return true
+				}
+				if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+					return true
+				}
+				for _, allowed := range allowedOrigins {
+					if strings.EqualFold(origin, allowed) {
+						return true
+					}
+				}
+				return false
+			},
+		},
+	}
+}
+
+// CollabNote upgrades to a WebSocket and joins the note's editing session.
+// See services.CollabMessage for the protocol.
+func (h *CollabHandler) CollabNote(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Checked before joining, so other sites cannot even load the note
+	if !h.upgrader.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	client, err := h.s.Join(r.Context(), workspaceID, noteID, userID)
+	if err != nil {
+		handleCollabError(w, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response
+		client.Leave()
+		return
+	}
+	conn.SetReadLimit(collabMaxMessage)
+
+	go writeCollab(conn, client)
+	readCollab(conn, client)
+}
+
+func readCollab(conn *websocket.Conn, client *services.CollabClient) {
+	defer func() {
+		client.Leave()
+		conn.Close()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	})
+	for {
+		var msg services.CollabMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Collab connection closed: %v", err)
+			}
+			return
+		}
+		client.Handle(msg)
+	}
+}
+
+// writeCollab forwards the client's outbound messages and keeps the
+// connection alive with pings. It closes the connection once the client
+// has been disconnected from its room.
+func writeCollab(conn *websocket.Conn, client *services.CollabClient) {
+	ticker := time.NewTicker(collabPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-client.Outbound():
+			conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func handleCollabError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrNoteForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrInvalidNoteData), errors.Is(err, services.ErrMissingNoteFields), errors.Is(err, services.ErrCRDTDocumentSize):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Failed to join note session: %v", err)
+		http.Error(w, "failed to join note session", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type CycleHandler struct {
+	s services.CycleServicer
+}
+
+func NewCycleHandler(service services.CycleServicer) *CycleHandler {
+	return &CycleHandler{s: service}
+}
+
+type createCycleRequest struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type updateCycleRequest struct {
+	Name      *string `json:"name"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+type assignTaskCycleRequest struct {
+	CycleID string `json:"cycle_id"`
+}
+
+func (h *CycleHandler) CreateCycle(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req createCycleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "invalid start_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "invalid end_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	cycle, err := h.s.CreateCycle(r.Context(), workspaceID, services.CycleInput{
+		Name:      req.Name,
+		StartDate: startDate,
+		EndDate:   endDate,
+	})
+	if err != nil {
+		handleCycleError(w, "create cycle", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(cycle)
+}
+
+func (h *CycleHandler) ListCycles(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	cycles, err := h.s.ListCycles(r.Context(), workspaceID)
+	if err != nil {
+		handleCycleError(w, "list cycles", err)
+		return
+	}
+
+	writeJSON(w, cycles)
+}
+
+func (h *CycleHandler) GetCycle(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	cycleID := r.PathValue("cycle_id")
+	if workspaceID == "" || cycleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	cycle, err := h.s.GetCycle(r.Context(), workspaceID, cycleID)
+	if err != nil {
+		handleCycleError(w, "get cycle", err)
+		return
+	}
+
+	writeJSON(w, cycle)
+}
+
+func (h *CycleHandler) UpdateCycle(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	cycleID := r.PathValue("cycle_id")
+	if workspaceID == "" || cycleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req updateCycleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input := services.UpdateCycleInput{Name: req.Name}
+	if req.StartDate != nil {
+		startDate, err := parseDate(*req.StartDate)
+		if err != nil {
+			http.Error(w, "invalid start_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		input.StartDate = &startDate
+	}
+	if req.EndDate != nil {
+		endDate, err := parseDate(*req.EndDate)
+		if err != nil {
+			http.Error(w, "invalid end_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		input.EndDate = &endDate
+	}
+
+	cycle, err := h.s.UpdateCycle(r.Context(), workspaceID, cycleID, input)
+	if err != nil {
+		handleCycleError(w, "update cycle", err)
+		return
+	}
+
+	writeJSON(w, cycle)
+}
+
+func (h *CycleHandler) DeleteCycle(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	cycleID := r.PathValue("cycle_id")
+	if workspaceID == "" || cycleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteCycle(r.Context(), workspaceID, cycleID); err != nil {
+		handleCycleError(w, "delete cycle", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CycleHandler) CloseCycle(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	cycleID := r.PathValue("cycle_id")
+	if workspaceID == "" || cycleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.s.CloseCycle(r.Context(), workspaceID, cycleID)
+	if err != nil {
+		handleCycleError(w, "close cycle", err)
+		return
+	}
+
+	writeJSON(w, result)
+}
+
+func (h *CycleHandler) GetCycleSummary(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	cycleID := r.PathValue("cycle_id")
+	if workspaceID == "" || cycleID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.s.GetCycleSummary(r.Context(), workspaceID, cycleID)
+	if err != nil {
+		handleCycleError(w, "get cycle summary", err)
+		return
+	}
+
+	writeJSON(w, summary)
+}
+
+func (h *CycleHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	var req assignTaskCycleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.s.AssignTask(r.Context(), taskID, req.CycleID)
+	if err != nil {
+		handleCycleError(w, "assign task to cycle", err)
+		return
+	}
+
+	writeJSON(w, task)
+}
+
+func handleCycleError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCycleData), errors.Is(err, services.ErrInvalidTaskData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrCycleNotFound), errors.Is(err, services.ErrTaskNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrCycleOverlap), errors.Is(err, services.ErrCycleClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
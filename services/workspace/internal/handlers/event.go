@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
 	"github.com/tomasohchom/motion/services/workspace/internal/services"
@@ -19,21 +20,32 @@ func NewEventHandler(service services.EventServicer) *EventHandler {
 }
 
 type EventRequestData struct {
-	Name           string `json:"name"`
-	Color          string `json:"color"`
-	EventDate      string `json:"event_date"` // ISO 8601 format: YYYY-MM-DD
-	EventTime      string `json:"event_time"` // HH:MM:SS format
-	DurationMinute int32  `json:"duration_minutes"`
-	AttendeesCount int32  `json:"attendees_count"`
+	Name            string `json:"name"`
+	Color           string `json:"color"`
+	StartsAt        string `json:"starts_at"` // RFC 3339, or YYYY-MM-DD for all-day events
+	Timezone        string `json:"timezone"`  // IANA name, such as Europe/Paris
+	AllDay          bool   `json:"all_day"`
+	DurationMinutes int32  `json:"duration_minutes"`
+	// Attendees are workspace members or emails; attendees_count follows
+	// them
+	Attendees []eventAttendeeRequest `json:"attendees"`
+	// RRule is an RFC 5545 recurrence rule, such as FREQ=WEEKLY;BYDAY=MO,WE
+	RRule   string   `json:"rrule"`
+	ExDates []string `json:"exdates"` // starts of the occurrences to leave out
 }
 
 type UpdateEventRequestData struct {
-	Name           *string `json:"name"`
-	Color          *string `json:"color"`
-	EventDate      *string `json:"event_date"` // ISO 8601 format: YYYY-MM-DD
-	EventTime      *string `json:"event_time"` // HH:MM:SS format
-	DurationMinute *int32  `json:"duration_minutes"`
-	AttendeesCount *int32  `json:"attendees_count"`
+	Name            *string `json:"name"`
+	Color           *string `json:"color"`
+	StartsAt        *string `json:"starts_at"` // RFC 3339, or YYYY-MM-DD for all-day events
+	Timezone        *string `json:"timezone"`  // IANA name, such as Europe/Paris
+	AllDay          *bool   `json:"all_day"`
+	DurationMinutes *int32  `json:"duration_minutes"`
+	// Attendees replaces the attendee list; those staying keep their RSVP
+	Attendees *[]eventAttendeeRequest `json:"attendees"`
+	// An empty rrule makes the event a one-off
+	RRule   *string   `json:"rrule"`
+	ExDates *[]string `json:"exdates"`
 }
 
 func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
@@ -55,31 +67,32 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse event date and time
-	eventDate, err := parseDate(eventReq.EventDate)
+	// Parse start time
+	startsAt, err := parseDateTime(eventReq.StartsAt)
 	if err != nil {
-		http.Error(w, "invalid event_date format (use YYYY-MM-DD)", http.StatusBadRequest)
-		return
-	}
-
-	eventTime, err := parseTime(eventReq.EventTime)
-	if err != nil {
-		http.Error(w, "invalid event_time format (use HH:MM:SS)", http.StatusBadRequest)
+		http.Error(w, "invalid starts_at format (use RFC 3339)", http.StatusBadRequest)
 		return
 	}
 
 	params := services.CreateEventParams{
-		Name:           eventReq.Name,
-		Color:          eventReq.Color,
-		EventDate:      eventDate,
-		EventTime:      eventTime,
-		DurationMinute: eventReq.DurationMinute,
-		AttendeesCount: eventReq.AttendeesCount,
+		Name:            eventReq.Name,
+		Color:           eventReq.Color,
+		StartsAt:        startsAt,
+		Timezone:        eventReq.Timezone,
+		AllDay:          eventReq.AllDay,
+		DurationMinutes: eventReq.DurationMinutes,
+		Attendees:       eventAttendeeParams(eventReq.Attendees),
+		RRule:           eventReq.RRule,
+	}
+	if params.ExDates, err = parseExDates(eventReq.ExDates); err != nil {
+		http.Error(w, "invalid exdates format (use RFC 3339)", http.StatusBadRequest)
+		return
 	}
 
 	event, err := h.s.AddEvent(r.Context(), workspaceID, params)
 	if err != nil {
-		if errors.Is(err, services.ErrMissingEventFields) || errors.Is(err, services.ErrInvalidEventData) {
+		if errors.Is(err, services.ErrMissingEventFields) || errors.Is(err, services.ErrInvalidEventData) ||
+			errors.Is(err, services.ErrInvalidEventAttendee) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -112,6 +125,12 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scope, err := parseEventScope(r)
+	if err != nil {
+		http.Error(w, "invalid occurrence format (use RFC 3339)", http.StatusBadRequest)
+		return
+	}
+
 	var updateReq UpdateEventRequestData
 	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -119,35 +138,40 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := services.UpdateEventParams{
-		Name:           updateReq.Name,
-		Color:          updateReq.Color,
-		DurationMinute: updateReq.DurationMinute,
-		AttendeesCount: updateReq.AttendeesCount,
+		Name:            updateReq.Name,
+		Color:           updateReq.Color,
+		Timezone:        updateReq.Timezone,
+		AllDay:          updateReq.AllDay,
+		DurationMinutes: updateReq.DurationMinutes,
+		RRule:           updateReq.RRule,
+	}
+	if updateReq.Attendees != nil {
+		attendees := eventAttendeeParams(*updateReq.Attendees)
+		params.Attendees = &attendees
 	}
 
-	// Parse optional date
-	if updateReq.EventDate != nil {
-		eventDate, err := parseDate(*updateReq.EventDate)
+	// Parse optional start time
+	if updateReq.StartsAt != nil {
+		startsAt, err := parseDateTime(*updateReq.StartsAt)
 		if err != nil {
-			http.Error(w, "invalid event_date format (use YYYY-MM-DD)", http.StatusBadRequest)
+			http.Error(w, "invalid starts_at format (use RFC 3339)", http.StatusBadRequest)
 			return
 		}
-		params.EventDate = &eventDate
+		params.StartsAt = &startsAt
 	}
-
-	// Parse optional time
-	if updateReq.EventTime != nil {
-		eventTime, err := parseTime(*updateReq.EventTime)
+	if updateReq.ExDates != nil {
+		exdates, err := parseExDates(*updateReq.ExDates)
 		if err != nil {
-			http.Error(w, "invalid event_time format (use HH:MM:SS)", http.StatusBadRequest)
+			http.Error(w, "invalid exdates format (use RFC 3339)", http.StatusBadRequest)
 			return
 		}
-		params.EventTime = &eventTime
+		params.ExDates = &exdates
 	}
 
-	event, err := h.s.UpdateEvent(r.Context(), eventID, workspaceID, params)
+	event, err := h.s.UpdateEvent(r.Context(), eventID, workspaceID, scope, params)
 	if err != nil {
-		if errors.Is(err, services.ErrMissingEventFields) || errors.Is(err, services.ErrInvalidEventData) {
+		if errors.Is(err, services.ErrMissingEventFields) || errors.Is(err, services.ErrInvalidEventData) ||
+			errors.Is(err, services.ErrInvalidEventAttendee) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -179,8 +203,18 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.s.RemoveEvent(r.Context(), eventID, workspaceID)
+	scope, err := parseEventScope(r)
 	if err != nil {
+		http.Error(w, "invalid occurrence format (use RFC 3339)", http.StatusBadRequest)
+		return
+	}
+
+	err = h.s.RemoveEvent(r.Context(), eventID, workspaceID, scope)
+	if err != nil {
+		if errors.Is(err, services.ErrMissingEventFields) || errors.Is(err, services.ErrInvalidEventData) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		if errors.Is(err, services.ErrEventNotFound) {
 			http.Error(w, "event not found", http.StatusNotFound)
 			return
@@ -236,8 +270,54 @@ func (h *EventHandler) ListWorkspaceEvents(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	events, err := h.s.GetWorkspaceEvents(r.Context(), workspaceID)
+	// Optional window, within which recurring events are expanded,
+	// ?color= filters, which may be repeated, and ?attendee=, a member's ID,
+	// an email or "me"
+	query := r.URL.Query()
+	opts := services.EventListOptions{Colors: query["color"], Attendee: query.Get("attendee")}
+	if opts.Attendee == "me" {
+		opts.Attendee = userId
+	}
+	for name, t := range map[string]*time.Time{"from": &opts.From, "to": &opts.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := parseDateTime(value)
+			if err != nil {
+				http.Error(w, "invalid "+name+" format (use RFC 3339)", http.StatusBadRequest)
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	// ?group_by=day|week|month buckets the events in ?timezone=
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		buckets, err := h.s.GetWorkspaceEventBuckets(r.Context(), workspaceID, opts, services.EventBucketOptions{
+			Size:     groupBy,
+			Timezone: query.Get("timezone"),
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidEventData) {
+				http.Error(w, "grouping needs from before to, a valid timezone and group_by of day, week or month", http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, services.ErrInvalidWorkspaceID) {
+				http.Error(w, "invalid workspace id", http.StatusBadRequest)
+				return
+			}
+			log.Printf("Failed to fetch events: %v", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, buckets)
+		return
+	}
+
+	events, err := h.s.GetWorkspaceEvents(r.Context(), workspaceID, opts)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidEventData) {
+			http.Error(w, "from must be before to", http.StatusBadRequest)
+			return
+		}
 		log.Printf("Failed to fetch events: %v", err)
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
@@ -269,3 +349,31 @@ func (h *EventHandler) GetEventColor(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"color": color})
 }
+
+// parseEventScope reads which occurrences of a recurring event a change
+// applies to: ?scope=this|following|all, and ?occurrence= giving the
+// original start of the occurrence the change was made from
+func parseEventScope(r *http.Request) (services.EventScope, error) {
+	query := r.URL.Query()
+	scope := services.EventScope{Scope: query.Get("scope")}
+	if occurrence := query.Get("occurrence"); occurrence != "" {
+		t, err := parseDateTime(occurrence)
+		if err != nil {
+			return services.EventScope{}, err
+		}
+		scope.Occurrence = t
+	}
+	return scope, nil
+}
+
+func parseExDates(values []string) ([]time.Time, error) {
+	exdates := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		t, err := parseDateTime(value)
+		if err != nil {
+			return nil, err
+		}
+		exdates = append(exdates, t)
+	}
+	return exdates, nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+// eventAttendeeRequest names an attendee: a workspace member by user_id, or
+// anyone by email. Attendees answer for themselves.
+type eventAttendeeRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+type rsvpRequest struct {
+	// Status is needs-action, accepted, declined or tentative
+	Status string `json:"status"`
+}
+
+type attendeeLinkResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// RespondToEvent records the signed-in member's answer to an event they
+// are invited to. ?occurrence= answers for one occurrence of a recurring
+// event.
+func (h *EventHandler) RespondToEvent(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceID := r.PathValue("workspace_id")
+	eventID := r.PathValue("event_id")
+	if workspaceID == "" || eventID == "" {
+		http.Error(w, "missing workspace id or event id", http.StatusBadRequest)
+		return
+	}
+
+	occurrence, err := parseOccurrence(r)
+	if err != nil {
+		http.Error(w, "invalid occurrence format (use RFC 3339)", http.StatusBadRequest)
+		return
+	}
+	var req rsvpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	attendee, err := h.s.RespondToEvent(r.Context(), eventID, workspaceID, userId, occurrence, req.Status)
+	if err != nil {
+		handleEventAttendeeError(w, "save RSVP", err)
+		return
+	}
+
+	writeJSON(w, attendee)
+}
+
+// CreateAttendeeLink returns a link through which an attendee outside the
+// workspace answers, replacing any previous one. The link is only shown
+// once.
+func (h *EventHandler) CreateAttendeeLink(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceID := r.PathValue("workspace_id")
+	eventID := r.PathValue("event_id")
+	attendeeID := r.PathValue("attendee_id")
+	if workspaceID == "" || eventID == "" || attendeeID == "" {
+		http.Error(w, "missing workspace id, event id or attendee id", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.s.CreateAttendeeLink(r.Context(), eventID, workspaceID, attendeeID)
+	if err != nil {
+		handleEventAttendeeError(w, "create RSVP link", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attendeeLinkResponse{
+		Token: token,
+		URL:   requestBaseURL(r) + "/event-invitations/" + token,
+	})
+}
+
+// GetInvitation shows the event of an RSVP link, which needs no sign-in
+func (h *EventHandler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.s.GetInvitation(r.Context(), r.PathValue("token"))
+
+	// The token is in the URL
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err != nil {
+		handleEventAttendeeError(w, "get invitation", err)
+		return
+	}
+
+	writeJSON(w, invitation)
+}
+
+// RespondToInvitation records the answer given through an RSVP link.
+// ?occurrence= answers for one occurrence of a recurring event.
+func (h *EventHandler) RespondToInvitation(w http.ResponseWriter, r *http.Request) {
+	occurrence, err := parseOccurrence(r)
+	if err != nil {
+		http.Error(w, "invalid occurrence format (use RFC 3339)", http.StatusBadRequest)
+		return
+	}
+	var req rsvpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	attendee, err := h.s.RespondToInvitation(r.Context(), r.PathValue("token"), occurrence, req.Status)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err != nil {
+		handleEventAttendeeError(w, "save RSVP", err)
+		return
+	}
+
+	writeJSON(w, attendee)
+}
+
+func eventAttendeeParams(attendees []eventAttendeeRequest) []services.EventAttendeeParams {
+	params := make([]services.EventAttendeeParams, len(attendees))
+	for i, a := range attendees {
+		params[i] = services.EventAttendeeParams{UserID: a.UserID, Email: a.Email}
+	}
+	return params
+}
+
+// parseOccurrence reads ?occurrence=, the original start of an occurrence
+// of a recurring event
+func parseOccurrence(r *http.Request) (time.Time, error) {
+	value := r.URL.Query().Get("occurrence")
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return parseDateTime(value)
+}
+
+func handleEventAttendeeError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEventAttendee), errors.Is(err, services.ErrInvalidEventData),
+		errors.Is(err, services.ErrMissingEventFields), errors.Is(err, services.ErrInvalidWorkspaceID):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrEventAttendeeNotFound), errors.Is(err, services.ErrEventNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
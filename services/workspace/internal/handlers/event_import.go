@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+const maxEventImportSize = 10 << 20
+
+// ImportEvents creates or updates events from an uploaded iCalendar (.ics)
+// file, sent either as the "file" field of a multipart form or as the
+// request body. ?color= is given to new events without a color of their
+// own.
+func (h *EventHandler) ImportEvents(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxEventImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			handleImportReadError(w, err)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		handleImportReadError(w, err)
+		return
+	}
+
+	report, err := h.s.ImportEvents(r.Context(), workspaceID, bytes.NewReader(data), services.EventImportOptions{
+		Color: r.URL.Query().Get("color"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidEventImport), errors.Is(err, services.ErrInvalidWorkspaceID):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrEventImportTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrEventNotFound):
+			http.Error(w, "workspace not found", http.StatusNotFound)
+		default:
+			log.Printf("Failed to import events: %v", err)
+			http.Error(w, "failed to import events", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, report)
+}
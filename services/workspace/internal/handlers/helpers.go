@@ -9,12 +9,11 @@ func parseDate(dateStr string) (time.Time, error) {
 	return time.Parse("2006-01-02", dateStr)
 }
 
-func parseTime(timeStr string) (time.Time, error) {
-	// Parse time format: HH:MM:SS
-	t, err := time.Parse("15:04:05", timeStr)
+func parseDateTime(value string) (time.Time, error) {
+	// Parse RFC 3339 with a zone offset, or a plain date as midnight UTC
+	t, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		// Try alternate format without seconds: HH:MM
-		t, err = time.Parse("15:04", timeStr)
+		t, err = parseDate(value)
 	}
 	return t, err
 }
@@ -3,8 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
 	"github.com/tomasohchom/motion/services/workspace/internal/services"
 )
 
@@ -17,19 +22,42 @@ func NewNoteHandler(service services.NoteServicer) *NoteHandler {
 }
 
 type createNoteRequest struct {
-	AuthorID string   `json:"author_id"`
 	Title    string   `json:"title"`
 	Content  string   `json:"content"`
 	Tags     []string `json:"tags"`
+	ParentID string   `json:"parent_id"`
+	// Visibility is workspace, private or restricted; it defaults to
+	// workspace
+	Visibility string `json:"visibility"`
+	// ExtractTasks turns unchecked "- [ ]" items into tasks
+	ExtractTasks bool `json:"extract_tasks"`
 }
 
 type updateNoteRequest struct {
-	Title   *string   `json:"title"`
-	Content *string   `json:"content"`
-	Tags    *[]string `json:"tags"`
+	Title        *string   `json:"title"`
+	Content      *string   `json:"content"`
+	Tags         *[]string `json:"tags"`
+	ExtractTasks bool      `json:"extract_tasks"`
 }
 
+type moveNoteRequest struct {
+	ParentID *string `json:"parent_id"`
+	Position *int    `json:"position"`
+}
+
+type reorderNotesRequest struct {
+	ParentID *string  `json:"parent_id"`
+	NoteIDs  []string `json:"note_ids"`
+}
+
+// CreateNote creates a note authored, and so owned, by the signed-in member
 func (h *NoteHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
+	authorID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || authorID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	workspaceID := r.PathValue("workspace_id")
 	if workspaceID == "" {
 		http.Error(w, "missing workspace id", http.StatusBadRequest)
@@ -43,20 +71,28 @@ func (h *NoteHandler) CreateNote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	note, err := h.s.CreateNote(r.Context(), services.CreateNoteInput{
-		WorkspaceID: workspaceID,
-		AuthorID:    req.AuthorID,
-		Title:       req.Title,
-		Content:     req.Content,
-		Tags:        req.Tags,
+		WorkspaceID:  workspaceID,
+		AuthorID:     authorID,
+		Title:        req.Title,
+		Content:      req.Content,
+		Tags:         req.Tags,
+		ParentID:     req.ParentID,
+		Visibility:   req.Visibility,
+		ExtractTasks: req.ExtractTasks,
 	})
 	if err != nil {
 		handleNoteError(w, err)
 		return
 	}
 
+	setNoteETag(w, note)
 	writeJSON(w, note)
 }
 
+// ListNotes returns notes most recently updated first, optionally filtered
+// by repeated tag parameters: notes must carry all of them, or any of them
+// with match=any. With ?mode=tree they are nested under their parents
+// instead, and ?mode=roots lists only the top level.
 func (h *NoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
 	workspaceID := r.PathValue("workspace_id")
 	if workspaceID == "" {
@@ -64,7 +100,33 @@ func (h *NoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	notes, err := h.s.ListNotes(r.Context(), workspaceID)
+	query := r.URL.Query()
+	filter := services.NoteTagFilter{Tags: query["tag"]}
+	switch query.Get("match") {
+	case "", "all":
+	case "any":
+		filter.MatchAny = true
+	default:
+		http.Error(w, "invalid match (expected any or all)", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		notes any
+		err   error
+	)
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	switch query.Get("mode") {
+	case "":
+		notes, err = h.s.ListNotes(r.Context(), workspaceID, viewerID, filter)
+	case "tree":
+		notes, err = h.s.ListNoteTree(r.Context(), workspaceID, viewerID)
+	case "roots":
+		notes, err = h.s.ListNoteChildren(r.Context(), workspaceID, "", viewerID)
+	default:
+		http.Error(w, "invalid mode (expected tree or roots)", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		handleNoteError(w, err)
 		return
@@ -73,6 +135,137 @@ func (h *NoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, notes)
 }
 
+func (h *NoteHandler) ListChildren(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	children, err := h.s.ListNoteChildren(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, children)
+}
+
+func (h *NoteHandler) GetBreadcrumbs(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	crumbs, err := h.s.GetBreadcrumbs(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, crumbs)
+}
+
+// MoveNote moves a note and its subtree. A null or missing parent_id moves
+// it to the top level and a missing position appends it.
+func (h *NoteHandler) MoveNote(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req moveNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	input := services.MoveNoteInput{EditorID: editorID, Position: req.Position}
+	if req.ParentID != nil {
+		input.ParentID = *req.ParentID
+	}
+	note, err := h.s.MoveNote(r.Context(), workspaceID, noteID, input)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	setNoteETag(w, note)
+	writeJSON(w, note)
+}
+
+// ReorderNotes sets the order of the children of parent_id, or of the top
+// level notes when it is null.
+func (h *NoteHandler) ReorderNotes(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req reorderNotesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	parentID := ""
+	if req.ParentID != nil {
+		parentID = *req.ParentID
+	}
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	children, err := h.s.ReorderNotes(r.Context(), workspaceID, parentID, editorID, req.NoteIDs)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, children)
+}
+
+// SearchNotes handles ?q= with optional repeated tag and an author_id
+// filter. Results must carry every given tag.
+func (h *NoteHandler) SearchNotes(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	input := services.NoteSearchInput{
+		ViewerID: viewerID,
+		Query:    query.Get("q"),
+		Tags:     query["tag"],
+		AuthorID: query.Get("author_id"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		input.Limit = n
+	}
+
+	results, err := h.s.SearchNotes(r.Context(), workspaceID, input)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, results)
+}
+
 func (h *NoteHandler) GetNote(w http.ResponseWriter, r *http.Request) {
 	workspaceID := r.PathValue("workspace_id")
 	noteID := r.PathValue("note_id")
@@ -81,12 +274,14 @@ func (h *NoteHandler) GetNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	note, err := h.s.GetNote(r.Context(), workspaceID, noteID)
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	note, err := h.s.GetNote(r.Context(), workspaceID, noteID, viewerID)
 	if err != nil {
 		handleNoteError(w, err)
 		return
 	}
 
+	setNoteETag(w, note)
 	writeJSON(w, note)
 }
 
@@ -98,22 +293,33 @@ func (h *NoteHandler) UpdateNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var req updateNoteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
 
+	editorID, _ := middleware.UserIDFromContext(r.Context())
 	note, err := h.s.UpdateNote(r.Context(), workspaceID, noteID, services.UpdateNoteInput{
-		Title:   req.Title,
-		Content: req.Content,
-		Tags:    req.Tags,
+		EditorID:        editorID,
+		ExpectedVersion: expectedVersion,
+		Title:           req.Title,
+		Content:         req.Content,
+		Tags:            req.Tags,
+		ExtractTasks:    req.ExtractTasks,
 	})
 	if err != nil {
 		handleNoteError(w, err)
 		return
 	}
 
+	setNoteETag(w, note)
 	writeJSON(w, note)
 }
 
@@ -125,7 +331,18 @@ func (h *NoteHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.s.DeleteNote(r.Context(), workspaceID, noteID); err != nil {
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	if err := h.s.DeleteNote(r.Context(), workspaceID, noteID, services.DeleteNoteInput{
+		EditorID:        editorID,
+		ExpectedVersion: expectedVersion,
+		Children:        r.URL.Query().Get("children"),
+	}); err != nil {
 		handleNoteError(w, err)
 		return
 	}
@@ -133,18 +350,267 @@ func (h *NoteHandler) DeleteNote(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type noteAccessRequest struct {
+	Visibility string                     `json:"visibility"`
+	Members    []services.NoteMemberInput `json:"members"`
+}
+
+// GetNoteAccess returns the note's visibility and members, along with the
+// caller's role
+func (h *NoteHandler) GetNoteAccess(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	access, err := h.s.GetNoteAccess(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, access)
+}
+
+// UpdateNoteAccess sets the note's visibility and replaces its members. Only
+// the note's author may call it.
+func (h *NoteHandler) UpdateNoteAccess(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req noteAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	access, err := h.s.UpdateNoteAccess(r.Context(), workspaceID, noteID, userID, services.UpdateNoteAccessInput{
+		Visibility: req.Visibility,
+		Members:    req.Members,
+	})
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, access)
+}
+
+func (h *NoteHandler) ListBacklinks(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	backlinks, err := h.s.ListBacklinks(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, backlinks)
+}
+
+func (h *NoteHandler) ListBrokenLinks(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	links, err := h.s.ListBrokenLinks(r.Context(), workspaceID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, links)
+}
+
+func (h *NoteHandler) GetNoteGraph(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	graph, err := h.s.GetNoteGraph(r.Context(), workspaceID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, graph)
+}
+
+func (h *NoteHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	revisions, err := h.s.ListRevisions(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, revisions)
+}
+
+func (h *NoteHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := parseRevision(r.PathValue("revision"))
+	if err != nil {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	rev, err := h.s.GetRevision(r.Context(), workspaceID, noteID, viewerID, revision)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, rev)
+}
+
+// DiffRevisions compares the revisions given by the from and to query
+// parameters.
+func (h *NoteHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseRevision(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from revision", http.StatusBadRequest)
+		return
+	}
+	to, err := parseRevision(r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to revision", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	diff, err := h.s.DiffRevisions(r.Context(), workspaceID, noteID, viewerID, from, to)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, diff)
+}
+
+func (h *NoteHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	revision, err := parseRevision(r.PathValue("revision"))
+	if err != nil {
+		http.Error(w, "invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	note, err := h.s.RestoreRevision(r.Context(), workspaceID, noteID, revision, editorID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	setNoteETag(w, note)
+	writeJSON(w, note)
+}
+
+func parseRevision(value string) (int32, error) {
+	revision, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || revision < 1 {
+		return 0, errors.New("invalid revision")
+	}
+	return int32(revision), nil
+}
+
+func setNoteETag(w http.ResponseWriter, note models.Note) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, note.Version))
+}
+
+// parseIfMatch reads the note version from an If-Match header. It returns
+// nil when the header is absent or "*".
+func parseIfMatch(r *http.Request) (*int32, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	value = strings.TrimPrefix(value, "W/")
+	version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid If-Match header")
+	}
+	v := int32(version)
+	return &v, nil
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(v)
 }
 
 func handleNoteError(w http.ResponseWriter, err error) {
+	var conflict *services.NoteConflictError
+	if errors.As(err, &conflict) {
+		// Send back what is on the server so the client can merge
+		setNoteETag(w, conflict.Current)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(conflict.Current)
+		return
+	}
+
 	switch {
 	case errors.Is(err, services.ErrMissingNoteFields):
 		http.Error(w, err.Error(), http.StatusBadRequest)
-	case errors.Is(err, services.ErrInvalidNoteData):
+	case errors.Is(err, services.ErrInvalidNoteData), errors.Is(err, services.ErrParentNoteNotFound), errors.Is(err, services.ErrInvalidNoteImport),
+		errors.Is(err, services.ErrInvalidNoteMember), errors.Is(err, services.ErrInvalidNoteComment):
 		http.Error(w, err.Error(), http.StatusBadRequest)
-	case errors.Is(err, services.ErrNoteNotFound):
+	case errors.Is(err, services.ErrNoteForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrNoteImportTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, services.ErrNoteCycle), errors.Is(err, services.ErrTagExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrNoteNotFound), errors.Is(err, services.ErrNoteRevisionNotFound), errors.Is(err, services.ErrNoteCommentNotFound):
 		http.Error(w, err.Error(), http.StatusNotFound)
 	default:
 		http.Error(w, "internal server error", http.StatusInternalServerError)
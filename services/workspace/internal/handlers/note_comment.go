@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type createCommentThreadRequest struct {
+	Body string `json:"body"`
+	// Either start and end, in characters, or block_id anchors the thread
+	Start   *int   `json:"start"`
+	End     *int   `json:"end"`
+	BlockID string `json:"block_id"`
+}
+
+type replyCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// ListCommentThreads returns the note's comment threads. ?status=open or
+// ?status=resolved narrows them down.
+func (h *NoteHandler) ListCommentThreads(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var resolved *bool
+	switch r.URL.Query().Get("status") {
+	case "", "all":
+	case "open":
+		resolved = new(bool)
+	case "resolved":
+		resolved = new(bool)
+		*resolved = true
+	default:
+		http.Error(w, "invalid status (expected open, resolved or all)", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	threads, err := h.s.ListCommentThreads(r.Context(), workspaceID, noteID, viewerID, resolved)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, threads)
+}
+
+// CreateCommentThread starts a thread. An If-Match header names the note
+// version the anchor was taken from, so stale offsets are refused.
+func (h *NoteHandler) CreateCommentThread(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req createCommentThreadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	authorID, _ := middleware.UserIDFromContext(r.Context())
+	thread, err := h.s.CreateCommentThread(r.Context(), workspaceID, noteID, services.CreateNoteCommentInput{
+		AuthorID:        authorID,
+		Body:            req.Body,
+		Start:           req.Start,
+		End:             req.End,
+		BlockID:         req.BlockID,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(thread)
+}
+
+func (h *NoteHandler) ReplyToCommentThread(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	threadID := r.PathValue("thread_id")
+	if workspaceID == "" || noteID == "" || threadID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req replyCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	authorID, _ := middleware.UserIDFromContext(r.Context())
+	comment, err := h.s.ReplyToCommentThread(r.Context(), workspaceID, noteID, threadID, authorID, req.Body)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (h *NoteHandler) ResolveCommentThread(w http.ResponseWriter, r *http.Request) {
+	h.setCommentThreadResolved(w, r, true)
+}
+
+func (h *NoteHandler) UnresolveCommentThread(w http.ResponseWriter, r *http.Request) {
+	h.setCommentThreadResolved(w, r, false)
+}
+
+func (h *NoteHandler) setCommentThreadResolved(w http.ResponseWriter, r *http.Request, resolved bool) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	threadID := r.PathValue("thread_id")
+	if workspaceID == "" || noteID == "" || threadID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	thread, err := h.s.ResolveCommentThread(r.Context(), workspaceID, noteID, threadID, userID, resolved)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, thread)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+)
+
+const maxNoteImportSize = 50 << 20
+
+func (h *NoteHandler) RenderNote(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	rendered, err := h.s.RenderNote(r.Context(), workspaceID, noteID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, rendered)
+}
+
+// ExportNotes downloads every note the caller can see as a zip of Markdown
+// files and HTML pages
+func (h *NoteHandler) ExportNotes(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	// Build the archive first so a failure can still be reported
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	var buf bytes.Buffer
+	if err := h.s.ExportNotes(r.Context(), workspaceID, viewerID, &buf); err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	filename := fmt.Sprintf("notes-%s.zip", time.Now().UTC().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Write(buf.Bytes())
+}
+
+// ImportNotes creates notes from an uploaded zip of Markdown files, sent
+// either as the "file" field of a multipart form or as the request body
+func (h *NoteHandler) ImportNotes(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxNoteImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			handleImportReadError(w, err)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		handleImportReadError(w, err)
+		return
+	}
+
+	report, err := h.s.ImportNotes(r.Context(), workspaceID, userID, bytes.NewReader(data), int64(len(data)))
+	if err != nil && len(report.Notes) > 0 {
+		// Tell the client which notes exist so it does not import them twice
+		log.Printf("Failed to import notes: %v", err)
+		report.Error = "import stopped partway; only the listed notes were created"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, report)
+}
+
+func handleImportReadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "import file is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "invalid import upload", http.StatusBadRequest)
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+// sharePasswordHeader carries the password for protected share links, so it
+// stays out of URLs and access logs
+const sharePasswordHeader = "X-Share-Password"
+
+var sharedNotePage = template.Must(template.New("shared_note").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+</head>
+<body>
+<article>
+<h1>{{.Title}}</h1>
+{{.Content}}
+</article>
+</body>
+</html>
+`))
+
+type NoteShareHandler struct {
+	s services.NoteShareServicer
+}
+
+func NewNoteShareHandler(service services.NoteShareServicer) *NoteShareHandler {
+	return &NoteShareHandler{s: service}
+}
+
+type createShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Password  string     `json:"password"`
+}
+
+func (h *NoteShareHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req createShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	share, err := h.s.CreateShare(r.Context(), workspaceID, noteID, services.CreateShareInput{
+		CreatedBy: userID,
+		ExpiresAt: req.ExpiresAt,
+		Password:  req.Password,
+	})
+	if err != nil {
+		handleShareError(w, "create share link", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(share)
+}
+
+func (h *NoteShareHandler) ListShares(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	if workspaceID == "" || noteID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	shares, err := h.s.ListShares(r.Context(), workspaceID, noteID, userID)
+	if err != nil {
+		handleShareError(w, "list share links", err)
+		return
+	}
+
+	writeJSON(w, shares)
+}
+
+func (h *NoteShareHandler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	shareID := r.PathValue("share_id")
+	if workspaceID == "" || noteID == "" || shareID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	if err := h.s.RevokeShare(r.Context(), workspaceID, noteID, shareID, userID); err != nil {
+		handleShareError(w, "revoke share link", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NoteShareHandler) ListShareAccesses(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	noteID := r.PathValue("note_id")
+	shareID := r.PathValue("share_id")
+	if workspaceID == "" || noteID == "" || shareID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	accesses, err := h.s.ListShareAccesses(r.Context(), workspaceID, noteID, shareID, userID)
+	if err != nil {
+		handleShareError(w, "list share link accesses", err)
+		return
+	}
+
+	writeJSON(w, accesses)
+}
+
+// GetSharedNote serves a note through its share link without requiring a
+// signed-in user. It returns an HTML page by default, or the raw Markdown
+// with ?format=markdown. Protected links expect the password in the
+// X-Share-Password header.
+func (h *NoteShareHandler) GetSharedNote(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = services.ShareFormatHTML
+	}
+
+	note, err := h.s.OpenShare(r.Context(), r.PathValue("token"), r.Header.Get(sharePasswordHeader), services.ShareAccess{
+		Format:    format,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+
+	// Links can be revoked at any time, and the token is in the URL
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err != nil {
+		handleShareError(w, "open share link", err)
+		return
+	}
+
+	if format == services.ShareFormatMarkdown {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(note.Content))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'")
+	sharedNotePage.Execute(w, struct {
+		Title   string
+		Content template.HTML
+	}{
+		Title: note.Title,
+		// Already sanitized by the service
+		Content: template.HTML(note.Content),
+	})
+}
+
+// clientIP returns the address a request came from, preferring the first
+// X-Forwarded-For entry set by a proxy. It is only used for access logs.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func handleShareError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidShareData), errors.Is(err, services.ErrInvalidNoteData), errors.Is(err, services.ErrMissingNoteFields):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrSharePasswordRequired), errors.Is(err, services.ErrShareWrongPassword):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrNoteForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrShareNotFound), errors.Is(err, services.ErrNoteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrShareExpired), errors.Is(err, services.ErrShareRevoked):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+)
+
+type renameTagRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type mergeTagsRequest struct {
+	Tags []string `json:"tags"`
+	Into string   `json:"into"`
+}
+
+func (h *NoteHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	viewerID, _ := middleware.UserIDFromContext(r.Context())
+	tags, err := h.s.ListTags(r.Context(), workspaceID, viewerID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, tags)
+}
+
+func (h *NoteHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req renameTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	result, err := h.s.RenameTag(r.Context(), workspaceID, req.From, req.To, editorID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, result)
+}
+
+func (h *NoteHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req mergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	editorID, _ := middleware.UserIDFromContext(r.Context())
+	result, err := h.s.MergeTags(r.Context(), workspaceID, req.Tags, req.Into, editorID)
+	if err != nil {
+		handleNoteError(w, err)
+		return
+	}
+
+	writeJSON(w, result)
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type ProjectHandler struct {
+	s services.ProjectServicer
+}
+
+func NewProjectHandler(service services.ProjectServicer) *ProjectHandler {
+	return &ProjectHandler{s: service}
+}
+
+type createProjectRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	LeadID      string `json:"lead_id"`
+	TargetDate  string `json:"target_date"`
+}
+
+// An empty target_date clears it; omitting it leaves it unchanged.
+type updateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Status      *string `json:"status"`
+	LeadID      *string `json:"lead_id"`
+	TargetDate  *string `json:"target_date"`
+}
+
+type createMilestoneRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TargetDate  string `json:"target_date"`
+}
+
+type updateMilestoneRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	TargetDate  *string `json:"target_date"`
+}
+
+type assignTaskProjectRequest struct {
+	ProjectID   string `json:"project_id"`
+	MilestoneID string `json:"milestone_id"`
+}
+
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req createProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	targetDate, err := parseOptionalDate(req.TargetDate)
+	if err != nil {
+		http.Error(w, "invalid target_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.s.CreateProject(r.Context(), workspaceID, services.ProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		LeadID:      req.LeadID,
+		TargetDate:  targetDate,
+	})
+	if err != nil {
+		handleProjectError(w, "create project", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(project)
+}
+
+func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	projects, err := h.s.ListProjects(r.Context(), workspaceID)
+	if err != nil {
+		handleProjectError(w, "list projects", err)
+		return
+	}
+
+	writeJSON(w, projects)
+}
+
+func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	if workspaceID == "" || projectID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.s.GetProject(r.Context(), workspaceID, projectID)
+	if err != nil {
+		handleProjectError(w, "get project", err)
+		return
+	}
+
+	writeJSON(w, project)
+}
+
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	if workspaceID == "" || projectID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req updateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input := services.UpdateProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		LeadID:      req.LeadID,
+	}
+	if req.TargetDate != nil {
+		targetDate, err := parseOptionalDate(*req.TargetDate)
+		if err != nil {
+			http.Error(w, "invalid target_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		input.TargetDate = targetDate
+		input.ClearTargetDate = targetDate == nil
+	}
+
+	project, err := h.s.UpdateProject(r.Context(), workspaceID, projectID, input)
+	if err != nil {
+		handleProjectError(w, "update project", err)
+		return
+	}
+
+	writeJSON(w, project)
+}
+
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	if workspaceID == "" || projectID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteProject(r.Context(), workspaceID, projectID); err != nil {
+		handleProjectError(w, "delete project", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) CreateMilestone(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	if workspaceID == "" || projectID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req createMilestoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	targetDate, err := parseOptionalDate(req.TargetDate)
+	if err != nil {
+		http.Error(w, "invalid target_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	milestone, err := h.s.CreateMilestone(r.Context(), workspaceID, projectID, services.MilestoneInput{
+		Name:        req.Name,
+		Description: req.Description,
+		TargetDate:  targetDate,
+	})
+	if err != nil {
+		handleProjectError(w, "create milestone", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(milestone)
+}
+
+func (h *ProjectHandler) UpdateMilestone(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	milestoneID := r.PathValue("milestone_id")
+	if workspaceID == "" || projectID == "" || milestoneID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req updateMilestoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input := services.UpdateMilestoneInput{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if req.TargetDate != nil {
+		targetDate, err := parseOptionalDate(*req.TargetDate)
+		if err != nil {
+			http.Error(w, "invalid target_date format (expected YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		input.TargetDate = targetDate
+		input.ClearTargetDate = targetDate == nil
+	}
+
+	milestone, err := h.s.UpdateMilestone(r.Context(), workspaceID, projectID, milestoneID, input)
+	if err != nil {
+		handleProjectError(w, "update milestone", err)
+		return
+	}
+
+	writeJSON(w, milestone)
+}
+
+func (h *ProjectHandler) DeleteMilestone(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	milestoneID := r.PathValue("milestone_id")
+	if workspaceID == "" || projectID == "" || milestoneID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteMilestone(r.Context(), workspaceID, projectID, milestoneID); err != nil {
+		handleProjectError(w, "delete milestone", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) ListProjectTasks(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	projectID := r.PathValue("project_id")
+	if workspaceID == "" || projectID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	tasks, err := h.s.ListProjectTasks(r.Context(), workspaceID, projectID)
+	if err != nil {
+		handleProjectError(w, "list project tasks", err)
+		return
+	}
+
+	writeJSON(w, tasks)
+}
+
+func (h *ProjectHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	var req assignTaskProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	task, err := h.s.AssignTask(r.Context(), taskID, req.ProjectID, req.MilestoneID)
+	if err != nil {
+		handleProjectError(w, "assign task to project", err)
+		return
+	}
+
+	writeJSON(w, task)
+}
+
+// parseOptionalDate returns nil for an empty string.
+func parseOptionalDate(dateStr string) (*time.Time, error) {
+	if dateStr == "" {
+		return nil, nil
+	}
+	date, err := parseDate(dateStr)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+func handleProjectError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidProjectData), errors.Is(err, services.ErrInvalidTaskData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrMilestoneNotFound),
+		errors.Is(err, services.ErrTaskNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -33,12 +33,13 @@ func (h *TaskHandler) CreateNewTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		AssigneeID  string    `json:"assignee_id"`
-		Status      string    `json:"status"`
-		Priority    string    `json:"priority"`
-		DueDate     time.Time `json:"due_date"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		AssigneeID  string     `json:"assignee_id"`
+		Status      string     `json:"status"`
+		Priority    string     `json:"priority"`
+		DueDate     time.Time  `json:"due_date"`
+		StartDate   *time.Time `json:"start_date"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -46,8 +47,12 @@ func (h *TaskHandler) CreateNewTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	task, err := h.s.CreateNewTask(r.Context(), workspaceId, req.Title,
-		req.Description, req.AssigneeID, req.Status, req.Priority, req.DueDate)
+		req.Description, req.AssigneeID, req.Status, req.Priority, req.DueDate, req.StartDate)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidTaskData) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("Failed to create task: %v", err)
 		http.Error(w, "failed to create task", http.StatusInternalServerError)
 		return
@@ -108,12 +113,13 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		AssigneeID  string    `json:"assignee_id"`
-		Status      string    `json:"status"`
-		Priority    string    `json:"priority"`
-		DueDate     time.Time `json:"due_date"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		AssigneeID  string     `json:"assignee_id"`
+		Status      string     `json:"status"`
+		Priority    string     `json:"priority"`
+		DueDate     time.Time  `json:"due_date"`
+		StartDate   *time.Time `json:"start_date"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -121,12 +127,15 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	task, err := h.s.UpdateTask(r.Context(), taskId, req.Title,
-		req.Description, req.AssigneeID, req.Status, req.Priority, req.DueDate)
+		req.Description, req.AssigneeID, req.Status, req.Priority, req.DueDate, req.StartDate)
 	if err != nil {
 		switch {
 		case errors.Is(err, services.ErrTaskNotFound):
 			http.Error(w, "task not found", http.StatusNotFound)
 			return
+		case errors.Is(err, services.ErrInvalidTaskData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		default:
 			log.Printf("Failed to update task: %v", err)
 			http.Error(w, "failed to update task", http.StatusInternalServerError)
@@ -160,3 +169,139 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *TaskHandler) ListTaskComments(w http.ResponseWriter, r *http.Request) {
+	taskId := r.PathValue("task_id")
+	if taskId == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.s.ListTaskComments(r.Context(), taskId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaskNotFound):
+			http.Error(w, "task not found", http.StatusNotFound)
+			return
+		default:
+			log.Printf("Failed to list task comments: %v", err)
+			http.Error(w, "failed to list task comments", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+func (h *TaskHandler) AddTaskComment(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	taskId := r.PathValue("task_id")
+	if taskId == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.s.AddTaskComment(r.Context(), taskId, userId, req.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTaskData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, services.ErrTaskNotFound):
+			http.Error(w, "task not found", http.StatusNotFound)
+			return
+		default:
+			log.Printf("Failed to add task comment: %v", err)
+			http.Error(w, "failed to add task comment", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+func (h *TaskHandler) ListTaskDependencies(w http.ResponseWriter, r *http.Request) {
+	taskId := r.PathValue("task_id")
+	if taskId == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	dependencies, err := h.s.ListTaskDependencies(r.Context(), taskId)
+	if err != nil {
+		handleTaskDependencyError(w, "list task dependencies", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dependencies)
+}
+
+func (h *TaskHandler) AddTaskDependency(w http.ResponseWriter, r *http.Request) {
+	taskId := r.PathValue("task_id")
+	if taskId == "" {
+		http.Error(w, "missing task id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DependsOnID string `json:"depends_on_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.AddTaskDependency(r.Context(), taskId, req.DependsOnID); err != nil {
+		handleTaskDependencyError(w, "add task dependency", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskHandler) RemoveTaskDependency(w http.ResponseWriter, r *http.Request) {
+	taskId := r.PathValue("task_id")
+	dependsOnId := r.PathValue("depends_on_id")
+	if taskId == "" || dependsOnId == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.RemoveTaskDependency(r.Context(), taskId, dependsOnId); err != nil {
+		handleTaskDependencyError(w, "remove task dependency", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleTaskDependencyError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTaskData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrTaskDependencyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrTaskDependencyCycle):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type TaskViewHandler struct {
+	s services.TaskViewServicer
+}
+
+func NewTaskViewHandler(service services.TaskViewServicer) *TaskViewHandler {
+	return &TaskViewHandler{s: service}
+}
+
+type createTaskViewRequest struct {
+	Name       string                   `json:"name"`
+	Visibility string                   `json:"visibility"`
+	Filters    services.TaskViewFilters `json:"filters"`
+	Sort       []services.TaskViewSort  `json:"sort"`
+	GroupBy    string                   `json:"group_by"`
+	Columns    []string                 `json:"columns"`
+}
+
+type updateTaskViewRequest struct {
+	Name       *string                   `json:"name"`
+	Visibility *string                   `json:"visibility"`
+	Filters    *services.TaskViewFilters `json:"filters"`
+	Sort       *[]services.TaskViewSort  `json:"sort"`
+	GroupBy    *string                   `json:"group_by"`
+	Columns    *[]string                 `json:"columns"`
+}
+
+func (h *TaskViewHandler) CreateTaskView(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	if workspaceId == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req createTaskViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.s.CreateTaskView(r.Context(), workspaceId, userId, services.TaskViewInput{
+		Name:       req.Name,
+		Visibility: req.Visibility,
+		Filters:    req.Filters,
+		Sort:       req.Sort,
+		GroupBy:    req.GroupBy,
+		Columns:    req.Columns,
+	})
+	if err != nil {
+		handleTaskViewError(w, "create task view", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(view)
+}
+
+func (h *TaskViewHandler) ListTaskViews(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	if workspaceId == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	views, err := h.s.ListTaskViews(r.Context(), workspaceId, userId)
+	if err != nil {
+		handleTaskViewError(w, "list task views", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(views)
+}
+
+func (h *TaskViewHandler) GetTaskView(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	viewId := r.PathValue("view_id")
+	if workspaceId == "" || viewId == "" {
+		http.Error(w, "missing workspace id or view id", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.s.GetTaskView(r.Context(), workspaceId, viewId, userId)
+	if err != nil {
+		handleTaskViewError(w, "get task view", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+func (h *TaskViewHandler) UpdateTaskView(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	viewId := r.PathValue("view_id")
+	if workspaceId == "" || viewId == "" {
+		http.Error(w, "missing workspace id or view id", http.StatusBadRequest)
+		return
+	}
+
+	var req updateTaskViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	view, err := h.s.UpdateTaskView(r.Context(), workspaceId, viewId, userId, services.UpdateTaskViewInput{
+		Name:       req.Name,
+		Visibility: req.Visibility,
+		Filters:    req.Filters,
+		Sort:       req.Sort,
+		GroupBy:    req.GroupBy,
+		Columns:    req.Columns,
+	})
+	if err != nil {
+		handleTaskViewError(w, "update task view", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+func (h *TaskViewHandler) DeleteTaskView(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	viewId := r.PathValue("view_id")
+	if workspaceId == "" || viewId == "" {
+		http.Error(w, "missing workspace id or view id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteTaskView(r.Context(), workspaceId, viewId, userId); err != nil {
+		handleTaskViewError(w, "delete task view", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TaskViewHandler) ExecuteTaskView(w http.ResponseWriter, r *http.Request) {
+	userId, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userId == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	workspaceId := r.PathValue("workspaceId")
+	viewId := r.PathValue("view_id")
+	if workspaceId == "" || viewId == "" {
+		http.Error(w, "missing workspace id or view id", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.s.ExecuteTaskView(r.Context(), workspaceId, viewId, userId)
+	if err != nil {
+		handleTaskViewError(w, "execute task view", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handleTaskViewError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTaskViewData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTaskViewNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrTaskViewAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/middleware"
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type TemplateHandler struct {
+	s services.TemplateServicer
+}
+
+func NewTemplateHandler(service services.TemplateServicer) *TemplateHandler {
+	return &TemplateHandler{s: service}
+}
+
+type createTemplateRequest struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Tags       []string `json:"tags"`
+	Checklist  []string `json:"checklist"`
+	Priority   string   `json:"priority"`
+	AssigneeID string   `json:"assignee_id"`
+	DueInDays  *int     `json:"due_in_days"`
+}
+
+// A null due_in_days clears it; omitting it leaves it unchanged.
+type updateTemplateRequest struct {
+	Name       *string         `json:"name"`
+	Title      *string         `json:"title"`
+	Content    *string         `json:"content"`
+	Tags       *[]string       `json:"tags"`
+	Checklist  *[]string       `json:"checklist"`
+	Priority   *string         `json:"priority"`
+	AssigneeID *string         `json:"assignee_id"`
+	DueInDays  json.RawMessage `json:"due_in_days"`
+}
+
+type instantiateTemplateRequest struct {
+	// Timezone is an IANA name such as "Europe/Paris"
+	Timezone string `json:"timezone"`
+	// Values fill in custom {{placeholders}}
+	Values     map[string]string `json:"values"`
+	ParentID   string            `json:"parent_id"`
+	AssigneeID string            `json:"assignee_id"`
+}
+
+func (h *TemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	var req createTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	template, err := h.s.CreateTemplate(r.Context(), workspaceID, userID, services.TemplateInput{
+		Kind:       req.Kind,
+		Name:       req.Name,
+		Title:      req.Title,
+		Content:    req.Content,
+		Tags:       req.Tags,
+		Checklist:  req.Checklist,
+		Priority:   req.Priority,
+		AssigneeID: req.AssigneeID,
+		DueInDays:  req.DueInDays,
+	})
+	if err != nil {
+		handleTemplateError(w, "create template", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// ListTemplates returns the workspace's templates. ?kind=note or ?kind=task
+// narrows them down.
+func (h *TemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	templates, err := h.s.ListTemplates(r.Context(), workspaceID, r.URL.Query().Get("kind"))
+	if err != nil {
+		handleTemplateError(w, "list templates", err)
+		return
+	}
+
+	writeJSON(w, templates)
+}
+
+func (h *TemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	templateID := r.PathValue("template_id")
+	if workspaceID == "" || templateID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.s.GetTemplate(r.Context(), workspaceID, templateID)
+	if err != nil {
+		handleTemplateError(w, "get template", err)
+		return
+	}
+
+	writeJSON(w, template)
+}
+
+func (h *TemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	templateID := r.PathValue("template_id")
+	if workspaceID == "" || templateID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	var req updateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input := services.UpdateTemplateInput{
+		Name:       req.Name,
+		Title:      req.Title,
+		Content:    req.Content,
+		Tags:       req.Tags,
+		Checklist:  req.Checklist,
+		Priority:   req.Priority,
+		AssigneeID: req.AssigneeID,
+	}
+	if req.DueInDays != nil {
+		if string(req.DueInDays) == "null" {
+			input.ClearDueInDays = true
+		} else if err := json.Unmarshal(req.DueInDays, &input.DueInDays); err != nil {
+			http.Error(w, "invalid due_in_days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	template, err := h.s.UpdateTemplate(r.Context(), workspaceID, templateID, input)
+	if err != nil {
+		handleTemplateError(w, "update template", err)
+		return
+	}
+
+	writeJSON(w, template)
+}
+
+func (h *TemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	templateID := r.PathValue("template_id")
+	if workspaceID == "" || templateID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.s.DeleteTemplate(r.Context(), workspaceID, templateID); err != nil {
+		handleTemplateError(w, "delete template", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InstantiateTemplate creates a note or task from the template
+func (h *TemplateHandler) InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	templateID := r.PathValue("template_id")
+	if workspaceID == "" || templateID == "" {
+		http.Error(w, "missing identifiers", http.StatusBadRequest)
+		return
+	}
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// The body is optional
+	var req instantiateTemplateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	instance, err := h.s.InstantiateTemplate(r.Context(), workspaceID, templateID, services.InstantiateTemplateInput{
+		UserID:     userID,
+		Timezone:   req.Timezone,
+		Values:     req.Values,
+		ParentID:   req.ParentID,
+		AssigneeID: req.AssigneeID,
+	})
+	if err != nil {
+		handleTemplateError(w, "instantiate template", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(instance)
+}
+
+func handleTemplateError(w http.ResponseWriter, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTemplateData),
+		errors.Is(err, services.ErrInvalidNoteData),
+		errors.Is(err, services.ErrMissingNoteFields),
+		errors.Is(err, services.ErrParentNoteNotFound),
+		errors.Is(err, services.ErrInvalidTaskData):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTemplateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/services"
+)
+
+type TimelineHandler struct {
+	s services.TimelineServicer
+}
+
+func NewTimelineHandler(service services.TimelineServicer) *TimelineHandler {
+	return &TimelineHandler{s: service}
+}
+
+// GetTimeline serves both the workspace and the project timeline; the
+// project_id path value is empty for the former.
+func (h *TimelineHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("workspace_id")
+	if workspaceID == "" {
+		http.Error(w, "missing workspace id", http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := h.s.GetTimeline(r.Context(), workspaceID, r.PathValue("project_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTaskData), errors.Is(err, services.ErrInvalidProjectData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrProjectNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			log.Printf("Failed to get timeline: %v", err)
+			http.Error(w, "failed to get timeline", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, timeline)
+}
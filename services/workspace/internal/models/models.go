@@ -6,11 +6,57 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ProjectStatus string
+
+const (
+	ProjectStatusPlanned   ProjectStatus = "planned"
+	ProjectStatusActive    ProjectStatus = "active"
+	ProjectStatusPaused    ProjectStatus = "paused"
+	ProjectStatusCompleted ProjectStatus = "completed"
+	ProjectStatusCancelled ProjectStatus = "cancelled"
+)
+
+func (e *ProjectStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectStatus(s)
+	case string:
+		*e = ProjectStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectStatus: %T", src)
+	}
+	return nil
+}
+
+type NullProjectStatus struct {
+	ProjectStatus ProjectStatus `json:"project_status"`
+	Valid         bool          `json:"valid"` // Valid is true if ProjectStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectStatus), nil
+}
+
 type TaskPriority string
 
 const (
@@ -98,6 +144,97 @@ func (ns NullTaskStatus) Value() (driver.Value, error) {
 	return string(ns.TaskStatus), nil
 }
 
+type AutomationRule struct {
+	ID            pgtype.UUID        `json:"id"`
+	WorkspaceID   pgtype.UUID        `json:"workspace_id"`
+	Name          string             `json:"name"`
+	Enabled       bool               `json:"enabled"`
+	TriggerType   string             `json:"trigger_type"`
+	TriggerConfig json.RawMessage    `json:"trigger_config"`
+	Conditions    json.RawMessage    `json:"conditions"`
+	Actions       json.RawMessage    `json:"actions"`
+	CreatedBy     pgtype.Text        `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AutomationRun struct {
+	ID          pgtype.UUID        `json:"id"`
+	RuleID      pgtype.UUID        `json:"rule_id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	TriggerType string             `json:"trigger_type"`
+	SubjectID   pgtype.UUID        `json:"subject_id"`
+	Depth       int32              `json:"depth"`
+	Status      string             `json:"status"`
+	Error       pgtype.Text        `json:"error"`
+	StartedAt   pgtype.Timestamptz `json:"started_at"`
+	FinishedAt  pgtype.Timestamptz `json:"finished_at"`
+}
+
+type CalendarFeed struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	UserID      string             `json:"user_id"`
+	TokenHash   string             `json:"token_hash"`
+	TokenHint   string             `json:"token_hint"`
+	Tasks       string             `json:"tasks"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Cycle struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	Name        string             `json:"name"`
+	StartDate   pgtype.Date        `json:"start_date"`
+	EndDate     pgtype.Date        `json:"end_date"`
+	ClosedAt    pgtype.Timestamptz `json:"closed_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Event struct {
+	ID               pgtype.UUID          `json:"id"`
+	WorkspaceID      pgtype.UUID          `json:"workspace_id"`
+	Name             string               `json:"name"`
+	Color            string               `json:"color"`
+	DurationMinutes  int32                `json:"duration_minutes"`
+	AttendeesCount   int32                `json:"attendees_count"`
+	CreatedAt        pgtype.Timestamptz   `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz   `json:"updated_at"`
+	StartsAt         pgtype.Timestamptz   `json:"starts_at"`
+	Timezone         string               `json:"timezone"`
+	AllDay           bool                 `json:"all_day"`
+	Rrule            pgtype.Text          `json:"rrule"`
+	Exdates          []pgtype.Timestamptz `json:"exdates"`
+	RecurringEventID pgtype.UUID          `json:"recurring_event_id"`
+	RecurrenceID     pgtype.Timestamptz   `json:"recurrence_id"`
+	EndsAt           pgtype.Timestamptz   `json:"ends_at"`
+	Sequence         int32                `json:"sequence"`
+	IcalUid          pgtype.Text          `json:"ical_uid"`
+}
+
+type EventAttendee struct {
+	ID            pgtype.UUID        `json:"id"`
+	EventID       pgtype.UUID        `json:"event_id"`
+	UserID        pgtype.Text        `json:"user_id"`
+	Email         pgtype.Text        `json:"email"`
+	Status        string             `json:"status"`
+	RsvpTokenHash pgtype.Text        `json:"rsvp_token_hash"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Milestone struct {
+	ID          pgtype.UUID        `json:"id"`
+	ProjectID   pgtype.UUID        `json:"project_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	TargetDate  pgtype.Date        `json:"target_date"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Note struct {
 	ID          pgtype.UUID        `json:"id"`
 	WorkspaceID pgtype.UUID        `json:"workspace_id"`
@@ -107,6 +244,103 @@ type Note struct {
 	Tags        []string           `json:"tags"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int32              `json:"version"`
+	ParentID    pgtype.UUID        `json:"parent_id"`
+	Position    int32              `json:"position"`
+	Visibility  string             `json:"visibility"`
+}
+
+type NoteComment struct {
+	ID        pgtype.UUID        `json:"id"`
+	ThreadID  pgtype.UUID        `json:"thread_id"`
+	AuthorID  pgtype.Text        `json:"author_id"`
+	Body      string             `json:"body"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type NoteCommentThread struct {
+	ID          pgtype.UUID        `json:"id"`
+	NoteID      pgtype.UUID        `json:"note_id"`
+	CreatedBy   pgtype.Text        `json:"created_by"`
+	AnchorStart pgtype.Int4        `json:"anchor_start"`
+	AnchorEnd   pgtype.Int4        `json:"anchor_end"`
+	BlockID     pgtype.Text        `json:"block_id"`
+	Quote       string             `json:"quote"`
+	ResolvedAt  pgtype.Timestamptz `json:"resolved_at"`
+	ResolvedBy  pgtype.Text        `json:"resolved_by"`
+	OrphanedAt  pgtype.Timestamptz `json:"orphaned_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NoteLink struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	SourceID    pgtype.UUID        `json:"source_id"`
+	TargetID    pgtype.UUID        `json:"target_id"`
+	TargetRef   string             `json:"target_ref"`
+	Kind        string             `json:"kind"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type NoteMember struct {
+	NoteID    pgtype.UUID        `json:"note_id"`
+	UserID    string             `json:"user_id"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type NoteRevision struct {
+	ID        pgtype.UUID        `json:"id"`
+	NoteID    pgtype.UUID        `json:"note_id"`
+	Revision  int32              `json:"revision"`
+	AuthorID  pgtype.Text        `json:"author_id"`
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	Tags      []string           `json:"tags"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type NoteShare struct {
+	ID           pgtype.UUID        `json:"id"`
+	WorkspaceID  pgtype.UUID        `json:"workspace_id"`
+	NoteID       pgtype.UUID        `json:"note_id"`
+	TokenHash    string             `json:"-"`
+	TokenHint    string             `json:"token_hint"`
+	PasswordHash pgtype.Text        `json:"-"`
+	CreatedBy    pgtype.Text        `json:"created_by"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt    pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type NoteShareAccess struct {
+	ID         pgtype.UUID        `json:"id"`
+	ShareID    pgtype.UUID        `json:"share_id"`
+	Status     string             `json:"status"`
+	Format     string             `json:"format"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	UserAgent  pgtype.Text        `json:"user_agent"`
+	AccessedAt pgtype.Timestamptz `json:"accessed_at"`
+}
+
+type NoteTask struct {
+	TaskID    pgtype.UUID        `json:"task_id"`
+	NoteID    pgtype.UUID        `json:"note_id"`
+	BlockID   string             `json:"block_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Project struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Status      ProjectStatus      `json:"status"`
+	LeadID      pgtype.Text        `json:"lead_id"`
+	TargetDate  pgtype.Date        `json:"target_date"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Task struct {
@@ -120,6 +354,63 @@ type Task struct {
 	DueDate     pgtype.Timestamptz `json:"due_date"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	ProjectID   pgtype.UUID        `json:"project_id"`
+	MilestoneID pgtype.UUID        `json:"milestone_id"`
+	CycleID     pgtype.UUID        `json:"cycle_id"`
+	StartDate   pgtype.Timestamptz `json:"start_date"`
+}
+
+type TaskComment struct {
+	ID        pgtype.UUID        `json:"id"`
+	TaskID    pgtype.UUID        `json:"task_id"`
+	AuthorID  pgtype.Text        `json:"author_id"`
+	Body      string             `json:"body"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TaskCycleEvent struct {
+	ID        int64              `json:"id"`
+	CycleID   pgtype.UUID        `json:"cycle_id"`
+	TaskID    pgtype.UUID        `json:"task_id"`
+	Event     string             `json:"event"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TaskDependency struct {
+	TaskID      pgtype.UUID        `json:"task_id"`
+	DependsOnID pgtype.UUID        `json:"depends_on_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type TaskView struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	OwnerID     string             `json:"owner_id"`
+	Name        string             `json:"name"`
+	Visibility  string             `json:"visibility"`
+	Filters     json.RawMessage    `json:"filters"`
+	Sort        json.RawMessage    `json:"sort"`
+	GroupBy     pgtype.Text        `json:"group_by"`
+	Columns     []string           `json:"columns"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Template struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	Kind        string             `json:"kind"`
+	Name        string             `json:"name"`
+	Title       string             `json:"title"`
+	Content     string             `json:"content"`
+	Tags        []string           `json:"tags"`
+	Checklist   []string           `json:"checklist"`
+	Priority    TaskPriority       `json:"priority"`
+	AssigneeID  pgtype.Text        `json:"assignee_id"`
+	DueInDays   pgtype.Int4        `json:"due_in_days"`
+	CreatedBy   pgtype.Text        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
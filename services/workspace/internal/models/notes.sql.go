@@ -17,14 +17,20 @@ INSERT INTO notes (
     author_id,
     title,
     content,
-    tags
+    tags,
+    parent_id,
+    position,
+    visibility
 )
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8
 )
 RETURNING
     id,
@@ -34,7 +40,11 @@ RETURNING
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 `
 
 type CreateNoteParams struct {
@@ -43,6 +53,9 @@ type CreateNoteParams struct {
 	Title       string      `json:"title"`
 	Content     string      `json:"content"`
 	Tags        []string    `json:"tags"`
+	ParentID    pgtype.UUID `json:"parent_id"`
+	Position    int32       `json:"position"`
+	Visibility  string      `json:"visibility"`
 }
 
 func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error) {
@@ -52,6 +65,9 @@ func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, e
 		arg.Title,
 		arg.Content,
 		arg.Tags,
+		arg.ParentID,
+		arg.Position,
+		arg.Visibility,
 	)
 	var i Note
 	err := row.Scan(
@@ -63,23 +79,35 @@ func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, e
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.ParentID,
+		&i.Position,
+		&i.Visibility,
 	)
 	return i, err
 }
 
-const deleteNote = `-- name: DeleteNote :exec
+const deleteNote = `-- name: DeleteNote :execrows
 DELETE FROM notes
-WHERE workspace_id = $1 AND id = $2
+WHERE
+    workspace_id = $1
+    AND id = $2
+    AND ($3::INTEGER IS NULL OR version = $3)
 `
 
 type DeleteNoteParams struct {
 	WorkspaceID pgtype.UUID `json:"workspace_id"`
 	ID          pgtype.UUID `json:"id"`
+	Version     pgtype.Int4 `json:"version"`
 }
 
-func (q *Queries) DeleteNote(ctx context.Context, arg DeleteNoteParams) error {
-	_, err := q.db.Exec(ctx, deleteNote, arg.WorkspaceID, arg.ID)
-	return err
+// A null version deletes the note whatever its version.
+func (q *Queries) DeleteNote(ctx context.Context, arg DeleteNoteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNote, arg.WorkspaceID, arg.ID, arg.Version)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getWorkspaceNote = `-- name: GetWorkspaceNote :one
@@ -91,7 +119,11 @@ SELECT
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 FROM notes
 WHERE
     workspace_id = $1
@@ -115,10 +147,235 @@ func (q *Queries) GetWorkspaceNote(ctx context.Context, arg GetWorkspaceNotePara
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.ParentID,
+		&i.Position,
+		&i.Visibility,
 	)
 	return i, err
 }
 
+const isNoteInSubtree = `-- name: IsNoteInSubtree :one
+WITH RECURSIVE chain AS (
+    SELECT
+        id,
+        parent_id
+    FROM notes
+    WHERE id = $1
+
+    UNION
+
+    SELECT
+        n.id,
+        n.parent_id
+    FROM notes AS n
+    INNER JOIN chain AS c ON n.id = c.parent_id
+)
+
+SELECT EXISTS (
+    SELECT 1
+    FROM chain
+    WHERE id = $2
+) AS in_subtree
+`
+
+type IsNoteInSubtreeParams struct {
+	CandidateID pgtype.UUID `json:"candidate_id"`
+	RootID      pgtype.UUID `json:"root_id"`
+}
+
+// Whether candidate_id is root_id or one of its descendants, found by
+// walking up from candidate_id.
+func (q *Queries) IsNoteInSubtree(ctx context.Context, arg IsNoteInSubtreeParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isNoteInSubtree, arg.CandidateID, arg.RootID)
+	var in_subtree bool
+	err := row.Scan(&in_subtree)
+	return in_subtree, err
+}
+
+const listNoteAncestors = `-- name: ListNoteAncestors :many
+WITH RECURSIVE chain AS (
+    SELECT
+        id,
+        parent_id,
+        title,
+        0 AS depth,
+        ARRAY[id] AS path
+    FROM notes
+    WHERE workspace_id = $1 AND id = $2
+
+    UNION ALL
+
+    SELECT
+        n.id,
+        n.parent_id,
+        n.title,
+        c.depth + 1 AS depth,
+        c.path || n.id AS path
+    FROM notes AS n
+    INNER JOIN chain AS c ON n.id = c.parent_id
+    WHERE NOT n.id = ANY(c.path)
+)
+
+SELECT
+    c.id,
+    c.parent_id,
+    c.title
+FROM chain AS c
+INNER JOIN notes AS n ON c.id = n.id
+WHERE (
+    n.visibility = 'workspace'
+    OR n.author_id = $3::TEXT
+    OR (
+        n.visibility = 'restricted'
+        AND EXISTS (
+            SELECT 1
+            FROM note_members AS m
+            WHERE m.note_id = n.id AND m.user_id = $3::TEXT
+        )
+    )
+)
+ORDER BY c.depth DESC
+`
+
+type ListNoteAncestorsRow struct {
+	ID       pgtype.UUID `json:"id"`
+	ParentID pgtype.UUID `json:"parent_id"`
+	Title    string      `json:"title"`
+}
+
+type ListNoteAncestorsParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ID          pgtype.UUID `json:"id"`
+	ViewerID    string      `json:"viewer_id"`
+}
+
+// The chain from the root down to the note itself, leaving out notes
+// viewer_id cannot see. path stops the walk should the parents ever loop.
+func (q *Queries) ListNoteAncestors(ctx context.Context, arg ListNoteAncestorsParams) ([]ListNoteAncestorsRow, error) {
+	rows, err := q.db.Query(ctx, listNoteAncestors, arg.WorkspaceID, arg.ID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNoteAncestorsRow
+	for rows.Next() {
+		var i ListNoteAncestorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Title,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNoteChildren = `-- name: ListNoteChildren :many
+SELECT
+    n.id,
+    n.workspace_id,
+    n.author_id,
+    n.title,
+    n.content,
+    n.tags,
+    n.created_at,
+    n.updated_at,
+    n.version,
+    n.parent_id,
+    n.position,
+    n.visibility,
+    (
+        SELECT count(*)
+        FROM notes AS c
+        WHERE c.parent_id = n.id
+    ) AS child_count
+FROM notes AS n
+WHERE
+    n.workspace_id = $1
+    AND n.parent_id IS NOT DISTINCT FROM $2::UUID
+    AND (
+        $3::TEXT IS NULL
+        OR (
+            n.visibility = 'workspace'
+            OR n.author_id = $3::TEXT
+            OR (
+                n.visibility = 'restricted'
+                AND EXISTS (
+                    SELECT 1
+                    FROM note_members AS m
+                    WHERE m.note_id = n.id AND m.user_id = $3::TEXT
+                )
+            )
+        )
+    )
+ORDER BY n.position ASC, n.created_at ASC
+`
+
+type ListNoteChildrenRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	AuthorID    pgtype.Text        `json:"author_id"`
+	Title       string             `json:"title"`
+	Content     string             `json:"content"`
+	Tags        []string           `json:"tags"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int32              `json:"version"`
+	ParentID    pgtype.UUID        `json:"parent_id"`
+	Position    int32              `json:"position"`
+	Visibility  string             `json:"visibility"`
+	ChildCount  int64              `json:"child_count"`
+}
+
+type ListNoteChildrenParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ParentID    pgtype.UUID `json:"parent_id"`
+	ViewerID    pgtype.Text `json:"viewer_id"`
+}
+
+// Children of a note in sibling order, or top-level notes when parent_id is
+// null. child_count lets clients load deeper levels lazily. A null viewer_id
+// includes notes regardless of visibility.
+func (q *Queries) ListNoteChildren(ctx context.Context, arg ListNoteChildrenParams) ([]ListNoteChildrenRow, error) {
+	rows, err := q.db.Query(ctx, listNoteChildren, arg.WorkspaceID, arg.ParentID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNoteChildrenRow
+	for rows.Next() {
+		var i ListNoteChildrenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.ParentID,
+			&i.Position,
+			&i.Visibility,
+			&i.ChildCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listWorkspaceNotes = `-- name: ListWorkspaceNotes :many
 SELECT
     id,
@@ -128,14 +385,203 @@ SELECT
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 FROM notes
-WHERE workspace_id = $1
+WHERE
+    workspace_id = $1
+    AND notes_lower_tags(tags) @> $2::TEXT []
+    AND (
+        cardinality($3::TEXT []) = 0
+        OR notes_lower_tags(tags) && $3::TEXT []
+    )
+    AND (
+        notes.visibility = 'workspace'
+        OR notes.author_id = $4::TEXT
+        OR (
+            notes.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = notes.id AND m.user_id = $4::TEXT
+            )
+        )
+    )
 ORDER BY updated_at DESC
 `
 
-func (q *Queries) ListWorkspaceNotes(ctx context.Context, workspaceID pgtype.UUID) ([]Note, error) {
-	rows, err := q.db.Query(ctx, listWorkspaceNotes, workspaceID)
+type ListWorkspaceNotesParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	AllTags     []string    `json:"all_tags"`
+	AnyTags     []string    `json:"any_tags"`
+	ViewerID    string      `json:"viewer_id"`
+}
+
+// Notes viewer_id can see that carry every tag in all_tags and, unless
+// any_tags is empty, at least one of any_tags. Tags are compared in lower
+// case.
+func (q *Queries) ListWorkspaceNotes(ctx context.Context, arg ListWorkspaceNotesParams) ([]Note, error) {
+	rows, err := q.db.Query(ctx, listWorkspaceNotes,
+		arg.WorkspaceID,
+		arg.AllTags,
+		arg.AnyTags,
+		arg.ViewerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Note
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.ParentID,
+			&i.Position,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkspaceTags = `-- name: ListWorkspaceTags :many
+SELECT
+    mode() WITHIN GROUP (ORDER BY t.tag)::TEXT AS tag,
+    count(DISTINCT n.id) AS note_count
+FROM notes AS n
+CROSS JOIN LATERAL unnest(n.tags) AS t (tag)
+WHERE
+    n.workspace_id = $1
+    AND (
+        n.visibility = 'workspace'
+        OR n.author_id = $2::TEXT
+        OR (
+            n.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = n.id AND m.user_id = $2::TEXT
+            )
+        )
+    )
+GROUP BY lower(t.tag)
+ORDER BY note_count DESC, tag ASC
+`
+
+type ListWorkspaceTagsRow struct {
+	Tag       string `json:"tag"`
+	NoteCount int64  `json:"note_count"`
+}
+
+type ListWorkspaceTagsParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ViewerID    string      `json:"viewer_id"`
+}
+
+// Tags in use on the notes viewer_id can see, grouped case-insensitively
+// under their most common spelling.
+func (q *Queries) ListWorkspaceTags(ctx context.Context, arg ListWorkspaceTagsParams) ([]ListWorkspaceTagsRow, error) {
+	rows, err := q.db.Query(ctx, listWorkspaceTags, arg.WorkspaceID, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWorkspaceTagsRow
+	for rows.Next() {
+		var i ListWorkspaceTagsRow
+		if err := rows.Scan(
+			&i.Tag,
+			&i.NoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockNoteTree = `-- name: LockNoteTree :exec
+SELECT pg_advisory_xact_lock(hashtextextended('notes:' || $1::UUID::TEXT, 0))
+`
+
+// Serializes changes to a workspace's note hierarchy until the transaction
+// ends, so concurrent moves cannot together create a cycle.
+func (q *Queries) LockNoteTree(ctx context.Context, workspaceID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockNoteTree, workspaceID)
+	return err
+}
+
+const lockNotesWithTags = `-- name: LockNotesWithTags :many
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = $1
+    AND EXISTS (
+        SELECT 1
+        FROM unnest(notes.tags) AS t (tag)
+        WHERE lower(t.tag) = ANY($2::TEXT [])
+    )
+    AND (
+        notes.visibility = 'workspace'
+        OR notes.author_id = $3::TEXT
+        OR (
+            notes.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE
+                    m.note_id = notes.id
+                    AND m.user_id = $3::TEXT
+                    AND m.role <> 'viewer'
+            )
+        )
+    )
+ORDER BY id ASC
+FOR UPDATE
+`
+
+type LockNotesWithTagsParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	Tags        []string    `json:"tags"`
+	EditorID    string      `json:"editor_id"`
+}
+
+// Notes editor_id can edit carrying any of tags, compared
+// case-insensitively, locked for update.
+func (q *Queries) LockNotesWithTags(ctx context.Context, arg LockNotesWithTagsParams) ([]Note, error) {
+	rows, err := q.db.Query(ctx, lockNotesWithTags, arg.WorkspaceID, arg.Tags, arg.EditorID)
 	if err != nil {
 		return nil, err
 	}
@@ -152,6 +598,293 @@ func (q *Queries) ListWorkspaceNotes(ctx context.Context, workspaceID pgtype.UUI
 			&i.Tags,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.ParentID,
+			&i.Position,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockWorkspaceNote = `-- name: LockWorkspaceNote :one
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = $1
+    AND id = $2
+FOR SHARE
+`
+
+type LockWorkspaceNoteParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ID          pgtype.UUID `json:"id"`
+}
+
+// Holds the note's content steady until the transaction ends.
+func (q *Queries) LockWorkspaceNote(ctx context.Context, arg LockWorkspaceNoteParams) (Note, error) {
+	row := q.db.QueryRow(ctx, lockWorkspaceNote, arg.WorkspaceID, arg.ID)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.ParentID,
+		&i.Position,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const lockWorkspaceNoteForUpdate = `-- name: LockWorkspaceNoteForUpdate :one
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = $1
+    AND id = $2
+FOR UPDATE
+`
+
+type LockWorkspaceNoteForUpdateParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ID          pgtype.UUID `json:"id"`
+}
+
+// Holds the note for a write that doesn't come from an editor, such as
+// ticking a checklist item when its task is completed.
+func (q *Queries) LockWorkspaceNoteForUpdate(ctx context.Context, arg LockWorkspaceNoteForUpdateParams) (Note, error) {
+	row := q.db.QueryRow(ctx, lockWorkspaceNoteForUpdate, arg.WorkspaceID, arg.ID)
+	var i Note
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.Tags,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.ParentID,
+		&i.Position,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const nextNotePosition = `-- name: NextNotePosition :one
+SELECT COALESCE(MAX(position) + 1, 0)::INTEGER AS position
+FROM notes
+WHERE
+    workspace_id = $1
+    AND parent_id IS NOT DISTINCT FROM $2::UUID
+`
+
+type NextNotePositionParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ParentID    pgtype.UUID `json:"parent_id"`
+}
+
+func (q *Queries) NextNotePosition(ctx context.Context, arg NextNotePositionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, nextNotePosition, arg.WorkspaceID, arg.ParentID)
+	var position int32
+	err := row.Scan(&position)
+	return position, err
+}
+
+const reorderNotes = `-- name: ReorderNotes :execrows
+UPDATE notes AS n
+SET position = o.ord - 1
+FROM unnest($1::UUID []) WITH ORDINALITY AS o (id, ord)
+WHERE n.workspace_id = $2 AND n.id = o.id
+`
+
+type ReorderNotesParams struct {
+	Ids         []pgtype.UUID `json:"ids"`
+	WorkspaceID pgtype.UUID   `json:"workspace_id"`
+}
+
+// Sets positions to match the order of ids.
+func (q *Queries) ReorderNotes(ctx context.Context, arg ReorderNotesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reorderNotes, arg.Ids, arg.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reparentNoteChildren = `-- name: ReparentNoteChildren :execrows
+UPDATE notes
+SET
+    parent_id = $1,
+    position = $2 + position
+WHERE
+    workspace_id = $3
+    AND parent_id = $4
+`
+
+type ReparentNoteChildrenParams struct {
+	NewParentID  pgtype.UUID `json:"new_parent_id"`
+	BasePosition int32       `json:"base_position"`
+	WorkspaceID  pgtype.UUID `json:"workspace_id"`
+	ParentID     pgtype.UUID `json:"parent_id"`
+}
+
+// Moves a note's children under new_parent_id, after the notes already
+// there.
+func (q *Queries) ReparentNoteChildren(ctx context.Context, arg ReparentNoteChildrenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reparentNoteChildren,
+		arg.NewParentID,
+		arg.BasePosition,
+		arg.WorkspaceID,
+		arg.ParentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchWorkspaceNotes = `-- name: SearchWorkspaceNotes :many
+WITH search AS (
+    SELECT to_tsquery('english', $1) AS query
+)
+
+SELECT
+    n.id,
+    n.workspace_id,
+    n.author_id,
+    n.title,
+    n.tags,
+    n.created_at,
+    n.updated_at,
+    n.version,
+    ts_rank_cd(notes_search_vector(n.title, n.tags, n.content), s.query) AS rank,
+    ts_headline(
+        'english', notes_html_escape(n.title), s.query,
+        'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'
+    ) AS title_highlight,
+    ts_headline(
+        'english', notes_html_escape(n.content), s.query,
+        'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>'
+    ) AS snippet
+FROM notes AS n
+CROSS JOIN search AS s
+WHERE
+    n.workspace_id = $2
+    AND notes_search_vector(n.title, n.tags, n.content) @@ s.query
+    AND notes_lower_tags(n.tags) @> $3::TEXT []
+    AND (
+        $4::TEXT IS NULL
+        OR n.author_id = $4::TEXT
+    )
+    AND (
+        n.visibility = 'workspace'
+        OR n.author_id = $5::TEXT
+        OR (
+            n.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = n.id AND m.user_id = $5::TEXT
+            )
+        )
+    )
+ORDER BY rank DESC, n.updated_at DESC
+LIMIT $6
+`
+
+type SearchWorkspaceNotesRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	WorkspaceID    pgtype.UUID        `json:"workspace_id"`
+	AuthorID       pgtype.Text        `json:"author_id"`
+	Title          string             `json:"title"`
+	Tags           []string           `json:"tags"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	Version        int32              `json:"version"`
+	Rank           float32            `json:"rank"`
+	TitleHighlight string             `json:"title_highlight"`
+	Snippet        string             `json:"snippet"`
+}
+
+type SearchWorkspaceNotesParams struct {
+	Query       string      `json:"query"`
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	Tags        []string    `json:"tags"`
+	AuthorID    pgtype.Text `json:"author_id"`
+	ViewerID    string      `json:"viewer_id"`
+	ResultLimit int32       `json:"result_limit"`
+}
+
+// Ranked full-text search over the notes viewer_id can see. An empty tags
+// array or null author matches all notes; tags are compared in lower case.
+// Highlights are HTML with matches in <mark>; the text is escaped first so
+// notes cannot add markup of their own.
+func (q *Queries) SearchWorkspaceNotes(ctx context.Context, arg SearchWorkspaceNotesParams) ([]SearchWorkspaceNotesRow, error) {
+	rows, err := q.db.Query(ctx, searchWorkspaceNotes,
+		arg.Query,
+		arg.WorkspaceID,
+		arg.Tags,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchWorkspaceNotesRow
+	for rows.Next() {
+		var i SearchWorkspaceNotesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.AuthorID,
+			&i.Title,
+			&i.Tags,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Rank,
+			&i.TitleHighlight,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
@@ -163,16 +896,52 @@ func (q *Queries) ListWorkspaceNotes(ctx context.Context, workspaceID pgtype.UUI
 	return items, nil
 }
 
+const setNoteParent = `-- name: SetNoteParent :exec
+UPDATE notes
+SET parent_id = $1
+WHERE workspace_id = $2 AND id = $3
+`
+
+type SetNoteParentParams struct {
+	ParentID    pgtype.UUID `json:"parent_id"`
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ID          pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) SetNoteParent(ctx context.Context, arg SetNoteParentParams) error {
+	_, err := q.db.Exec(ctx, setNoteParent, arg.ParentID, arg.WorkspaceID, arg.ID)
+	return err
+}
+
+const setNoteVisibility = `-- name: SetNoteVisibility :exec
+UPDATE notes
+SET visibility = $3
+WHERE workspace_id = $1 AND id = $2
+`
+
+type SetNoteVisibilityParams struct {
+	WorkspaceID pgtype.UUID `json:"workspace_id"`
+	ID          pgtype.UUID `json:"id"`
+	Visibility  string      `json:"visibility"`
+}
+
+func (q *Queries) SetNoteVisibility(ctx context.Context, arg SetNoteVisibilityParams) error {
+	_, err := q.db.Exec(ctx, setNoteVisibility, arg.WorkspaceID, arg.ID, arg.Visibility)
+	return err
+}
+
 const updateNote = `-- name: UpdateNote :one
 UPDATE notes
 SET
     title = $3,
     content = $4,
     tags = $5,
+    version = version + 1,
     updated_at = now()
 WHERE
     workspace_id = $1
     AND id = $2
+    AND version = $6
 RETURNING
     id,
     workspace_id,
@@ -181,7 +950,11 @@ RETURNING
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 `
 
 type UpdateNoteParams struct {
@@ -190,6 +963,7 @@ type UpdateNoteParams struct {
 	Title       string      `json:"title"`
 	Content     string      `json:"content"`
 	Tags        []string    `json:"tags"`
+	Version     int32       `json:"version"`
 }
 
 func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
@@ -199,6 +973,7 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, e
 		arg.Title,
 		arg.Content,
 		arg.Tags,
+		arg.Version,
 	)
 	var i Note
 	err := row.Scan(
@@ -210,6 +985,10 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, e
 		&i.Tags,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.ParentID,
+		&i.Position,
+		&i.Visibility,
 	)
 	return i, err
 }
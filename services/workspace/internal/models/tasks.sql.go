@@ -13,23 +13,25 @@ import (
 
 const createNewTask = `-- name: CreateNewTask :one
 INSERT INTO tasks (
-  workspace_id,
-  title,
-  description,
-  assignee_id,
-  status,
-  priority,
-  due_date
+    workspace_id,
+    title,
+    description,
+    assignee_id,
+    status,
+    priority,
+    due_date,
+    start_date
 ) VALUES (
-  $1, -- workspace_id
-  $2, -- title
-  $3, -- description
-  $4, -- assignee_id
-  $5, -- status
-  $6, -- priority
-  $7  -- due_date
+    $1, -- workspace_id
+    $2, -- title
+    $3, -- description
+    $4, -- assignee_id
+    $5, -- status
+    $6, -- priority
+    $7, -- due_date
+    $8  -- start_date
 )
-RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at
+RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at, project_id, milestone_id, cycle_id, start_date
 `
 
 type CreateNewTaskParams struct {
@@ -40,6 +42,7 @@ type CreateNewTaskParams struct {
 	Status      TaskStatus         `json:"status"`
 	Priority    TaskPriority       `json:"priority"`
 	DueDate     pgtype.Timestamptz `json:"due_date"`
+	StartDate   pgtype.Timestamptz `json:"start_date"`
 }
 
 func (q *Queries) CreateNewTask(ctx context.Context, arg CreateNewTaskParams) (Task, error) {
@@ -51,6 +54,7 @@ func (q *Queries) CreateNewTask(ctx context.Context, arg CreateNewTaskParams) (T
 		arg.Status,
 		arg.Priority,
 		arg.DueDate,
+		arg.StartDate,
 	)
 	var i Task
 	err := row.Scan(
@@ -64,12 +68,39 @@ func (q *Queries) CreateNewTask(ctx context.Context, arg CreateNewTaskParams) (T
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.MilestoneID,
+		&i.CycleID,
+		&i.StartDate,
 	)
 	return i, err
 }
 
+const createTaskDependency = `-- name: CreateTaskDependency :exec
+INSERT INTO task_dependencies (
+    task_id,
+    depends_on_id
+)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING
+`
+
+type CreateTaskDependencyParams struct {
+	TaskID      pgtype.UUID `json:"task_id"`
+	DependsOnID pgtype.UUID `json:"depends_on_id"`
+}
+
+func (q *Queries) CreateTaskDependency(ctx context.Context, arg CreateTaskDependencyParams) error {
+	_, err := q.db.Exec(ctx, createTaskDependency, arg.TaskID, arg.DependsOnID)
+	return err
+}
+
 const deleteTask = `-- name: DeleteTask :exec
-DELETE FROM tasks WHERE id = $1
+DELETE FROM tasks
+WHERE id = $1
 `
 
 func (q *Queries) DeleteTask(ctx context.Context, id pgtype.UUID) error {
@@ -77,6 +108,96 @@ func (q *Queries) DeleteTask(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteTaskDependency = `-- name: DeleteTaskDependency :execrows
+DELETE FROM task_dependencies
+WHERE task_id = $1 AND depends_on_id = $2
+`
+
+type DeleteTaskDependencyParams struct {
+	TaskID      pgtype.UUID `json:"task_id"`
+	DependsOnID pgtype.UUID `json:"depends_on_id"`
+}
+
+func (q *Queries) DeleteTaskDependency(ctx context.Context, arg DeleteTaskDependencyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTaskDependency, arg.TaskID, arg.DependsOnID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const dependencyCreatesCycle = `-- name: DependencyCreatesCycle :one
+WITH RECURSIVE chain AS (
+    SELECT depends_on_id
+    FROM task_dependencies
+    WHERE task_id = $1
+    UNION
+    SELECT d.depends_on_id
+    FROM task_dependencies AS d
+    INNER JOIN chain AS c ON d.task_id = c.depends_on_id
+)
+
+SELECT EXISTS (
+    SELECT 1
+    FROM chain
+    WHERE depends_on_id = $2
+) AS creates_cycle
+`
+
+type DependencyCreatesCycleParams struct {
+	BlockerID pgtype.UUID `json:"blocker_id"`
+	TaskID    pgtype.UUID `json:"task_id"`
+}
+
+// Reports whether the blocker already depends, directly or transitively, on
+// the task.
+func (q *Queries) DependencyCreatesCycle(ctx context.Context, arg DependencyCreatesCycleParams) (bool, error) {
+	row := q.db.QueryRow(ctx, dependencyCreatesCycle, arg.BlockerID, arg.TaskID)
+	var creates_cycle bool
+	err := row.Scan(&creates_cycle)
+	return creates_cycle, err
+}
+
+const getProjectTaskStatusCounts = `-- name: GetProjectTaskStatusCounts :many
+SELECT
+    milestone_id,
+    status,
+    COUNT(*) AS task_count
+FROM tasks
+WHERE project_id = $1
+GROUP BY milestone_id, status
+`
+
+type GetProjectTaskStatusCountsRow struct {
+	MilestoneID pgtype.UUID `json:"milestone_id"`
+	Status      TaskStatus  `json:"status"`
+	TaskCount   int64       `json:"task_count"`
+}
+
+func (q *Queries) GetProjectTaskStatusCounts(ctx context.Context, projectID pgtype.UUID) ([]GetProjectTaskStatusCountsRow, error) {
+	rows, err := q.db.Query(ctx, getProjectTaskStatusCounts, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProjectTaskStatusCountsRow
+	for rows.Next() {
+		var i GetProjectTaskStatusCountsRow
+		if err := rows.Scan(
+			&i.MilestoneID,
+			&i.Status,
+			&i.TaskCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTaskByID = `-- name: GetTaskByID :one
 SELECT
     t.id AS task_id,
@@ -88,6 +209,10 @@ SELECT
     t.due_date,
     t.created_at,
     t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
 
     -- Assignee info
     u.id AS assignee_id,
@@ -95,8 +220,8 @@ SELECT
     u.last_name AS assignee_last_name,
     u.username AS assignee_username,
     u.email AS assignee_email
-FROM tasks t
-LEFT JOIN users u ON t.assignee_id = u.id
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
 WHERE t.id = $1
 `
 
@@ -110,6 +235,10 @@ type GetTaskByIDRow struct {
 	DueDate           pgtype.Timestamptz `json:"due_date"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ProjectID         pgtype.UUID        `json:"project_id"`
+	MilestoneID       pgtype.UUID        `json:"milestone_id"`
+	CycleID           pgtype.UUID        `json:"cycle_id"`
+	StartDate         pgtype.Timestamptz `json:"start_date"`
 	AssigneeID        pgtype.Text        `json:"assignee_id"`
 	AssigneeFirstName pgtype.Text        `json:"assignee_first_name"`
 	AssigneeLastName  pgtype.Text        `json:"assignee_last_name"`
@@ -130,6 +259,10 @@ func (q *Queries) GetTaskByID(ctx context.Context, id pgtype.UUID) (GetTaskByIDR
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.MilestoneID,
+		&i.CycleID,
+		&i.StartDate,
 		&i.AssigneeID,
 		&i.AssigneeFirstName,
 		&i.AssigneeLastName,
@@ -139,6 +272,182 @@ func (q *Queries) GetTaskByID(ctx context.Context, id pgtype.UUID) (GetTaskByIDR
 	return i, err
 }
 
+const getTasksByCycle = `-- name: GetTasksByCycle :many
+SELECT
+    t.id AS task_id,
+    t.workspace_id,
+    t.title,
+    t.description,
+    t.status,
+    t.priority,
+    t.due_date,
+    t.created_at,
+    t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
+
+    -- Assignee info
+    u.id AS assignee_id,
+    u.first_name AS assignee_first_name,
+    u.last_name AS assignee_last_name,
+    u.username AS assignee_username,
+    u.email AS assignee_email
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
+WHERE t.cycle_id = $1
+ORDER BY t.created_at DESC
+`
+
+type GetTasksByCycleRow struct {
+	TaskID            pgtype.UUID        `json:"task_id"`
+	WorkspaceID       pgtype.UUID        `json:"workspace_id"`
+	Title             string             `json:"title"`
+	Description       pgtype.Text        `json:"description"`
+	Status            TaskStatus         `json:"status"`
+	Priority          TaskPriority       `json:"priority"`
+	DueDate           pgtype.Timestamptz `json:"due_date"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ProjectID         pgtype.UUID        `json:"project_id"`
+	MilestoneID       pgtype.UUID        `json:"milestone_id"`
+	CycleID           pgtype.UUID        `json:"cycle_id"`
+	StartDate         pgtype.Timestamptz `json:"start_date"`
+	AssigneeID        pgtype.Text        `json:"assignee_id"`
+	AssigneeFirstName pgtype.Text        `json:"assignee_first_name"`
+	AssigneeLastName  pgtype.Text        `json:"assignee_last_name"`
+	AssigneeUsername  pgtype.Text        `json:"assignee_username"`
+	AssigneeEmail     pgtype.Text        `json:"assignee_email"`
+}
+
+func (q *Queries) GetTasksByCycle(ctx context.Context, cycleID pgtype.UUID) ([]GetTasksByCycleRow, error) {
+	rows, err := q.db.Query(ctx, getTasksByCycle, cycleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTasksByCycleRow
+	for rows.Next() {
+		var i GetTasksByCycleRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.WorkspaceID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProjectID,
+			&i.MilestoneID,
+			&i.CycleID,
+			&i.StartDate,
+			&i.AssigneeID,
+			&i.AssigneeFirstName,
+			&i.AssigneeLastName,
+			&i.AssigneeUsername,
+			&i.AssigneeEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTasksByProject = `-- name: GetTasksByProject :many
+SELECT
+    t.id AS task_id,
+    t.workspace_id,
+    t.title,
+    t.description,
+    t.status,
+    t.priority,
+    t.due_date,
+    t.created_at,
+    t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
+
+    -- Assignee info
+    u.id AS assignee_id,
+    u.first_name AS assignee_first_name,
+    u.last_name AS assignee_last_name,
+    u.username AS assignee_username,
+    u.email AS assignee_email
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
+WHERE t.project_id = $1
+ORDER BY t.created_at DESC
+`
+
+type GetTasksByProjectRow struct {
+	TaskID            pgtype.UUID        `json:"task_id"`
+	WorkspaceID       pgtype.UUID        `json:"workspace_id"`
+	Title             string             `json:"title"`
+	Description       pgtype.Text        `json:"description"`
+	Status            TaskStatus         `json:"status"`
+	Priority          TaskPriority       `json:"priority"`
+	DueDate           pgtype.Timestamptz `json:"due_date"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ProjectID         pgtype.UUID        `json:"project_id"`
+	MilestoneID       pgtype.UUID        `json:"milestone_id"`
+	CycleID           pgtype.UUID        `json:"cycle_id"`
+	StartDate         pgtype.Timestamptz `json:"start_date"`
+	AssigneeID        pgtype.Text        `json:"assignee_id"`
+	AssigneeFirstName pgtype.Text        `json:"assignee_first_name"`
+	AssigneeLastName  pgtype.Text        `json:"assignee_last_name"`
+	AssigneeUsername  pgtype.Text        `json:"assignee_username"`
+	AssigneeEmail     pgtype.Text        `json:"assignee_email"`
+}
+
+func (q *Queries) GetTasksByProject(ctx context.Context, projectID pgtype.UUID) ([]GetTasksByProjectRow, error) {
+	rows, err := q.db.Query(ctx, getTasksByProject, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTasksByProjectRow
+	for rows.Next() {
+		var i GetTasksByProjectRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.WorkspaceID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProjectID,
+			&i.MilestoneID,
+			&i.CycleID,
+			&i.StartDate,
+			&i.AssigneeID,
+			&i.AssigneeFirstName,
+			&i.AssigneeLastName,
+			&i.AssigneeUsername,
+			&i.AssigneeEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTasksByWorkspace = `-- name: GetTasksByWorkspace :many
 SELECT
     t.id AS task_id,
@@ -150,6 +459,10 @@ SELECT
     t.due_date,
     t.created_at,
     t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
 
     -- Assignee info
     u.id AS assignee_id,
@@ -157,8 +470,8 @@ SELECT
     u.last_name AS assignee_last_name,
     u.username AS assignee_username,
     u.email AS assignee_email
-FROM tasks t
-LEFT JOIN users u ON t.assignee_id = u.id
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
 WHERE t.workspace_id = $1
 ORDER BY t.created_at DESC
 `
@@ -173,6 +486,10 @@ type GetTasksByWorkspaceRow struct {
 	DueDate           pgtype.Timestamptz `json:"due_date"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	ProjectID         pgtype.UUID        `json:"project_id"`
+	MilestoneID       pgtype.UUID        `json:"milestone_id"`
+	CycleID           pgtype.UUID        `json:"cycle_id"`
+	StartDate         pgtype.Timestamptz `json:"start_date"`
 	AssigneeID        pgtype.Text        `json:"assignee_id"`
 	AssigneeFirstName pgtype.Text        `json:"assignee_first_name"`
 	AssigneeLastName  pgtype.Text        `json:"assignee_last_name"`
@@ -199,6 +516,10 @@ func (q *Queries) GetTasksByWorkspace(ctx context.Context, workspaceID pgtype.UU
 			&i.DueDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ProjectID,
+			&i.MilestoneID,
+			&i.CycleID,
+			&i.StartDate,
 			&i.AssigneeID,
 			&i.AssigneeFirstName,
 			&i.AssigneeLastName,
@@ -215,17 +536,199 @@ func (q *Queries) GetTasksByWorkspace(ctx context.Context, workspaceID pgtype.UU
 	return items, nil
 }
 
+const getWorkspaceProjectTaskStatusCounts = `-- name: GetWorkspaceProjectTaskStatusCounts :many
+SELECT
+    project_id,
+    status,
+    COUNT(*) AS task_count
+FROM tasks
+WHERE
+    workspace_id = $1
+    AND project_id IS NOT NULL
+GROUP BY project_id, status
+`
+
+type GetWorkspaceProjectTaskStatusCountsRow struct {
+	ProjectID pgtype.UUID `json:"project_id"`
+	Status    TaskStatus  `json:"status"`
+	TaskCount int64       `json:"task_count"`
+}
+
+func (q *Queries) GetWorkspaceProjectTaskStatusCounts(ctx context.Context, workspaceID pgtype.UUID) ([]GetWorkspaceProjectTaskStatusCountsRow, error) {
+	rows, err := q.db.Query(ctx, getWorkspaceProjectTaskStatusCounts, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceProjectTaskStatusCountsRow
+	for rows.Next() {
+		var i GetWorkspaceProjectTaskStatusCountsRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Status,
+			&i.TaskCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskDependencies = `-- name: ListTaskDependencies :many
+SELECT task_id, depends_on_id, created_at
+FROM task_dependencies
+WHERE task_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListTaskDependencies(ctx context.Context, taskID pgtype.UUID) ([]TaskDependency, error) {
+	rows, err := q.db.Query(ctx, listTaskDependencies, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskDependency
+	for rows.Next() {
+		var i TaskDependency
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.DependsOnID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkspaceTaskDependencies = `-- name: ListWorkspaceTaskDependencies :many
+SELECT
+    d.task_id,
+    d.depends_on_id,
+    d.created_at
+FROM task_dependencies AS d
+INNER JOIN tasks AS t ON d.task_id = t.id
+WHERE t.workspace_id = $1
+`
+
+func (q *Queries) ListWorkspaceTaskDependencies(ctx context.Context, workspaceID pgtype.UUID) ([]TaskDependency, error) {
+	rows, err := q.db.Query(ctx, listWorkspaceTaskDependencies, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskDependency
+	for rows.Next() {
+		var i TaskDependency
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.DependsOnID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setTaskCycle = `-- name: SetTaskCycle :one
+UPDATE tasks
+SET
+    cycle_id = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at, project_id, milestone_id, cycle_id, start_date
+`
+
+type SetTaskCycleParams struct {
+	ID      pgtype.UUID `json:"id"`
+	CycleID pgtype.UUID `json:"cycle_id"`
+}
+
+func (q *Queries) SetTaskCycle(ctx context.Context, arg SetTaskCycleParams) (Task, error) {
+	row := q.db.QueryRow(ctx, setTaskCycle, arg.ID, arg.CycleID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.Title,
+		&i.Description,
+		&i.AssigneeID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.MilestoneID,
+		&i.CycleID,
+		&i.StartDate,
+	)
+	return i, err
+}
+
+const setTaskProject = `-- name: SetTaskProject :one
+UPDATE tasks
+SET
+    project_id = $2,
+    milestone_id = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at, project_id, milestone_id, cycle_id, start_date
+`
+
+type SetTaskProjectParams struct {
+	ID          pgtype.UUID `json:"id"`
+	ProjectID   pgtype.UUID `json:"project_id"`
+	MilestoneID pgtype.UUID `json:"milestone_id"`
+}
+
+func (q *Queries) SetTaskProject(ctx context.Context, arg SetTaskProjectParams) (Task, error) {
+	row := q.db.QueryRow(ctx, setTaskProject, arg.ID, arg.ProjectID, arg.MilestoneID)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.Title,
+		&i.Description,
+		&i.AssigneeID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.MilestoneID,
+		&i.CycleID,
+		&i.StartDate,
+	)
+	return i, err
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
 SET
-  title = $2, -- title
-  description = $3, -- description
-  assignee_id = $4, -- assignee_id
-  status = $5, -- status
-  priority = $6, -- priority
-  due_date = $7  -- due_date
+    title = $2, -- title
+    description = $3, -- description
+    assignee_id = $4, -- assignee_id
+    status = $5, -- status
+    priority = $6, -- priority
+    due_date = $7, -- due_date
+    start_date = $8  -- start_date
 WHERE id = $1
-RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at
+RETURNING id, workspace_id, title, description, assignee_id, status, priority, due_date, created_at, updated_at, project_id, milestone_id, cycle_id, start_date
 `
 
 type UpdateTaskParams struct {
@@ -236,6 +739,7 @@ type UpdateTaskParams struct {
 	Status      TaskStatus         `json:"status"`
 	Priority    TaskPriority       `json:"priority"`
 	DueDate     pgtype.Timestamptz `json:"due_date"`
+	StartDate   pgtype.Timestamptz `json:"start_date"`
 }
 
 func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error) {
@@ -247,6 +751,7 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		arg.Status,
 		arg.Priority,
 		arg.DueDate,
+		arg.StartDate,
 	)
 	var i Task
 	err := row.Scan(
@@ -260,6 +765,10 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ProjectID,
+		&i.MilestoneID,
+		&i.CycleID,
+		&i.StartDate,
 	)
 	return i, err
 }
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+// Automation errors
+var (
+	ErrAutomationRuleNotFound = errors.New("automation rule not found")
+	ErrInvalidAutomationData  = errors.New("invalid automation data")
+)
+
+// Automation triggers
+const (
+	TriggerTaskCreated       = "task_created"
+	TriggerTaskStatusChanged = "task_status_changed"
+	TriggerTaskDueDatePassed = "task_due_date_passed"
+	TriggerNoteTagged        = "note_tagged"
+)
+
+// Automation actions
+const (
+	ActionSetAssignee = "set_assignee"
+	ActionSetPriority = "set_priority"
+	ActionAddComment  = "add_comment"
+	ActionCreateTask  = "create_task"
+	ActionWebhook     = "webhook"
+)
+
+// Condition operators
+const (
+	OpEquals     = "equals"
+	OpNotEquals  = "not_equals"
+	OpContains   = "contains"
+	OpIsEmpty    = "is_empty"
+	OpIsNotEmpty = "is_not_empty"
+)
+
+var (
+	automationTriggers  = []string{TriggerTaskCreated, TriggerTaskStatusChanged, TriggerTaskDueDatePassed, TriggerNoteTagged}
+	automationOperators = []string{OpEquals, OpNotEquals, OpContains, OpIsEmpty, OpIsNotEmpty}
+	taskConditionFields = []string{"title", "description", "status", "priority", "assignee_id"}
+	noteConditionFields = []string{"title", "author_id", "tags"}
+	taskOnlyActions     = []string{ActionSetAssignee, ActionSetPriority, ActionAddComment}
+)
+
+const automationRunsLimit = 100
+
+type AutomationTriggerConfig struct {
+	// Status narrows task_status_changed to transitions into this status
+	Status string `json:"status,omitempty"`
+	// Tag is required for note_tagged
+	Tag string `json:"tag,omitempty"`
+}
+
+// AutomationCondition is evaluated against the task or note that fired the
+// trigger. All conditions of a rule must hold for its actions to run.
+type AutomationCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value,omitempty"`
+}
+
+type AutomationAction struct {
+	Type       string `json:"type"`
+	AssigneeID string `json:"assignee_id,omitempty"`
+	Priority   string `json:"priority,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Title      string `json:"title,omitempty"`
+	DueInDays  int    `json:"due_in_days,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+type AutomationRuleInput struct {
+	Name          string
+	Enabled       *bool
+	TriggerType   string
+	TriggerConfig AutomationTriggerConfig
+	Conditions    []AutomationCondition
+	Actions       []AutomationAction
+}
+
+type UpdateAutomationRuleInput struct {
+	Name          *string
+	Enabled       *bool
+	TriggerType   *string
+	TriggerConfig *AutomationTriggerConfig
+	Conditions    *[]AutomationCondition
+	Actions       *[]AutomationAction
+}
+
+type AutomationServicer interface {
+	CreateRule(ctx context.Context, workspaceID, userID string, input AutomationRuleInput) (models.AutomationRule, error)
+	ListRules(ctx context.Context, workspaceID string) ([]models.AutomationRule, error)
+	GetRule(ctx context.Context, workspaceID, ruleID string) (models.AutomationRule, error)
+	UpdateRule(ctx context.Context, workspaceID, ruleID string, input UpdateAutomationRuleInput) (models.AutomationRule, error)
+	DeleteRule(ctx context.Context, workspaceID, ruleID string) error
+	ListRuns(ctx context.Context, workspaceID, ruleID string) ([]models.AutomationRun, error)
+}
+
+type AutomationService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ AutomationServicer = (*AutomationService)(nil)
+
+func NewAutomationService(store *store.Store) *AutomationService {
+	return &AutomationService{s: store}
+}
+
+func (s *AutomationService) CreateRule(ctx context.Context, workspaceID, userID string, input AutomationRuleInput) (models.AutomationRule, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.AutomationRule{}, ErrInvalidAutomationData
+	}
+
+	params, err := encodeAutomationRule(input)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+	params.WorkspaceID = wsID
+	params.CreatedBy = pgtype.Text{String: userID, Valid: userID != ""}
+
+	rule, err := s.s.Queries.CreateAutomationRule(ctx, params)
+	if err != nil {
+		return models.AutomationRule{}, fmt.Errorf("failed to create automation rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *AutomationService) ListRules(ctx context.Context, workspaceID string) ([]models.AutomationRule, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidAutomationData
+	}
+
+	rules, err := s.s.Queries.ListAutomationRules(ctx, wsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation rules: %w", err)
+	}
+	if rules == nil {
+		rules = make([]models.AutomationRule, 0)
+	}
+	return rules, nil
+}
+
+func (s *AutomationService) GetRule(ctx context.Context, workspaceID, ruleID string) (models.AutomationRule, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.AutomationRule{}, ErrInvalidAutomationData
+	}
+	rID, err := parseUUID(ruleID)
+	if err != nil {
+		return models.AutomationRule{}, ErrInvalidAutomationData
+	}
+
+	rule, err := s.s.Queries.GetAutomationRule(ctx, models.GetAutomationRuleParams{
+		WorkspaceID: wsID,
+		ID:          rID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AutomationRule{}, ErrAutomationRuleNotFound
+		}
+		return models.AutomationRule{}, fmt.Errorf("failed to get automation rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *AutomationService) UpdateRule(ctx context.Context, workspaceID, ruleID string, input UpdateAutomationRuleInput) (models.AutomationRule, error) {
+	current, err := s.GetRule(ctx, workspaceID, ruleID)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+
+	merged, err := decodeAutomationRule(current)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+	if input.Name != nil {
+		merged.Name = *input.Name
+	}
+	if input.Enabled != nil {
+		merged.Enabled = input.Enabled
+	}
+	if input.TriggerType != nil {
+		merged.TriggerType = *input.TriggerType
+	}
+	if input.TriggerConfig != nil {
+		merged.TriggerConfig = *input.TriggerConfig
+	}
+	if input.Conditions != nil {
+		merged.Conditions = *input.Conditions
+	}
+	if input.Actions != nil {
+		merged.Actions = *input.Actions
+	}
+
+	encoded, err := encodeAutomationRule(merged)
+	if err != nil {
+		return models.AutomationRule{}, err
+	}
+
+	updated, err := s.s.Queries.UpdateAutomationRule(ctx, models.UpdateAutomationRuleParams{
+		WorkspaceID:   current.WorkspaceID,
+		ID:            current.ID,
+		Name:          encoded.Name,
+		Enabled:       encoded.Enabled,
+		TriggerType:   encoded.TriggerType,
+		TriggerConfig: encoded.TriggerConfig,
+		Conditions:    encoded.Conditions,
+		Actions:       encoded.Actions,
+	})
+	if err != nil {
+		return models.AutomationRule{}, fmt.Errorf("failed to update automation rule: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *AutomationService) DeleteRule(ctx context.Context, workspaceID, ruleID string) error {
+	rule, err := s.GetRule(ctx, workspaceID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	err = s.s.Queries.DeleteAutomationRule(ctx, models.DeleteAutomationRuleParams{
+		WorkspaceID: rule.WorkspaceID,
+		ID:          rule.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", err)
+	}
+	return nil
+}
+
+func (s *AutomationService) ListRuns(ctx context.Context, workspaceID, ruleID string) ([]models.AutomationRun, error) {
+	rule, err := s.GetRule(ctx, workspaceID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.s.Queries.ListAutomationRuns(ctx, models.ListAutomationRunsParams{
+		RuleID: rule.ID,
+		Limit:  automationRunsLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation runs: %w", err)
+	}
+	if runs == nil {
+		runs = make([]models.AutomationRun, 0)
+	}
+	return runs, nil
+}
+
+// encodeAutomationRule validates a rule definition and converts it to its
+// column representation. The caller fills in the workspace and creator.
+func encodeAutomationRule(input AutomationRuleInput) (models.CreateAutomationRuleParams, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || !slices.Contains(automationTriggers, input.TriggerType) {
+		return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+	}
+
+	config := input.TriggerConfig
+	switch input.TriggerType {
+	case TriggerTaskStatusChanged:
+		if config.Status != "" && !slices.Contains(taskStatusOrder, models.TaskStatus(config.Status)) {
+			return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+		}
+		config.Tag = ""
+	case TriggerNoteTagged:
+		config.Tag = strings.TrimSpace(config.Tag)
+		if config.Tag == "" {
+			return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+		}
+		config.Status = ""
+	default:
+		config = AutomationTriggerConfig{}
+	}
+
+	isNoteTrigger := input.TriggerType == TriggerNoteTagged
+	fields := taskConditionFields
+	if isNoteTrigger {
+		fields = noteConditionFields
+	}
+	for _, cond := range input.Conditions {
+		if !slices.Contains(fields, cond.Field) || !slices.Contains(automationOperators, cond.Operator) {
+			return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+		}
+	}
+
+	if len(input.Actions) == 0 {
+		return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+	}
+	for _, action := range input.Actions {
+		if isNoteTrigger && slices.Contains(taskOnlyActions, action.Type) {
+			return models.CreateAutomationRuleParams{}, ErrInvalidAutomationData
+		}
+		if err := validateAutomationAction(action); err != nil {
+			return models.CreateAutomationRuleParams{}, err
+		}
+	}
+
+	conditions := input.Conditions
+	if conditions == nil {
+		conditions = []AutomationCondition{}
+	}
+	enabled := true
+	if input.Enabled != nil {
+		enabled = *input.Enabled
+	}
+
+	encodedConfig, err := json.Marshal(config)
+	if err != nil {
+		return models.CreateAutomationRuleParams{}, fmt.Errorf("failed to encode trigger config: %w", err)
+	}
+	encodedConditions, err := json.Marshal(conditions)
+	if err != nil {
+		return models.CreateAutomationRuleParams{}, fmt.Errorf("failed to encode conditions: %w", err)
+	}
+	encodedActions, err := json.Marshal(input.Actions)
+	if err != nil {
+		return models.CreateAutomationRuleParams{}, fmt.Errorf("failed to encode actions: %w", err)
+	}
+
+	return models.CreateAutomationRuleParams{
+		Name:          name,
+		Enabled:       enabled,
+		TriggerType:   input.TriggerType,
+		TriggerConfig: encodedConfig,
+		Conditions:    encodedConditions,
+		Actions:       encodedActions,
+	}, nil
+}
+
+func validateAutomationAction(action AutomationAction) error {
+	switch action.Type {
+	case ActionSetAssignee:
+		// An empty assignee unassigns the task
+		return nil
+	case ActionSetPriority:
+		if !slices.Contains(taskPriorityOrder, models.TaskPriority(action.Priority)) {
+			return ErrInvalidAutomationData
+		}
+	case ActionAddComment:
+		if strings.TrimSpace(action.Comment) == "" {
+			return ErrInvalidAutomationData
+		}
+	case ActionCreateTask:
+		if action.DueInDays < 0 {
+			return ErrInvalidAutomationData
+		}
+		if action.Priority != "" && !slices.Contains(taskPriorityOrder, models.TaskPriority(action.Priority)) {
+			return ErrInvalidAutomationData
+		}
+	case ActionWebhook:
+		u, err := url.Parse(action.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return ErrInvalidAutomationData
+		}
+		// Hostnames are checked once resolved, when the webhook is called
+		if ip, err := netip.ParseAddr(u.Hostname()); (err == nil && !isPublicAddr(ip)) || strings.EqualFold(u.Hostname(), "localhost") {
+			return ErrInvalidAutomationData
+		}
+	default:
+		return ErrInvalidAutomationData
+	}
+	return nil
+}
+
+func decodeAutomationRule(rule models.AutomationRule) (AutomationRuleInput, error) {
+	enabled := rule.Enabled
+	def := AutomationRuleInput{
+		Name:        rule.Name,
+		Enabled:     &enabled,
+		TriggerType: rule.TriggerType,
+	}
+	if err := json.Unmarshal(rule.TriggerConfig, &def.TriggerConfig); err != nil {
+		return AutomationRuleInput{}, fmt.Errorf("failed to decode trigger config: %w", err)
+	}
+	if err := json.Unmarshal(rule.Conditions, &def.Conditions); err != nil {
+		return AutomationRuleInput{}, fmt.Errorf("failed to decode conditions: %w", err)
+	}
+	if err := json.Unmarshal(rule.Actions, &def.Actions); err != nil {
+		return AutomationRuleInput{}, fmt.Errorf("failed to decode actions: %w", err)
+	}
+	return def, nil
+}
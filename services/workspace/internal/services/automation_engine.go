@@ -0,0 +1,561 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+const (
+	// maxAutomationDepth bounds how many rules can fire in a row off events
+	// produced by other rules' actions.
+	maxAutomationDepth  = 3
+	automationQueueSize = 256
+	automationTimeout   = 30 * time.Second
+	dueDatePollInterval = time.Minute
+	// Webhooks are called by their own workers, so slow endpoints do not
+	// hold up other rules
+	webhookWorkers = 4
+	webhookTimeout = 10 * time.Second
+)
+
+var errWebhookAddress = errors.New("webhook address is not public")
+
+// nonPublicPrefixes are reserved ranges the net/netip predicates leave out
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Automation run statuses
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunSkipped   = "skipped"
+)
+
+// AutomationEvent describes a committed mutation that may fire automation
+// rules.
+type AutomationEvent struct {
+	Trigger     string
+	WorkspaceID pgtype.UUID
+	SubjectID   pgtype.UUID
+	// Status is the new status for task_status_changed
+	Status models.TaskStatus
+	// Tags are the tags added by the mutation for note_tagged
+	Tags []string
+
+	chain automationChain
+}
+
+// AutomationEmitter receives events once the mutation that produced them
+// has been committed. Emit must not block the caller.
+type AutomationEmitter interface {
+	Emit(ctx context.Context, event AutomationEvent)
+}
+
+// automationChain tracks the rules whose actions led to an event, so a rule
+// can't trigger itself and chains stay bounded.
+type automationChain struct {
+	depth int
+	rules []pgtype.UUID
+}
+
+type automationChainKey struct{}
+
+func chainFromContext(ctx context.Context) automationChain {
+	chain, _ := ctx.Value(automationChainKey{}).(automationChain)
+	return chain
+}
+
+type AutomationEngine struct {
+	s        *store.Store
+	queue    chan AutomationEvent
+	webhooks chan webhookJob
+	client   *http.Client
+}
+
+// webhookJob holds the webhook actions of a rule run, which is recorded
+// once they are done
+type webhookJob struct {
+	rule    models.AutomationRule
+	event   AutomationEvent
+	subject automationSubject
+	actions []AutomationAction
+	started time.Time
+}
+
+// Compile time interface implementation check
+var _ AutomationEmitter = (*AutomationEngine)(nil)
+
+func NewAutomationEngine(store *store.Store) *AutomationEngine {
+	// Rules are written by any member, so webhooks may only reach public
+	// addresses. The check is made on the address dialed, after DNS
+	// resolution and on redirects.
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(ip) {
+				return errWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &AutomationEngine{
+		s:        store,
+		queue:    make(chan AutomationEvent, automationQueueSize),
+		webhooks: make(chan webhookJob, automationQueueSize),
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: webhookTimeout,
+				MaxIdleConns:        16,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+// Start processes queued events and polls for passed due dates until ctx is
+// cancelled.
+func (e *AutomationEngine) Start(ctx context.Context) {
+	for range webhookWorkers {
+		go e.deliverWebhooks(ctx)
+	}
+
+	ticker := time.NewTicker(dueDatePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-e.queue:
+			e.handle(ctx, event)
+		case <-ticker.C:
+			e.checkDueDates(ctx)
+		}
+	}
+}
+
+func (e *AutomationEngine) Emit(ctx context.Context, event AutomationEvent) {
+	event.chain = chainFromContext(ctx)
+	select {
+	case e.queue <- event:
+	default:
+		log.Printf("WARNING: automation queue full, dropping %s event for %s", event.Trigger, uuidString(event.SubjectID))
+	}
+}
+
+func (e *AutomationEngine) handle(ctx context.Context, event AutomationEvent) {
+	ctx, cancel := context.WithTimeout(ctx, automationTimeout)
+	defer cancel()
+
+	rules, err := e.s.Queries.ListEnabledAutomationRules(ctx, models.ListEnabledAutomationRulesParams{
+		WorkspaceID: event.WorkspaceID,
+		TriggerType: event.Trigger,
+	})
+	if err != nil {
+		log.Printf("Failed to load automation rules: %v", err)
+		return
+	}
+	for _, rule := range rules {
+		e.run(ctx, rule, event)
+	}
+}
+
+func (e *AutomationEngine) checkDueDates(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, automationTimeout)
+	defer cancel()
+
+	rules, err := e.s.Queries.ListEnabledAutomationRulesByTrigger(ctx, TriggerTaskDueDatePassed)
+	if err != nil {
+		log.Printf("Failed to load due date automation rules: %v", err)
+		return
+	}
+	for _, rule := range rules {
+		tasks, err := e.s.Queries.ListOverdueTasksForRule(ctx, models.ListOverdueTasksForRuleParams{
+			WorkspaceID: rule.WorkspaceID,
+			RuleID:      rule.ID,
+		})
+		if err != nil {
+			log.Printf("Failed to load overdue tasks: %v", err)
+			continue
+		}
+		for _, task := range tasks {
+			e.run(ctx, rule, AutomationEvent{
+				Trigger:     TriggerTaskDueDatePassed,
+				WorkspaceID: task.WorkspaceID,
+				SubjectID:   task.ID,
+			})
+		}
+	}
+}
+
+// run evaluates a single rule against an event and records the outcome in
+// the execution log. Rules whose trigger or conditions don't match are not
+// logged.
+func (e *AutomationEngine) run(ctx context.Context, rule models.AutomationRule, event AutomationEvent) {
+	started := time.Now()
+	def, err := decodeAutomationRule(rule)
+	if err != nil {
+		e.record(ctx, rule, event, started, RunFailed, err)
+		return
+	}
+	if !triggerMatches(def, event) {
+		return
+	}
+
+	if slices.Contains(event.chain.rules, rule.ID) {
+		e.record(ctx, rule, event, started, RunSkipped, errors.New("loop detected: rule already ran in this chain"))
+		return
+	}
+	if event.chain.depth >= maxAutomationDepth {
+		e.record(ctx, rule, event, started, RunSkipped, fmt.Errorf("loop protection: chain depth limit of %d reached", maxAutomationDepth))
+		return
+	}
+
+	subject, err := e.loadSubject(ctx, event)
+	if err != nil {
+		e.record(ctx, rule, event, started, RunFailed, err)
+		return
+	}
+	for _, cond := range def.Conditions {
+		if !subject.matches(cond) {
+			return
+		}
+	}
+
+	actionCtx := context.WithValue(ctx, automationChainKey{}, automationChain{
+		depth: event.chain.depth + 1,
+		rules: append(slices.Clone(event.chain.rules), rule.ID),
+	})
+	var webhooks []AutomationAction
+	for _, action := range def.Actions {
+		// Webhooks are called last, off this goroutine
+		if action.Type == ActionWebhook {
+			webhooks = append(webhooks, action)
+			continue
+		}
+		if err := e.execute(actionCtx, event, subject, action); err != nil {
+			e.record(ctx, rule, event, started, RunFailed, fmt.Errorf("%s: %w", action.Type, err))
+			return
+		}
+	}
+	if len(webhooks) > 0 {
+		select {
+		case e.webhooks <- webhookJob{rule: rule, event: event, subject: subject, actions: webhooks, started: started}:
+		default:
+			e.record(ctx, rule, event, started, RunFailed, errors.New("webhook queue full"))
+		}
+		return
+	}
+	e.record(ctx, rule, event, started, RunSucceeded, nil)
+}
+
+// deliverWebhooks calls queued webhooks until ctx is cancelled
+func (e *AutomationEngine) deliverWebhooks(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-e.webhooks:
+			e.deliver(ctx, job)
+		}
+	}
+}
+
+func (e *AutomationEngine) deliver(ctx context.Context, job webhookJob) {
+	ctx, cancel := context.WithTimeout(ctx, automationTimeout)
+	defer cancel()
+
+	for _, action := range job.actions {
+		if err := e.callWebhook(ctx, job.rule, job.event, job.subject, action); err != nil {
+			e.record(ctx, job.rule, job.event, job.started, RunFailed, fmt.Errorf("%s: %w", action.Type, err))
+			return
+		}
+	}
+	e.record(ctx, job.rule, job.event, job.started, RunSucceeded, nil)
+}
+
+func (e *AutomationEngine) record(ctx context.Context, rule models.AutomationRule, event AutomationEvent,
+	started time.Time, status string, runErr error) {
+	var errText pgtype.Text
+	if runErr != nil {
+		errText = pgtype.Text{String: runErr.Error(), Valid: true}
+	}
+	_, err := e.s.Queries.CreateAutomationRun(ctx, models.CreateAutomationRunParams{
+		RuleID:      rule.ID,
+		WorkspaceID: rule.WorkspaceID,
+		TriggerType: event.Trigger,
+		SubjectID:   event.SubjectID,
+		Depth:       int32(event.chain.depth),
+		Status:      status,
+		Error:       errText,
+		StartedAt:   pgtype.Timestamptz{Time: started, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Failed to record automation run: %v", err)
+	}
+}
+
+func triggerMatches(def AutomationRuleInput, event AutomationEvent) bool {
+	switch def.TriggerType {
+	case TriggerTaskStatusChanged:
+		return def.TriggerConfig.Status == "" || models.TaskStatus(def.TriggerConfig.Status) == event.Status
+	case TriggerNoteTagged:
+		return slices.ContainsFunc(event.Tags, func(tag string) bool {
+			return strings.EqualFold(tag, def.TriggerConfig.Tag)
+		})
+	}
+	return def.TriggerType == event.Trigger
+}
+
+// automationSubject is a snapshot of the task or note an event refers to.
+type automationSubject struct {
+	kind   string // "task" or "note"
+	task   models.GetTaskByIDRow
+	title  string
+	fields map[string]string
+	tags   []string
+}
+
+func (e *AutomationEngine) loadSubject(ctx context.Context, event AutomationEvent) (automationSubject, error) {
+	if event.Trigger == TriggerNoteTagged {
+		note, err := e.s.Queries.GetWorkspaceNote(ctx, models.GetWorkspaceNoteParams{
+			WorkspaceID: event.WorkspaceID,
+			ID:          event.SubjectID,
+		})
+		if err != nil {
+			return automationSubject{}, fmt.Errorf("failed to load note: %w", err)
+		}
+		return automationSubject{
+			kind:  "note",
+			title: note.Title,
+			fields: map[string]string{
+				"title":     note.Title,
+				"author_id": note.AuthorID.String,
+			},
+			tags: note.Tags,
+		}, nil
+	}
+
+	task, err := e.s.Queries.GetTaskByID(ctx, event.SubjectID)
+	if err != nil {
+		return automationSubject{}, fmt.Errorf("failed to load task: %w", err)
+	}
+	return automationSubject{
+		kind:  "task",
+		task:  task,
+		title: task.Title,
+		fields: map[string]string{
+			"title":       task.Title,
+			"description": task.Description.String,
+			"status":      string(task.Status),
+			"priority":    string(task.Priority),
+			"assignee_id": task.AssigneeID.String,
+		},
+	}, nil
+}
+
+func (s automationSubject) matches(cond AutomationCondition) bool {
+	if cond.Field == "tags" {
+		has := slices.ContainsFunc(s.tags, func(tag string) bool {
+			return strings.EqualFold(tag, cond.Value)
+		})
+		switch cond.Operator {
+		case OpEquals, OpContains:
+			return has
+		case OpNotEquals:
+			return !has
+		case OpIsEmpty:
+			return len(s.tags) == 0
+		case OpIsNotEmpty:
+			return len(s.tags) > 0
+		}
+		return false
+	}
+
+	value := s.fields[cond.Field]
+	switch cond.Operator {
+	case OpEquals:
+		return strings.EqualFold(value, cond.Value)
+	case OpNotEquals:
+		return !strings.EqualFold(value, cond.Value)
+	case OpContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(cond.Value))
+	case OpIsEmpty:
+		return value == ""
+	case OpIsNotEmpty:
+		return value != ""
+	}
+	return false
+}
+
+func (e *AutomationEngine) execute(ctx context.Context, event AutomationEvent, subject automationSubject, action AutomationAction) error {
+	switch action.Type {
+	case ActionSetAssignee:
+		return e.updateTask(ctx, subject.task, func(p *models.UpdateTaskParams) {
+			p.AssigneeID = pgtype.Text{String: action.AssigneeID, Valid: action.AssigneeID != ""}
+		})
+	case ActionSetPriority:
+		return e.updateTask(ctx, subject.task, func(p *models.UpdateTaskParams) {
+			p.Priority = models.TaskPriority(action.Priority)
+		})
+	case ActionAddComment:
+		_, err := e.s.Queries.CreateTaskComment(ctx, models.CreateTaskCommentParams{
+			TaskID: subject.task.TaskID,
+			Body:   action.Comment,
+		})
+		return err
+	case ActionCreateTask:
+		return e.createFollowUp(ctx, event, subject, action)
+	}
+	return fmt.Errorf("unknown action %q", action.Type)
+}
+
+func (e *AutomationEngine) updateTask(ctx context.Context, task models.GetTaskByIDRow, apply func(*models.UpdateTaskParams)) error {
+	params := models.UpdateTaskParams{
+		ID:          task.TaskID,
+		Title:       task.Title,
+		Description: task.Description,
+		AssigneeID:  task.AssigneeID,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		DueDate:     task.DueDate,
+		StartDate:   task.StartDate,
+	}
+	apply(&params)
+	_, err := e.s.Queries.UpdateTask(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+func (e *AutomationEngine) createFollowUp(ctx context.Context, event AutomationEvent, subject automationSubject, action AutomationAction) error {
+	title := strings.TrimSpace(action.Title)
+	if title == "" {
+		title = "Follow up: " + subject.title
+	}
+	priority := models.TaskPriorityMedium
+	if action.Priority != "" {
+		priority = models.TaskPriority(action.Priority)
+	}
+	var dueDate pgtype.Timestamptz
+	if action.DueInDays > 0 {
+		dueDate = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, action.DueInDays), Valid: true}
+	}
+
+	task, err := e.s.Queries.CreateNewTask(ctx, models.CreateNewTaskParams{
+		WorkspaceID: event.WorkspaceID,
+		Title:       title,
+		AssigneeID:  pgtype.Text{String: action.AssigneeID, Valid: action.AssigneeID != ""},
+		Status:      models.TaskStatusToDo,
+		Priority:    priority,
+		DueDate:     dueDate,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The follow-up is a new task like any other and may fire rules itself
+	e.Emit(ctx, AutomationEvent{
+		Trigger:     TriggerTaskCreated,
+		WorkspaceID: task.WorkspaceID,
+		SubjectID:   task.ID,
+	})
+	return nil
+}
+
+type webhookPayload struct {
+	RuleID      string    `json:"rule_id"`
+	RuleName    string    `json:"rule_name"`
+	Trigger     string    `json:"trigger"`
+	WorkspaceID string    `json:"workspace_id"`
+	SubjectType string    `json:"subject_type"`
+	SubjectID   string    `json:"subject_id"`
+	Title       string    `json:"title"`
+	FiredAt     time.Time `json:"fired_at"`
+}
+
+func (e *AutomationEngine) callWebhook(ctx context.Context, rule models.AutomationRule, event AutomationEvent,
+	subject automationSubject, action AutomationAction) error {
+	body, err := json.Marshal(webhookPayload{
+		RuleID:      uuidString(rule.ID),
+		RuleName:    rule.Name,
+		Trigger:     event.Trigger,
+		WorkspaceID: uuidString(event.WorkspaceID),
+		SubjectType: subject.kind,
+		SubjectID:   uuidString(event.SubjectID),
+		Title:       subject.title,
+		FiredAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, action.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// isPublicAddr reports whether ip is a public unicast address, which
+// webhooks may call
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func uuidString(id pgtype.UUID) string {
+	value, err := id.Value()
+	if err != nil || value == nil {
+		return ""
+	}
+	return value.(string)
+}
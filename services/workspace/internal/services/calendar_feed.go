@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+var (
+	ErrCalendarFeedNotFound    = errors.New("calendar feed not found")
+	ErrInvalidCalendarFeedData = errors.New("invalid calendar feed data")
+)
+
+// How a calendar feed includes task due dates
+const (
+	CalendarFeedTasksNone  = "none"
+	CalendarFeedTasksTodo  = "todo"
+	CalendarFeedTasksEvent = "event"
+)
+
+// icalUIDDomain ends the UIDs of the events and tasks in calendar feeds
+const icalUIDDomain = "@motion"
+
+type CalendarFeedServicer interface {
+	CreateFeed(ctx context.Context, workspaceID, userID, tasks string) (CreatedCalendarFeed, error)
+	GetFeed(ctx context.Context, workspaceID, userID string) (CalendarFeed, error)
+	UpdateFeed(ctx context.Context, workspaceID, userID, tasks string) (CalendarFeed, error)
+	DeleteFeed(ctx context.Context, workspaceID, userID string) error
+	RenderFeed(ctx context.Context, token string) (string, error)
+}
+
+// CalendarFeed is a member's calendar feed of a workspace. Its token is
+// only known when the feed is created; afterwards the hint identifies it.
+type CalendarFeed struct {
+	ID          pgtype.UUID        `json:"id"`
+	WorkspaceID pgtype.UUID        `json:"workspace_id"`
+	UserID      string             `json:"user_id"`
+	TokenHint   string             `json:"token_hint"`
+	Tasks       string             `json:"tasks"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// CreatedCalendarFeed is a new calendar feed along with its token
+type CreatedCalendarFeed struct {
+	CalendarFeed
+	Token string `json:"token"`
+}
+
+type CalendarFeedService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ CalendarFeedServicer = (*CalendarFeedService)(nil)
+
+func NewCalendarFeedService(store *store.Store) *CalendarFeedService {
+	return &CalendarFeedService{s: store}
+}
+
+// CreateFeed gives the member a calendar feed of the workspace. A member
+// has a single feed per workspace, so creating it again replaces the token
+// and the old URL stops working.
+func (s *CalendarFeedService) CreateFeed(ctx context.Context, workspaceID, userID, tasks string) (CreatedCalendarFeed, error) {
+	if tasks == "" {
+		tasks = CalendarFeedTasksNone
+	}
+	if !validCalendarFeedTasks(tasks) {
+		return CreatedCalendarFeed{}, ErrInvalidCalendarFeedData
+	}
+	wsID, err := s.checkMember(ctx, workspaceID, userID)
+	if err != nil {
+		return CreatedCalendarFeed{}, err
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return CreatedCalendarFeed{}, err
+	}
+	feed, err := s.s.Queries.UpsertCalendarFeed(ctx, models.UpsertCalendarFeedParams{
+		WorkspaceID: wsID,
+		UserID:      userID,
+		TokenHash:   hashSecretToken(token),
+		TokenHint:   token[:6],
+		Tasks:       tasks,
+	})
+	if err != nil {
+		return CreatedCalendarFeed{}, fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+	return CreatedCalendarFeed{CalendarFeed: toCalendarFeed(feed), Token: token}, nil
+}
+
+func (s *CalendarFeedService) GetFeed(ctx context.Context, workspaceID, userID string) (CalendarFeed, error) {
+	wsID, err := s.checkMember(ctx, workspaceID, userID)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+
+	feed, err := s.s.Queries.GetCalendarFeed(ctx, models.GetCalendarFeedParams{
+		WorkspaceID: wsID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CalendarFeed{}, ErrCalendarFeedNotFound
+		}
+		return CalendarFeed{}, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return toCalendarFeed(feed), nil
+}
+
+// UpdateFeed changes how the feed includes tasks, keeping its URL
+func (s *CalendarFeedService) UpdateFeed(ctx context.Context, workspaceID, userID, tasks string) (CalendarFeed, error) {
+	if !validCalendarFeedTasks(tasks) {
+		return CalendarFeed{}, ErrInvalidCalendarFeedData
+	}
+	wsID, err := s.checkMember(ctx, workspaceID, userID)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+
+	feed, err := s.s.Queries.UpdateCalendarFeedTasks(ctx, models.UpdateCalendarFeedTasksParams{
+		WorkspaceID: wsID,
+		UserID:      userID,
+		Tasks:       tasks,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CalendarFeed{}, ErrCalendarFeedNotFound
+		}
+		return CalendarFeed{}, fmt.Errorf("failed to update calendar feed: %w", err)
+	}
+	return toCalendarFeed(feed), nil
+}
+
+func (s *CalendarFeedService) DeleteFeed(ctx context.Context, workspaceID, userID string) error {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil || userID == "" {
+		return ErrInvalidCalendarFeedData
+	}
+
+	rows, err := s.s.Queries.DeleteCalendarFeed(ctx, models.DeleteCalendarFeedParams{
+		WorkspaceID: wsID,
+		UserID:      userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	if rows == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// RenderFeed returns the iCalendar document behind a feed token: the
+// workspace's events, and its tasks' due dates when the feed includes them.
+// Feeds of members who left the workspace stop working.
+func (s *CalendarFeedService) RenderFeed(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", ErrCalendarFeedNotFound
+	}
+	feed, err := s.s.Queries.GetCalendarFeedByTokenHash(ctx, hashSecretToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCalendarFeedNotFound
+		}
+		return "", fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+		UserID:      feed.UserID,
+		WorkspaceID: feed.WorkspaceID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return "", ErrCalendarFeedNotFound
+	}
+
+	workspace, err := s.s.Queries.GetWorkspaceById(ctx, feed.WorkspaceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get workspace: %w", err)
+	}
+	events, err := s.s.Queries.ListWorkspaceEvents(ctx, models.ListWorkspaceEventsParams{
+		WorkspaceID: feed.WorkspaceID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch events: %w", err)
+	}
+	var tasks []models.GetTasksByWorkspaceRow
+	if feed.Tasks != CalendarFeedTasksNone {
+		if tasks, err = s.s.Queries.GetTasksByWorkspace(ctx, feed.WorkspaceID); err != nil {
+			return "", fmt.Errorf("failed to fetch tasks: %w", err)
+		}
+	}
+
+	var w icalWriter
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", icalProductID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", workspace.Name)
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+
+	series := make(map[pgtype.UUID]models.Event)
+	var zones []string
+	for _, event := range events {
+		if event.Rrule.Valid {
+			series[event.ID] = event
+		}
+		if !event.AllDay && event.Timezone != "UTC" && !slices.Contains(zones, event.Timezone) {
+			zones = append(zones, event.Timezone)
+		}
+	}
+	now := time.Now()
+	for _, zone := range zones {
+		if loc, err := loadEventTimezone(zone); err == nil {
+			w.timezone(loc, now)
+		}
+	}
+
+	for _, event := range events {
+		writeICalEvent(&w, event, series)
+	}
+	for _, task := range tasks {
+		writeICalTask(&w, task, feed.Tasks)
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.String(), nil
+}
+
+// checkMember makes sure the user belongs to the workspace, returning the
+// workspace id
+func (s *CalendarFeedService) checkMember(ctx context.Context, workspaceID, userID string) (pgtype.UUID, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil || userID == "" {
+		return pgtype.UUID{}, ErrInvalidCalendarFeedData
+	}
+	isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+		UserID:      userID,
+		WorkspaceID: wsID,
+	})
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return pgtype.UUID{}, ErrWorkspaceAccessDenied
+	}
+	return wsID, nil
+}
+
+// writeICalEvent writes an event as a VEVENT. An occurrence edited on its
+// own shares the UID of its series and names the occurrence it replaces.
+func writeICalEvent(w *icalWriter, event models.Event, series map[pgtype.UUID]models.Event) {
+	source := event
+	var recurrenceID time.Time
+	var recurrenceAllDay bool
+	if event.RecurringEventID.Valid {
+		parent, ok := series[event.RecurringEventID]
+		if !ok {
+			return
+		}
+		source = parent
+		recurrenceID = event.RecurrenceID.Time.In(eventLocation(parent))
+		recurrenceAllDay = parent.AllDay
+	}
+
+	loc := eventLocation(event)
+	start := event.StartsAt.Time.In(loc)
+	w.line("BEGIN", "VEVENT")
+	if source.IcalUid.Valid {
+		// Imported events keep the UID they came with
+		w.text("UID", source.IcalUid.String)
+	} else {
+		w.text("UID", uuidString(source.ID)+icalUIDDomain)
+	}
+	w.utc("DTSTAMP", event.UpdatedAt.Time)
+	w.utc("CREATED", event.CreatedAt.Time)
+	w.utc("LAST-MODIFIED", event.UpdatedAt.Time)
+	w.line("SEQUENCE", fmt.Sprint(event.Sequence))
+	w.text("SUMMARY", event.Name)
+	if event.RecurringEventID.Valid {
+		w.times("RECURRENCE-ID", recurrenceAllDay, recurrenceID)
+	}
+	w.times("DTSTART", event.AllDay, start)
+	if event.AllDay {
+		w.times("DTEND", true, start.AddDate(0, 0, int(event.DurationMinutes/minutesPerDay)))
+	} else {
+		w.times("DTEND", false, start.Add(time.Duration(event.DurationMinutes)*time.Minute))
+	}
+	if event.Rrule.Valid {
+		if rule, err := parseRecurrenceRule(event.Rrule.String); err == nil {
+			w.line("RRULE", icalRule(rule, event.AllDay, loc).String())
+		}
+		if len(event.Exdates) > 0 {
+			exdates := make([]time.Time, len(event.Exdates))
+			for i, exdate := range event.Exdates {
+				exdates[i] = exdate.Time.In(loc)
+			}
+			w.times("EXDATE", event.AllDay, exdates...)
+		}
+	}
+	w.line("END", "VEVENT")
+}
+
+// icalRule adapts UNTIL to the type of the event's start, as RFC 5545
+// requires: a date for all-day events, a UTC date-time otherwise
+func icalRule(rule recurrenceRule, allDay bool, loc *time.Location) recurrenceRule {
+	switch {
+	case rule.until.IsZero():
+	case allDay && !rule.untilDate:
+		y, m, d := rule.until.In(loc).Date()
+		rule.until, rule.untilDate = time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+	case !allDay && rule.untilDate:
+		y, m, d := rule.until.Date()
+		rule.until, rule.untilDate = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Second).UTC(), false
+	}
+	return rule
+}
+
+// writeICalTask writes a task's due date as a VTODO, or as an all-day
+// VEVENT for calendar apps that leave to-dos out. Tasks without a due date
+// are skipped.
+func writeICalTask(w *icalWriter, task models.GetTasksByWorkspaceRow, format string) {
+	if !task.DueDate.Valid {
+		return
+	}
+	y, m, d := task.DueDate.Time.UTC().Date()
+	due := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	if format == CalendarFeedTasksEvent {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", "task-due-"+uuidString(task.TaskID)+icalUIDDomain)
+		w.utc("DTSTAMP", task.UpdatedAt.Time)
+		w.utc("LAST-MODIFIED", task.UpdatedAt.Time)
+		w.text("SUMMARY", task.Title)
+		if task.Description.Valid {
+			w.text("DESCRIPTION", task.Description.String)
+		}
+		w.times("DTSTART", true, due)
+		w.times("DTEND", true, due.AddDate(0, 0, 1))
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+		return
+	}
+
+	w.line("BEGIN", "VTODO")
+	w.line("UID", "task-"+uuidString(task.TaskID)+icalUIDDomain)
+	w.utc("DTSTAMP", task.UpdatedAt.Time)
+	w.utc("CREATED", task.CreatedAt.Time)
+	w.utc("LAST-MODIFIED", task.UpdatedAt.Time)
+	w.text("SUMMARY", task.Title)
+	if task.Description.Valid {
+		w.text("DESCRIPTION", task.Description.String)
+	}
+	if task.StartDate.Valid {
+		y, m, d := task.StartDate.Time.UTC().Date()
+		if start := time.Date(y, m, d, 0, 0, 0, 0, time.UTC); start.Before(due) {
+			w.times("DTSTART", true, start)
+		}
+	}
+	w.times("DUE", true, due)
+	switch task.Status {
+	case models.TaskStatusDone:
+		w.line("STATUS", "COMPLETED")
+	case models.TaskStatusInProgress, models.TaskStatusReview:
+		w.line("STATUS", "IN-PROCESS")
+	default:
+		w.line("STATUS", "NEEDS-ACTION")
+	}
+	switch task.Priority {
+	case models.TaskPriorityHigh:
+		w.line("PRIORITY", "1")
+	case models.TaskPriorityMedium:
+		w.line("PRIORITY", "5")
+	case models.TaskPriorityLow:
+		w.line("PRIORITY", "9")
+	}
+	w.line("END", "VTODO")
+}
+
+func validCalendarFeedTasks(tasks string) bool {
+	switch tasks {
+	case CalendarFeedTasksNone, CalendarFeedTasksTodo, CalendarFeedTasksEvent:
+		return true
+	}
+	return false
+}
+
+func toCalendarFeed(feed models.CalendarFeed) CalendarFeed {
+	return CalendarFeed{
+		ID:          feed.ID,
+		WorkspaceID: feed.WorkspaceID,
+		UserID:      feed.UserID,
+		TokenHint:   feed.TokenHint,
+		Tasks:       feed.Tasks,
+		CreatedAt:   feed.CreatedAt,
+		UpdatedAt:   feed.UpdatedAt,
+	}
+}
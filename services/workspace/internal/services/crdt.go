@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidCRDTOp    = errors.New("invalid document operation")
+	ErrUnknownCRDTRef   = errors.New("operation references an unknown character")
+	ErrCRDTDocumentSize = errors.New("document is too large")
+)
+
+// maxCRDTLength bounds the number of characters, tombstones included, a
+// collaborative document may hold
+const maxCRDTLength = 1 << 20
+
+// CRDT operation kinds
+const (
+	CRDTInsert = "insert"
+	CRDTDelete = "delete"
+)
+
+// CRDTID identifies one character of a collaborative document. IDs are
+// Lamport timestamps: Seq must be greater than any Seq the site has seen,
+// and ties are broken by Site.
+type CRDTID struct {
+	Site string `json:"site"`
+	Seq  uint64 `json:"seq"`
+}
+
+func (id CRDTID) less(other CRDTID) bool {
+	if id.Seq != other.Seq {
+		return id.Seq < other.Seq
+	}
+	return id.Site < other.Site
+}
+
+// CRDTOp is one edit to a document. An insert places Text after the
+// character After, or at the start of the document when After is nil. Its
+// characters take consecutive Seqs starting at ID. A delete removes the
+// character ID.
+type CRDTOp struct {
+	Kind  string  `json:"kind"`
+	ID    CRDTID  `json:"id"`
+	After *CRDTID `json:"after,omitempty"`
+	Text  string  `json:"text,omitempty"`
+}
+
+// CRDTRun is a stretch of consecutive characters from one site, used to
+// send whole documents. Character i of the run has Seq+i.
+type CRDTRun struct {
+	Site    string `json:"site"`
+	Seq     uint64 `json:"seq"`
+	Text    string `json:"text"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+type crdtNode struct {
+	id      CRDTID
+	value   rune
+	deleted bool
+	next    *crdtNode
+}
+
+// CRDTDoc is a replicated growable array (RGA) of characters. Deleted
+// characters stay behind as tombstones so later operations can still refer
+// to them. Applying the same operations in any causal order gives the same
+// text on every replica.
+type CRDTDoc struct {
+	head  crdtNode
+	nodes map[CRDTID]*crdtNode
+	clock uint64
+}
+
+func NewCRDTDoc() *CRDTDoc {
+	return &CRDTDoc{nodes: make(map[CRDTID]*crdtNode)}
+}
+
+// Clock is the highest Seq seen so far
+func (d *CRDTDoc) Clock() uint64 {
+	return d.clock
+}
+
+// Apply integrates an operation. Re-applying an operation is a no-op.
+func (d *CRDTDoc) Apply(op CRDTOp) error {
+	if op.ID.Site == "" || op.ID.Seq == 0 {
+		return ErrInvalidCRDTOp
+	}
+
+	switch op.Kind {
+	case CRDTInsert:
+		if op.Text == "" || !utf8.ValidString(op.Text) {
+			return ErrInvalidCRDTOp
+		}
+		ref := &d.head
+		if op.After != nil {
+			node, ok := d.nodes[*op.After]
+			if !ok {
+				return ErrUnknownCRDTRef
+			}
+			ref = node
+		}
+		if len(d.nodes)+utf8.RuneCountInString(op.Text) > maxCRDTLength {
+			return ErrCRDTDocumentSize
+		}
+		id := op.ID
+		for _, r := range op.Text {
+			ref = d.insert(ref, id, r)
+			id.Seq++
+		}
+		d.clock = max(d.clock, id.Seq-1)
+	case CRDTDelete:
+		node, ok := d.nodes[op.ID]
+		if !ok {
+			return ErrUnknownCRDTRef
+		}
+		node.deleted = true
+		d.clock = max(d.clock, op.ID.Seq)
+	default:
+		return ErrInvalidCRDTOp
+	}
+	return nil
+}
+
+// insert places a character after ref and returns its node. Concurrent
+// inserts after the same character are ordered by descending ID, which
+// means skipping over any following characters with a greater ID. Their
+// own descendants always have greater IDs too, so they are skipped along
+// with them.
+func (d *CRDTDoc) insert(ref *crdtNode, id CRDTID, value rune) *crdtNode {
+	if node, ok := d.nodes[id]; ok {
+		return node
+	}
+	for ref.next != nil && id.less(ref.next.id) {
+		ref = ref.next
+	}
+	node := &crdtNode{id: id, value: value, next: ref.next}
+	ref.next = node
+	d.nodes[id] = node
+	return node
+}
+
+// Text returns the visible document content
+func (d *CRDTDoc) Text() string {
+	text, _ := d.visible()
+	return text
+}
+
+// visible returns the visible content along with the ID of each of its
+// characters.
+func (d *CRDTDoc) visible() (string, []CRDTID) {
+	var (
+		runes []rune
+		ids   []CRDTID
+	)
+	for node := d.head.next; node != nil; node = node.next {
+		if !node.deleted {
+			runes = append(runes, node.value)
+			ids = append(ids, node.id)
+		}
+	}
+	return string(runes), ids
+}
+
+// Runs encodes the whole document, tombstones included, so another replica
+// can rebuild it.
+func (d *CRDTDoc) Runs() []CRDTRun {
+	runs := make([]CRDTRun, 0)
+	var (
+		text []rune
+		last *crdtNode
+	)
+	flush := func() {
+		if len(text) > 0 {
+			runs[len(runs)-1].Text = string(text)
+			text = text[:0]
+		}
+	}
+	for node := d.head.next; node != nil; node = node.next {
+		if last == nil || node.id.Site != last.id.Site || node.id.Seq != last.id.Seq+1 || node.deleted != last.deleted {
+			flush()
+			runs = append(runs, CRDTRun{Site: node.id.Site, Seq: node.id.Seq, Deleted: node.deleted})
+		}
+		text = append(text, node.value)
+		last = node
+	}
+	flush()
+	return runs
+}
+
+// replaceOps returns the operations that turn the characters ids, whose
+// text is from, into to, along with the IDs that make up to afterwards.
+// Only the changed middle is replaced, which keeps concurrent edits to the
+// unchanged start and end intact. New characters are attributed to site.
+func (d *CRDTDoc) replaceOps(site string, ids []CRDTID, from, to string) ([]CRDTOp, []CRDTID) {
+	a, b := []rune(from), []rune(to)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []CRDTOp
+	for _, id := range ids[prefix : len(a)-suffix] {
+		ops = append(ops, CRDTOp{Kind: CRDTDelete, ID: id})
+	}
+	next := make([]CRDTID, 0, len(b))
+	next = append(next, ids[:prefix]...)
+	if inserted := b[prefix : len(b)-suffix]; len(inserted) > 0 {
+		op := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: site, Seq: d.clock + 1}, Text: string(inserted)}
+		if prefix > 0 {
+			after := ids[prefix-1]
+			op.After = &after
+		}
+		ops = append(ops, op)
+		for i := range inserted {
+			next = append(next, CRDTID{Site: site, Seq: op.ID.Seq + uint64(i)})
+		}
+	}
+	next = append(next, ids[len(a)-suffix:]...)
+	return ops, next
+}
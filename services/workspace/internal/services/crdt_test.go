@@ -0,0 +1,390 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func crdtRef(site string, seq uint64) *CRDTID {
+	return &CRDTID{Site: site, Seq: seq}
+}
+
+// permutations returns every ordering of ops
+func permutations(ops []CRDTOp) [][]CRDTOp {
+	if len(ops) <= 1 {
+		return [][]CRDTOp{ops}
+	}
+	var out [][]CRDTOp
+	for i := range ops {
+		rest := make([]CRDTOp, 0, len(ops)-1)
+		rest = append(rest, ops[:i]...)
+		rest = append(rest, ops[i+1:]...)
+		for _, tail := range permutations(rest) {
+			out = append(out, append([]CRDTOp{ops[i]}, tail...))
+		}
+	}
+	return out
+}
+
+// rebuild applies runs to a new document, the way a client loads a
+// snapshot
+func rebuild(t *testing.T, runs []CRDTRun) *CRDTDoc {
+	t.Helper()
+	doc := NewCRDTDoc()
+	var (
+		after   *CRDTID
+		deleted []CRDTID
+	)
+	for _, run := range runs {
+		op := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: run.Site, Seq: run.Seq}, After: after, Text: run.Text}
+		if err := doc.Apply(op); err != nil {
+			t.Fatalf("Apply(%+v) = %v", op, err)
+		}
+		n := uint64(len([]rune(run.Text)))
+		if run.Deleted {
+			for i := uint64(0); i < n; i++ {
+				deleted = append(deleted, CRDTID{Site: run.Site, Seq: run.Seq + i})
+			}
+		}
+		after = crdtRef(run.Site, run.Seq+n-1)
+	}
+	for _, id := range deleted {
+		if err := doc.Apply(CRDTOp{Kind: CRDTDelete, ID: id}); err != nil {
+			t.Fatalf("deleting %+v: %v", id, err)
+		}
+	}
+	return doc
+}
+
+func TestCRDTConvergence(t *testing.T) {
+	// Every test starts from "abc" made by the server, and applies ops that
+	// were made concurrently on other replicas
+	base := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "server", Seq: 1}, Text: "abc"}
+
+	tests := []struct {
+		name string
+		ops  []CRDTOp
+		want string
+	}{
+		{
+			name: "inserts at the same position",
+			ops: []CRDTOp{
+				{Kind: CRDTInsert, ID: CRDTID{Site: "alice", Seq: 4}, After: crdtRef("server", 1), Text: "XX"},
+				{Kind: CRDTInsert, ID: CRDTID{Site: "bob", Seq: 4}, After: crdtRef("server", 1), Text: "YY"},
+				{Kind: CRDTInsert, ID: CRDTID{Site: "carol", Seq: 5}, After: crdtRef("server", 1), Text: "Z"},
+			},
+			want: "aZYYXXbc",
+		},
+		{
+			name: "inserts at the start",
+			ops: []CRDTOp{
+				{Kind: CRDTInsert, ID: CRDTID{Site: "alice", Seq: 4}, Text: "1"},
+				{Kind: CRDTInsert, ID: CRDTID{Site: "bob", Seq: 4}, Text: "2"},
+			},
+			want: "21abc",
+		},
+		{
+			name: "insert after a deleted character",
+			ops: []CRDTOp{
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 2}},
+				{Kind: CRDTInsert, ID: CRDTID{Site: "bob", Seq: 4}, After: crdtRef("server", 2), Text: "X"},
+			},
+			want: "aXc",
+		},
+		{
+			name: "deletes of the same character",
+			ops: []CRDTOp{
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 3}},
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 3}},
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 1}},
+			},
+			want: "b",
+		},
+		{
+			name: "mixed inserts and deletes",
+			ops: []CRDTOp{
+				{Kind: CRDTInsert, ID: CRDTID{Site: "alice", Seq: 4}, After: crdtRef("server", 3), Text: "de"},
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 2}},
+				{Kind: CRDTInsert, ID: CRDTID{Site: "bob", Seq: 4}, After: crdtRef("server", 2), Text: "é"},
+				{Kind: CRDTDelete, ID: CRDTID{Site: "server", Seq: 3}},
+			},
+			want: "aéde",
+		},
+	}
+	for _, tt := range tests {
+		for _, order := range permutations(tt.ops) {
+			doc := NewCRDTDoc()
+			if err := doc.Apply(base); err != nil {
+				t.Fatalf("Apply(%+v) = %v", base, err)
+			}
+			for _, op := range order {
+				if err := doc.Apply(op); err != nil {
+					t.Fatalf("%s: Apply(%+v) = %v", tt.name, op, err)
+				}
+			}
+			if got := doc.Text(); got != tt.want {
+				t.Errorf("%s: applying %+v got %q want %q", tt.name, order, got, tt.want)
+			}
+			if got := rebuild(t, doc.Runs()).Text(); got != tt.want {
+				t.Errorf("%s: rebuilding from runs got %q want %q", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCRDTApply(t *testing.T) {
+	tests := []struct {
+		name string
+		op   CRDTOp
+		want error
+	}{
+		{"missing site", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Seq: 1}, Text: "a"}, ErrInvalidCRDTOp},
+		{"zero seq", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "a"}, Text: "a"}, ErrInvalidCRDTOp},
+		{"empty insert", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "a", Seq: 1}}, ErrInvalidCRDTOp},
+		{"invalid utf-8", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "a", Seq: 1}, Text: "\xff"}, ErrInvalidCRDTOp},
+		{"unknown kind", CRDTOp{Kind: "move", ID: CRDTID{Site: "a", Seq: 1}}, ErrInvalidCRDTOp},
+		{"unknown after", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "a", Seq: 1}, After: crdtRef("b", 9), Text: "a"}, ErrUnknownCRDTRef},
+		{"unknown delete", CRDTOp{Kind: CRDTDelete, ID: CRDTID{Site: "b", Seq: 9}}, ErrUnknownCRDTRef},
+		{"too large", CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "a", Seq: 1}, Text: strings.Repeat("a", maxCRDTLength+1)}, ErrCRDTDocumentSize},
+	}
+	for _, tt := range tests {
+		doc := NewCRDTDoc()
+		if err := doc.Apply(tt.op); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v want %v", tt.name, err, tt.want)
+		}
+		if doc.Text() != "" || doc.Clock() != 0 {
+			t.Errorf("%s: failed op changed the document to %q, clock %d", tt.name, doc.Text(), doc.Clock())
+		}
+	}
+}
+
+func TestCRDTReplaceOps(t *testing.T) {
+	tests := []struct {
+		from, to string
+	}{
+		{"hello world", "hello brave world"},
+		{"hello world", "world"},
+		{"abc", ""},
+		{"", "abc"},
+		{"abc", "xyz"},
+	}
+	for _, tt := range tests {
+		doc := NewCRDTDoc()
+		if tt.from != "" {
+			doc.Apply(CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "server", Seq: 1}, Text: tt.from})
+		}
+		_, ids := doc.visible()
+		ops, next := doc.replaceOps("alice", ids, tt.from, tt.to)
+		for _, op := range ops {
+			if err := doc.Apply(op); err != nil {
+				t.Fatalf("Apply(%+v) = %v", op, err)
+			}
+		}
+		text, got := doc.visible()
+		if text != tt.to {
+			t.Errorf("replacing %q with %q got %q", tt.from, tt.to, text)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(next) {
+			t.Errorf("replacing %q with %q got IDs %v want %v", tt.from, tt.to, got, next)
+		}
+	}
+}
+
+// newTestRoom returns a loaded room holding content, as Join would
+func newTestRoom(t *testing.T, content string) *collabRoom {
+	t.Helper()
+	doc := NewCRDTDoc()
+	if err := doc.Apply(CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: collabServerSite, Seq: 1}, Text: content}); err != nil {
+		t.Fatal(err)
+	}
+	room := &collabRoom{
+		ready:   make(chan struct{}),
+		doc:     doc,
+		epoch:   "epoch",
+		clients: make(map[*CollabClient]struct{}),
+	}
+	room.baseText, room.baseIDs = doc.visible()
+	close(room.ready)
+	return room
+}
+
+// drain returns the messages waiting for client
+func drain(client *CollabClient) []CollabMessage {
+	var msgs []CollabMessage
+	for {
+		select {
+		case msg := <-client.Outbound():
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestCollabSync(t *testing.T) {
+	room := newTestRoom(t, "a")
+	// Every batch appends a character, one more than the log keeps
+	after := CRDTID{Site: collabServerSite, Seq: 1}
+	for i := uint64(0); i <= collabLogSize; i++ {
+		id := CRDTID{Site: "writer", Seq: i + 2}
+		if err := room.apply("writer", "", []CRDTOp{{Kind: CRDTInsert, ID: id, After: &after, Text: "b"}}); err != nil {
+			t.Fatal(err)
+		}
+		after = id
+	}
+	last := room.seq
+
+	tests := []struct {
+		name     string
+		epoch    string
+		since    uint64
+		snapshot bool
+		ops      int
+	}{
+		{"up to date", "epoch", last, false, 0},
+		{"behind", "epoch", last - 10, false, 10},
+		// One place in the send buffer goes to the welcome
+		{"as many as fit", "epoch", last - collabClientSend + 1, false, collabClientSend - 1},
+		{"more than fit", "epoch", last - collabClientSend, true, 0},
+		{"log truncated", "epoch", last - collabLogSize - 1, true, 0},
+		{"never synced", "", 0, true, 0},
+		{"other epoch", "reloaded", last, true, 0},
+		{"ahead", "epoch", last + 1, true, 0},
+	}
+	for _, tt := range tests {
+		client := room.join("user")
+		client.Handle(CollabMessage{Type: CollabSync, Epoch: tt.epoch, Since: tt.since})
+		msgs := drain(client)
+		client.Leave()
+
+		if tt.snapshot {
+			if len(msgs) != 1 || msgs[0].Type != CollabSnapshot {
+				t.Errorf("%s: got %d messages, want a snapshot", tt.name, len(msgs))
+				continue
+			}
+			snapshot := msgs[0]
+			if snapshot.Epoch != "epoch" || snapshot.Seq != last || snapshot.Clock != room.doc.Clock() {
+				t.Errorf("%s: got snapshot at %s/%d clock %d want epoch/%d clock %d", tt.name, snapshot.Epoch, snapshot.Seq, snapshot.Clock, last, room.doc.Clock())
+			}
+			if got := rebuild(t, snapshot.Runs).Text(); got != room.doc.Text() {
+				t.Errorf("%s: snapshot has %q want %q", tt.name, got, room.doc.Text())
+			}
+			continue
+		}
+		if len(msgs) != tt.ops+1 {
+			t.Errorf("%s: got %d messages want a welcome and %d ops", tt.name, len(msgs), tt.ops)
+			continue
+		}
+		if welcome := msgs[0]; welcome.Type != CollabWelcome || welcome.Site != client.presence.ClientID || welcome.Clock != room.doc.Clock() {
+			t.Errorf("%s: got %s for site %q clock %d want welcome for %q clock %d", tt.name, welcome.Type, welcome.Site, welcome.Clock, client.presence.ClientID, room.doc.Clock())
+		}
+		for i, msg := range msgs[1:] {
+			if want := tt.since + uint64(i) + 1; msg.Type != CollabOps || msg.Seq != want {
+				t.Errorf("%s: got %s %d want ops %d", tt.name, msg.Type, msg.Seq, want)
+				break
+			}
+		}
+	}
+}
+
+func TestCollabResyncAfterTruncation(t *testing.T) {
+	room := newTestRoom(t, "hello")
+
+	// A client catches up, then misses more batches than the room keeps
+	client := room.join("user")
+	client.Handle(CollabMessage{Type: CollabSync})
+	snapshot := drain(client)[0]
+	client.Leave()
+
+	after := CRDTID{Site: collabServerSite, Seq: 5}
+	for i := uint64(0); i < collabLogSize+5; i++ {
+		id := CRDTID{Site: "writer", Seq: snapshot.Clock + i + 1}
+		room.apply("writer", "", []CRDTOp{{Kind: CRDTInsert, ID: id, After: &after, Text: "!"}})
+		after = id
+	}
+	room.apply("writer", "", []CRDTOp{{Kind: CRDTDelete, ID: CRDTID{Site: collabServerSite, Seq: 1}}})
+
+	client = room.join("user")
+	client.Handle(CollabMessage{Type: CollabSync, Epoch: snapshot.Epoch, Since: snapshot.Seq})
+	msgs := drain(client)
+	if len(msgs) != 1 || msgs[0].Type != CollabSnapshot {
+		t.Fatalf("got %d messages want a snapshot", len(msgs))
+	}
+
+	// Edits made against the new snapshot apply on both sides
+	local := rebuild(t, msgs[0].Runs)
+	op := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: msgs[0].Site, Seq: msgs[0].Clock + 1}, After: crdtRef(collabServerSite, 2), Text: "E"}
+	if err := local.Apply(op); err != nil {
+		t.Fatal(err)
+	}
+	client.Handle(CollabMessage{Type: CollabOps, Ops: []CRDTOp{op}})
+	drain(client)
+
+	want := "eEllo" + strings.Repeat("!", collabLogSize+5)
+	if got := room.doc.Text(); got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got := local.Text(); got != want {
+		t.Errorf("client has %q want %q", got, want)
+	}
+}
+
+func TestCollabReconnectThroughOps(t *testing.T) {
+	room := newTestRoom(t, "hi")
+
+	client := room.join("user")
+	client.Handle(CollabMessage{Type: CollabSync})
+	snapshot := drain(client)[0]
+	local := rebuild(t, snapshot.Runs)
+	client.Leave()
+
+	// Someone else types while the client is away
+	op := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: "writer", Seq: snapshot.Clock + 1}, After: crdtRef(collabServerSite, 2), Text: "!"}
+	if err := room.apply("writer", "", []CRDTOp{op}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		since func() uint64
+	}{
+		{"behind", func() uint64 { return snapshot.Seq }},
+		{"up to date", func() uint64 { return room.seq }},
+	}
+	for _, tt := range tests {
+		client := room.join("user")
+		client.Handle(CollabMessage{Type: CollabSync, Epoch: snapshot.Epoch, Since: tt.since()})
+		msgs := drain(client)
+		if len(msgs) == 0 || msgs[0].Type != CollabWelcome {
+			t.Fatalf("%s: got %d messages want a welcome first", tt.name, len(msgs))
+		}
+		site := msgs[0].Site
+		if site == snapshot.Site {
+			t.Errorf("%s: reconnection kept site %q", tt.name, site)
+		}
+		for _, msg := range msgs[1:] {
+			for _, op := range msg.Ops {
+				local.Apply(op)
+			}
+		}
+
+		// Typing on the site from the welcome is accepted
+		insert := CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: site, Seq: max(local.Clock(), msgs[0].Clock) + 1}, After: crdtRef(collabServerSite, 1), Text: "o"}
+		local.Apply(insert)
+		client.Handle(CollabMessage{Type: CollabOps, Ops: []CRDTOp{insert}})
+		for _, msg := range drain(client) {
+			if msg.Type == CollabError {
+				t.Errorf("%s: insert on the welcome's site got %q", tt.name, msg.Error)
+			}
+		}
+		client.Leave()
+		if got, want := room.doc.Text(), local.Text(); got != want {
+			t.Errorf("%s: room has %q, client %q", tt.name, got, want)
+		}
+	}
+	if got := room.doc.Text(); got != "hooi!" {
+		t.Errorf("got %q want %q", got, "hooi!")
+	}
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+// Cycle errors
+var (
+	ErrCycleNotFound    = errors.New("cycle not found")
+	ErrInvalidCycleData = errors.New("invalid cycle data")
+	ErrCycleOverlap     = errors.New("cycle overlaps an existing cycle")
+	ErrCycleClosed      = errors.New("cycle is closed")
+)
+
+// Task cycle history events
+const (
+	CycleEventAdded       = "added"
+	CycleEventRemoved     = "removed"
+	CycleEventCompleted   = "completed"
+	CycleEventReopened    = "reopened"
+	CycleEventCarriedIn   = "carried_in"
+	CycleEventCarriedOver = "carried_over"
+)
+
+const (
+	cycleClosePollInterval = time.Hour
+	cycleCloseTimeout      = time.Minute
+)
+
+type CycleInput struct {
+	Name      string
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+type UpdateCycleInput struct {
+	Name      *string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+type CycleDetail struct {
+	Cycle models.Cycle                `json:"cycle"`
+	Tasks []models.GetTasksByCycleRow `json:"tasks"`
+}
+
+// CycleCloseResult reports where a closed cycle's unfinished tasks went.
+// NextCycle is nil when there was nothing to carry over and no open cycle
+// follows.
+type CycleCloseResult struct {
+	Cycle       models.Cycle  `json:"cycle"`
+	NextCycle   *models.Cycle `json:"next_cycle"`
+	CarriedOver int           `json:"carried_over"`
+}
+
+// CycleSummary is computed from the cycle's task history. Initial scope is
+// what the cycle held on its start date plus carried-in tasks; added and
+// removed count changes made after that.
+type CycleSummary struct {
+	Cycle          models.Cycle `json:"cycle"`
+	InitialScope   int          `json:"initial_scope"`
+	ScopeAdded     int          `json:"scope_added"`
+	ScopeRemoved   int          `json:"scope_removed"`
+	TotalScope     int          `json:"total_scope"`
+	CarriedIn      int          `json:"carried_in"`
+	Completed      int          `json:"completed"`
+	CarriedOver    int          `json:"carried_over"`
+	CompletionRate float64      `json:"completion_rate"`
+}
+
+type CycleServicer interface {
+	CreateCycle(ctx context.Context, workspaceID string, input CycleInput) (models.Cycle, error)
+	ListCycles(ctx context.Context, workspaceID string) ([]models.Cycle, error)
+	GetCycle(ctx context.Context, workspaceID, cycleID string) (CycleDetail, error)
+	UpdateCycle(ctx context.Context, workspaceID, cycleID string, input UpdateCycleInput) (models.Cycle, error)
+	DeleteCycle(ctx context.Context, workspaceID, cycleID string) error
+	CloseCycle(ctx context.Context, workspaceID, cycleID string) (CycleCloseResult, error)
+	GetCycleSummary(ctx context.Context, workspaceID, cycleID string) (CycleSummary, error)
+	AssignTask(ctx context.Context, taskID, cycleID string) (models.Task, error)
+}
+
+type CycleService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ CycleServicer = (*CycleService)(nil)
+
+func NewCycleService(store *store.Store) *CycleService {
+	return &CycleService{s: store}
+}
+
+// Start closes cycles once their end date has passed, carrying unfinished
+// tasks over. It blocks until ctx is cancelled.
+func (s *CycleService) Start(ctx context.Context) {
+	ticker := time.NewTicker(cycleClosePollInterval)
+	defer ticker.Stop()
+	for {
+		s.closeEndedCycles(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *CycleService) CreateCycle(ctx context.Context, workspaceID string, input CycleInput) (models.Cycle, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Cycle{}, ErrInvalidCycleData
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || input.EndDate.Before(input.StartDate) {
+		return models.Cycle{}, ErrInvalidCycleData
+	}
+	start := pgtype.Date{Time: input.StartDate, Valid: true}
+	end := pgtype.Date{Time: input.EndDate, Valid: true}
+	if err := checkOverlap(ctx, s.s.Queries, wsID, pgtype.UUID{}, start, end); err != nil {
+		return models.Cycle{}, err
+	}
+
+	cycle, err := s.s.Queries.CreateCycle(ctx, models.CreateCycleParams{
+		WorkspaceID: wsID,
+		Name:        name,
+		StartDate:   start,
+		EndDate:     end,
+	})
+	if err != nil {
+		return models.Cycle{}, fmt.Errorf("failed to create cycle: %w", err)
+	}
+	return cycle, nil
+}
+
+func (s *CycleService) ListCycles(ctx context.Context, workspaceID string) ([]models.Cycle, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidCycleData
+	}
+
+	cycles, err := s.s.Queries.ListWorkspaceCycles(ctx, wsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cycles: %w", err)
+	}
+	if cycles == nil {
+		cycles = make([]models.Cycle, 0)
+	}
+	return cycles, nil
+}
+
+func (s *CycleService) GetCycle(ctx context.Context, workspaceID, cycleID string) (CycleDetail, error) {
+	cycle, err := s.getCycle(ctx, workspaceID, cycleID)
+	if err != nil {
+		return CycleDetail{}, err
+	}
+
+	tasks, err := s.s.Queries.GetTasksByCycle(ctx, cycle.ID)
+	if err != nil {
+		return CycleDetail{}, fmt.Errorf("failed to get cycle tasks: %w", err)
+	}
+	if tasks == nil {
+		tasks = make([]models.GetTasksByCycleRow, 0)
+	}
+	return CycleDetail{Cycle: cycle, Tasks: tasks}, nil
+}
+
+func (s *CycleService) UpdateCycle(ctx context.Context, workspaceID, cycleID string, input UpdateCycleInput) (models.Cycle, error) {
+	current, err := s.getCycle(ctx, workspaceID, cycleID)
+	if err != nil {
+		return models.Cycle{}, err
+	}
+	if current.ClosedAt.Valid {
+		return models.Cycle{}, ErrCycleClosed
+	}
+
+	params := models.UpdateCycleParams{
+		WorkspaceID: current.WorkspaceID,
+		ID:          current.ID,
+		Name:        current.Name,
+		StartDate:   current.StartDate,
+		EndDate:     current.EndDate,
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return models.Cycle{}, ErrInvalidCycleData
+		}
+		params.Name = name
+	}
+	if input.StartDate != nil {
+		params.StartDate = pgtype.Date{Time: *input.StartDate, Valid: true}
+	}
+	if input.EndDate != nil {
+		params.EndDate = pgtype.Date{Time: *input.EndDate, Valid: true}
+	}
+	if params.EndDate.Time.Before(params.StartDate.Time) {
+		return models.Cycle{}, ErrInvalidCycleData
+	}
+	if err := checkOverlap(ctx, s.s.Queries, current.WorkspaceID, current.ID, params.StartDate, params.EndDate); err != nil {
+		return models.Cycle{}, err
+	}
+
+	cycle, err := s.s.Queries.UpdateCycle(ctx, params)
+	if err != nil {
+		return models.Cycle{}, fmt.Errorf("failed to update cycle: %w", err)
+	}
+	return cycle, nil
+}
+
+func (s *CycleService) DeleteCycle(ctx context.Context, workspaceID, cycleID string) error {
+	cycle, err := s.getCycle(ctx, workspaceID, cycleID)
+	if err != nil {
+		return err
+	}
+
+	err = s.s.Queries.DeleteCycle(ctx, models.DeleteCycleParams{
+		WorkspaceID: cycle.WorkspaceID,
+		ID:          cycle.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete cycle: %w", err)
+	}
+	return nil
+}
+
+// CloseCycle closes a cycle ahead of its end date.
+func (s *CycleService) CloseCycle(ctx context.Context, workspaceID, cycleID string) (CycleCloseResult, error) {
+	cycle, err := s.getCycle(ctx, workspaceID, cycleID)
+	if err != nil {
+		return CycleCloseResult{}, err
+	}
+	return s.closeCycle(ctx, cycle)
+}
+
+func (s *CycleService) GetCycleSummary(ctx context.Context, workspaceID, cycleID string) (CycleSummary, error) {
+	cycle, err := s.getCycle(ctx, workspaceID, cycleID)
+	if err != nil {
+		return CycleSummary{}, err
+	}
+
+	events, err := s.s.Queries.ListCycleTaskEvents(ctx, cycle.ID)
+	if err != nil {
+		return CycleSummary{}, fmt.Errorf("failed to get cycle history: %w", err)
+	}
+	return summarizeCycle(cycle, events), nil
+}
+
+// AssignTask moves a task into a cycle. An empty cycleID removes the task
+// from its current cycle.
+func (s *CycleService) AssignTask(ctx context.Context, taskID, cycleID string) (models.Task, error) {
+	tID, err := parseUUID(taskID)
+	if err != nil {
+		return models.Task{}, ErrInvalidTaskData
+	}
+
+	current, err := s.s.Queries.GetTaskByID(ctx, tID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Task{}, ErrTaskNotFound
+		}
+		return models.Task{}, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	var next pgtype.UUID
+	if cycleID != "" {
+		cycle, err := s.getCycle(ctx, uuidString(current.WorkspaceID), cycleID)
+		if err != nil {
+			return models.Task{}, err
+		}
+		if cycle.ClosedAt.Valid {
+			return models.Task{}, ErrCycleClosed
+		}
+		next = cycle.ID
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	task, err := queries.SetTaskCycle(ctx, models.SetTaskCycleParams{ID: tID, CycleID: next})
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to assign task to cycle: %w", err)
+	}
+
+	if current.CycleID != next {
+		if current.CycleID.Valid {
+			if err := recordCycleEvent(ctx, queries, current.CycleID, tID, CycleEventRemoved); err != nil {
+				return models.Task{}, err
+			}
+		}
+		if next.Valid {
+			if err := recordCycleEvent(ctx, queries, next, tID, CycleEventAdded); err != nil {
+				return models.Task{}, err
+			}
+			if task.Status == models.TaskStatusDone {
+				if err := recordCycleEvent(ctx, queries, next, tID, CycleEventCompleted); err != nil {
+					return models.Task{}, err
+				}
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Task{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return task, nil
+}
+
+func (s *CycleService) closeEndedCycles(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, cycleCloseTimeout)
+	defer cancel()
+
+	cycles, err := s.s.Queries.ListCyclesToClose(ctx)
+	if err != nil {
+		log.Printf("Failed to list ended cycles: %v", err)
+		return
+	}
+	for _, cycle := range cycles {
+		result, err := s.closeCycle(ctx, cycle)
+		if err != nil {
+			log.Printf("Failed to close cycle %s: %v", uuidString(cycle.ID), err)
+			continue
+		}
+		log.Printf("Closed cycle %s, carried %d tasks over", uuidString(cycle.ID), result.CarriedOver)
+	}
+}
+
+// closeCycle marks a cycle closed and moves its unfinished tasks into the
+// next open cycle. When there are tasks to move and no open cycle follows,
+// one of the same length is created right after it.
+func (s *CycleService) closeCycle(ctx context.Context, cycle models.Cycle) (CycleCloseResult, error) {
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return CycleCloseResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	closed, err := queries.CloseCycle(ctx, cycle.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return CycleCloseResult{}, ErrCycleClosed
+		}
+		return CycleCloseResult{}, fmt.Errorf("failed to close cycle: %w", err)
+	}
+
+	next, err := queries.GetNextCycle(ctx, models.GetNextCycleParams{
+		WorkspaceID: cycle.WorkspaceID,
+		StartDate:   cycle.StartDate,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		unfinished, err := queries.CountUnfinishedCycleTasks(ctx, cycle.ID)
+		if err != nil {
+			return CycleCloseResult{}, fmt.Errorf("failed to count unfinished tasks: %w", err)
+		}
+		if unfinished == 0 {
+			if err := tx.Commit(ctx); err != nil {
+				return CycleCloseResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+			}
+			return CycleCloseResult{Cycle: closed}, nil
+		}
+
+		length := cycle.EndDate.Time.Sub(cycle.StartDate.Time)
+		start := cycle.EndDate.Time.AddDate(0, 0, 1)
+		params := models.CreateCycleParams{
+			WorkspaceID: cycle.WorkspaceID,
+			Name:        nextCycleName(cycle.Name),
+			StartDate:   pgtype.Date{Time: start, Valid: true},
+			EndDate:     pgtype.Date{Time: start.Add(length), Valid: true},
+		}
+		if err := checkOverlap(ctx, queries, params.WorkspaceID, pgtype.UUID{}, params.StartDate, params.EndDate); err != nil {
+			return CycleCloseResult{}, err
+		}
+		next, err = queries.CreateCycle(ctx, params)
+		if err != nil {
+			return CycleCloseResult{}, fmt.Errorf("failed to create next cycle: %w", err)
+		}
+	} else if err != nil {
+		return CycleCloseResult{}, fmt.Errorf("failed to get next cycle: %w", err)
+	}
+
+	moved, err := queries.CarryOverCycleTasks(ctx, models.CarryOverCycleTasksParams{
+		NextCycleID: next.ID,
+		CycleID:     cycle.ID,
+	})
+	if err != nil {
+		return CycleCloseResult{}, fmt.Errorf("failed to carry over tasks: %w", err)
+	}
+	for _, taskID := range moved {
+		if err := recordCycleEvent(ctx, queries, cycle.ID, taskID, CycleEventCarriedOver); err != nil {
+			return CycleCloseResult{}, err
+		}
+		if err := recordCycleEvent(ctx, queries, next.ID, taskID, CycleEventCarriedIn); err != nil {
+			return CycleCloseResult{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return CycleCloseResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return CycleCloseResult{Cycle: closed, NextCycle: &next, CarriedOver: len(moved)}, nil
+}
+
+func (s *CycleService) getCycle(ctx context.Context, workspaceID, cycleID string) (models.Cycle, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Cycle{}, ErrInvalidCycleData
+	}
+	cID, err := parseUUID(cycleID)
+	if err != nil {
+		return models.Cycle{}, ErrInvalidCycleData
+	}
+
+	cycle, err := s.s.Queries.GetCycle(ctx, models.GetCycleParams{
+		WorkspaceID: wsID,
+		ID:          cID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Cycle{}, ErrCycleNotFound
+		}
+		return models.Cycle{}, fmt.Errorf("failed to get cycle: %w", err)
+	}
+	return cycle, nil
+}
+
+func checkOverlap(ctx context.Context, q *models.Queries, workspaceID, cycleID pgtype.UUID, start, end pgtype.Date) error {
+	overlapping, err := q.CountOverlappingCycles(ctx, models.CountOverlappingCyclesParams{
+		WorkspaceID: workspaceID,
+		ID:          cycleID,
+		RangeEnd:    end,
+		RangeStart:  start,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check cycle overlap: %w", err)
+	}
+	if overlapping > 0 {
+		return ErrCycleOverlap
+	}
+	return nil
+}
+
+func recordCycleEvent(ctx context.Context, q *models.Queries, cycleID, taskID pgtype.UUID, event string) error {
+	err := q.CreateTaskCycleEvent(ctx, models.CreateTaskCycleEventParams{
+		CycleID: cycleID,
+		TaskID:  taskID,
+		Event:   event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record cycle history: %w", err)
+	}
+	return nil
+}
+
+// recordCycleStatusChange notes a task being finished or reopened inside
+// its cycle.
+func recordCycleStatusChange(ctx context.Context, q *models.Queries, task models.Task, previous models.TaskStatus) error {
+	if !task.CycleID.Valid || task.Status == previous {
+		return nil
+	}
+	switch {
+	case task.Status == models.TaskStatusDone:
+		return recordCycleEvent(ctx, q, task.CycleID, task.ID, CycleEventCompleted)
+	case previous == models.TaskStatusDone:
+		return recordCycleEvent(ctx, q, task.CycleID, task.ID, CycleEventReopened)
+	}
+	return nil
+}
+
+// summarizeCycle replays a cycle's history in order. A task counts as
+// completed if its last status event in the cycle is a completion.
+func summarizeCycle(cycle models.Cycle, events []models.TaskCycleEvent) CycleSummary {
+	summary := CycleSummary{Cycle: cycle}
+	inScope := make(map[pgtype.UUID]bool)
+	done := make(map[pgtype.UUID]bool)
+	carriedOver := make(map[pgtype.UUID]bool)
+
+	for _, e := range events {
+		beforeStart := e.CreatedAt.Time.Before(cycle.StartDate.Time)
+		switch e.Event {
+		case CycleEventAdded, CycleEventCarriedIn:
+			if inScope[e.TaskID] {
+				continue
+			}
+			inScope[e.TaskID] = true
+			if e.Event == CycleEventCarriedIn {
+				summary.CarriedIn++
+			}
+			// Carried-over work is part of the plan even though it
+			// usually arrives after the cycle has started
+			if beforeStart || e.Event == CycleEventCarriedIn {
+				summary.InitialScope++
+			} else {
+				summary.ScopeAdded++
+			}
+		case CycleEventRemoved:
+			if !inScope[e.TaskID] {
+				continue
+			}
+			delete(inScope, e.TaskID)
+			delete(done, e.TaskID)
+			if beforeStart {
+				summary.InitialScope--
+			} else {
+				summary.ScopeRemoved++
+			}
+		case CycleEventCompleted:
+			done[e.TaskID] = true
+		case CycleEventReopened:
+			delete(done, e.TaskID)
+		case CycleEventCarriedOver:
+			carriedOver[e.TaskID] = true
+		}
+	}
+
+	summary.TotalScope = summary.InitialScope + summary.ScopeAdded - summary.ScopeRemoved
+	summary.Completed = len(done)
+	summary.CarriedOver = len(carriedOver)
+	if summary.TotalScope > 0 {
+		rate := float64(summary.Completed) / float64(summary.TotalScope) * 100
+		summary.CompletionRate = math.Round(rate*10) / 10
+	}
+	return summary
+}
+
+// nextCycleName increments a trailing number ("Cycle 4" -> "Cycle 5").
+func nextCycleName(name string) string {
+	i := strings.LastIndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	prefix, digits := name[:i+1], name[i+1:]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return name + " (next)"
+	}
+	return prefix + strconv.Itoa(n+1)
+}
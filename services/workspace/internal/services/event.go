@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -33,11 +35,17 @@ var (
 )
 
 type EventServicer interface {
-	AddEvent(ctx context.Context, workspaceID string, params CreateEventParams) (models.Event, error)
-	UpdateEvent(ctx context.Context, eventID string, workspaceID string, params UpdateEventParams) (models.Event, error)
-	RemoveEvent(ctx context.Context, eventID string, workspaceID string) error
-	GetEvent(ctx context.Context, eventID string, workspaceID string) (models.Event, error)
-	GetWorkspaceEvents(ctx context.Context, workspaceID string) ([]models.Event, error)
+	AddEvent(ctx context.Context, workspaceID string, params CreateEventParams) (Event, error)
+	UpdateEvent(ctx context.Context, eventID string, workspaceID string, scope EventScope, params UpdateEventParams) (Event, error)
+	RemoveEvent(ctx context.Context, eventID string, workspaceID string, scope EventScope) error
+	GetEvent(ctx context.Context, eventID string, workspaceID string) (Event, error)
+	GetWorkspaceEvents(ctx context.Context, workspaceID string, opts EventListOptions) ([]Event, error)
+	GetWorkspaceEventBuckets(ctx context.Context, workspaceID string, opts EventListOptions, bucket EventBucketOptions) ([]EventBucket, error)
+	ImportEvents(ctx context.Context, workspaceID string, calendar io.Reader, opts EventImportOptions) (EventImportReport, error)
+	RespondToEvent(ctx context.Context, eventID, workspaceID, userID string, occurrence time.Time, status string) (EventAttendee, error)
+	CreateAttendeeLink(ctx context.Context, eventID, workspaceID, attendeeID string) (string, error)
+	GetInvitation(ctx context.Context, token string) (EventInvitation, error)
+	RespondToInvitation(ctx context.Context, token string, occurrence time.Time, status string) (EventAttendee, error)
 	GetEventTypeColor(color string) (string, error)
 }
 
@@ -52,204 +60,445 @@ func NewEventService(store *store.Store) *EventService {
 	return &EventService{s: store}
 }
 
+// Event is an event with its start and end given in its own timezone
+type Event struct {
+	models.Event
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	// RecurrenceID is the original start of an occurrence of a recurring
+	// event
+	RecurrenceID *time.Time      `json:"recurrence_id,omitempty"`
+	Attendees    []EventAttendee `json:"attendees"`
+}
+
 type CreateEventParams struct {
-	Name           string    `json:"name"`
-	Color          string    `json:"color"`
-	EventDate      time.Time `json:"event_date"`
-	EventTime      time.Time `json:"event_time"`
-	DurationMinute int32     `json:"duration_minutes"`
-	AttendeesCount int32     `json:"attendees_count"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	// StartsAt is the start instant. For all-day events only its calendar
+	// date, as written, matters.
+	StartsAt time.Time `json:"starts_at"`
+	// Timezone is an IANA name, UTC when empty
+	Timezone string `json:"timezone"`
+	AllDay   bool   `json:"all_day"`
+	// DurationMinutes defaults to a day for all-day events, which last
+	// whole days
+	DurationMinutes int32                 `json:"duration_minutes"`
+	Attendees       []EventAttendeeParams `json:"attendees"`
+	// RRule makes the event recurring, following RFC 5545
+	RRule string `json:"rrule"`
+	// ExDates are the starts of occurrences to leave out
+	ExDates []time.Time `json:"exdates"`
+	// ICalUID is the UID of an event imported from an iCalendar file
+	ICalUID string `json:"ical_uid"`
 }
 
 type UpdateEventParams struct {
-	Name           *string    `json:"name"`
-	Color          *string    `json:"color"`
-	EventDate      *time.Time `json:"event_date"`
-	EventTime      *time.Time `json:"event_time"`
-	DurationMinute *int32     `json:"duration_minutes"`
-	AttendeesCount *int32     `json:"attendees_count"`
+	Name            *string    `json:"name"`
+	Color           *string    `json:"color"`
+	StartsAt        *time.Time `json:"starts_at"`
+	Timezone        *string    `json:"timezone"`
+	AllDay          *bool      `json:"all_day"`
+	DurationMinutes *int32     `json:"duration_minutes"`
+	// Attendees replaces the attendees of the event, or of the occurrences
+	// changed
+	Attendees *[]EventAttendeeParams `json:"attendees"`
+	// An empty RRule makes the event a one-off
+	RRule   *string      `json:"rrule"`
+	ExDates *[]time.Time `json:"exdates"`
+}
+
+// Which occurrences of a recurring event a change applies to
+const (
+	EventScopeThis      = "this"
+	EventScopeFollowing = "following"
+	EventScopeAll       = "all"
+)
+
+// EventScope picks the occurrences of a recurring event a change applies
+// to. Occurrence is the original start of the occurrence the change was
+// made from; this and following need it.
+type EventScope struct {
+	Scope      string
+	Occurrence time.Time
+}
+
+// EventListOptions bounds the events listed. Either end of the window may
+// be left zero.
+type EventListOptions struct {
+	From time.Time
+	To   time.Time
+	// Colors keeps the events of these colors only. Color types such as
+	// "blue" match their hex code too.
+	Colors []string
+	// Attendee keeps the events a member, by ID, or an email is invited to
+	Attendee string
+}
+
+// Event bucket sizes
+const (
+	EventBucketDay   = "day"
+	EventBucketWeek  = "week"
+	EventBucketMonth = "month"
+)
+
+// EventBucketOptions groups events into the days, weeks or months of a
+// timezone. Weeks start on Monday.
+type EventBucketOptions struct {
+	Size string
+	// Timezone is an IANA name, UTC when empty
+	Timezone string
 }
 
-func (s *EventService) AddEvent(ctx context.Context, workspaceID string, params CreateEventParams) (models.Event, error) {
+// EventBucket holds the events overlapping one day, week or month. An
+// event lasting several is in each of them.
+type EventBucket struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Events []Event   `json:"events"`
+}
+
+const (
+	minutesPerDay = 24 * 60
+	// defaultRecurrenceHorizon bounds the expansion of recurring events
+	// when the window has no end
+	defaultRecurrenceHorizon = 365 * 24 * time.Hour
+	maxEventOccurrences      = 1000
+	maxEventBuckets          = 1000
+)
+
+func (s *EventService) AddEvent(ctx context.Context, workspaceID string, params CreateEventParams) (Event, error) {
 	// Validate input
 	if workspaceID == "" {
-		return models.Event{}, ErrInvalidWorkspaceID
+		return Event{}, ErrInvalidWorkspaceID
 	}
 
 	if params.Name == "" {
-		return models.Event{}, ErrMissingEventFields
+		return Event{}, ErrMissingEventFields
 	}
 
 	if params.Color == "" {
-		return models.Event{}, ErrMissingEventFields
+		return Event{}, ErrMissingEventFields
 	}
 
-	if params.DurationMinute <= 0 {
-		return models.Event{}, ErrInvalidEventData
+	if params.StartsAt.IsZero() {
+		return Event{}, ErrMissingEventFields
 	}
 
-	if params.AttendeesCount < 0 {
-		return models.Event{}, ErrInvalidEventData
+	if params.Timezone == "" {
+		params.Timezone = "UTC"
+	}
+	loc, err := loadEventTimezone(params.Timezone)
+	if err != nil {
+		return Event{}, err
+	}
+
+	startsAt := params.StartsAt
+	if params.AllDay {
+		startsAt = startOfDay(startsAt, loc)
+		if params.DurationMinutes == 0 {
+			params.DurationMinutes = minutesPerDay
+		}
+	}
+	if err := checkEventDuration(params.DurationMinutes, params.AllDay); err != nil {
+		return Event{}, err
+	}
+
+	rrule, err := normalizeRRule(params.RRule)
+	if err != nil {
+		return Event{}, err
 	}
 
 	// Convert workspace ID to UUID
 	var wsID pgtype.UUID
 	if err := wsID.Scan(workspaceID); err != nil {
-		return models.Event{}, ErrInvalidWorkspaceID
+		return Event{}, ErrInvalidWorkspaceID
 	}
 
 	// Verify workspace exists and is accessible
-	_, err := s.s.Queries.GetWorkspaceById(ctx, wsID)
+	_, err = s.s.Queries.GetWorkspaceById(ctx, wsID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return models.Event{}, ErrEventNotFound
+			return Event{}, ErrEventNotFound
 		}
-		return models.Event{}, fmt.Errorf("failed to verify workspace: %w", err)
-	}
-
-	// Create event
-	event := models.Event{
-		ID:             pgtype.UUID{},
-		WorkspaceID:    wsID,
-		Name:           params.Name,
-		Color:          params.Color,
-		EventDate:      pgtype.Date{InfinityModifier: pgtype.Infinity},
-		EventTime:      pgtype.Time{},
-		DurationMinute: params.DurationMinute,
-		AttendeesCount: params.AttendeesCount,
-		CreatedAt:      pgtype.Timestamptz{},
-		UpdatedAt:      pgtype.Timestamptz{},
-	}
-
-	// Set event date
-	if err := event.EventDate.Scan(params.EventDate); err != nil {
-		return models.Event{}, ErrInvalidEventData
-	}
-
-	// Set event time
-	eventTimeStr := params.EventTime.Format("15:04:05")
-	if err := event.EventTime.Scan(eventTimeStr); err != nil {
-		return models.Event{}, ErrInvalidEventData
-	}
-
-	// Insert into database
-	row := s.s.Pool.QueryRow(ctx, `
-		INSERT INTO events (workspace_id, name, color, event_date, event_time, duration_minutes, attendees_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, workspace_id, name, color, event_date, event_time, duration_minutes, attendees_count, created_at, updated_at
-	`, wsID, params.Name, params.Color, params.EventDate, eventTimeStr, params.DurationMinute, params.AttendeesCount)
-
-	if err := row.Scan(
-		&event.ID,
-		&event.WorkspaceID,
-		&event.Name,
-		&event.Color,
-		&event.EventDate,
-		&event.EventTime,
-		&event.DurationMinute,
-		&event.AttendeesCount,
-		&event.CreatedAt,
-		&event.UpdatedAt,
-	); err != nil {
-		return models.Event{}, fmt.Errorf("failed to insert event: %w", err)
+		return Event{}, fmt.Errorf("failed to verify workspace: %w", err)
 	}
 
-	return event, nil
+	attendees, err := s.resolveAttendees(ctx, wsID, params.Attendees)
+	if err != nil {
+		return Event{}, err
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	event, err := insertEvent(ctx, queries, models.Event{
+		WorkspaceID:     wsID,
+		Name:            params.Name,
+		Color:           params.Color,
+		StartsAt:        pgtype.Timestamptz{Time: startsAt, Valid: true},
+		Timezone:        params.Timezone,
+		AllDay:          params.AllDay,
+		DurationMinutes: params.DurationMinutes,
+		Rrule:           rrule,
+		Exdates:         normalizeExdates(params.ExDates, params.AllDay, loc),
+		IcalUid:         pgtype.Text{String: params.ICalUID, Valid: params.ICalUID != ""},
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	if len(attendees) > 0 {
+		if err := setEventAttendees(ctx, queries, event.ID, attendees); err != nil {
+			return Event{}, err
+		}
+		event.AttendeesCount = int32(len(attendees))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Event{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.loadEvent(ctx, event)
 }
 
-func (s *EventService) UpdateEvent(ctx context.Context, eventID string, workspaceID string, params UpdateEventParams) (models.Event, error) {
+// UpdateEvent changes an event. For a recurring event, the scope picks
+// whether only one occurrence, that occurrence and the following ones, or
+// the whole series change. Changing a single occurrence stores it as an
+// event of its own; changing the following ones splits the series in two.
+func (s *EventService) UpdateEvent(ctx context.Context, eventID string, workspaceID string, scope EventScope, params UpdateEventParams) (Event, error) {
 	// Validate input
 	if eventID == "" || workspaceID == "" {
-		return models.Event{}, ErrInvalidEventData
+		return Event{}, ErrInvalidEventData
 	}
 
 	var eID, wsID pgtype.UUID
 	if err := eID.Scan(eventID); err != nil {
-		return models.Event{}, ErrInvalidEventData
+		return Event{}, ErrInvalidEventData
 	}
 	if err := wsID.Scan(workspaceID); err != nil {
-		return models.Event{}, ErrInvalidWorkspaceID
+		return Event{}, ErrInvalidWorkspaceID
 	}
 
 	// Get existing event
 	event, err := s.getEventByID(ctx, eID, wsID)
 	if err != nil {
-		return models.Event{}, err
+		return Event{}, err
+	}
+	if scope, err = checkEventScope(event, scope); err != nil {
+		return Event{}, err
 	}
 
-	// Update fields
-	if params.Name != nil {
-		if *params.Name == "" {
-			return models.Event{}, ErrMissingEventFields
+	// Attendees are checked up front, and set on whichever event the
+	// change ends up in
+	var attendees []EventAttendeeParams
+	if params.Attendees != nil {
+		if attendees, err = s.resolveAttendees(ctx, wsID, *params.Attendees); err != nil {
+			return Event{}, err
 		}
-		event.Name = *params.Name
 	}
 
-	if params.Color != nil {
-		if *params.Color == "" {
-			return models.Event{}, ErrMissingEventFields
-		}
-		event.Color = *params.Color
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
 
-	if params.EventDate != nil {
-		if err := event.EventDate.Scan(*params.EventDate); err != nil {
-			return models.Event{}, ErrInvalidEventData
+	updated, err := s.updateEvent(ctx, queries, event, scope, params)
+	if err != nil {
+		return Event{}, err
+	}
+	if params.Attendees != nil {
+		if err := setEventAttendees(ctx, queries, updated.ID, attendees); err != nil {
+			return Event{}, err
 		}
+		updated.AttendeesCount = int32(len(attendees))
 	}
 
-	if params.EventTime != nil {
-		eventTimeStr := params.EventTime.Format("15:04:05")
-		if err := event.EventTime.Scan(eventTimeStr); err != nil {
-			return models.Event{}, ErrInvalidEventData
+	if err := tx.Commit(ctx); err != nil {
+		return Event{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.loadEvent(ctx, updated.Event)
+}
+
+// updateEvent applies a change to the occurrences of the event the scope
+// picks, through the caller's transaction
+func (s *EventService) updateEvent(ctx context.Context, q *models.Queries, event models.Event, scope EventScope, params UpdateEventParams) (Event, error) {
+	var err error
+
+	// An edited occurrence changes on its own; wider scopes apply to its
+	// series
+	if event.RecurringEventID.Valid {
+		if scope.Scope == EventScopeThis {
+			if params.RRule != nil || params.ExDates != nil {
+				return Event{}, ErrInvalidEventData
+			}
+			return updateEventRow(ctx, q, event, params)
+		}
+		scope.Occurrence = event.RecurrenceID.Time
+		if event, err = s.getEventByID(ctx, event.RecurringEventID, event.WorkspaceID); err != nil {
+			return Event{}, err
 		}
 	}
 
-	if params.DurationMinute != nil {
-		if *params.DurationMinute <= 0 {
-			return models.Event{}, ErrInvalidEventData
+	if !event.Rrule.Valid || scope.Scope == EventScopeAll {
+		return updateSeries(ctx, q, event, params)
+	}
+	if err := checkOccurrence(event, scope.Occurrence); err != nil {
+		return Event{}, err
+	}
+	if scope.Scope == EventScopeThis {
+		return updateOccurrence(ctx, q, event, scope.Occurrence, params)
+	}
+	if scope.Occurrence.Equal(event.StartsAt.Time) {
+		return updateSeries(ctx, q, event, params)
+	}
+	return updateFollowing(ctx, q, event, scope.Occurrence, params)
+}
+
+// updateEventRow changes a single event row
+func updateEventRow(ctx context.Context, q *models.Queries, event models.Event, params UpdateEventParams) (Event, error) {
+	if err := applyEventUpdate(&event, params); err != nil {
+		return Event{}, err
+	}
+	updated, err := saveEvent(ctx, q, event)
+	if err != nil {
+		return Event{}, err
+	}
+	return toEvent(updated), nil
+}
+
+// updateSeries changes an event, or every occurrence of a recurring one.
+// Removed and edited occurrences move along with the start, and are
+// dropped when the recurrence rule changes.
+func updateSeries(ctx context.Context, q *models.Queries, event models.Event, params UpdateEventParams) (Event, error) {
+	previous := event
+	if err := applyEventUpdate(&event, params); err != nil {
+		return Event{}, err
+	}
+	ruleChanged := event.Rrule != previous.Rrule
+	from, to := toEvent(previous).StartsAt, toEvent(event).StartsAt
+	if params.ExDates == nil {
+		if ruleChanged {
+			event.Exdates = nil
+		} else {
+			event.Exdates = shiftExdates(event.Exdates, from, to)
 		}
-		event.DurationMinute = *params.DurationMinute
 	}
 
-	if params.AttendeesCount != nil {
-		if *params.AttendeesCount < 0 {
-			return models.Event{}, ErrInvalidEventData
+	updated, err := saveEvent(ctx, q, event)
+	if err != nil {
+		return Event{}, err
+	}
+	if previous.Rrule.Valid {
+		if err := moveEventOverrides(ctx, q, previous.ID, updated.ID, from, to, ruleChanged); err != nil {
+			return Event{}, err
 		}
-		event.AttendeesCount = *params.AttendeesCount
 	}
 
-	// Update in database
-	row := s.s.Pool.QueryRow(ctx, `
-		UPDATE events
-		SET name = $1, color = $2, event_date = $3, event_time = $4, duration_minutes = $5, attendees_count = $6, updated_at = NOW()
-		WHERE id = $7 AND workspace_id = $8
-		RETURNING id, workspace_id, name, color, event_date, event_time, duration_minutes, attendees_count, created_at, updated_at
-	`, event.Name, event.Color, event.EventDate, event.EventTime, event.DurationMinute, event.AttendeesCount, eID, wsID)
+	return toEvent(updated), nil
+}
 
-	var updatedEvent models.Event
-	if err := row.Scan(
-		&updatedEvent.ID,
-		&updatedEvent.WorkspaceID,
-		&updatedEvent.Name,
-		&updatedEvent.Color,
-		&updatedEvent.EventDate,
-		&updatedEvent.EventTime,
-		&updatedEvent.DurationMinute,
-		&updatedEvent.AttendeesCount,
-		&updatedEvent.CreatedAt,
-		&updatedEvent.UpdatedAt,
-	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return models.Event{}, ErrEventNotFound
+// updateOccurrence changes one occurrence of a recurring event, storing it
+// as an event of its own the first time
+func updateOccurrence(ctx context.Context, q *models.Queries, series models.Event, occurrence time.Time, params UpdateEventParams) (Event, error) {
+	if params.RRule != nil || params.ExDates != nil {
+		return Event{}, ErrInvalidEventData
+	}
+
+	override, err := q.GetEventOverride(ctx, models.GetEventOverrideParams{
+		RecurringEventID: series.ID,
+		RecurrenceID:     pgtype.Timestamptz{Time: occurrence, Valid: true},
+	})
+	if err == nil {
+		return updateEventRow(ctx, q, override, params)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Event{}, fmt.Errorf("failed to fetch event occurrence: %w", err)
+	}
+
+	override = series
+	override.StartsAt = pgtype.Timestamptz{Time: occurrence, Valid: true}
+	override.Rrule = pgtype.Text{}
+	override.Exdates = nil
+	override.RecurringEventID = series.ID
+	override.RecurrenceID = pgtype.Timestamptz{Time: occurrence, Valid: true}
+	if err := applyEventUpdate(&override, params); err != nil {
+		return Event{}, err
+	}
+
+	// The occurrence starts out with the series' attendees and answers
+	created, err := insertEvent(ctx, q, override)
+	if err != nil {
+		return Event{}, err
+	}
+	if err := q.CopyEventAttendees(ctx, models.CopyEventAttendeesParams{
+		ToEventID:   created.ID,
+		FromEventID: series.ID,
+	}); err != nil {
+		return Event{}, fmt.Errorf("failed to copy event attendees: %w", err)
+	}
+
+	return toEvent(created), nil
+}
+
+// updateFollowing ends a recurring event before the occurrence, and starts
+// a new series from it with the changes applied
+func updateFollowing(ctx context.Context, q *models.Queries, series models.Event, occurrence time.Time, params UpdateEventParams) (Event, error) {
+	rule, start, err := eventRule(series)
+	if err != nil {
+		return Event{}, err
+	}
+	before, after := rule.truncate(start, occurrence)
+
+	// The new series is ours, not the imported one
+	next := series
+	next.IcalUid = pgtype.Text{}
+	next.StartsAt = pgtype.Timestamptz{Time: occurrence, Valid: true}
+	next.Rrule = pgtype.Text{String: after.String(), Valid: true}
+	next.Exdates = exdatesFrom(series.Exdates, occurrence, true)
+	if err := applyEventUpdate(&next, params); err != nil {
+		return Event{}, err
+	}
+	ruleChanged := next.Rrule.String != after.String()
+	from, to := occurrence.In(start.Location()), toEvent(next).StartsAt
+	if params.ExDates == nil {
+		if ruleChanged {
+			next.Exdates = nil
+		} else {
+			next.Exdates = shiftExdates(next.Exdates, from, to)
 		}
-		return models.Event{}, fmt.Errorf("failed to update event: %w", err)
 	}
 
-	return updatedEvent, nil
+	series.Rrule = pgtype.Text{String: before.String(), Valid: true}
+	series.Exdates = exdatesFrom(series.Exdates, occurrence, false)
+
+	if _, err := saveEvent(ctx, q, series); err != nil {
+		return Event{}, err
+	}
+	created, err := insertEvent(ctx, q, next)
+	if err != nil {
+		return Event{}, err
+	}
+	if err := q.CopyEventAttendees(ctx, models.CopyEventAttendeesParams{
+		ToEventID:   created.ID,
+		FromEventID: series.ID,
+	}); err != nil {
+		return Event{}, fmt.Errorf("failed to copy event attendees: %w", err)
+	}
+	if err := moveEventOverrides(ctx, q, series.ID, created.ID, from, to, ruleChanged || !created.Rrule.Valid); err != nil {
+		return Event{}, err
+	}
+
+	return toEvent(created), nil
 }
 
-func (s *EventService) RemoveEvent(ctx context.Context, eventID string, workspaceID string) error {
+// RemoveEvent deletes an event. For a recurring event, the scope picks
+// whether only one occurrence, that occurrence and the following ones, or
+// the whole series go.
+func (s *EventService) RemoveEvent(ctx context.Context, eventID string, workspaceID string, scope EventScope) error {
 	// Validate input
 	if eventID == "" || workspaceID == "" {
 		return ErrInvalidEventData
@@ -263,85 +512,268 @@ func (s *EventService) RemoveEvent(ctx context.Context, eventID string, workspac
 		return ErrInvalidWorkspaceID
 	}
 
-	// Verify event exists and belongs to workspace
-	_, err := s.getEventByID(ctx, eID, wsID)
+	event, err := s.getEventByID(ctx, eID, wsID)
 	if err != nil {
 		return err
 	}
+	if scope, err = checkEventScope(event, scope); err != nil {
+		return err
+	}
+
+	if event.RecurringEventID.Valid {
+		scope.Occurrence = event.RecurrenceID.Time
+		if event, err = s.getEventByID(ctx, event.RecurringEventID, wsID); err != nil {
+			return err
+		}
+	}
 
-	// Delete event
-	commandTag, err := s.s.Pool.Exec(ctx, `
-		DELETE FROM events WHERE id = $1 AND workspace_id = $2
-	`, eID, wsID)
+	if !event.Rrule.Valid || scope.Scope == EventScopeAll ||
+		(scope.Scope == EventScopeFollowing && scope.Occurrence.Equal(event.StartsAt.Time)) {
+		return s.deleteEvent(ctx, s.s.Queries, event.WorkspaceID, event.ID)
+	}
+	if err := checkOccurrence(event, scope.Occurrence); err != nil {
+		return err
+	}
 
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	occurrence := pgtype.Timestamptz{Time: scope.Occurrence, Valid: true}
+	if scope.Scope == EventScopeThis {
+		event.Exdates = append(event.Exdates, occurrence)
+		override, err := queries.GetEventOverride(ctx, models.GetEventOverrideParams{
+			RecurringEventID: event.ID,
+			RecurrenceID:     occurrence,
+		})
+		switch {
+		case err == nil:
+			if err := s.deleteEvent(ctx, queries, override.WorkspaceID, override.ID); err != nil {
+				return err
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return fmt.Errorf("failed to fetch event occurrence: %w", err)
+		}
+	} else {
+		rule, start, err := eventRule(event)
+		if err != nil {
+			return err
+		}
+		before, _ := rule.truncate(start, scope.Occurrence)
+		event.Rrule = pgtype.Text{String: before.String(), Valid: true}
+		event.Exdates = exdatesFrom(event.Exdates, scope.Occurrence, false)
+		if err := queries.DeleteEventOverrides(ctx, models.DeleteEventOverridesParams{
+			EventID: event.ID,
+			Since:   occurrence,
+		}); err != nil {
+			return fmt.Errorf("failed to delete event occurrences: %w", err)
+		}
+	}
+	if _, err := saveEvent(ctx, queries, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *EventService) deleteEvent(ctx context.Context, q *models.Queries, workspaceID, eventID pgtype.UUID) error {
+	rows, err := q.DeleteEvent(ctx, models.DeleteEventParams{
+		WorkspaceID: workspaceID,
+		ID:          eventID,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
+	if rows == 0 {
 		return ErrEventNotFound
 	}
 
 	return nil
 }
 
-func (s *EventService) GetEvent(ctx context.Context, eventID string, workspaceID string) (models.Event, error) {
+func (s *EventService) GetEvent(ctx context.Context, eventID string, workspaceID string) (Event, error) {
 	var eID, wsID pgtype.UUID
 	if err := eID.Scan(eventID); err != nil {
-		return models.Event{}, ErrInvalidEventData
+		return Event{}, ErrInvalidEventData
 	}
 	if err := wsID.Scan(workspaceID); err != nil {
-		return models.Event{}, ErrInvalidWorkspaceID
+		return Event{}, ErrInvalidWorkspaceID
 	}
 
-	return s.getEventByID(ctx, eID, wsID)
+	event, err := s.getEventByID(ctx, eID, wsID)
+	if err != nil {
+		return Event{}, err
+	}
+	return s.loadEvent(ctx, event)
 }
 
-func (s *EventService) GetWorkspaceEvents(ctx context.Context, workspaceID string) ([]models.Event, error) {
+// GetWorkspaceEvents lists the events overlapping the window, recurring
+// events being expanded into their occurrences. Without an end to the
+// window, occurrences stop a year from now.
+func (s *EventService) GetWorkspaceEvents(ctx context.Context, workspaceID string, opts EventListOptions) ([]Event, error) {
 	var wsID pgtype.UUID
 	if err := wsID.Scan(workspaceID); err != nil {
 		return nil, ErrInvalidWorkspaceID
 	}
+	if !opts.From.IsZero() && !opts.To.IsZero() && !opts.From.Before(opts.To) {
+		return nil, ErrInvalidEventData
+	}
 
-	rows, err := s.s.Pool.Query(ctx, `
-		SELECT id, workspace_id, name, color, event_date, event_time, duration_minutes, attendees_count, created_at, updated_at
-		FROM events
-		WHERE workspace_id = $1
-		ORDER BY event_date ASC, event_time ASC
-	`, wsID)
-
+	rows, err := s.s.Queries.ListWorkspaceEvents(ctx, models.ListWorkspaceEventsParams{
+		WorkspaceID: wsID,
+		WindowStart: pgtype.Timestamptz{Time: opts.From, Valid: !opts.From.IsZero()},
+		WindowEnd:   pgtype.Timestamptz{Time: opts.To, Valid: !opts.To.IsZero()},
+		Colors:      s.eventColors(opts.Colors),
+		Attendee:    pgtype.Text{String: opts.Attendee, Valid: opts.Attendee != ""},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch events: %w", err)
 	}
-	defer rows.Close()
 
-	var events []models.Event
-	for rows.Next() {
-		var event models.Event
-		if err := rows.Scan(
-			&event.ID,
-			&event.WorkspaceID,
-			&event.Name,
-			&event.Color,
-			&event.EventDate,
-			&event.EventTime,
-			&event.DurationMinute,
-			&event.AttendeesCount,
-			&event.CreatedAt,
-			&event.UpdatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
+	var seriesIDs []pgtype.UUID
+	for _, row := range rows {
+		if row.Rrule.Valid {
+			seriesIDs = append(seriesIDs, row.ID)
+		}
+	}
+	edited := make(map[pgtype.UUID]map[int64]bool)
+	if len(seriesIDs) > 0 {
+		overrides, err := s.s.Queries.ListEventRecurrenceIDs(ctx, seriesIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch event occurrences: %w", err)
+		}
+		for _, o := range overrides {
+			if edited[o.RecurringEventID] == nil {
+				edited[o.RecurringEventID] = make(map[int64]bool)
+			}
+			edited[o.RecurringEventID][o.RecurrenceID.Time.Unix()] = true
 		}
-		events = append(events, event)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating events: %w", err)
+	to := opts.To
+	if to.IsZero() {
+		to = time.Now().Add(defaultRecurrenceHorizon)
+	}
+	events := make([]Event, 0, len(rows))
+	for _, row := range rows {
+		if row.Rrule.Valid {
+			events = append(events, expandEvent(row, edited[row.ID], opts.From, to)...)
+		} else {
+			events = append(events, toEvent(row))
+		}
+	}
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+	if err := s.withAttendees(ctx, events); err != nil {
+		return nil, err
 	}
 
 	return events, nil
 }
 
+// GetWorkspaceEventBuckets lists the events of the window grouped into
+// calendar days, weeks or months, for calendar views. The window must be
+// bounded, and is widened to whole buckets. Every bucket is returned, empty
+// ones included, and all-day events fall on their dates whatever the bucket
+// timezone.
+func (s *EventService) GetWorkspaceEventBuckets(ctx context.Context, workspaceID string, opts EventListOptions, bucket EventBucketOptions) ([]EventBucket, error) {
+	switch bucket.Size {
+	case EventBucketDay, EventBucketWeek, EventBucketMonth:
+	default:
+		return nil, ErrInvalidEventData
+	}
+	if opts.From.IsZero() || opts.To.IsZero() || !opts.From.Before(opts.To) {
+		return nil, ErrInvalidEventData
+	}
+	if bucket.Timezone == "" {
+		bucket.Timezone = "UTC"
+	}
+	loc, err := loadEventTimezone(bucket.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []EventBucket
+	for start := bucketStart(opts.From.In(loc), bucket.Size); start.Before(opts.To); {
+		if len(buckets) == maxEventBuckets {
+			return nil, ErrInvalidEventData
+		}
+		end := nextBucket(start, bucket.Size)
+		buckets = append(buckets, EventBucket{Start: start, End: end, Events: []Event{}})
+		start = end
+	}
+
+	opts.From, opts.To = buckets[0].Start, buckets[len(buckets)-1].End
+	events, err := s.GetWorkspaceEvents(ctx, workspaceID, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		start, end := event.StartsAt, event.EndsAt
+		if event.AllDay {
+			start, end = startOfDay(start, loc), startOfDay(end, loc)
+		}
+		// The first bucket ending after the event starts
+		i, _ := slices.BinarySearchFunc(buckets, start, func(b EventBucket, t time.Time) int {
+			if b.End.After(t) {
+				return 1
+			}
+			return -1
+		})
+		for ; i < len(buckets) && buckets[i].Start.Before(end); i++ {
+			buckets[i].Events = append(buckets[i].Events, event)
+		}
+	}
+
+	return buckets, nil
+}
+
+// bucketStart returns the start of the day, week or month t falls in
+func bucketStart(t time.Time, size string) time.Time {
+	y, m, d := t.Date()
+	switch size {
+	case EventBucketWeek:
+		return time.Date(y, m, d-int((t.Weekday()+6)%7), 0, 0, 0, 0, t.Location())
+	case EventBucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextBucket(start time.Time, size string) time.Time {
+	switch size {
+	case EventBucketWeek:
+		return start.AddDate(0, 0, 7)
+	case EventBucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// eventColors adds the hex codes of color types to the colors filtered on
+func (s *EventService) eventColors(colors []string) []string {
+	if len(colors) == 0 {
+		return nil
+	}
+	out := slices.Clone(colors)
+	for _, color := range colors {
+		if hex, err := s.GetEventTypeColor(color); err == nil {
+			out = append(out, hex)
+		}
+	}
+	return out
+}
+
 // GetEventTypeColor returns the hex color code for the given color type
 func (s *EventService) GetEventTypeColor(color string) (string, error) {
 	switch color {
@@ -363,25 +795,11 @@ func (s *EventService) GetEventTypeColor(color string) (string, error) {
 }
 
 func (s *EventService) getEventByID(ctx context.Context, eventID, workspaceID pgtype.UUID) (models.Event, error) {
-	row := s.s.Pool.QueryRow(ctx, `
-		SELECT id, workspace_id, name, color, event_date, event_time, duration_minutes, attendees_count, created_at, updated_at
-		FROM events
-		WHERE id = $1 AND workspace_id = $2
-	`, eventID, workspaceID)
-
-	var event models.Event
-	if err := row.Scan(
-		&event.ID,
-		&event.WorkspaceID,
-		&event.Name,
-		&event.Color,
-		&event.EventDate,
-		&event.EventTime,
-		&event.DurationMinute,
-		&event.AttendeesCount,
-		&event.CreatedAt,
-		&event.UpdatedAt,
-	); err != nil {
+	event, err := s.s.Queries.GetWorkspaceEvent(ctx, models.GetWorkspaceEventParams{
+		WorkspaceID: workspaceID,
+		ID:          eventID,
+	})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.Event{}, ErrEventNotFound
 		}
@@ -390,3 +808,383 @@ func (s *EventService) getEventByID(ctx context.Context, eventID, workspaceID pg
 
 	return event, nil
 }
+
+func insertEvent(ctx context.Context, q *models.Queries, event models.Event) (models.Event, error) {
+	created, err := q.CreateEvent(ctx, models.CreateEventParams{
+		WorkspaceID:      event.WorkspaceID,
+		Name:             event.Name,
+		Color:            event.Color,
+		StartsAt:         event.StartsAt,
+		Timezone:         event.Timezone,
+		AllDay:           event.AllDay,
+		DurationMinutes:  event.DurationMinutes,
+		AttendeesCount:   event.AttendeesCount,
+		Rrule:            event.Rrule,
+		Exdates:          nonNilExdates(event.Exdates),
+		RecurringEventID: event.RecurringEventID,
+		RecurrenceID:     event.RecurrenceID,
+		EndsAt:           eventEnd(event),
+		IcalUid:          event.IcalUid,
+	})
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to insert event: %w", err)
+	}
+	return created, nil
+}
+
+func saveEvent(ctx context.Context, q *models.Queries, event models.Event) (models.Event, error) {
+	updated, err := q.UpdateEvent(ctx, models.UpdateEventParams{
+		WorkspaceID:     event.WorkspaceID,
+		ID:              event.ID,
+		Name:            event.Name,
+		Color:           event.Color,
+		StartsAt:        event.StartsAt,
+		Timezone:        event.Timezone,
+		AllDay:          event.AllDay,
+		DurationMinutes: event.DurationMinutes,
+		Rrule:           event.Rrule,
+		Exdates:         nonNilExdates(event.Exdates),
+		EndsAt:          eventEnd(event),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Event{}, ErrEventNotFound
+		}
+		return models.Event{}, fmt.Errorf("failed to update event: %w", err)
+	}
+	return updated, nil
+}
+
+// moveEventOverrides hands the occurrences of the series fromID edited on
+// their own, from the occurrence at from on, over to the series toID, which
+// starts at to; or drops them
+func moveEventOverrides(ctx context.Context, q *models.Queries, fromID, toID pgtype.UUID, from, to time.Time, drop bool) error {
+	since := pgtype.Timestamptz{Time: from, Valid: true}
+	if drop {
+		if err := q.DeleteEventOverrides(ctx, models.DeleteEventOverridesParams{
+			EventID: fromID,
+			Since:   since,
+		}); err != nil {
+			return fmt.Errorf("failed to delete event occurrences: %w", err)
+		}
+		return nil
+	}
+
+	overrides, err := q.ListEventOverrides(ctx, models.ListEventOverridesParams{
+		RecurringEventID: fromID,
+		RecurrenceID:     since,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list event occurrences: %w", err)
+	}
+	for _, override := range overrides {
+		recurrenceID := shiftOccurrence(override.RecurrenceID.Time, from, to)
+		if override.RecurringEventID == toID && recurrenceID.Equal(override.RecurrenceID.Time) {
+			continue
+		}
+		if err := q.SetEventRecurrence(ctx, models.SetEventRecurrenceParams{
+			ID:               override.ID,
+			RecurringEventID: toID,
+			RecurrenceID:     pgtype.Timestamptz{Time: recurrenceID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to move event occurrence: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyEventUpdate applies the set fields of params to the event
+func applyEventUpdate(event *models.Event, params UpdateEventParams) error {
+	wasAllDay := event.AllDay
+
+	if params.Name != nil {
+		if *params.Name == "" {
+			return ErrMissingEventFields
+		}
+		event.Name = *params.Name
+	}
+
+	if params.Color != nil {
+		if *params.Color == "" {
+			return ErrMissingEventFields
+		}
+		event.Color = *params.Color
+	}
+
+	// An all-day event keeps its date when only its timezone changes
+	previous, err := loadEventTimezone(event.Timezone)
+	if err != nil {
+		return err
+	}
+	startsAt := event.StartsAt.Time.In(previous)
+
+	if params.Timezone != nil {
+		event.Timezone = *params.Timezone
+	}
+	loc, err := loadEventTimezone(event.Timezone)
+	if err != nil {
+		return err
+	}
+
+	if params.StartsAt != nil {
+		if params.StartsAt.IsZero() {
+			return ErrMissingEventFields
+		}
+		startsAt = *params.StartsAt
+	}
+
+	if params.AllDay != nil {
+		event.AllDay = *params.AllDay
+	}
+	if event.AllDay {
+		startsAt = startOfDay(startsAt, loc)
+		if !wasAllDay && params.DurationMinutes == nil {
+			event.DurationMinutes = minutesPerDay
+		}
+	}
+	event.StartsAt = pgtype.Timestamptz{Time: startsAt, Valid: true}
+
+	if params.DurationMinutes != nil {
+		event.DurationMinutes = *params.DurationMinutes
+	}
+	if err := checkEventDuration(event.DurationMinutes, event.AllDay); err != nil {
+		return err
+	}
+
+	if params.RRule != nil {
+		if event.RecurringEventID.Valid {
+			return ErrInvalidEventData
+		}
+		if event.Rrule, err = normalizeRRule(*params.RRule); err != nil {
+			return err
+		}
+	}
+	if params.ExDates != nil {
+		event.Exdates = normalizeExdates(*params.ExDates, event.AllDay, loc)
+	}
+
+	return nil
+}
+
+// toEvent expresses an event's times in its timezone
+func toEvent(event models.Event) Event {
+	loc := eventLocation(event)
+	startsAt := event.StartsAt.Time.In(loc)
+	out := Event{
+		Event:    event,
+		StartsAt: startsAt,
+		EndsAt:   startsAt.Add(time.Duration(event.DurationMinutes) * time.Minute),
+	}
+	if event.RecurrenceID.Valid {
+		recurrenceID := event.RecurrenceID.Time.In(loc)
+		out.RecurrenceID = &recurrenceID
+	}
+	return out
+}
+
+// eventLocation returns an event's timezone, UTC when it no longer loads
+func eventLocation(event models.Event) *time.Location {
+	loc, err := loadEventTimezone(event.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// eventEnd returns when an event ends, or when the last occurrence of a
+// recurring one does. It is left unset for series without end.
+func eventEnd(event models.Event) pgtype.Timestamptz {
+	last := event.StartsAt.Time
+	if event.Rrule.Valid {
+		rule, start, err := eventRule(event)
+		if err != nil {
+			return pgtype.Timestamptz{}
+		}
+		var ok bool
+		if last, ok = rule.last(start); !ok {
+			return pgtype.Timestamptz{}
+		}
+	}
+	return pgtype.Timestamptz{
+		Time:  last.Add(time.Duration(event.DurationMinutes) * time.Minute),
+		Valid: true,
+	}
+}
+
+// expandEvent returns the occurrences of a recurring event overlapping the
+// window, leaving out removed ones and those edited on their own
+func expandEvent(event models.Event, edited map[int64]bool, from, to time.Time) []Event {
+	rule, start, err := eventRule(event)
+	if err != nil {
+		return []Event{toEvent(event)}
+	}
+	removed := make(map[int64]bool, len(event.Exdates))
+	for _, exdate := range event.Exdates {
+		removed[exdate.Time.Unix()] = true
+	}
+
+	duration := time.Duration(event.DurationMinutes) * time.Minute
+	var occurrences []Event
+	rule.each(start, func(t time.Time) bool {
+		if !t.Before(to) || len(occurrences) >= maxEventOccurrences {
+			return false
+		}
+		if removed[t.Unix()] || edited[t.Unix()] || (!from.IsZero() && !t.Add(duration).After(from)) {
+			return true
+		}
+		occurrence := t
+		occurrences = append(occurrences, Event{
+			Event:        event,
+			StartsAt:     t,
+			EndsAt:       t.Add(duration),
+			RecurrenceID: &occurrence,
+		})
+		return true
+	})
+	return occurrences
+}
+
+// eventRule returns a recurring event's rule, and its start in its
+// timezone
+func eventRule(event models.Event) (recurrenceRule, time.Time, error) {
+	rule, err := parseRecurrenceRule(event.Rrule.String)
+	if err != nil {
+		return recurrenceRule{}, time.Time{}, ErrInvalidEventData
+	}
+	loc, err := loadEventTimezone(event.Timezone)
+	if err != nil {
+		return recurrenceRule{}, time.Time{}, err
+	}
+	return rule, event.StartsAt.Time.In(loc), nil
+}
+
+// checkOccurrence makes sure a recurring event has a remaining occurrence
+// starting at t
+func checkOccurrence(event models.Event, t time.Time) error {
+	if t.IsZero() {
+		return ErrMissingEventFields
+	}
+	rule, start, err := eventRule(event)
+	if err != nil {
+		return err
+	}
+	for _, exdate := range event.Exdates {
+		if exdate.Time.Equal(t) {
+			return ErrEventNotFound
+		}
+	}
+	if !rule.isOccurrence(start, t.In(start.Location())) {
+		return ErrEventNotFound
+	}
+	return nil
+}
+
+// checkEventScope defaults the scope to the whole series, or to the
+// occurrence itself when the event is an edited occurrence
+func checkEventScope(event models.Event, scope EventScope) (EventScope, error) {
+	switch scope.Scope {
+	case "":
+		scope.Scope = EventScopeAll
+		if event.RecurringEventID.Valid {
+			scope.Scope = EventScopeThis
+		}
+	case EventScopeThis, EventScopeFollowing, EventScopeAll:
+	default:
+		return EventScope{}, ErrInvalidEventData
+	}
+	return scope, nil
+}
+
+func normalizeRRule(value string) (pgtype.Text, error) {
+	if value == "" {
+		return pgtype.Text{}, nil
+	}
+	rule, err := parseRecurrenceRule(value)
+	if err != nil {
+		return pgtype.Text{}, ErrInvalidEventData
+	}
+	return pgtype.Text{String: rule.String(), Valid: true}, nil
+}
+
+// normalizeExdates stores removed occurrences to the second; for all-day
+// events only their date matters
+func normalizeExdates(exdates []time.Time, allDay bool, loc *time.Location) []pgtype.Timestamptz {
+	out := make([]pgtype.Timestamptz, 0, len(exdates))
+	for _, t := range exdates {
+		if allDay {
+			t = startOfDay(t, loc)
+		}
+		out = append(out, pgtype.Timestamptz{Time: t.Truncate(time.Second), Valid: true})
+	}
+	return out
+}
+
+func shiftExdates(exdates []pgtype.Timestamptz, from, to time.Time) []pgtype.Timestamptz {
+	out := make([]pgtype.Timestamptz, len(exdates))
+	for i, exdate := range exdates {
+		out[i] = pgtype.Timestamptz{Time: shiftOccurrence(exdate.Time, from, to), Valid: true}
+	}
+	return out
+}
+
+// shiftOccurrence moves t, an occurrence of a series starting at from, the
+// way the series moved to start at to: by as many days and the same change
+// of wall clock time, in to's timezone. Unlike a fixed duration, this
+// keeps occurrences on either side of a DST change in step.
+func shiftOccurrence(t, from, to time.Time) time.Time {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	days := int(time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC).Sub(time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+	seconds := clockSeconds(to) - clockSeconds(from)
+
+	y, m, d := t.In(from.Location()).Date()
+	return time.Date(y, m, d+days, 0, 0, clockSeconds(t.In(from.Location()))+seconds, 0, to.Location())
+}
+
+func clockSeconds(t time.Time) int {
+	hour, min, sec := t.Clock()
+	return hour*3600 + min*60 + sec
+}
+
+// exdatesFrom keeps the exdates from t on, or those before t
+func exdatesFrom(exdates []pgtype.Timestamptz, t time.Time, after bool) []pgtype.Timestamptz {
+	out := make([]pgtype.Timestamptz, 0, len(exdates))
+	for _, exdate := range exdates {
+		if exdate.Time.Before(t) != after {
+			out = append(out, exdate)
+		}
+	}
+	return out
+}
+
+func nonNilExdates(exdates []pgtype.Timestamptz) []pgtype.Timestamptz {
+	if exdates == nil {
+		return []pgtype.Timestamptz{}
+	}
+	return exdates
+}
+
+func loadEventTimezone(name string) (*time.Location, error) {
+	// "Local" would depend on the server's zone
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidEventData
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidEventData
+	}
+	return loc, nil
+}
+
+func checkEventDuration(minutes int32, allDay bool) error {
+	if minutes <= 0 || (allDay && minutes%minutesPerDay != 0) {
+		return ErrInvalidEventData
+	}
+	return nil
+}
+
+// startOfDay returns midnight in loc of the calendar date t is written in
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	ErrEventAttendeeNotFound = errors.New("event attendee not found")
+	ErrInvalidEventAttendee  = errors.New("invalid event attendee")
+)
+
+// RSVP statuses, as in the iCalendar PARTSTAT
+const (
+	EventRSVPNeedsAction = "needs-action"
+	EventRSVPAccepted    = "accepted"
+	EventRSVPDeclined    = "declined"
+	EventRSVPTentative   = "tentative"
+)
+
+// EventAttendeeParams names an attendee: a workspace member by ID, or
+// anyone by email. An email of a member makes that member the attendee.
+type EventAttendeeParams struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	// Status is the attendee's answer. When empty, new attendees have yet
+	// to answer and others keep their answer.
+	Status string `json:"status"`
+}
+
+// EventAttendee is an attendee of an event. Members come with their email
+// and name.
+type EventAttendee struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EventInvitation is what an attendee answering through an RSVP link sees
+// of the event
+type EventInvitation struct {
+	EventName string    `json:"event_name"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Timezone  string    `json:"timezone"`
+	AllDay    bool      `json:"all_day"`
+	RRule     string    `json:"rrule,omitempty"`
+	Email     string    `json:"email"`
+	Status    string    `json:"status"`
+}
+
+// RespondToEvent records the signed-in member's answer to an event. For a
+// recurring event, a non-zero occurrence answers for that occurrence only.
+func (s *EventService) RespondToEvent(ctx context.Context, eventID, workspaceID, userID string, occurrence time.Time, status string) (EventAttendee, error) {
+	if userID == "" {
+		return EventAttendee{}, ErrEventAttendeeNotFound
+	}
+	eID, err := parseUUID(eventID)
+	if err != nil {
+		return EventAttendee{}, ErrInvalidEventData
+	}
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return EventAttendee{}, ErrInvalidWorkspaceID
+	}
+	event, err := s.getEventByID(ctx, eID, wsID)
+	if err != nil {
+		return EventAttendee{}, err
+	}
+
+	return s.respond(ctx, event, occurrence, status, func(eventID pgtype.UUID) (models.EventAttendee, error) {
+		return s.s.Queries.GetEventAttendeeByUser(ctx, models.GetEventAttendeeByUserParams{
+			EventID: eventID,
+			UserID:  pgtype.Text{String: userID, Valid: true},
+		})
+	})
+}
+
+// CreateAttendeeLink returns a new RSVP link token for an attendee outside
+// the workspace, replacing the previous one. Members answer signed in.
+func (s *EventService) CreateAttendeeLink(ctx context.Context, eventID, workspaceID, attendeeID string) (string, error) {
+	eID, err := parseUUID(eventID)
+	if err != nil {
+		return "", ErrInvalidEventData
+	}
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return "", ErrInvalidWorkspaceID
+	}
+	aID, err := parseUUID(attendeeID)
+	if err != nil {
+		return "", ErrEventAttendeeNotFound
+	}
+	event, err := s.getEventByID(ctx, eID, wsID)
+	if err != nil {
+		return "", err
+	}
+
+	attendee, err := s.s.Queries.GetEventAttendee(ctx, models.GetEventAttendeeParams{
+		EventID: event.ID,
+		ID:      aID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrEventAttendeeNotFound
+		}
+		return "", fmt.Errorf("failed to fetch event attendee: %w", err)
+	}
+	if !attendee.Email.Valid {
+		return "", ErrInvalidEventAttendee
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.s.Queries.SetEventAttendeeTokenHash(ctx, models.SetEventAttendeeTokenHashParams{
+		ID:            attendee.ID,
+		RsvpTokenHash: pgtype.Text{String: hashSecretToken(token), Valid: true},
+	}); err != nil {
+		return "", fmt.Errorf("failed to save RSVP link: %w", err)
+	}
+	return token, nil
+}
+
+// GetInvitation returns the event an RSVP link token was made for
+func (s *EventService) GetInvitation(ctx context.Context, token string) (EventInvitation, error) {
+	attendee, event, err := s.getInvitee(ctx, token)
+	if err != nil {
+		return EventInvitation{}, err
+	}
+	out := toEvent(event)
+	return EventInvitation{
+		EventName: event.Name,
+		StartsAt:  out.StartsAt,
+		EndsAt:    out.EndsAt,
+		Timezone:  event.Timezone,
+		AllDay:    event.AllDay,
+		RRule:     event.Rrule.String,
+		Email:     attendee.Email.String,
+		Status:    attendee.Status,
+	}, nil
+}
+
+// RespondToInvitation records the answer of the attendee an RSVP link
+// token was made for. For a recurring event, a non-zero occurrence answers
+// for that occurrence only.
+func (s *EventService) RespondToInvitation(ctx context.Context, token string, occurrence time.Time, status string) (EventAttendee, error) {
+	attendee, event, err := s.getInvitee(ctx, token)
+	if err != nil {
+		return EventAttendee{}, err
+	}
+	return s.respond(ctx, event, occurrence, status, func(eventID pgtype.UUID) (models.EventAttendee, error) {
+		return s.s.Queries.GetEventAttendeeByEmail(ctx, models.GetEventAttendeeByEmailParams{
+			EventID: eventID,
+			Email:   attendee.Email,
+		})
+	})
+}
+
+func (s *EventService) getInvitee(ctx context.Context, token string) (models.GetEventAttendeeByTokenHashRow, models.Event, error) {
+	if token == "" {
+		return models.GetEventAttendeeByTokenHashRow{}, models.Event{}, ErrEventAttendeeNotFound
+	}
+	attendee, err := s.s.Queries.GetEventAttendeeByTokenHash(ctx, pgtype.Text{String: hashSecretToken(token), Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.GetEventAttendeeByTokenHashRow{}, models.Event{}, ErrEventAttendeeNotFound
+		}
+		return models.GetEventAttendeeByTokenHashRow{}, models.Event{}, fmt.Errorf("failed to fetch event attendee: %w", err)
+	}
+	event, err := s.getEventByID(ctx, attendee.EventID, attendee.WorkspaceID)
+	if err != nil {
+		return models.GetEventAttendeeByTokenHashRow{}, models.Event{}, err
+	}
+	return attendee, event, nil
+}
+
+// respond sets the status of the attendee found by find. Answering for one
+// occurrence of a recurring event stores the occurrence as an event of its
+// own the first time, with the series' attendees.
+func (s *EventService) respond(ctx context.Context, event models.Event, occurrence time.Time, status string, find func(pgtype.UUID) (models.EventAttendee, error)) (EventAttendee, error) {
+	if !isEventRSVPStatus(status) {
+		return EventAttendee{}, ErrInvalidEventAttendee
+	}
+
+	if event.Rrule.Valid && !occurrence.IsZero() {
+		// Make sure the attendee is invited before storing the occurrence
+		if _, err := find(event.ID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return EventAttendee{}, ErrEventAttendeeNotFound
+			}
+			return EventAttendee{}, fmt.Errorf("failed to fetch event attendee: %w", err)
+		}
+		override, err := s.s.Queries.GetEventOverride(ctx, models.GetEventOverrideParams{
+			RecurringEventID: event.ID,
+			RecurrenceID:     pgtype.Timestamptz{Time: occurrence, Valid: true},
+		})
+		switch {
+		case err == nil:
+			event = override
+		case errors.Is(err, pgx.ErrNoRows):
+			if err := checkOccurrence(event, occurrence); err != nil {
+				return EventAttendee{}, err
+			}
+			created, err := s.storeOccurrence(ctx, event, occurrence)
+			if err != nil {
+				return EventAttendee{}, err
+			}
+			event = created
+		default:
+			return EventAttendee{}, fmt.Errorf("failed to fetch event occurrence: %w", err)
+		}
+	}
+
+	attendee, err := find(event.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EventAttendee{}, ErrEventAttendeeNotFound
+		}
+		return EventAttendee{}, fmt.Errorf("failed to fetch event attendee: %w", err)
+	}
+	if err := s.s.Queries.SetEventAttendeeStatus(ctx, models.SetEventAttendeeStatusParams{
+		ID:     attendee.ID,
+		Status: status,
+	}); err != nil {
+		return EventAttendee{}, fmt.Errorf("failed to save RSVP: %w", err)
+	}
+
+	attendees, err := s.eventAttendees(ctx, []pgtype.UUID{event.ID})
+	if err != nil {
+		return EventAttendee{}, err
+	}
+	for _, a := range attendees[event.ID] {
+		if a.ID == uuidString(attendee.ID) {
+			return a, nil
+		}
+	}
+	return EventAttendee{}, ErrEventAttendeeNotFound
+}
+
+// resolveAttendees checks and normalizes the attendees given for an event
+// of the workspace: members must belong to it, emails of members become
+// those members, and repeated attendees are dropped.
+func (s *EventService) resolveAttendees(ctx context.Context, wsID pgtype.UUID, attendees []EventAttendeeParams) ([]EventAttendeeParams, error) {
+	out := make([]EventAttendeeParams, 0, len(attendees))
+	seen := make(map[EventAttendeeParams]bool, len(attendees))
+	for _, a := range attendees {
+		if a.Status != "" && !isEventRSVPStatus(a.Status) {
+			return nil, ErrInvalidEventAttendee
+		}
+		switch {
+		case (a.UserID == "") == (a.Email == ""):
+			return nil, ErrInvalidEventAttendee
+		case a.Email != "":
+			address, err := mail.ParseAddress(a.Email)
+			if err != nil {
+				return nil, ErrInvalidEventAttendee
+			}
+			a.Email = strings.ToLower(address.Address)
+			user, err := s.s.Queries.GetUserByEmail(ctx, a.Email)
+			switch {
+			case err == nil:
+				isMember, err := s.isWorkspaceMember(ctx, wsID, user.ID)
+				if err != nil {
+					return nil, err
+				}
+				if isMember {
+					a.UserID, a.Email = user.ID, ""
+				}
+			case !errors.Is(err, pgx.ErrNoRows):
+				return nil, fmt.Errorf("failed to fetch user: %w", err)
+			}
+		default:
+			isMember, err := s.isWorkspaceMember(ctx, wsID, a.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if !isMember {
+				return nil, ErrInvalidEventAttendee
+			}
+		}
+
+		key := EventAttendeeParams{UserID: a.UserID, Email: a.Email}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (s *EventService) isWorkspaceMember(ctx context.Context, wsID pgtype.UUID, userID string) (bool, error) {
+	isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+		UserID:      userID,
+		WorkspaceID: wsID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check membership: %w", err)
+	}
+	return isMember, nil
+}
+
+// setEventAttendees makes the resolved attendees those of the event.
+// Attendees staying keep their answer and RSVP link unless given a status.
+func setEventAttendees(ctx context.Context, q *models.Queries, eventID pgtype.UUID, attendees []EventAttendeeParams) error {
+	rows, err := q.ListEventAttendees(ctx, []pgtype.UUID{eventID})
+	if err != nil {
+		return fmt.Errorf("failed to fetch event attendees: %w", err)
+	}
+	existing := make(map[EventAttendeeParams]models.ListEventAttendeesRow, len(rows))
+	for _, row := range rows {
+		existing[EventAttendeeParams{UserID: row.UserID.String, Email: row.Email.String}] = row
+	}
+
+	for _, a := range attendees {
+		key := EventAttendeeParams{UserID: a.UserID, Email: a.Email}
+		row, ok := existing[key]
+		delete(existing, key)
+		switch {
+		case !ok:
+			status := a.Status
+			if status == "" {
+				status = EventRSVPNeedsAction
+			}
+			if err := q.CreateEventAttendee(ctx, models.CreateEventAttendeeParams{
+				EventID: eventID,
+				UserID:  pgtype.Text{String: a.UserID, Valid: a.UserID != ""},
+				Email:   pgtype.Text{String: a.Email, Valid: a.Email != ""},
+				Status:  status,
+			}); err != nil {
+				return fmt.Errorf("failed to add event attendee: %w", err)
+			}
+		case a.Status != "" && a.Status != row.Status:
+			if err := q.SetEventAttendeeStatus(ctx, models.SetEventAttendeeStatusParams{
+				ID:     row.ID,
+				Status: a.Status,
+			}); err != nil {
+				return fmt.Errorf("failed to save RSVP: %w", err)
+			}
+		}
+	}
+	for _, row := range existing {
+		if err := q.DeleteEventAttendee(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to remove event attendee: %w", err)
+		}
+	}
+
+	if err := q.RefreshEventAttendeesCount(ctx, eventID); err != nil {
+		return fmt.Errorf("failed to count event attendees: %w", err)
+	}
+	return nil
+}
+
+// storeOccurrence stores an occurrence of a recurring event as an event of
+// its own, in a transaction of its own
+func (s *EventService) storeOccurrence(ctx context.Context, series models.Event, occurrence time.Time) (models.Event, error) {
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Event{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := updateOccurrence(ctx, s.s.Queries.WithTx(tx), series, occurrence, UpdateEventParams{})
+	if err != nil {
+		return models.Event{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.Event{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return created.Event, nil
+}
+
+// eventAttendees returns the attendees of the events, by event
+func (s *EventService) eventAttendees(ctx context.Context, eventIDs []pgtype.UUID) (map[pgtype.UUID][]EventAttendee, error) {
+	rows, err := s.s.Queries.ListEventAttendees(ctx, eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch event attendees: %w", err)
+	}
+	out := make(map[pgtype.UUID][]EventAttendee, len(eventIDs))
+	for _, row := range rows {
+		attendee := EventAttendee{
+			ID:        uuidString(row.ID),
+			UserID:    row.UserID.String,
+			Email:     row.Email.String,
+			Username:  row.Username.String,
+			Status:    row.Status,
+			UpdatedAt: row.UpdatedAt.Time,
+		}
+		if row.UserID.Valid {
+			attendee.Email = row.UserEmail.String
+			attendee.Name = strings.TrimSpace(row.FirstName.String + " " + row.LastName.String)
+		}
+		out[row.EventID] = append(out[row.EventID], attendee)
+	}
+	return out, nil
+}
+
+// loadEvent expresses an event in its timezone, with its attendees
+func (s *EventService) loadEvent(ctx context.Context, event models.Event) (Event, error) {
+	events := []Event{toEvent(event)}
+	if err := s.withAttendees(ctx, events); err != nil {
+		return Event{}, err
+	}
+	return events[0], nil
+}
+
+// withAttendees fills in the attendees of the events. Occurrences of a
+// recurring event share the series' attendees.
+func (s *EventService) withAttendees(ctx context.Context, events []Event) error {
+	ids := make([]pgtype.UUID, 0, len(events))
+	seen := make(map[pgtype.UUID]bool, len(events))
+	for _, event := range events {
+		if !seen[event.ID] {
+			seen[event.ID] = true
+			ids = append(ids, event.ID)
+		}
+	}
+	attendees, err := s.eventAttendees(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		events[i].Attendees = attendees[events[i].ID]
+		if events[i].Attendees == nil {
+			events[i].Attendees = []EventAttendee{}
+		}
+	}
+	return nil
+}
+
+func isEventRSVPStatus(status string) bool {
+	switch status {
+	case EventRSVPNeedsAction, EventRSVPAccepted, EventRSVPDeclined, EventRSVPTentative:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,727 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	ErrInvalidEventImport  = errors.New("invalid iCalendar file")
+	ErrEventImportTooLarge = errors.New("iCalendar file has too many events")
+)
+
+const (
+	maxImportEvents = 5000
+	// defaultImportedEventMinutes is the duration of timed events without
+	// an end, or ending as they start
+	defaultImportedEventMinutes = 60
+	defaultImportedEventName    = "Untitled event"
+)
+
+// What an import did with an event
+const (
+	EventImportCreated = "created"
+	EventImportUpdated = "updated"
+	EventImportSkipped = "skipped"
+)
+
+// Reasons an event of an import was skipped
+const (
+	eventImportSkipUnchanged    = "unchanged"
+	eventImportSkipCancelled    = "cancelled"
+	eventImportSkipMissingUID   = "missing UID"
+	eventImportSkipMissingStart = "missing DTSTART"
+	eventImportSkipDuplicate    = "duplicate UID"
+	eventImportSkipBadTime      = "unreadable date or time"
+	eventImportSkipTimezone     = "unknown timezone"
+	eventImportSkipRecurrence   = "unsupported recurrence"
+	eventImportSkipNoSeries     = "recurring event not imported"
+	eventImportSkipNoOccurrence = "not an occurrence of its recurring event"
+	eventImportSkipInvalid      = "invalid event"
+)
+
+var (
+	errICalTimezone  = errors.New("unknown timezone")
+	icalColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+// EventImportOptions tunes an import
+type EventImportOptions struct {
+	// Color is given to created events without a COLOR of their own, blue
+	// when empty
+	Color string
+}
+
+type EventImportReport struct {
+	Created int             `json:"created"`
+	Updated int             `json:"updated"`
+	Skipped int             `json:"skipped"`
+	Events  []ImportedEvent `json:"events"`
+}
+
+// ImportedEvent is what an import did with one VEVENT
+type ImportedEvent struct {
+	UID string `json:"uid"`
+	// RecurrenceID is the original start of an occurrence of a recurring
+	// event, edited or cancelled on its own
+	RecurrenceID *time.Time `json:"recurrence_id,omitempty"`
+	Name         string     `json:"name"`
+	// Status is created, updated or skipped
+	Status  string `json:"status"`
+	EventID string `json:"event_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// icalEvent is a VEVENT read from an iCalendar file
+type icalEvent struct {
+	params CreateEventParams
+	// recurrence is the RECURRENCE-ID of an edited occurrence, read once
+	// its series' timezone is known
+	recurrence *icalProperty
+	cancelled  bool
+	zones      *icalZones
+}
+
+// ImportEvents creates events from an iCalendar file, such as an export of
+// another calendar app. Events keep their UID, so importing a file again
+// updates the events that changed since instead of duplicating them.
+// Recurring events are imported with their removed occurrences and those
+// edited or cancelled on their own. Events that cannot be imported are
+// listed in the report with the reason; an import never deletes a whole
+// event. Events are saved one by one: an error stops the import, leaving
+// those before in place.
+func (s *EventService) ImportEvents(ctx context.Context, workspaceID string, calendar io.Reader, opts EventImportOptions) (EventImportReport, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return EventImportReport{}, ErrInvalidWorkspaceID
+	}
+	if opts.Color == "" {
+		opts.Color = EventColorBlue
+	}
+
+	roots, err := parseICal(calendar)
+	if err != nil {
+		return EventImportReport{}, ErrInvalidEventImport
+	}
+	var components []*icalComponent
+	zones := make(map[*icalComponent]*icalZones)
+	for _, root := range roots {
+		if root.name != "VCALENDAR" {
+			return EventImportReport{}, ErrInvalidEventImport
+		}
+		z := newICalZones(root)
+		for _, c := range root.components {
+			if c.name == "VEVENT" {
+				components = append(components, c)
+				zones[c] = z
+			}
+		}
+	}
+	if len(components) == 0 {
+		return EventImportReport{}, ErrInvalidEventImport
+	}
+	if len(components) > maxImportEvents {
+		return EventImportReport{}, ErrEventImportTooLarge
+	}
+
+	if _, err := s.s.Queries.GetWorkspaceById(ctx, wsID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return EventImportReport{}, ErrEventNotFound
+		}
+		return EventImportReport{}, fmt.Errorf("failed to verify workspace: %w", err)
+	}
+
+	report := EventImportReport{Events: make([]ImportedEvent, 0, len(components))}
+	add := func(entry ImportedEvent) {
+		switch entry.Status {
+		case EventImportCreated:
+			report.Created++
+		case EventImportUpdated:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+		report.Events = append(report.Events, entry)
+	}
+
+	// Series go first, so their occurrences have something to attach to
+	series, occurrences, skipped := s.readICalEvents(components, zones)
+	for _, entry := range skipped {
+		add(entry)
+	}
+	for _, event := range series {
+		entry, err := s.importSeries(ctx, workspaceID, wsID, event, opts)
+		if err != nil {
+			return EventImportReport{}, err
+		}
+		add(entry)
+	}
+	for _, event := range occurrences {
+		entry, err := s.importOccurrence(ctx, workspaceID, wsID, event)
+		if err != nil {
+			return EventImportReport{}, err
+		}
+		add(entry)
+	}
+
+	return report, nil
+}
+
+// importSeries creates or updates an event, or a whole recurring one
+func (s *EventService) importSeries(ctx context.Context, workspaceID string, wsID pgtype.UUID, event icalEvent, opts EventImportOptions) (ImportedEvent, error) {
+	entry := ImportedEvent{UID: event.params.ICalUID, Name: event.params.Name}
+
+	existing, err := s.s.Queries.GetEventByICalUID(ctx, models.GetEventByICalUIDParams{
+		WorkspaceID: wsID,
+		IcalUid:     pgtype.Text{String: event.params.ICalUID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		params := event.params
+		if params.Color == "" {
+			params.Color = opts.Color
+		}
+		created, err := s.AddEvent(ctx, workspaceID, params)
+		if err != nil {
+			return skipInvalidImport(entry, err)
+		}
+		entry.Status, entry.EventID = EventImportCreated, uuidString(created.ID)
+		return entry, nil
+	}
+	if err != nil {
+		return ImportedEvent{}, fmt.Errorf("failed to fetch imported event: %w", err)
+	}
+
+	entry.EventID = uuidString(existing.ID)
+	params := importUpdateParams(event.params, true)
+	if changed, err := s.importChanges(ctx, existing, params); err != nil || !changed {
+		return skipUnchangedImport(entry, err)
+	}
+	if _, err := s.UpdateEvent(ctx, entry.EventID, workspaceID, EventScope{Scope: EventScopeAll}, params); err != nil {
+		return skipInvalidImport(entry, err)
+	}
+	entry.Status = EventImportUpdated
+	return entry, nil
+}
+
+// importOccurrence edits or cancels one occurrence of an imported
+// recurring event
+func (s *EventService) importOccurrence(ctx context.Context, workspaceID string, wsID pgtype.UUID, event icalEvent) (ImportedEvent, error) {
+	entry := ImportedEvent{UID: event.params.ICalUID, Name: event.params.Name}
+	skip := func(reason string) (ImportedEvent, error) {
+		entry.Status, entry.Reason = EventImportSkipped, reason
+		return entry, nil
+	}
+
+	series, err := s.s.Queries.GetEventByICalUID(ctx, models.GetEventByICalUIDParams{
+		WorkspaceID: wsID,
+		IcalUid:     pgtype.Text{String: event.params.ICalUID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return skip(eventImportSkipNoSeries)
+	}
+	if err != nil {
+		return ImportedEvent{}, fmt.Errorf("failed to fetch imported event: %w", err)
+	}
+	entry.EventID = uuidString(series.ID)
+	if !series.Rrule.Valid {
+		return skip(eventImportSkipNoOccurrence)
+	}
+
+	times, _, err := event.zones.times(*event.recurrence, eventLocation(series))
+	if err != nil {
+		return skip(icalTimeSkipReason(err))
+	}
+	occurrence := times[0].In(eventLocation(series))
+	entry.RecurrenceID = &occurrence
+
+	override, err := s.s.Queries.GetEventOverride(ctx, models.GetEventOverrideParams{
+		RecurringEventID: series.ID,
+		RecurrenceID:     pgtype.Timestamptz{Time: occurrence, Valid: true},
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ImportedEvent{}, fmt.Errorf("failed to fetch event occurrence: %w", err)
+	}
+
+	if event.cancelled {
+		if found {
+			// The series already leaves the occurrence out
+			if err := s.deleteEvent(ctx, s.s.Queries, wsID, override.ID); err != nil {
+				return ImportedEvent{}, err
+			}
+		} else if err := s.RemoveEvent(ctx, entry.EventID, workspaceID, EventScope{Scope: EventScopeThis, Occurrence: occurrence}); err != nil {
+			if errors.Is(err, ErrEventNotFound) {
+				return skip(eventImportSkipCancelled)
+			}
+			return skipInvalidImport(entry, err)
+		}
+		entry.Status = EventImportUpdated
+		return entry, nil
+	}
+
+	params := importUpdateParams(event.params, false)
+	if found {
+		entry.EventID = uuidString(override.ID)
+		if changed, err := s.importChanges(ctx, override, params); err != nil || !changed {
+			return skipUnchangedImport(entry, err)
+		}
+		if _, err := s.UpdateEvent(ctx, entry.EventID, workspaceID, EventScope{Scope: EventScopeThis}, params); err != nil {
+			return skipInvalidImport(entry, err)
+		}
+		entry.Status = EventImportUpdated
+		return entry, nil
+	}
+
+	created, err := s.UpdateEvent(ctx, entry.EventID, workspaceID, EventScope{Scope: EventScopeThis, Occurrence: occurrence}, params)
+	if errors.Is(err, ErrEventNotFound) {
+		return skip(eventImportSkipNoOccurrence)
+	}
+	if err != nil {
+		return skipInvalidImport(entry, err)
+	}
+	entry.Status, entry.EventID = EventImportCreated, uuidString(created.ID)
+	return entry, nil
+}
+
+// skipInvalidImport reports an event the event service rejected as
+// skipped, and returns other errors
+func skipInvalidImport(entry ImportedEvent, err error) (ImportedEvent, error) {
+	if errors.Is(err, ErrInvalidEventData) || errors.Is(err, ErrMissingEventFields) || errors.Is(err, ErrInvalidEventAttendee) {
+		entry.Status, entry.Reason = EventImportSkipped, eventImportSkipInvalid
+		return entry, nil
+	}
+	return ImportedEvent{}, err
+}
+
+// skipUnchangedImport reports an event left as is, because nothing changed
+// or because the changes are invalid
+func skipUnchangedImport(entry ImportedEvent, err error) (ImportedEvent, error) {
+	if err != nil {
+		return skipInvalidImport(entry, err)
+	}
+	entry.Status, entry.Reason = EventImportSkipped, eventImportSkipUnchanged
+	return entry, nil
+}
+
+// importUpdateParams turns an imported event into the update of an
+// existing one. Events keep their color when the file gives none.
+func importUpdateParams(p CreateEventParams, series bool) UpdateEventParams {
+	params := UpdateEventParams{
+		Name:            &p.Name,
+		StartsAt:        &p.StartsAt,
+		Timezone:        &p.Timezone,
+		AllDay:          &p.AllDay,
+		DurationMinutes: &p.DurationMinutes,
+		Attendees:       &p.Attendees,
+	}
+	if p.Color != "" {
+		params.Color = &p.Color
+	}
+	if series {
+		exdates := p.ExDates
+		if exdates == nil {
+			exdates = []time.Time{}
+		}
+		params.RRule, params.ExDates = &p.RRule, &exdates
+	}
+	return params
+}
+
+// importChanges reports whether the update changes the event or its
+// attendees
+func (s *EventService) importChanges(ctx context.Context, event models.Event, params UpdateEventParams) (bool, error) {
+	updated := event
+	if err := applyEventUpdate(&updated, params); err != nil {
+		return false, err
+	}
+	if updated.Name != event.Name || updated.Color != event.Color ||
+		!updated.StartsAt.Time.Equal(event.StartsAt.Time) || updated.Timezone != event.Timezone ||
+		updated.AllDay != event.AllDay || updated.DurationMinutes != event.DurationMinutes ||
+		updated.Rrule != event.Rrule ||
+		len(updated.Exdates) != len(event.Exdates) {
+		return true, nil
+	}
+	removed := make(map[int64]bool, len(event.Exdates))
+	for _, exdate := range event.Exdates {
+		removed[exdate.Time.Unix()] = true
+	}
+	for _, exdate := range updated.Exdates {
+		if !removed[exdate.Time.Unix()] {
+			return true, nil
+		}
+	}
+
+	attendees, err := s.resolveAttendees(ctx, event.WorkspaceID, *params.Attendees)
+	if err != nil {
+		return false, err
+	}
+	existing, err := s.eventAttendees(ctx, []pgtype.UUID{event.ID})
+	if err != nil {
+		return false, err
+	}
+	if len(attendees) != len(existing[event.ID]) {
+		return true, nil
+	}
+	statuses := make(map[EventAttendeeParams]string, len(attendees))
+	for _, a := range existing[event.ID] {
+		key := EventAttendeeParams{UserID: a.UserID}
+		if a.UserID == "" {
+			key.Email = a.Email
+		}
+		statuses[key] = a.Status
+	}
+	for _, a := range attendees {
+		status, ok := statuses[EventAttendeeParams{UserID: a.UserID, Email: a.Email}]
+		if !ok || (a.Status != "" && a.Status != status) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// readICalEvents reads the VEVENTs of an import into whole events, or
+// series, and occurrences of recurring ones edited or cancelled on their
+// own. Those that cannot be imported are returned as skipped.
+func (s *EventService) readICalEvents(components []*icalComponent, zones map[*icalComponent]*icalZones) (series, occurrences []icalEvent, skipped []ImportedEvent) {
+	seen := make(map[string]bool)
+	for _, c := range components {
+		event, reason := s.readICalEvent(c, zones[c])
+		key := event.params.ICalUID
+		if event.recurrence != nil {
+			key += "\x00" + event.recurrence.value
+		}
+		switch {
+		case reason != "":
+		case seen[key]:
+			reason = eventImportSkipDuplicate
+		case event.recurrence != nil:
+			occurrences = append(occurrences, event)
+			seen[key] = true
+		case event.cancelled:
+			reason = eventImportSkipCancelled
+		default:
+			series = append(series, event)
+			seen[key] = true
+		}
+		if reason != "" {
+			skipped = append(skipped, ImportedEvent{
+				UID:    event.params.ICalUID,
+				Name:   event.params.Name,
+				Status: EventImportSkipped,
+				Reason: reason,
+			})
+		}
+	}
+
+	// Occurrences cancelled on their own are removed from their series, so
+	// importing the file again leaves the series as is
+	cancelled := make(map[string][]*icalProperty)
+	for _, event := range occurrences {
+		if event.cancelled {
+			cancelled[event.params.ICalUID] = append(cancelled[event.params.ICalUID], event.recurrence)
+		}
+	}
+	for i := range series {
+		event := &series[i]
+		if event.params.RRule == "" {
+			continue
+		}
+		for _, p := range cancelled[event.params.ICalUID] {
+			if times, _, err := event.zones.times(*p, event.params.StartsAt.Location()); err == nil {
+				event.params.ExDates = append(event.params.ExDates, times[0])
+			}
+		}
+	}
+	return series, occurrences, skipped
+}
+
+// readICalEvent reads a VEVENT, returning why it cannot be imported if so
+func (s *EventService) readICalEvent(c *icalComponent, zones *icalZones) (icalEvent, string) {
+	event := icalEvent{zones: zones}
+	event.params.ICalUID = strings.TrimSpace(c.propText("UID"))
+	event.params.Name = strings.TrimSpace(c.propText("SUMMARY"))
+	if event.params.Name == "" {
+		event.params.Name = defaultImportedEventName
+	}
+	event.cancelled = strings.EqualFold(c.propText("STATUS"), "CANCELLED")
+	if event.params.ICalUID == "" {
+		return event, eventImportSkipMissingUID
+	}
+
+	if p, ok := c.prop("RECURRENCE-ID"); ok {
+		// Occurrences standing for the following ones too are not supported
+		if p.params["RANGE"] != "" {
+			return event, eventImportSkipRecurrence
+		}
+		event.recurrence = &p
+	}
+	if event.cancelled && event.recurrence != nil {
+		return event, ""
+	}
+
+	dtstart, ok := c.prop("DTSTART")
+	if !ok {
+		return event, eventImportSkipMissingStart
+	}
+	times, allDay, err := zones.times(dtstart, zones.floating)
+	if err != nil {
+		return event, icalTimeSkipReason(err)
+	}
+	start := times[0]
+	event.params.AllDay = allDay
+
+	rrules := c.propsNamed("RRULE")
+	if len(rrules) > 1 || len(c.propsNamed("RDATE")) > 0 || (len(rrules) > 0 && event.recurrence != nil) {
+		return event, eventImportSkipRecurrence
+	}
+	if len(rrules) == 1 {
+		rrule, err := normalizeRRule(rrules[0].value)
+		if err != nil {
+			return event, eventImportSkipRecurrence
+		}
+		event.params.RRule = rrule.String
+	}
+	// UTC times of one-off events read better in the calendar's timezone
+	if start.Location() == time.UTC && event.params.RRule == "" {
+		start = start.In(zones.floating)
+	}
+	event.params.StartsAt = start
+	event.params.Timezone = start.Location().String()
+
+	minutes, err := icalEventMinutes(c, zones, start, allDay)
+	if err != nil {
+		return event, icalTimeSkipReason(err)
+	}
+	event.params.DurationMinutes = minutes
+
+	if event.params.RRule != "" {
+		for _, p := range c.propsNamed("EXDATE") {
+			exdates, _, err := zones.times(p, start.Location())
+			if err != nil {
+				return event, icalTimeSkipReason(err)
+			}
+			event.params.ExDates = append(event.params.ExDates, exdates...)
+		}
+	}
+
+	event.params.Attendees = icalAttendees(c)
+	event.params.Color = s.icalEventColor(c.propText("COLOR"))
+	return event, ""
+}
+
+// icalAttendees returns the attendees of a VEVENT with an email address,
+// and their answers
+func icalAttendees(c *icalComponent) []EventAttendeeParams {
+	attendees := []EventAttendeeParams{}
+	for _, p := range c.propsNamed("ATTENDEE") {
+		value := strings.TrimSpace(p.value)
+		if len(value) < len("mailto:") || !strings.EqualFold(value[:len("mailto:")], "mailto:") {
+			continue
+		}
+		address, err := mail.ParseAddress(value[len("mailto:"):])
+		if err != nil {
+			continue
+		}
+		attendee := EventAttendeeParams{Email: address.Address}
+		if status := strings.ToLower(p.params["PARTSTAT"]); isEventRSVPStatus(status) {
+			attendee.Status = status
+		}
+		attendees = append(attendees, attendee)
+	}
+	return attendees
+}
+
+// icalEventMinutes returns how long an event lasts, from its DTEND or
+// DURATION. All-day events last whole days, at least one.
+func icalEventMinutes(c *icalComponent, zones *icalZones, start time.Time, allDay bool) (int32, error) {
+	var d time.Duration
+	if p, ok := c.prop("DTEND"); ok {
+		times, endAllDay, err := zones.times(p, start.Location())
+		if err != nil {
+			return 0, err
+		}
+		if allDay && endAllDay {
+			// Days, rather than hours, for DST changes not to count
+			y, m, day := times[0].Date()
+			d = time.Date(y, m, day, 0, 0, 0, 0, time.UTC).Sub(time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC))
+		} else {
+			d = times[0].Sub(start)
+		}
+	} else if p, ok := c.prop("DURATION"); ok {
+		var err error
+		if d, err = parseICalDuration(p.value); err != nil {
+			return 0, err
+		}
+	}
+	if d < 0 || d/time.Minute > math.MaxInt32 {
+		return 0, ErrInvalidEventData
+	}
+
+	if allDay {
+		days := (d + 24*time.Hour - 1) / (24 * time.Hour)
+		return int32(max(days, 1)) * minutesPerDay, nil
+	}
+	if d == 0 {
+		return defaultImportedEventMinutes, nil
+	}
+	return int32((d + time.Minute - 1) / time.Minute), nil
+}
+
+// icalEventColor returns the color of a COLOR property, a CSS color name,
+// if events have it. Empty values and other colors are left out.
+func (s *EventService) icalEventColor(value string) string {
+	if icalColorPattern.MatchString(value) {
+		return strings.ToUpper(value)
+	}
+	if hex, err := s.GetEventTypeColor(strings.ToLower(strings.TrimSpace(value))); err == nil {
+		return hex
+	}
+	return ""
+}
+
+func icalTimeSkipReason(err error) string {
+	switch {
+	case errors.Is(err, errICalTimezone):
+		return eventImportSkipTimezone
+	case errors.Is(err, ErrInvalidEventData):
+		return eventImportSkipInvalid
+	default:
+		return eventImportSkipBadTime
+	}
+}
+
+// icalZones resolves the TZIDs of an iCalendar file to locations
+type icalZones struct {
+	// floating holds dates and times given without a timezone, from the
+	// calendar's X-WR-TIMEZONE, UTC otherwise
+	floating *time.Location
+	// defined holds the VTIMEZONEs not named after an IANA zone
+	defined map[string]*time.Location
+}
+
+func newICalZones(calendar *icalComponent) *icalZones {
+	z := &icalZones{floating: time.UTC, defined: make(map[string]*time.Location)}
+	for _, c := range calendar.components {
+		if c.name != "VTIMEZONE" {
+			continue
+		}
+		tzid := c.propText("TZID")
+		if _, ok := z.location(tzid); ok {
+			continue
+		}
+		if loc, err := loadEventTimezone(c.propText("X-LIC-LOCATION")); err == nil {
+			z.defined[tzid] = loc
+		} else if loc, ok := fixedICalZone(c); ok {
+			z.defined[tzid] = loc
+		}
+	}
+	if loc, ok := z.location(calendar.propText("X-WR-TIMEZONE")); ok {
+		z.floating = loc
+	}
+	return z
+}
+
+// location resolves a TZID, either an IANA name, one ending with it, as in
+// /mozilla.org/20050126_1/Europe/Paris, or one of the file's VTIMEZONEs
+func (z *icalZones) location(tzid string) (*time.Location, bool) {
+	if loc, ok := z.defined[tzid]; ok {
+		return loc, true
+	}
+	if loc, err := loadEventTimezone(tzid); err == nil {
+		return loc, true
+	}
+	for i := 0; i < len(tzid); i++ {
+		if tzid[i] != '/' {
+			continue
+		}
+		if loc, err := loadEventTimezone(tzid[i+1:]); err == nil {
+			return loc, true
+		}
+	}
+	return nil, false
+}
+
+// times reads the values of a date or date-time property, and whether they
+// are dates. Dates and floating times are placed in loc.
+func (z *icalZones) times(p icalProperty, loc *time.Location) ([]time.Time, bool, error) {
+	if tzid := p.params["TZID"]; tzid != "" {
+		var ok bool
+		if loc, ok = z.location(tzid); !ok {
+			return nil, false, errICalTimezone
+		}
+	}
+	allDay := strings.EqualFold(p.params["VALUE"], "DATE")
+	var times []time.Time
+	for _, value := range strings.Split(strings.TrimSpace(p.value), ",") {
+		var t time.Time
+		var err error
+		switch {
+		case allDay || len(value) == len(icalDateLayout):
+			allDay = true
+			t, err = time.ParseInLocation(icalDateLayout, value, loc)
+		case strings.HasSuffix(value, "Z"):
+			t, err = time.Parse(icalDateTimeLayout+"Z", value)
+		default:
+			t, err = time.ParseInLocation(icalDateTimeLayout, value, loc)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		times = append(times, t)
+	}
+	return times, allDay, nil
+}
+
+// fixedICalZone returns an Etc zone for a VTIMEZONE keeping the same whole
+// hour offset all year
+func fixedICalZone(c *icalComponent) (*time.Location, bool) {
+	offset, found := 0, false
+	for _, observance := range c.components {
+		value, err := parseICalOffset(observance.propText("TZOFFSETTO"))
+		if err != nil || (found && value != offset) {
+			return nil, false
+		}
+		offset, found = value, true
+	}
+	if !found || offset%3600 != 0 {
+		return nil, false
+	}
+	if offset == 0 {
+		return time.UTC, true
+	}
+	// Etc zones have their sign reversed: Etc/GMT-1 is UTC+1
+	loc, err := loadEventTimezone(fmt.Sprintf("Etc/GMT%+d", -offset/3600))
+	return loc, err == nil
+}
+
+// parseICalOffset parses a UTC offset such as +0100, in seconds
+func parseICalOffset(value string) (int, error) {
+	if len(value) != 5 && len(value) != 7 || (value[0] != '+' && value[0] != '-') {
+		return 0, errors.New("malformed offset")
+	}
+	n, err := strconv.Atoi(value[1:])
+	if err != nil {
+		return 0, errors.New("malformed offset")
+	}
+	if len(value) == 5 {
+		n *= 100
+	}
+	seconds := n/10000*3600 + n/100%100*60 + n%100
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
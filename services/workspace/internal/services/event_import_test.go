@@ -0,0 +1,424 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readTestCalendar parses a VCALENDAR made of lines
+func readTestCalendar(t *testing.T, lines ...string) *icalComponent {
+	t.Helper()
+	calendar := "BEGIN:VCALENDAR\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	roots, err := parseICal(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatalf("parseICal() = %v", err)
+	}
+	return roots[0]
+}
+
+func TestICalZonesLocation(t *testing.T) {
+	calendar := readTestCalendar(t,
+		"X-WR-TIMEZONE:America/New_York",
+		"BEGIN:VTIMEZONE",
+		"TZID:Custom Paris",
+		"X-LIC-LOCATION:Europe/Paris",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Fixed",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"TZOFFSETTO:+0100",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Fixed West",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:-0300",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Zero",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:+0000",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Half hour",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:+0530",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Changing",
+		"BEGIN:STANDARD",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"TZOFFSETTO:+0200",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Berlin",
+		"X-LIC-LOCATION:Asia/Tokyo",
+		"END:VTIMEZONE",
+	)
+	zones := newICalZones(calendar)
+
+	if got := zones.floating.String(); got != "America/New_York" {
+		t.Errorf("floating got %s want America/New_York", got)
+	}
+
+	tests := []struct {
+		tzid string
+		want string
+	}{
+		{"Europe/Paris", "Europe/Paris"},
+		{"/mozilla.org/20050126_1/Europe/Paris", "Europe/Paris"},
+		{"/citadel.org/20190914_1/America/Chicago", "America/Chicago"},
+		{"Custom Paris", "Europe/Paris"},
+		{"Fixed", "Etc/GMT-1"},
+		{"Fixed West", "Etc/GMT+3"},
+		{"Zero", "UTC"},
+		// IANA names are not redefined by VTIMEZONEs
+		{"Europe/Berlin", "Europe/Berlin"},
+		{"Half hour", ""},
+		{"Changing", ""},
+		{"Nowhere", ""},
+		{"Local", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		loc, ok := zones.location(tt.tzid)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("location(%q) got %s want none", tt.tzid, loc)
+			}
+			continue
+		}
+		if !ok || loc.String() != tt.want {
+			t.Errorf("location(%q) got %v, %t want %s", tt.tzid, loc, ok, tt.want)
+		}
+	}
+}
+
+func TestICalZonesFloating(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+	}{
+		{nil, "UTC"},
+		{[]string{"X-WR-TIMEZONE:Europe/Paris"}, "Europe/Paris"},
+		{[]string{"X-WR-TIMEZONE:Nowhere"}, "UTC"},
+	}
+	for _, tt := range tests {
+		if got := newICalZones(readTestCalendar(t, tt.lines...)).floating.String(); got != tt.want {
+			t.Errorf("floating for %q got %s want %s", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestICalZonesTimes(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	zones := newICalZones(readTestCalendar(t))
+
+	tests := []struct {
+		line   string
+		want   []time.Time
+		allDay bool
+		err    error
+	}{
+		{"DTSTART;TZID=Europe/Paris:20240310T090000", []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, paris)}, false, nil},
+		{`DTSTART;TZID="/mozilla.org/20050126_1/Europe/Paris":20240310T090000`, []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, paris)}, false, nil},
+		{"DTSTART:20240310T090000Z", []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)}, false, nil},
+		// Floating times are in the given location
+		{"DTSTART:20240310T090000", []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, tokyo)}, false, nil},
+		// A UTC time ignores its TZID
+		{"DTSTART;TZID=Europe/Paris:20240310T090000Z", []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)}, false, nil},
+		{"DTSTART;VALUE=DATE:20240310", []time.Time{time.Date(2024, 3, 10, 0, 0, 0, 0, tokyo)}, true, nil},
+		{"DTSTART:20240310", []time.Time{time.Date(2024, 3, 10, 0, 0, 0, 0, tokyo)}, true, nil},
+		{"EXDATE;TZID=Europe/Paris:20240101T090000,20240108T090000", []time.Time{
+			time.Date(2024, 1, 1, 9, 0, 0, 0, paris),
+			time.Date(2024, 1, 8, 9, 0, 0, 0, paris),
+		}, false, nil},
+		{"DTSTART;TZID=Nowhere:20240310T090000", nil, false, errICalTimezone},
+		{"DTSTART:2024-03-10", nil, false, errors.New("any")},
+		{"DTSTART;VALUE=DATE:20240310T090000", nil, false, errors.New("any")},
+	}
+	for _, tt := range tests {
+		p, err := parseICalLine(tt.line)
+		if err != nil {
+			t.Fatalf("parseICalLine(%q) = %v", tt.line, err)
+		}
+		times, allDay, err := zones.times(p, tokyo)
+		if tt.err != nil {
+			if err == nil || (errors.Is(tt.err, errICalTimezone) && !errors.Is(err, errICalTimezone)) {
+				t.Errorf("times(%q) got error %v want %v", tt.line, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("times(%q) = %v", tt.line, err)
+			continue
+		}
+		if allDay != tt.allDay || len(times) != len(tt.want) {
+			t.Errorf("times(%q) got %v, %t want %v, %t", tt.line, times, allDay, tt.want, tt.allDay)
+			continue
+		}
+		for i := range times {
+			if !times[i].Equal(tt.want[i]) || times[i].Location().String() != tt.want[i].Location().String() {
+				t.Errorf("times(%q)[%d] got %v want %v", tt.line, i, times[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseICalOffset(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"+0100", 3600},
+		{"-0500", -5 * 3600},
+		{"+0530", 5*3600 + 30*60},
+		{"+0000", 0},
+		{"-001530", -(15*60 + 30)},
+	}
+	for _, tt := range tests {
+		got, err := parseICalOffset(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseICalOffset(%q) got %d, %v want %d", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "0100", "+100", "+01:00", "+01000", "UTC"} {
+		if _, err := parseICalOffset(value); err == nil {
+			t.Errorf("parseICalOffset(%q) got no error", value)
+		}
+	}
+}
+
+func TestReadICalEvents(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	calendar := readTestCalendar(t,
+		"X-WR-TIMEZONE:Europe/Paris",
+		// A weekly series, one occurrence of which is edited and three
+		// cancelled on their own
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"SUMMARY:Weekly sync",
+		"DTSTART;TZID=Europe/Paris:20240101T090000",
+		"DTEND;TZID=Europe/Paris:20240101T093000",
+		"RRULE:FREQ=WEEKLY",
+		"EXDATE;TZID=Europe/Paris:20240108T090000",
+		`ATTENDEE;CN="Doe; Jane";PARTSTAT=ACCEPTED:mailto:jane@example.com`,
+		"ATTENDEE:urn:uuid:not-an-email",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID;TZID=Europe/Paris:20240115T090000",
+		"SUMMARY:Weekly sync, moved",
+		"DTSTART;TZID=Europe/Paris:20240115T110000",
+		"DURATION:PT1H",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID:20240122T080000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		// Floating, so in the series' timezone
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID:20240129T090000",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID;TZID=Nowhere:20240205T090000",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly",
+		"RECURRENCE-ID;RANGE=THISANDFUTURE;TZID=Europe/Paris:20240212T090000",
+		"DTSTART;TZID=Europe/Paris:20240212T100000",
+		"END:VEVENT",
+		// A one-off event with a cancelled occurrence, which is left to the
+		// import to skip
+		"BEGIN:VEVENT",
+		"UID:single",
+		"DTSTART:20240301T120000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:single",
+		"RECURRENCE-ID:20240301T120000Z",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:single",
+		"DTSTART:20240302T120000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled",
+		"STATUS:CANCELLED",
+		"DTSTART:20240301T120000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:edited-series",
+		"RECURRENCE-ID:20240301T120000Z",
+		"DTSTART:20240301T120000Z",
+		"RRULE:FREQ=DAILY",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:No UID",
+		"DTSTART:20240301T120000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:no-start",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:no-zone",
+		"DTSTART;TZID=Nowhere:20240301T120000",
+		"END:VEVENT",
+	)
+	components := calendar.components
+	zones := make(map[*icalComponent]*icalZones)
+	z := newICalZones(calendar)
+	for _, c := range components {
+		zones[c] = z
+	}
+
+	s := &EventService{}
+	series, occurrences, skipped := s.readICalEvents(components, zones)
+
+	var gotSkipped []string
+	for _, entry := range skipped {
+		gotSkipped = append(gotSkipped, entry.UID+": "+entry.Reason)
+	}
+	wantSkipped := []string{
+		"weekly: " + eventImportSkipRecurrence,
+		"single: " + eventImportSkipDuplicate,
+		"cancelled: " + eventImportSkipCancelled,
+		"edited-series: " + eventImportSkipRecurrence,
+		": " + eventImportSkipMissingUID,
+		"no-start: " + eventImportSkipMissingStart,
+		"no-zone: " + eventImportSkipTimezone,
+	}
+	if fmt.Sprint(gotSkipped) != fmt.Sprint(wantSkipped) {
+		t.Errorf("skipped got %q want %q", gotSkipped, wantSkipped)
+	}
+
+	if len(series) != 2 {
+		t.Fatalf("got %d series want 2", len(series))
+	}
+	weekly := series[0].params
+	if weekly.ICalUID != "weekly" || weekly.Name != "Weekly sync" || weekly.RRule != "FREQ=WEEKLY" {
+		t.Errorf("got series %q %q %q want weekly", weekly.ICalUID, weekly.Name, weekly.RRule)
+	}
+	if want := time.Date(2024, 1, 1, 9, 0, 0, 0, paris); !weekly.StartsAt.Equal(want) || weekly.Timezone != "Europe/Paris" {
+		t.Errorf("got start %v in %s want %v in Europe/Paris", weekly.StartsAt, weekly.Timezone, want)
+	}
+	if weekly.DurationMinutes != 30 {
+		t.Errorf("got duration %d want 30", weekly.DurationMinutes)
+	}
+	// The file's own EXDATE, then the cancelled occurrences whose times
+	// could be read
+	wantExDates := []time.Time{
+		time.Date(2024, 1, 8, 9, 0, 0, 0, paris),
+		time.Date(2024, 1, 22, 9, 0, 0, 0, paris),
+		time.Date(2024, 1, 29, 9, 0, 0, 0, paris),
+	}
+	if len(weekly.ExDates) != len(wantExDates) {
+		t.Errorf("got exdates %v want %v", weekly.ExDates, wantExDates)
+	} else {
+		for i, exdate := range weekly.ExDates {
+			if !exdate.Equal(wantExDates[i]) {
+				t.Errorf("exdate %d got %v want %v", i, exdate, wantExDates[i])
+			}
+		}
+	}
+	if want := []EventAttendeeParams{{Email: "jane@example.com", Status: EventRSVPAccepted}}; fmt.Sprint(weekly.Attendees) != fmt.Sprint(want) {
+		t.Errorf("got attendees %+v want %+v", weekly.Attendees, want)
+	}
+
+	single := series[1].params
+	if single.ICalUID != "single" || single.Name != defaultImportedEventName || len(single.ExDates) != 0 {
+		t.Errorf("got series %q %q with exdates %v want single without", single.ICalUID, single.Name, single.ExDates)
+	}
+	// One-off UTC times are shown in the calendar's timezone
+	if single.Timezone != "Europe/Paris" || single.DurationMinutes != defaultImportedEventMinutes {
+		t.Errorf("got %s for %d minutes want Europe/Paris for %d", single.Timezone, single.DurationMinutes, defaultImportedEventMinutes)
+	}
+
+	tests := []struct {
+		uid        string
+		recurrence string
+		cancelled  bool
+		name       string
+	}{
+		{"weekly", "20240115T090000", false, "Weekly sync, moved"},
+		{"weekly", "20240122T080000Z", true, defaultImportedEventName},
+		{"weekly", "20240129T090000", true, defaultImportedEventName},
+		{"weekly", "20240205T090000", true, defaultImportedEventName},
+		{"single", "20240301T120000Z", true, defaultImportedEventName},
+	}
+	if len(occurrences) != len(tests) {
+		t.Fatalf("got %d occurrences want %d", len(occurrences), len(tests))
+	}
+	for i, tt := range tests {
+		event := occurrences[i]
+		if event.params.ICalUID != tt.uid || event.recurrence.value != tt.recurrence || event.cancelled != tt.cancelled || event.params.Name != tt.name {
+			t.Errorf("occurrence %d got %q %q cancelled %t %q want %q %q cancelled %t %q", i,
+				event.params.ICalUID, event.recurrence.value, event.cancelled, event.params.Name,
+				tt.uid, tt.recurrence, tt.cancelled, tt.name)
+		}
+	}
+	moved := occurrences[0].params
+	if want := time.Date(2024, 1, 15, 11, 0, 0, 0, paris); !moved.StartsAt.Equal(want) || moved.DurationMinutes != 60 {
+		t.Errorf("got moved occurrence at %v for %d minutes want %v for 60", moved.StartsAt, moved.DurationMinutes, want)
+	}
+}
+
+func TestICalEventMinutes(t *testing.T) {
+	zones := newICalZones(readTestCalendar(t))
+	paris := mustLoadLocation(t, "Europe/Paris")
+
+	tests := []struct {
+		name   string
+		lines  []string
+		start  time.Time
+		allDay bool
+		want   int32
+	}{
+		{"end", []string{"DTEND:20240101T103000Z"}, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false, 90},
+		{"duration", []string{"DURATION:PT45M"}, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false, 45},
+		{"none", nil, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false, defaultImportedEventMinutes},
+		{"partial minute", []string{"DURATION:PT90S"}, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false, 2},
+		{"all day", []string{"DTEND;VALUE=DATE:20240104"}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true, 3 * minutesPerDay},
+		{"all day without end", nil, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true, minutesPerDay},
+		// Over a DST change the days are still whole
+		{"all day over DST", []string{"DTEND;VALUE=DATE:20240402"}, time.Date(2024, 3, 30, 0, 0, 0, 0, paris), true, 3 * minutesPerDay},
+	}
+	for _, tt := range tests {
+		c := &icalComponent{name: "VEVENT"}
+		for _, line := range tt.lines {
+			p, err := parseICalLine(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.props = append(c.props, p)
+		}
+		got, err := icalEventMinutes(c, zones, tt.start, tt.allDay)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %d, %v want %d", tt.name, got, err, tt.want)
+		}
+	}
+
+	c := &icalComponent{name: "VEVENT", props: []icalProperty{{name: "DTEND", value: "20240101T080000Z"}}}
+	if _, err := icalEventMinutes(c, zones, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false); !errors.Is(err, ErrInvalidEventData) {
+		t.Errorf("ending before the start got %v want %v", err, ErrInvalidEventData)
+	}
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+const (
+	// maxEmptyRecurrencePeriods stops expanding rules that no longer
+	// produce occurrences, such as the 31st of every other February
+	maxEmptyRecurrencePeriods = 1000
+	// maxRecurrenceCount and maxRecurrenceYears bound how long rules
+	// recur, so walking every occurrence stays cheap
+	maxRecurrenceCount = 10000
+	maxRecurrenceYears = 100
+	rruleUntilLayout   = "20060102T150405Z"
+	rruleDateLayout    = "20060102"
+)
+
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ruleWeekday is a BYDAY entry. A non-zero ordinal picks the nth such
+// weekday of the month, counting from the end when negative.
+type ruleWeekday struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+// recurrenceRule is the subset of RFC 5545 RRULE events support: daily,
+// weekly on given weekdays and monthly by day or weekday, bounded by UNTIL
+// or COUNT.
+type recurrenceRule struct {
+	freq       string
+	interval   int
+	byDay      []ruleWeekday
+	byMonthDay []int
+	weekStart  time.Weekday
+	count      int
+	// until is inclusive. With untilDate only its calendar date matters.
+	until     time.Time
+	untilDate bool
+}
+
+// parseRecurrenceRule parses an RRULE value, with or without the "RRULE:"
+// prefix
+func parseRecurrenceRule(value string) (recurrenceRule, error) {
+	rule := recurrenceRule{interval: 1, weekStart: time.Monday}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return recurrenceRule{}, fmt.Errorf("empty rule")
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return recurrenceRule{}, fmt.Errorf("malformed rule part %q", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(val)
+			if err == nil && rule.interval < 1 {
+				err = fmt.Errorf("interval must be positive")
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(val)
+			if err == nil && (rule.count < 1 || rule.count > maxRecurrenceCount) {
+				err = fmt.Errorf("count must be between 1 and %d", maxRecurrenceCount)
+			}
+		case "UNTIL":
+			if rule.until, err = time.Parse(rruleUntilLayout, val); err != nil {
+				rule.until, err = time.Parse(rruleDateLayout, val)
+				rule.untilDate = err == nil
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				wd, err := parseRuleWeekday(day)
+				if err != nil {
+					return recurrenceRule{}, err
+				}
+				rule.byDay = append(rule.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return recurrenceRule{}, fmt.Errorf("invalid month day %q", day)
+				}
+				rule.byMonthDay = append(rule.byMonthDay, n)
+			}
+		case "WKST":
+			i := slices.Index(rruleWeekdays, strings.ToUpper(val))
+			if i < 0 {
+				return recurrenceRule{}, fmt.Errorf("invalid week start %q", val)
+			}
+			rule.weekStart = time.Weekday(i)
+		default:
+			return recurrenceRule{}, fmt.Errorf("unsupported rule part %q", name)
+		}
+		if err != nil {
+			return recurrenceRule{}, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	switch rule.freq {
+	case FreqDaily:
+		if len(rule.byDay) > 0 || len(rule.byMonthDay) > 0 {
+			return recurrenceRule{}, fmt.Errorf("daily rules take no BYDAY or BYMONTHDAY")
+		}
+	case FreqWeekly:
+		if len(rule.byMonthDay) > 0 {
+			return recurrenceRule{}, fmt.Errorf("weekly rules take no BYMONTHDAY")
+		}
+		for _, wd := range rule.byDay {
+			if wd.ordinal != 0 {
+				return recurrenceRule{}, fmt.Errorf("weekly rules take plain weekdays")
+			}
+		}
+	case FreqMonthly:
+		if len(rule.byDay) > 0 && len(rule.byMonthDay) > 0 {
+			return recurrenceRule{}, fmt.Errorf("monthly rules take BYDAY or BYMONTHDAY, not both")
+		}
+	default:
+		return recurrenceRule{}, fmt.Errorf("unsupported frequency %q", rule.freq)
+	}
+	if rule.count > 0 && !rule.until.IsZero() {
+		return recurrenceRule{}, fmt.Errorf("COUNT and UNTIL are exclusive")
+	}
+	return rule, nil
+}
+
+func parseRuleWeekday(value string) (ruleWeekday, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return ruleWeekday{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	i := slices.Index(rruleWeekdays, value[len(value)-2:])
+	if i < 0 {
+		return ruleWeekday{}, fmt.Errorf("invalid weekday %q", value)
+	}
+	wd := ruleWeekday{weekday: time.Weekday(i)}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return ruleWeekday{}, fmt.Errorf("invalid weekday %q", value)
+		}
+		wd.ordinal = n
+	}
+	return wd, nil
+}
+
+// String formats the rule as an RRULE value, without the prefix
+func (r recurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		days := make([]string, len(r.byDay))
+		for i, wd := range r.byDay {
+			days[i] = rruleWeekdays[wd.weekday]
+			if wd.ordinal != 0 {
+				days[i] = strconv.Itoa(wd.ordinal) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.byMonthDay) > 0 {
+		days := make([]string, len(r.byMonthDay))
+		for i, day := range r.byMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.weekStart != time.Monday {
+		parts = append(parts, "WKST="+rruleWeekdays[r.weekStart])
+	}
+	switch {
+	case r.count > 0:
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	case r.untilDate:
+		parts = append(parts, "UNTIL="+r.until.Format(rruleDateLayout))
+	case !r.until.IsZero():
+		parts = append(parts, "UNTIL="+r.until.UTC().Format(rruleUntilLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// each calls fn with the start of every occurrence in order, beginning
+// with start, until fn returns false or the rule ends. Occurrences keep
+// start's wall clock time in its location. Rules end maxRecurrenceYears
+// after start at the latest, whatever their UNTIL.
+func (r recurrenceRule) each(start time.Time, fn func(time.Time) bool) {
+	loc := start.Location()
+	horizon := start.AddDate(maxRecurrenceYears, 0, 0)
+	hour, min, sec := start.Clock()
+	n, empty := 0, 0
+	for period := 0; empty < maxEmptyRecurrencePeriods; period++ {
+		days := r.periodDays(start, period*r.interval)
+		if len(days) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, day := range days {
+			y, m, d := day.Date()
+			t := time.Date(y, m, d, hour, min, sec, 0, loc)
+			if t.Before(start) {
+				continue
+			}
+			if r.afterUntil(t) || t.After(horizon) {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			if n++; r.count > 0 && n >= r.count {
+				return
+			}
+		}
+	}
+}
+
+func (r recurrenceRule) afterUntil(t time.Time) bool {
+	if r.until.IsZero() {
+		return false
+	}
+	if r.untilDate {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.until)
+	}
+	return t.After(r.until)
+}
+
+// periodDays returns the days, at midnight UTC, the rule picks in the
+// period offset periods after start's, in order
+func (r recurrenceRule) periodDays(start time.Time, offset int) []time.Time {
+	y, m, d := start.Date()
+	first := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	switch r.freq {
+	case FreqDaily:
+		return []time.Time{first.AddDate(0, 0, offset)}
+
+	case FreqWeekly:
+		weekStart := first.AddDate(0, 0, -int((first.Weekday()-r.weekStart+7)%7)+7*offset)
+		weekdays := []time.Weekday{start.Weekday()}
+		if len(r.byDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, wd := range r.byDay {
+				weekdays = append(weekdays, wd.weekday)
+			}
+		}
+		var days []time.Time
+		for _, wd := range weekdays {
+			days = append(days, weekStart.AddDate(0, 0, int((wd-r.weekStart+7)%7)))
+		}
+		return sortedDays(days)
+
+	case FreqMonthly:
+		month := time.Date(y, m+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		length := month.AddDate(0, 1, -1).Day()
+		var monthDays []int
+		switch {
+		case len(r.byMonthDay) > 0:
+			for _, day := range r.byMonthDay {
+				if day < 0 {
+					day = length + day + 1
+				}
+				monthDays = append(monthDays, day)
+			}
+		case len(r.byDay) > 0:
+			for _, wd := range r.byDay {
+				if wd.ordinal != 0 {
+					monthDays = append(monthDays, nthWeekday(month.Year(), month.Month(), wd.weekday, wd.ordinal))
+					continue
+				}
+				for day := nthWeekday(month.Year(), month.Month(), wd.weekday, 1); day <= length; day += 7 {
+					monthDays = append(monthDays, day)
+				}
+			}
+		default:
+			monthDays = append(monthDays, d)
+		}
+		var days []time.Time
+		for _, day := range monthDays {
+			if day >= 1 && day <= length {
+				days = append(days, month.AddDate(0, 0, day-1))
+			}
+		}
+		return sortedDays(days)
+	}
+	return nil
+}
+
+// nthWeekday returns the day of the month of its nth such weekday, counting
+// from the end when n is negative. The day is out of the month when there
+// are fewer.
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) int {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	firstMatch := 1 + int((weekday-first.Weekday()+7)%7)
+	if n > 0 {
+		return firstMatch + 7*(n-1)
+	}
+	length := first.AddDate(0, 1, -1).Day()
+	lastMatch := firstMatch + 7*((length-firstMatch)/7)
+	return lastMatch + 7*(n+1)
+}
+
+func sortedDays(days []time.Time) []time.Time {
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(days, func(a, b time.Time) bool { return a.Equal(b) })
+}
+
+// truncate ends the rule just before the occurrence at split. Occurrences
+// from split on are left to a new series, whose rule is returned as well;
+// it keeps the remaining count when the rule has one.
+func (r recurrenceRule) truncate(start, split time.Time) (recurrenceRule, recurrenceRule) {
+	before, after := r, r
+	before.count = 0
+	before.until = split.Add(-time.Second).UTC()
+	before.untilDate = false
+	if r.count > 0 {
+		n := 0
+		r.each(start, func(t time.Time) bool {
+			if !t.Before(split) {
+				return false
+			}
+			n++
+			return true
+		})
+		after.count = max(r.count-n, 1)
+	}
+	return before, after
+}
+
+// last returns the start of the rule's last occurrence, or false when the
+// rule has no end
+func (r recurrenceRule) last(start time.Time) (time.Time, bool) {
+	if r.count == 0 && r.until.IsZero() {
+		return time.Time{}, false
+	}
+	last := start
+	r.each(start, func(t time.Time) bool {
+		last = t
+		return true
+	})
+	return last, true
+}
+
+// isOccurrence reports whether the rule has an occurrence starting at t
+func (r recurrenceRule) isOccurrence(start, t time.Time) bool {
+	found := false
+	r.each(start, func(occurrence time.Time) bool {
+		if occurrence.Equal(t) {
+			found = true
+		}
+		return occurrence.Before(t)
+	})
+	return found
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("could not load %s: %v", name, err)
+	}
+	return loc
+}
+
+// occurrences returns up to n occurrences of the rule from start, formatted
+// in start's location
+func occurrences(t *testing.T, rule string, start time.Time, n int) []string {
+	t.Helper()
+	r, err := parseRecurrenceRule(rule)
+	if err != nil {
+		t.Fatalf("parseRecurrenceRule(%q): %v", rule, err)
+	}
+	var out []string
+	r.each(start, func(o time.Time) bool {
+		out = append(out, o.Format(time.RFC3339))
+		return len(out) < n
+	})
+	return out
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string // normalized rule, or "" when the rule is invalid
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=mo,we;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3", "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3"},
+		{"FREQ=WEEKLY;WKST=SU;UNTIL=20251231", "FREQ=WEEKLY;WKST=SU;UNTIL=20251231"},
+		{"FREQ=DAILY;UNTIL=20251231T235959Z", "FREQ=DAILY;UNTIL=20251231T235959Z"},
+		{"FREQ=DAILY;COUNT=10000", "FREQ=DAILY;COUNT=10000"},
+		{"", ""},
+		{"FREQ=YEARLY", ""},
+		{"FREQ=DAILY;COUNT=0", ""},
+		{"FREQ=DAILY;COUNT=10001", ""},
+		{"FREQ=DAILY;COUNT=2000000000", ""},
+		{"FREQ=DAILY;INTERVAL=0", ""},
+		{"FREQ=DAILY;BYDAY=MO", ""},
+		{"FREQ=WEEKLY;BYDAY=1MO", ""},
+		{"FREQ=WEEKLY;BYMONTHDAY=1", ""},
+		{"FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1", ""},
+		{"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+		{"FREQ=MONTHLY;BYDAY=6MO", ""},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20251231", ""},
+		{"FREQ=DAILY;BYHOUR=9", ""},
+		{"FREQ", ""},
+	}
+	for _, tt := range tests {
+		r, err := parseRecurrenceRule(tt.rule)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("parseRecurrenceRule(%q) = %q, want an error", tt.rule, r)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRecurrenceRule(%q): %v", tt.rule, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("parseRecurrenceRule(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleEach(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		// all checks that the rule ends after want
+		all  bool
+		want []string
+	}{
+		{
+			name:  "daily keeps the wall clock across DST",
+			rule:  "FREQ=DAILY",
+			start: time.Date(2025, time.March, 29, 9, 30, 0, 0, paris),
+			want:  []string{"2025-03-29T09:30:00+01:00", "2025-03-30T09:30:00+02:00", "2025-03-31T09:30:00+02:00"},
+		},
+		{
+			name:  "weekly on weekdays",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			start: time.Date(2025, time.November, 17, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2025-11-17T09:00:00Z", "2025-11-19T09:00:00Z", "2025-11-21T09:00:00Z", "2025-11-24T09:00:00Z"},
+		},
+		{
+			name:  "weekly skips weekdays before the start",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR",
+			start: time.Date(2025, time.November, 19, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2025-11-21T09:00:00Z", "2025-11-24T09:00:00Z"},
+		},
+		{
+			name:  "every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			start: time.Date(2025, time.November, 17, 9, 0, 0, 0, time.UTC),
+			want:  []string{"2025-11-17T09:00:00Z", "2025-12-01T09:00:00Z", "2025-12-15T09:00:00Z"},
+		},
+		{
+			name:  "last Friday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: time.Date(2025, time.January, 31, 15, 0, 0, 0, time.UTC),
+			want:  []string{"2025-01-31T15:00:00Z", "2025-02-28T15:00:00Z", "2025-03-28T15:00:00Z", "2025-04-25T15:00:00Z"},
+		},
+		{
+			name:  "second Tuesday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: time.Date(2025, time.January, 1, 10, 0, 0, 0, time.UTC),
+			want:  []string{"2025-01-14T10:00:00Z", "2025-02-11T10:00:00Z", "2025-03-11T10:00:00Z"},
+		},
+		{
+			name:  "the 31st skips shorter months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: time.Date(2025, time.January, 31, 8, 0, 0, 0, time.UTC),
+			want:  []string{"2025-01-31T08:00:00Z", "2025-03-31T08:00:00Z", "2025-05-31T08:00:00Z", "2025-07-31T08:00:00Z"},
+		},
+		{
+			name:  "last day of the month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			start: time.Date(2024, time.January, 31, 8, 0, 0, 0, time.UTC),
+			want:  []string{"2024-01-31T08:00:00Z", "2024-02-29T08:00:00Z", "2024-03-31T08:00:00Z"},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: time.Date(2025, time.November, 17, 9, 0, 0, 0, time.UTC),
+			all:   true,
+			want:  []string{"2025-11-17T09:00:00Z", "2025-11-18T09:00:00Z"},
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20251118T090000Z",
+			start: time.Date(2025, time.November, 17, 9, 0, 0, 0, time.UTC),
+			all:   true,
+			want:  []string{"2025-11-17T09:00:00Z", "2025-11-18T09:00:00Z"},
+		},
+		{
+			name:  "until date covers the whole day",
+			rule:  "FREQ=DAILY;UNTIL=20251118",
+			start: time.Date(2025, time.November, 17, 23, 0, 0, 0, paris),
+			all:   true,
+			want:  []string{"2025-11-17T23:00:00+01:00", "2025-11-18T23:00:00+01:00"},
+		},
+		{
+			name:  "never on February 30th",
+			rule:  "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30",
+			start: time.Date(2025, time.February, 1, 8, 0, 0, 0, time.UTC),
+			all:   true,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := len(tt.want)
+			if tt.all {
+				n++
+			}
+			got := occurrences(t, tt.rule, tt.start, n)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRuleHorizon(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	r, err := parseRecurrenceRule("FREQ=DAILY;UNTIL=99991231T000000Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last, ok := r.last(start)
+	if !ok {
+		t.Fatal("last() = false, want the last occurrence")
+	}
+	if want := start.AddDate(maxRecurrenceYears, 0, 0); !last.Equal(want) {
+		t.Errorf("last() = %v, want %v", last, want)
+	}
+
+	// Rules without an end stop at the horizon as well
+	r, _ = parseRecurrenceRule("FREQ=DAILY")
+	n := 0
+	r.each(start, func(time.Time) bool {
+		n++
+		return true
+	})
+	if days := int(start.AddDate(maxRecurrenceYears, 0, 0).Sub(start).Hours()/24) + 1; n != days {
+		t.Errorf("each() produced %d occurrences, want %d", n, days)
+	}
+}
+
+func TestRecurrenceRuleLast(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	tests := []struct {
+		rule  string
+		start time.Time
+		want  string // "" when the rule has no end
+	}{
+		{"FREQ=DAILY", time.Date(2025, time.March, 1, 9, 0, 0, 0, paris), ""},
+		{"FREQ=DAILY;COUNT=31", time.Date(2025, time.March, 1, 9, 0, 0, 0, paris), "2025-03-31T09:00:00+02:00"},
+		{"FREQ=WEEKLY;BYDAY=TU,TH;UNTIL=20251120", time.Date(2025, time.November, 4, 18, 0, 0, 0, time.UTC), "2025-11-20T18:00:00Z"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", time.Date(2025, time.January, 31, 15, 0, 0, 0, time.UTC), "2025-03-28T15:00:00Z"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31;COUNT=2", time.Date(2025, time.January, 31, 8, 0, 0, 0, time.UTC), "2025-03-31T08:00:00Z"},
+	}
+	for _, tt := range tests {
+		r, err := parseRecurrenceRule(tt.rule)
+		if err != nil {
+			t.Fatalf("parseRecurrenceRule(%q): %v", tt.rule, err)
+		}
+		last, ok := r.last(tt.start)
+		switch {
+		case tt.want == "" && ok:
+			t.Errorf("%s: last() = %v, want no end", tt.rule, last)
+		case tt.want != "" && (!ok || last.Format(time.RFC3339) != tt.want):
+			t.Errorf("%s: last() = %v, %v, want %s", tt.rule, last, ok, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleTruncate(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	start := time.Date(2025, time.March, 27, 9, 30, 0, 0, paris)
+	split := time.Date(2025, time.March, 31, 9, 30, 0, 0, paris)
+	tests := []struct {
+		rule              string
+		before, after     string
+		beforeOccurrences []string
+	}{
+		{
+			rule:   "FREQ=DAILY;COUNT=10",
+			before: "FREQ=DAILY;UNTIL=20250331T072959Z",
+			after:  "FREQ=DAILY;COUNT=6",
+			beforeOccurrences: []string{
+				"2025-03-27T09:30:00+01:00", "2025-03-28T09:30:00+01:00",
+				"2025-03-29T09:30:00+01:00", "2025-03-30T09:30:00+02:00",
+			},
+		},
+		{
+			rule:   "FREQ=DAILY;UNTIL=20250405",
+			before: "FREQ=DAILY;UNTIL=20250331T072959Z",
+			after:  "FREQ=DAILY;UNTIL=20250405",
+			beforeOccurrences: []string{
+				"2025-03-27T09:30:00+01:00", "2025-03-28T09:30:00+01:00",
+				"2025-03-29T09:30:00+01:00", "2025-03-30T09:30:00+02:00",
+			},
+		},
+		{
+			rule:              "FREQ=WEEKLY;BYDAY=MO,TH",
+			before:            "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20250331T072959Z",
+			after:             "FREQ=WEEKLY;BYDAY=MO,TH",
+			beforeOccurrences: []string{"2025-03-27T09:30:00+01:00"},
+		},
+	}
+	for _, tt := range tests {
+		r, err := parseRecurrenceRule(tt.rule)
+		if err != nil {
+			t.Fatalf("parseRecurrenceRule(%q): %v", tt.rule, err)
+		}
+		before, after := r.truncate(start, split)
+		if before.String() != tt.before || after.String() != tt.after {
+			t.Errorf("%s: truncate() = %q, %q, want %q, %q", tt.rule, before, after, tt.before, tt.after)
+		}
+		got := occurrences(t, before.String(), start, 100)
+		if strings.Join(got, " ") != strings.Join(tt.beforeOccurrences, " ") {
+			t.Errorf("%s: occurrences before the split = %v, want %v", tt.rule, got, tt.beforeOccurrences)
+		}
+		if !after.isOccurrence(split, split) {
+			t.Errorf("%s: the new series does not start at the split", tt.rule)
+		}
+	}
+}
+
+func TestCheckEventScope(t *testing.T) {
+	series := models.Event{Rrule: pgtype.Text{String: "FREQ=DAILY", Valid: true}}
+	override := models.Event{RecurringEventID: pgtype.UUID{Valid: true}}
+	tests := []struct {
+		event models.Event
+		scope string
+		want  string
+	}{
+		{series, "", EventScopeAll},
+		{override, "", EventScopeThis},
+		{override, EventScopeAll, EventScopeAll},
+		{series, EventScopeFollowing, EventScopeFollowing},
+		{models.Event{}, EventScopeThis, EventScopeThis},
+	}
+	for _, tt := range tests {
+		got, err := checkEventScope(tt.event, EventScope{Scope: tt.scope})
+		if err != nil || got.Scope != tt.want {
+			t.Errorf("checkEventScope(%q) got %q, %v want %q", tt.scope, got.Scope, err, tt.want)
+		}
+	}
+	if _, err := checkEventScope(series, EventScope{Scope: "some"}); err == nil {
+		t.Errorf("checkEventScope(%q) got no error", "some")
+	}
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// icalProductID identifies the app in the calendars it writes
+	icalProductID      = "-//Motion//Workspace Calendar//EN"
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+	icalMaxLineOctets  = 75
+	// icalMaxLineLength bounds a line read, before unfolding
+	icalMaxLineLength = 1 << 20
+)
+
+var (
+	icalDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+	icalTextEscaper     = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	icalTextUnescaper   = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// icalWriter writes iCalendar (RFC 5545) content lines
+type icalWriter struct {
+	b strings.Builder
+}
+
+// line writes a content line, folded at 75 octets without splitting UTF-8
+// characters. name may carry parameters, as in "DTSTART;VALUE=DATE".
+func (w *icalWriter) line(name, value string) {
+	n := 0
+	for _, r := range name + ":" + value {
+		size := utf8.RuneLen(r)
+		if n+size > icalMaxLineOctets {
+			w.b.WriteString("\r\n ")
+			n = 1
+		}
+		w.b.WriteRune(r)
+		n += size
+	}
+	w.b.WriteString("\r\n")
+}
+
+// text writes a TEXT property, escaping its value
+func (w *icalWriter) text(name, value string) {
+	w.line(name, icalTextEscaper.Replace(value))
+}
+
+// utc writes a date-time property in UTC, such as DTSTAMP
+func (w *icalWriter) utc(name string, t time.Time) {
+	w.line(name, t.UTC().Format(icalDateTimeLayout)+"Z")
+}
+
+// times writes a date or date-time property with one or more values, all
+// in the location of the first. Date-times outside UTC refer to the
+// VTIMEZONE of their location.
+func (w *icalWriter) times(name string, allDay bool, values ...time.Time) {
+	if len(values) == 0 {
+		return
+	}
+	loc := values[0].Location()
+	layout, suffix := icalDateTimeLayout, ""
+	switch {
+	case allDay:
+		name += ";VALUE=DATE"
+		layout = icalDateLayout
+	case loc == time.UTC:
+		suffix = "Z"
+	default:
+		name += ";TZID=" + loc.String()
+	}
+	formatted := make([]string, len(values))
+	for i, t := range values {
+		formatted[i] = t.In(loc).Format(layout) + suffix
+	}
+	w.line(name, strings.Join(formatted, ","))
+}
+
+// timezone writes a VTIMEZONE for loc. The offset changes loc goes through
+// this year are given as yearly rules, the way calendar apps write them;
+// past rule changes are left to the apps' own zone data.
+func (w *icalWriter) timezone(loc *time.Location, now time.Time) {
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+
+	year := now.In(loc).Year()
+	t := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	observances := 0
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.Year() > year {
+			break
+		}
+		_, from := t.Zone()
+		name, to := end.Zone()
+		// Observances start at the local time, before the change, of the
+		// same weekday of the month in 1970
+		local := end.UTC().Add(time.Duration(from) * time.Second)
+		n := (local.Day()-1)/7 + 1
+		if local.Day()+7 > time.Date(local.Year(), local.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+			n = -1
+		}
+		start := time.Date(1970, local.Month(), nthWeekday(1970, local.Month(), local.Weekday(), n),
+			local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+
+		kind := "STANDARD"
+		if end.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		w.line("BEGIN", kind)
+		w.line("DTSTART", start.Format(icalDateTimeLayout))
+		w.line("RRULE", fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", local.Month(), n, rruleWeekdays[local.Weekday()]))
+		w.line("TZOFFSETFROM", icalOffset(from))
+		w.line("TZOFFSETTO", icalOffset(to))
+		w.text("TZNAME", name)
+		w.line("END", kind)
+		observances++
+		t = end
+	}
+
+	if observances == 0 {
+		name, offset := t.Zone()
+		w.line("BEGIN", "STANDARD")
+		w.line("DTSTART", "19700101T000000")
+		w.line("TZOFFSETFROM", icalOffset(offset))
+		w.line("TZOFFSETTO", icalOffset(offset))
+		w.text("TZNAME", name)
+		w.line("END", "STANDARD")
+	}
+
+	w.line("END", "VTIMEZONE")
+}
+
+func (w *icalWriter) String() string {
+	return w.b.String()
+}
+
+// icalOffset formats a UTC offset in seconds, such as +0100
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		offset += fmt.Sprintf("%02d", seconds%60)
+	}
+	return offset
+}
+
+// icalProperty is a content line of an iCalendar document. Parameter names
+// are upper case, and quotes are dropped from their values.
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// text returns the value of a TEXT property, unescaped
+func (p icalProperty) text() string {
+	return icalTextUnescaper.Replace(p.value)
+}
+
+// icalComponent is a BEGIN/END block of an iCalendar document, such as a
+// VEVENT
+type icalComponent struct {
+	name       string
+	props      []icalProperty
+	components []*icalComponent
+}
+
+// prop returns the first property with the name
+func (c *icalComponent) prop(name string) (icalProperty, bool) {
+	for _, p := range c.props {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return icalProperty{}, false
+}
+
+// propText returns the unescaped value of the first property with the name
+func (c *icalComponent) propText(name string) string {
+	p, _ := c.prop(name)
+	return p.text()
+}
+
+// propsNamed returns every property with the name
+func (c *icalComponent) propsNamed(name string) []icalProperty {
+	var props []icalProperty
+	for _, p := range c.props {
+		if p.name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// parseICal parses an iCalendar document into its top-level components,
+// usually a single VCALENDAR
+func parseICal(r io.Reader) ([]*icalComponent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), icalMaxLineLength)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			// A folded line continues the previous one
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var roots, stack []*icalComponent
+	for _, line := range lines {
+		p, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch p.name {
+		case "BEGIN":
+			c := &icalComponent{name: strings.ToUpper(p.value)}
+			if len(stack) == 0 {
+				roots = append(roots, c)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.components = append(parent.components, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.ToUpper(p.value) {
+				return nil, fmt.Errorf("unexpected END:%s", p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("property %s outside a component", p.name)
+			}
+			c := stack[len(stack)-1]
+			c.props = append(c.props, p)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated %s", stack[len(stack)-1].name)
+	}
+	return roots, nil
+}
+
+// parseICalLine parses an unfolded content line, name;param=value:value
+func parseICalLine(line string) (icalProperty, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return icalProperty{}, fmt.Errorf("malformed line %q", line)
+	}
+	p := icalProperty{name: strings.ToUpper(line[:i])}
+	rest := line[i:]
+	for rest[0] == ';' {
+		rest = rest[1:]
+		// Quoted parameter values may hold ; and :
+		quoted := false
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			if r == '"' {
+				quoted = !quoted
+			}
+			return !quoted && (r == ';' || r == ':')
+		})
+		if end < 0 {
+			return icalProperty{}, fmt.Errorf("malformed line %q", line)
+		}
+		name, value, ok := strings.Cut(rest[:end], "=")
+		if !ok || name == "" {
+			return icalProperty{}, fmt.Errorf("malformed parameter in %q", line)
+		}
+		if p.params == nil {
+			p.params = make(map[string]string)
+		}
+		p.params[strings.ToUpper(name)] = strings.ReplaceAll(value, `"`, "")
+		rest = rest[end:]
+	}
+	p.value = rest[1:]
+	return p, nil
+}
+
+// parseICalDuration parses a DURATION value such as PT1H30M or P1D
+func parseICalDuration(value string) (time.Duration, error) {
+	m := icalDurationPattern.FindStringSubmatch(value)
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, errors.New("malformed duration")
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, errors.New("malformed duration")
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICalLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		name   string
+		params map[string]string
+		value  string
+	}{
+		{"SUMMARY:Standup", "SUMMARY", nil, "Standup"},
+		{"summary:Standup", "SUMMARY", nil, "Standup"},
+		{"DESCRIPTION:a: b; c", "DESCRIPTION", nil, "a: b; c"},
+		{"SUMMARY:", "SUMMARY", nil, ""},
+		{"DTSTART;TZID=Europe/Paris:20240101T090000", "DTSTART", map[string]string{"TZID": "Europe/Paris"}, "20240101T090000"},
+		{"DTSTART;value=DATE:20240101", "DTSTART", map[string]string{"VALUE": "DATE"}, "20240101"},
+		{
+			`ATTENDEE;CN="Doe; Jane: PhD";PARTSTAT=ACCEPTED:mailto:jane@example.com`,
+			"ATTENDEE", map[string]string{"CN": "Doe; Jane: PhD", "PARTSTAT": "ACCEPTED"}, "mailto:jane@example.com",
+		},
+		{`DTSTART;TZID="America/New_York":20240101T090000`, "DTSTART", map[string]string{"TZID": "America/New_York"}, "20240101T090000"},
+		{`X-TEST;A="";B=x:y`, "X-TEST", map[string]string{"A": "", "B": "x"}, "y"},
+	}
+	for _, tt := range tests {
+		p, err := parseICalLine(tt.line)
+		if err != nil {
+			t.Errorf("parseICalLine(%q) = %v", tt.line, err)
+			continue
+		}
+		if p.name != tt.name || p.value != tt.value || fmt.Sprint(p.params) != fmt.Sprint(tt.params) {
+			t.Errorf("parseICalLine(%q) got %s %v %q want %s %v %q", tt.line, p.name, p.params, p.value, tt.name, tt.params, tt.value)
+		}
+	}
+
+	for _, line := range []string{
+		"SUMMARY",
+		":Standup",
+		";TZID=UTC:20240101",
+		"DTSTART;TZID=UTC",
+		"DTSTART;TZID:20240101",
+		"DTSTART;=UTC:20240101",
+		`ATTENDEE;CN="Jane:mailto:jane@example.com`,
+	} {
+		if _, err := parseICalLine(line); err == nil {
+			t.Errorf("parseICalLine(%q) got no error", line)
+		}
+	}
+}
+
+func TestParseICal(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:1",
+		"SUMMARY:A long",
+		"  folded summary",
+		"DESCRIPTION:Tab",
+		"\tfolded",
+		"ATTENDEE;CN=\"Doe, ",
+		" Jane\":mailto:jane@example.com",
+		"",
+		"END:VEVENT",
+		"begin:vevent",
+		"UID:2",
+		"end:vevent",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+
+	roots, err := parseICal(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatalf("parseICal() = %v", err)
+	}
+	if len(roots) != 1 || roots[0].name != "VCALENDAR" || len(roots[0].components) != 2 {
+		t.Fatalf("got %d roots want one VCALENDAR with two events", len(roots))
+	}
+	if got := roots[0].propText("VERSION"); got != "2.0" {
+		t.Errorf("VERSION got %q want %q", got, "2.0")
+	}
+
+	event := roots[0].components[0]
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"UID", "1"},
+		{"SUMMARY", "A long folded summary"},
+		{"DESCRIPTION", "Tabfolded"},
+		{"ATTENDEE", "mailto:jane@example.com"},
+	}
+	for _, tt := range tests {
+		if got := event.propText(tt.name); got != tt.want {
+			t.Errorf("%s got %q want %q", tt.name, got, tt.want)
+		}
+	}
+	if p, _ := event.prop("ATTENDEE"); p.params["CN"] != "Doe, Jane" {
+		t.Errorf("ATTENDEE CN got %q want %q", p.params["CN"], "Doe, Jane")
+	}
+	if second := roots[0].components[1]; second.name != "VEVENT" || second.propText("UID") != "2" {
+		t.Errorf("got second component %s %q want VEVENT 2", second.name, second.propText("UID"))
+	}
+}
+
+func TestParseICalMalformed(t *testing.T) {
+	tests := []string{
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VEVENT\n",
+		"END:VCALENDAR\n",
+		"SUMMARY:outside\n",
+		"BEGIN:VCALENDAR\nnot a property\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nX-LONG:" + strings.Repeat("a", icalMaxLineLength) + "\nEND:VCALENDAR\n",
+	}
+	for _, calendar := range tests {
+		if _, err := parseICal(strings.NewReader(calendar)); err == nil {
+			t.Errorf("parseICal(%.40q) got no error", calendar)
+		}
+	}
+}
+
+func TestICalPropertyText(t *testing.T) {
+	p := icalProperty{value: `Line one\nLine two\, with\; escapes\\`}
+	if got, want := p.text(), "Line one\nLine two, with; escapes\\"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestParseICalDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"PT1H30M", 90 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"P1W", 7 * 24 * time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+		{"PT45S", 45 * time.Second},
+		{"-PT15M", -15 * time.Minute},
+		{"+PT15M", 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseICalDuration(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("parseICalDuration(%q) got %v, %v want %v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "P", "PT", "P1H", "1H", "PT1.5H"} {
+		if _, err := parseICalDuration(value); err == nil {
+			t.Errorf("parseICalDuration(%q) got no error", value)
+		}
+	}
+}
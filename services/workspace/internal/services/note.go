@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -17,14 +18,54 @@ var (
 	ErrNoteNotFound      = errors.New("note not found")
 	ErrInvalidNoteData   = errors.New("invalid note data")
 	ErrMissingNoteFields = errors.New("missing required note fields")
+	ErrNoteConflict      = errors.New("note was modified by someone else")
 )
 
+// NoteConflictError is returned when a write's expected version does not
+// match the stored note. It carries the current server state.
+type NoteConflictError struct {
+	Current models.Note
+}
+
+func (e *NoteConflictError) Error() string {
+	return ErrNoteConflict.Error()
+}
+
+func (e *NoteConflictError) Is(target error) bool {
+	return target == ErrNoteConflict
+}
+
 type NoteServicer interface {
 	CreateNote(ctx context.Context, input CreateNoteInput) (models.Note, error)
-	GetNote(ctx context.Context, workspaceID, noteID string) (models.Note, error)
-	ListNotes(ctx context.Context, workspaceID string) ([]models.Note, error)
+	GetNote(ctx context.Context, workspaceID, noteID, viewerID string) (models.Note, error)
+	ListNotes(ctx context.Context, workspaceID, viewerID string, filter NoteTagFilter) ([]models.Note, error)
+	SearchNotes(ctx context.Context, workspaceID string, input NoteSearchInput) ([]models.SearchWorkspaceNotesRow, error)
 	UpdateNote(ctx context.Context, workspaceID, noteID string, input UpdateNoteInput) (models.Note, error)
-	DeleteNote(ctx context.Context, workspaceID, noteID string) error
+	DeleteNote(ctx context.Context, workspaceID, noteID string, input DeleteNoteInput) error
+	GetNoteAccess(ctx context.Context, workspaceID, noteID, viewerID string) (NoteAccess, error)
+	UpdateNoteAccess(ctx context.Context, workspaceID, noteID, userID string, input UpdateNoteAccessInput) (NoteAccess, error)
+	ListNoteTree(ctx context.Context, workspaceID, viewerID string) ([]*NoteTreeNode, error)
+	ListNoteChildren(ctx context.Context, workspaceID, parentID, viewerID string) ([]models.ListNoteChildrenRow, error)
+	GetBreadcrumbs(ctx context.Context, workspaceID, noteID, viewerID string) ([]models.ListNoteAncestorsRow, error)
+	MoveNote(ctx context.Context, workspaceID, noteID string, input MoveNoteInput) (models.Note, error)
+	ReorderNotes(ctx context.Context, workspaceID, parentID, editorID string, noteIDs []string) ([]models.ListNoteChildrenRow, error)
+	ListBacklinks(ctx context.Context, workspaceID, noteID, viewerID string) ([]NoteBacklink, error)
+	ListBrokenLinks(ctx context.Context, workspaceID, viewerID string) ([]models.ListBrokenNoteLinksRow, error)
+	GetNoteGraph(ctx context.Context, workspaceID, viewerID string) (NoteGraph, error)
+	RenderNote(ctx context.Context, workspaceID, noteID, viewerID string) (RenderedNote, error)
+	ExportNotes(ctx context.Context, workspaceID, viewerID string, w io.Writer) error
+	ImportNotes(ctx context.Context, workspaceID, authorID string, archive io.ReaderAt, size int64) (NoteImportReport, error)
+	ListTags(ctx context.Context, workspaceID, viewerID string) ([]models.ListWorkspaceTagsRow, error)
+	RenameTag(ctx context.Context, workspaceID, from, to, editorID string) (TagChangeResult, error)
+	MergeTags(ctx context.Context, workspaceID string, tags []string, into, editorID string) (TagChangeResult, error)
+	ListRevisions(ctx context.Context, workspaceID, noteID, viewerID string) ([]models.ListNoteRevisionsRow, error)
+	GetRevision(ctx context.Context, workspaceID, noteID, viewerID string, revision int32) (models.NoteRevision, error)
+	DiffRevisions(ctx context.Context, workspaceID, noteID, viewerID string, from, to int32) (NoteRevisionDiff, error)
+	RestoreRevision(ctx context.Context, workspaceID, noteID string, revision int32, editorID string) (models.Note, error)
+	ListCommentThreads(ctx context.Context, workspaceID, noteID, viewerID string, resolved *bool) ([]NoteCommentThread, error)
+	CreateCommentThread(ctx context.Context, workspaceID, noteID string, input CreateNoteCommentInput) (NoteCommentThread, error)
+	ReplyToCommentThread(ctx context.Context, workspaceID, noteID, threadID, authorID, body string) (models.NoteComment, error)
+	ResolveCommentThread(ctx context.Context, workspaceID, noteID, threadID, userID string, resolved bool) (NoteCommentThread, error)
 }
 
 type CreateNoteInput struct {
@@ -33,22 +74,45 @@ type CreateNoteInput struct {
 	Title       string
 	Content     string
 	Tags        []string
+	// ParentID nests the new note under another; it is added last
+	ParentID string
+	// Visibility defaults to NoteVisibilityWorkspace
+	Visibility string
+	// ExtractTasks turns unchecked checklist items into linked tasks
+	ExtractTasks bool
+}
+
+// NoteTagFilter restricts notes to those carrying all of Tags, or any of
+// them with MatchAny set. Tags match regardless of case.
+type NoteTagFilter struct {
+	Tags     []string
+	MatchAny bool
 }
 
 type UpdateNoteInput struct {
-	Title   *string
-	Content *string
-	Tags    *[]string
+	// EditorID must be allowed to edit the note, and is recorded as the
+	// author of the resulting revision
+	EditorID string
+	// ExpectedVersion, when set, makes the update conditional on the
+	// note still being at that version
+	ExpectedVersion *int32
+	Title           *string
+	Content         *string
+	Tags            *[]string
+	// ExtractTasks turns unchecked checklist items that aren't linked to a
+	// task yet into linked tasks
+	ExtractTasks bool
 }
 
 type NoteService struct {
-	s *store.Store
+	s      *store.Store
+	events AutomationEmitter
 }
 
 var _ NoteServicer = (*NoteService)(nil)
 
-func NewNoteService(store *store.Store) *NoteService {
-	return &NoteService{s: store}
+func NewNoteService(store *store.Store, events AutomationEmitter) *NoteService {
+	return &NoteService{s: store, events: events}
 }
 
 func (s *NoteService) CreateNote(ctx context.Context, input CreateNoteInput) (models.Note, error) {
@@ -68,25 +132,90 @@ func (s *NoteService) CreateNote(ctx context.Context, input CreateNoteInput) (mo
 	if input.AuthorID == "" {
 		return models.Note{}, ErrMissingNoteFields
 	}
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = NoteVisibilityWorkspace
+	}
+	if !isNoteVisibility(visibility) {
+		return models.Note{}, ErrInvalidNoteData
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
 
-	note, err := s.s.Queries.CreateNote(ctx, models.CreateNoteParams{
+	parent, err := resolveParentNote(ctx, queries, wsID, input.ParentID, input.AuthorID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	var (
+		tasks []models.Task
+		links []noteTaskLink
+	)
+	if input.ExtractTasks {
+		content, tasks, links, err = extractNoteTasks(ctx, queries, wsID, pgtype.UUID{}, content)
+		if err != nil {
+			return models.Note{}, err
+		}
+	}
+	position, err := queries.NextNotePosition(ctx, models.NextNotePositionParams{
+		WorkspaceID: wsID,
+		ParentID:    parent,
+	})
+	if err != nil {
+		return models.Note{}, fmt.Errorf("failed to get note position: %w", err)
+	}
+
+	note, err := queries.CreateNote(ctx, models.CreateNoteParams{
 		WorkspaceID: wsID,
 		AuthorID: pgtype.Text{
 			String: input.AuthorID,
 			Valid:  true,
 		},
-		Title:   title,
-		Content: content,
-		Tags:    tags,
+		Title:      title,
+		Content:    content,
+		Tags:       tags,
+		ParentID:   parent,
+		Position:   position,
+		Visibility: visibility,
 	})
 	if err != nil {
 		return models.Note{}, fmt.Errorf("failed to create note: %w", err)
 	}
 
+	if err := recordNoteRevision(ctx, queries, note, input.AuthorID); err != nil {
+		return models.Note{}, err
+	}
+	if err := syncNoteLinks(ctx, queries, note); err != nil {
+		return models.Note{}, err
+	}
+	if err := resolveNoteLinks(ctx, queries, note); err != nil {
+		return models.Note{}, err
+	}
+	if err := linkNoteTasks(ctx, queries, note.ID, links); err != nil {
+		return models.Note{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Note{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.emitTagged(ctx, note, note.Tags)
+	s.emitTasks(ctx, tasks, nil)
+
 	return note, nil
 }
 
-func (s *NoteService) GetNote(ctx context.Context, workspaceID, noteID string) (models.Note, error) {
+// GetNote returns a note the viewer can see
+func (s *NoteService) GetNote(ctx context.Context, workspaceID, noteID, viewerID string) (models.Note, error) {
+	return s.getNoteAs(ctx, workspaceID, noteID, viewerID, false)
+}
+
+// getNote returns a note regardless of its visibility
+func (s *NoteService) getNote(ctx context.Context, workspaceID, noteID string) (models.Note, error) {
 	wsID, err := parseUUID(workspaceID)
 	if err != nil {
 		return models.Note{}, ErrInvalidNoteData
@@ -109,13 +238,25 @@ func (s *NoteService) GetNote(ctx context.Context, workspaceID, noteID string) (
 	return note, nil
 }
 
-func (s *NoteService) ListNotes(ctx context.Context, workspaceID string) ([]models.Note, error) {
+// ListNotes returns the notes the viewer can see
+func (s *NoteService) ListNotes(ctx context.Context, workspaceID, viewerID string, filter NoteTagFilter) ([]models.Note, error) {
 	wsID, err := parseUUID(workspaceID)
 	if err != nil {
 		return nil, ErrInvalidNoteData
 	}
 
-	notes, err := s.s.Queries.ListWorkspaceNotes(ctx, wsID)
+	params := models.ListWorkspaceNotesParams{
+		WorkspaceID: wsID,
+		AllTags:     []string{},
+		AnyTags:     []string{},
+		ViewerID:    viewerID,
+	}
+	if filter.MatchAny {
+		params.AnyTags = tagFilter(filter.Tags)
+	} else {
+		params.AllTags = tagFilter(filter.Tags)
+	}
+	notes, err := s.s.Queries.ListWorkspaceNotes(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list notes: %w", err)
 	}
@@ -126,10 +267,13 @@ func (s *NoteService) ListNotes(ctx context.Context, workspaceID string) ([]mode
 }
 
 func (s *NoteService) UpdateNote(ctx context.Context, workspaceID, noteID string, input UpdateNoteInput) (models.Note, error) {
-	current, err := s.GetNote(ctx, workspaceID, noteID)
+	current, err := s.getNoteAs(ctx, workspaceID, noteID, input.EditorID, true)
 	if err != nil {
 		return models.Note{}, err
 	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != current.Version {
+		return models.Note{}, &NoteConflictError{Current: current}
+	}
 
 	title := current.Title
 	if input.Title != nil {
@@ -148,35 +292,194 @@ func (s *NoteService) UpdateNote(ctx context.Context, workspaceID, noteID string
 		tags = normalizeTags(*input.Tags)
 	}
 
-	updated, err := s.s.Queries.UpdateNote(ctx, models.UpdateNoteParams{
+	renamed := title != current.Title
+	if renamed {
+		// Keep links from the note to itself working
+		content = rewriteWikiLinks(content, current.Title, title)
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	var (
+		created []models.Task
+		links   []noteTaskLink
+	)
+	if input.ExtractTasks {
+		content, created, links, err = extractNoteTasks(ctx, queries, current.WorkspaceID, current.ID, content)
+		if err != nil {
+			return models.Note{}, err
+		}
+	}
+
+	updated, err := queries.UpdateNote(ctx, models.UpdateNoteParams{
 		WorkspaceID: current.WorkspaceID,
 		ID:          current.ID,
 		Title:       title,
 		Content:     content,
 		Tags:        tags,
+		Version:     current.Version,
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Someone else saved between our read and write
+			return models.Note{}, s.conflict(ctx, workspaceID, noteID)
+		}
 		return models.Note{}, fmt.Errorf("failed to update note: %w", err)
 	}
+
+	if err := recordNoteRevision(ctx, queries, updated, input.EditorID); err != nil {
+		return models.Note{}, err
+	}
+	if err := syncNoteLinks(ctx, queries, updated); err != nil {
+		return models.Note{}, err
+	}
+	if err := remapNoteComments(ctx, queries, updated.ID, current.Content, updated.Content); err != nil {
+		return models.Note{}, err
+	}
+	changed, err := syncNoteTaskStatus(ctx, queries, updated.ID, current.Content, updated.Content)
+	if err != nil {
+		return models.Note{}, err
+	}
+	if err := linkNoteTasks(ctx, queries, updated.ID, links); err != nil {
+		return models.Note{}, err
+	}
+	if renamed {
+		if err := renameNoteLinks(ctx, queries, updated, current.Title, input.EditorID); err != nil {
+			return models.Note{}, err
+		}
+		if err := resolveNoteLinks(ctx, queries, updated); err != nil {
+			return models.Note{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Note{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.emitTagged(ctx, updated, addedTags(current.Tags, updated.Tags))
+	s.emitTasks(ctx, created, changed)
+
 	return updated, nil
 }
 
-func (s *NoteService) DeleteNote(ctx context.Context, workspaceID, noteID string) error {
-	note, err := s.GetNote(ctx, workspaceID, noteID)
+func (s *NoteService) DeleteNote(ctx context.Context, workspaceID, noteID string, input DeleteNoteInput) error {
+	note, err := s.getNoteAs(ctx, workspaceID, noteID, input.EditorID, true)
 	if err != nil {
 		return err
 	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != note.Version {
+		return &NoteConflictError{Current: note}
+	}
+
+	policy := input.Children
+	if policy == "" {
+		policy = NoteDeleteReparent
+	}
+	if policy != NoteDeleteReparent && policy != NoteDeleteCascade {
+		return ErrInvalidNoteData
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
 
-	err = s.s.Queries.DeleteNote(ctx, models.DeleteNoteParams{
+	// Cascading is left to the parent_id foreign key
+	if policy == NoteDeleteReparent {
+		position, err := queries.NextNotePosition(ctx, models.NextNotePositionParams{
+			WorkspaceID: note.WorkspaceID,
+			ParentID:    note.ParentID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get note position: %w", err)
+		}
+		if _, err := queries.ReparentNoteChildren(ctx, models.ReparentNoteChildrenParams{
+			NewParentID:  note.ParentID,
+			BasePosition: position,
+			WorkspaceID:  note.WorkspaceID,
+			ParentID:     note.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to reparent note children: %w", err)
+		}
+	}
+
+	// Without an expected version, the note is deleted even if it was
+	// updated in the meantime
+	var version pgtype.Int4
+	if input.ExpectedVersion != nil {
+		version = pgtype.Int4{Int32: *input.ExpectedVersion, Valid: true}
+	}
+	deleted, err := queries.DeleteNote(ctx, models.DeleteNoteParams{
 		WorkspaceID: note.WorkspaceID,
 		ID:          note.ID,
+		Version:     version,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete note: %w", err)
 	}
+	if deleted == 0 {
+		return s.conflict(ctx, workspaceID, noteID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
+// conflict reports a lost race on a conditional write, or not found if the
+// note has since been deleted.
+func (s *NoteService) conflict(ctx context.Context, workspaceID, noteID string) error {
+	current, err := s.getNote(ctx, workspaceID, noteID)
+	if err != nil {
+		return err
+	}
+	return &NoteConflictError{Current: current}
+}
+
+// emitTagged notifies the automation engine about tags newly added to a note.
+func (s *NoteService) emitTagged(ctx context.Context, note models.Note, tags []string) {
+	if s.events == nil || len(tags) == 0 {
+		return
+	}
+	s.events.Emit(ctx, AutomationEvent{
+		Trigger:     TriggerNoteTagged,
+		WorkspaceID: note.WorkspaceID,
+		SubjectID:   note.ID,
+		Tags:        tags,
+	})
+}
+
+// emitTasks notifies the automation engine about tasks created from a note
+// and tasks whose checklist item was ticked or unticked.
+func (s *NoteService) emitTasks(ctx context.Context, created, changed []models.Task) {
+	if s.events == nil {
+		return
+	}
+	for _, task := range created {
+		s.events.Emit(ctx, AutomationEvent{
+			Trigger:     TriggerTaskCreated,
+			WorkspaceID: task.WorkspaceID,
+			SubjectID:   task.ID,
+		})
+	}
+	for _, task := range changed {
+		s.events.Emit(ctx, AutomationEvent{
+			Trigger:     TriggerTaskStatusChanged,
+			WorkspaceID: task.WorkspaceID,
+			SubjectID:   task.ID,
+			Status:      task.Status,
+		})
+	}
+}
+
 func parseUUID(id string) (pgtype.UUID, error) {
 	var out pgtype.UUID
 	if err := out.Scan(id); err != nil {
@@ -205,3 +508,29 @@ func normalizeTags(tags []string) []string {
 	}
 	return result
 }
+
+// tagFilter normalizes tags to filter notes by. Filters match tags
+// case-insensitively, the way ListTags groups them.
+func tagFilter(tags []string) []string {
+	tags = normalizeTags(tags)
+	for i, tag := range tags {
+		tags[i] = strings.ToLower(tag)
+	}
+	return tags
+}
+
+// addedTags returns the tags in next that weren't in prev, compared
+// case-insensitively like normalizeTags.
+func addedTags(prev, next []string) []string {
+	existing := make(map[string]struct{}, len(prev))
+	for _, tag := range prev {
+		existing[strings.ToLower(tag)] = struct{}{}
+	}
+	var added []string
+	for _, tag := range next {
+		if _, ok := existing[strings.ToLower(tag)]; !ok {
+			added = append(added, tag)
+		}
+	}
+	return added
+}
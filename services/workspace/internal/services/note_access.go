@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	ErrNoteForbidden     = errors.New("not allowed to change this note")
+	ErrInvalidNoteMember = errors.New("note members must belong to the workspace")
+)
+
+// Who can see a note
+const (
+	// NoteVisibilityWorkspace notes are visible to and editable by every
+	// workspace member
+	NoteVisibilityWorkspace = "workspace"
+	// NoteVisibilityPrivate notes are only visible to their author
+	NoteVisibilityPrivate = "private"
+	// NoteVisibilityRestricted notes are visible to their author and the
+	// members they are shared with
+	NoteVisibilityRestricted = "restricted"
+)
+
+// What a user may do with a note
+const (
+	NoteRoleViewer = "viewer"
+	NoteRoleEditor = "editor"
+	// NoteRoleOwner is the note's author, who also controls its visibility
+	NoteRoleOwner = "owner"
+)
+
+type NoteMemberInput struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type UpdateNoteAccessInput struct {
+	Visibility string
+	// Members replaces the note's members. They only apply to restricted
+	// notes, but are kept when switching visibility.
+	Members []NoteMemberInput
+}
+
+// NoteAccess describes who can see a note, and what the requesting user
+// may do with it
+type NoteAccess struct {
+	Visibility string                      `json:"visibility"`
+	Role       string                      `json:"role"`
+	Members    []models.ListNoteMembersRow `json:"members"`
+}
+
+func (s *NoteService) GetNoteAccess(ctx context.Context, workspaceID, noteID, viewerID string) (NoteAccess, error) {
+	note, err := s.getNote(ctx, workspaceID, noteID)
+	if err != nil {
+		return NoteAccess{}, err
+	}
+	role, err := noteRole(ctx, s.s.Queries, note, viewerID)
+	if err != nil {
+		return NoteAccess{}, err
+	}
+	if role == "" {
+		return NoteAccess{}, ErrNoteNotFound
+	}
+	return noteAccess(ctx, s.s.Queries, note, role)
+}
+
+// UpdateNoteAccess sets a note's visibility and members. Only the note's
+// author may do this.
+func (s *NoteService) UpdateNoteAccess(ctx context.Context, workspaceID, noteID, userID string, input UpdateNoteAccessInput) (NoteAccess, error) {
+	if !isNoteVisibility(input.Visibility) {
+		return NoteAccess{}, ErrInvalidNoteData
+	}
+	userIDs := make([]string, 0, len(input.Members))
+	for _, member := range input.Members {
+		if member.UserID == "" || (member.Role != NoteRoleViewer && member.Role != NoteRoleEditor) {
+			return NoteAccess{}, ErrInvalidNoteData
+		}
+		if slices.Contains(userIDs, member.UserID) {
+			return NoteAccess{}, ErrInvalidNoteData
+		}
+		userIDs = append(userIDs, member.UserID)
+	}
+
+	note, err := s.getNote(ctx, workspaceID, noteID)
+	if err != nil {
+		return NoteAccess{}, err
+	}
+	role, err := noteRole(ctx, s.s.Queries, note, userID)
+	if err != nil {
+		return NoteAccess{}, err
+	}
+	switch role {
+	case "":
+		return NoteAccess{}, ErrNoteNotFound
+	case NoteRoleOwner:
+	default:
+		return NoteAccess{}, ErrNoteForbidden
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return NoteAccess{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	if len(userIDs) > 0 {
+		count, err := queries.CountWorkspaceMembers(ctx, models.CountWorkspaceMembersParams{
+			WorkspaceID: note.WorkspaceID,
+			UserIds:     userIDs,
+		})
+		if err != nil {
+			return NoteAccess{}, fmt.Errorf("failed to check workspace members: %w", err)
+		}
+		if count != int64(len(userIDs)) {
+			return NoteAccess{}, ErrInvalidNoteMember
+		}
+	}
+
+	if err := queries.SetNoteVisibility(ctx, models.SetNoteVisibilityParams{
+		WorkspaceID: note.WorkspaceID,
+		ID:          note.ID,
+		Visibility:  input.Visibility,
+	}); err != nil {
+		return NoteAccess{}, fmt.Errorf("failed to set note visibility: %w", err)
+	}
+	if err := queries.DeleteNoteMembers(ctx, note.ID); err != nil {
+		return NoteAccess{}, fmt.Errorf("failed to clear note members: %w", err)
+	}
+	for _, member := range input.Members {
+		// The author always owns the note
+		if member.UserID == userID {
+			continue
+		}
+		if err := queries.CreateNoteMember(ctx, models.CreateNoteMemberParams{
+			NoteID: note.ID,
+			UserID: member.UserID,
+			Role:   member.Role,
+		}); err != nil {
+			return NoteAccess{}, fmt.Errorf("failed to add note member: %w", err)
+		}
+	}
+
+	note.Visibility = input.Visibility
+	access, err := noteAccess(ctx, queries, note, role)
+	if err != nil {
+		return NoteAccess{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return NoteAccess{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return access, nil
+}
+
+// getNoteAs returns a note the user can see, failing with ErrNoteNotFound
+// otherwise so that hidden notes are indistinguishable from missing ones.
+// With write set, the user must also be allowed to edit it.
+func (s *NoteService) getNoteAs(ctx context.Context, workspaceID, noteID, userID string, write bool) (models.Note, error) {
+	note, err := s.getNote(ctx, workspaceID, noteID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	role, err := noteRole(ctx, s.s.Queries, note, userID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	if role == "" {
+		return models.Note{}, ErrNoteNotFound
+	}
+	if write && role == NoteRoleViewer {
+		return models.Note{}, ErrNoteForbidden
+	}
+	return note, nil
+}
+
+// noteRole returns what the user may do with the note, or "" when they
+// cannot see it
+func noteRole(ctx context.Context, q *models.Queries, note models.Note, userID string) (string, error) {
+	if userID == "" {
+		return "", nil
+	}
+	if note.AuthorID.Valid && note.AuthorID.String == userID {
+		return NoteRoleOwner, nil
+	}
+	switch note.Visibility {
+	case NoteVisibilityWorkspace:
+		return NoteRoleEditor, nil
+	case NoteVisibilityRestricted:
+		role, err := q.GetNoteMemberRole(ctx, models.GetNoteMemberRoleParams{
+			NoteID: note.ID,
+			UserID: userID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to get note member: %w", err)
+		}
+		return role, nil
+	}
+	return "", nil
+}
+
+func noteAccess(ctx context.Context, q *models.Queries, note models.Note, role string) (NoteAccess, error) {
+	members, err := q.ListNoteMembers(ctx, note.ID)
+	if err != nil {
+		return NoteAccess{}, fmt.Errorf("failed to list note members: %w", err)
+	}
+	if members == nil {
+		members = make([]models.ListNoteMembersRow, 0)
+	}
+	return NoteAccess{Visibility: note.Visibility, Role: role, Members: members}, nil
+}
+
+func isNoteVisibility(visibility string) bool {
+	switch visibility {
+	case NoteVisibilityWorkspace, NoteVisibilityPrivate, NoteVisibilityRestricted:
+		return true
+	}
+	return false
+}
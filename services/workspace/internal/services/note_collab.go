@@ -0,0 +1,533 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	collabPersistInterval = 5 * time.Second
+	collabPersistTimeout  = 10 * time.Second
+	// collabRoomLinger keeps an empty room open so clients that drop can
+	// reconnect and catch up instead of starting over
+	collabRoomLinger = 30 * time.Second
+	// collabLogSize is how many op batches a room keeps for catch-up
+	collabLogSize    = 1000
+	collabClientSend = 256
+	// collabServerSite attributes edits the server makes itself, such as
+	// merging in a note update made outside the session
+	collabServerSite = "server"
+)
+
+// Collab message types
+const (
+	CollabSync      = "sync"
+	CollabWelcome   = "welcome"
+	CollabSnapshot  = "snapshot"
+	CollabOps       = "ops"
+	CollabAwareness = "awareness"
+	CollabPresence  = "presence"
+	CollabSaved     = "saved"
+	CollabError     = "error"
+)
+
+// Presence events
+const (
+	PresenceJoin   = "join"
+	PresenceUpdate = "update"
+	PresenceLeave  = "leave"
+)
+
+// CollabCursor is a selection in terms of character IDs, so it stays put
+// as others edit. Each end sits after the given character, or at the start
+// of the document when nil.
+type CollabCursor struct {
+	Anchor *CRDTID `json:"anchor"`
+	Head   *CRDTID `json:"head"`
+}
+
+type CollabPresenceState struct {
+	ClientID string        `json:"client_id"`
+	UserID   string        `json:"user_id"`
+	Name     string        `json:"name,omitempty"`
+	Color    string        `json:"color,omitempty"`
+	Cursor   *CollabCursor `json:"cursor,omitempty"`
+}
+
+// CollabMessage is the envelope for everything exchanged over a collab
+// session. Clients send:
+//
+//   - sync: with the Epoch and Seq of the last state they saw, if any.
+//     They get a welcome followed by the ops they missed, or a snapshot if
+//     those are gone or too many. Both carry the Site of the connection.
+//   - ops: edits made locally, with IDs on the Site from their welcome or
+//     snapshot.
+//   - awareness: their cursor, name and color in Presence[0].
+//
+// The server sends welcome, snapshot, ops (to everyone, including their
+// origin), presence, saved once content has been written to the note, and
+// error.
+type CollabMessage struct {
+	Type     string                `json:"type"`
+	Epoch    string                `json:"epoch,omitempty"`
+	Since    uint64                `json:"since,omitempty"`
+	Seq      uint64                `json:"seq,omitempty"`
+	Site     string                `json:"site,omitempty"`
+	Clock    uint64                `json:"clock,omitempty"`
+	Version  int32                 `json:"version,omitempty"`
+	Origin   string                `json:"origin,omitempty"`
+	Ops      []CRDTOp              `json:"ops,omitempty"`
+	Runs     []CRDTRun             `json:"runs,omitempty"`
+	Event    string                `json:"event,omitempty"`
+	Presence []CollabPresenceState `json:"presence,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+type NoteCollabServicer interface {
+	Join(ctx context.Context, workspaceID, noteID, userID string) (*CollabClient, error)
+}
+
+// NoteCollabService keeps one in-memory document per note being edited and
+// periodically writes it back through the note service.
+type NoteCollabService struct {
+	notes NoteServicer
+
+	mu    sync.Mutex
+	rooms map[string]*collabRoom
+}
+
+// Compile time interface implementation check
+var _ NoteCollabServicer = (*NoteCollabService)(nil)
+
+func NewNoteCollabService(notes NoteServicer) *NoteCollabService {
+	return &NoteCollabService{
+		notes: notes,
+		rooms: make(map[string]*collabRoom),
+	}
+}
+
+// Start persists edited documents and closes idle rooms until ctx is
+// cancelled, then flushes what is left.
+func (s *NoteCollabService) Start(ctx context.Context) {
+	ticker := time.NewTicker(collabPersistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(context.Background(), true)
+			return
+		case <-ticker.C:
+			s.flush(ctx, false)
+		}
+	}
+}
+
+// Join connects a user to the note's room, loading the note if nobody is
+// editing it yet. The user must be allowed to edit the note.
+func (s *NoteCollabService) Join(ctx context.Context, workspaceID, noteID, userID string) (*CollabClient, error) {
+	if userID == "" {
+		return nil, ErrMissingNoteFields
+	}
+	access, err := s.notes.GetNoteAccess(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if access.Role == NoteRoleViewer {
+		return nil, ErrNoteForbidden
+	}
+	key := workspaceID + "/" + noteID
+	for {
+		s.mu.Lock()
+		room, ok := s.rooms[key]
+		if !ok {
+			room = &collabRoom{
+				workspaceID: workspaceID,
+				noteID:      noteID,
+				ready:       make(chan struct{}),
+			}
+			s.rooms[key] = room
+			s.mu.Unlock()
+			s.load(ctx, key, room, userID)
+		} else {
+			s.mu.Unlock()
+		}
+
+		select {
+		case <-room.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if room.err != nil {
+			return nil, room.err
+		}
+		if client := room.join(userID); client != nil {
+			return client, nil
+		}
+		// The room closed under us; the note is saved so load it again
+	}
+}
+
+func (s *NoteCollabService) load(ctx context.Context, key string, room *collabRoom, userID string) {
+	defer close(room.ready)
+
+	note, err := s.notes.GetNote(ctx, room.workspaceID, room.noteID, userID)
+	if err != nil {
+		room.err = err
+		s.mu.Lock()
+		delete(s.rooms, key)
+		s.mu.Unlock()
+		return
+	}
+
+	room.doc = NewCRDTDoc()
+	if note.Content != "" {
+		err = room.doc.Apply(CRDTOp{Kind: CRDTInsert, ID: CRDTID{Site: collabServerSite, Seq: 1}, Text: note.Content})
+	}
+	if err != nil {
+		room.err = err
+		s.mu.Lock()
+		delete(s.rooms, key)
+		s.mu.Unlock()
+		return
+	}
+	room.epoch = randomID()
+	room.version = note.Version
+	room.baseText, room.baseIDs = room.doc.visible()
+	room.clients = make(map[*CollabClient]struct{})
+	room.idleSince = time.Now()
+}
+
+func (s *NoteCollabService) flush(ctx context.Context, all bool) {
+	s.mu.Lock()
+	rooms := make(map[string]*collabRoom, len(s.rooms))
+	for key, room := range s.rooms {
+		rooms[key] = room
+	}
+	s.mu.Unlock()
+
+	for key, room := range rooms {
+		select {
+		case <-room.ready:
+		default:
+			continue
+		}
+		if room.err != nil {
+			continue
+		}
+
+		pctx, cancel := context.WithTimeout(ctx, collabPersistTimeout)
+		gone := s.persist(pctx, room)
+		cancel()
+
+		s.mu.Lock()
+		room.mu.Lock()
+		if gone || all || (len(room.clients) == 0 && !room.dirty && time.Since(room.idleSince) > collabRoomLinger) {
+			room.close()
+			delete(s.rooms, key)
+		}
+		room.mu.Unlock()
+		s.mu.Unlock()
+	}
+}
+
+// persist writes the document back to the note if it changed. If the note
+// was updated elsewhere in the meantime, that update is merged into the
+// document and written on the next pass. It reports whether the note no
+// longer exists, or can no longer be edited by the room.
+func (s *NoteCollabService) persist(ctx context.Context, room *collabRoom) bool {
+	room.mu.Lock()
+	if !room.dirty {
+		room.mu.Unlock()
+		return false
+	}
+	text, ids := room.doc.visible()
+	version := room.version
+	editor := room.lastEditor
+	room.dirty = false
+	room.mu.Unlock()
+
+	note, err := s.notes.UpdateNote(ctx, room.workspaceID, room.noteID, UpdateNoteInput{
+		EditorID:        editor,
+		ExpectedVersion: &version,
+		Content:         &text,
+	})
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	var conflict *NoteConflictError
+	switch {
+	case errors.As(err, &conflict):
+		room.merge(conflict.Current.Content, conflict.Current.Version)
+		return false
+	case errors.Is(err, ErrNoteNotFound), errors.Is(err, ErrNoteForbidden):
+		room.broadcast(CollabMessage{Type: CollabError, Error: err.Error()}, nil)
+		return true
+	case err != nil:
+		log.Printf("Failed to persist collaborative note %s: %v", room.noteID, err)
+		room.dirty = true
+		return false
+	}
+
+	room.version = note.Version
+	room.baseText, room.baseIDs = text, ids
+	room.broadcast(CollabMessage{Type: CollabSaved, Version: note.Version}, nil)
+	return false
+}
+
+type collabBatch struct {
+	seq    uint64
+	origin string
+	ops    []CRDTOp
+}
+
+type collabRoom struct {
+	workspaceID string
+	noteID      string
+
+	// ready is closed once the note is loaded or err is set
+	ready chan struct{}
+	err   error
+
+	mu      sync.Mutex
+	doc     *CRDTDoc
+	epoch   string
+	seq     uint64
+	log     []collabBatch
+	clients map[*CollabClient]struct{}
+	closed  bool
+
+	// version, baseText and baseIDs describe the note as last saved, so
+	// outside updates can be diffed against what the room started from
+	version    int32
+	baseText   string
+	baseIDs    []CRDTID
+	dirty      bool
+	lastEditor string
+	idleSince  time.Time
+}
+
+func (r *collabRoom) join(userID string) *CollabClient {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+
+	client := &CollabClient{
+		room: r,
+		send: make(chan CollabMessage, collabClientSend),
+	}
+	client.presence = CollabPresenceState{ClientID: randomID(), UserID: userID}
+	r.clients[client] = struct{}{}
+	r.broadcast(CollabMessage{
+		Type:     CollabPresence,
+		Event:    PresenceJoin,
+		Presence: []CollabPresenceState{client.presence},
+	}, client)
+	return client
+}
+
+func (r *collabRoom) leave(client *CollabClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[client]; !ok {
+		return
+	}
+	r.drop(client)
+	r.broadcast(CollabMessage{
+		Type:     CollabPresence,
+		Event:    PresenceLeave,
+		Presence: []CollabPresenceState{client.presence},
+	}, nil)
+}
+
+func (r *collabRoom) drop(client *CollabClient) {
+	delete(r.clients, client)
+	close(client.send)
+	if len(r.clients) == 0 {
+		r.idleSince = time.Now()
+	}
+}
+
+func (r *collabRoom) close() {
+	r.closed = true
+	for client := range r.clients {
+		r.drop(client)
+	}
+}
+
+// broadcast sends msg to every client but skip. Clients too slow to keep
+// up are disconnected and will resync when they come back.
+func (r *collabRoom) broadcast(msg CollabMessage, skip *CollabClient) {
+	for client := range r.clients {
+		if client != skip {
+			r.sendTo(client, msg)
+		}
+	}
+}
+
+func (r *collabRoom) sendTo(client *CollabClient, msg CollabMessage) {
+	if _, ok := r.clients[client]; !ok {
+		// Already dropped, and its channel closed
+		return
+	}
+	select {
+	case client.send <- msg:
+	default:
+		r.drop(client)
+	}
+}
+
+func (r *collabRoom) snapshot(client *CollabClient) CollabMessage {
+	presence := make([]CollabPresenceState, 0, len(r.clients))
+	for other := range r.clients {
+		if other != client {
+			presence = append(presence, other.presence)
+		}
+	}
+	return CollabMessage{
+		Type:     CollabSnapshot,
+		Epoch:    r.epoch,
+		Seq:      r.seq,
+		Site:     client.presence.ClientID,
+		Clock:    r.doc.Clock(),
+		Version:  r.version,
+		Runs:     r.doc.Runs(),
+		Presence: presence,
+	}
+}
+
+// sync answers a client's sync with the batches it missed when the room
+// still has them and they fit in its send buffer, or a full snapshot
+// otherwise. Every connection edits on a new site, so the batches follow a
+// welcome naming it.
+func (r *collabRoom) sync(client *CollabClient, epoch string, since uint64) {
+	if epoch != r.epoch || since > r.seq || r.seq-since >= collabClientSend || (since < r.seq && since+1 < r.log[0].seq) {
+		r.sendTo(client, r.snapshot(client))
+		return
+	}
+	r.sendTo(client, CollabMessage{
+		Type:  CollabWelcome,
+		Epoch: r.epoch,
+		Seq:   since,
+		Site:  client.presence.ClientID,
+		Clock: r.doc.Clock(),
+	})
+	for _, batch := range r.log {
+		if batch.seq > since {
+			r.sendTo(client, CollabMessage{Type: CollabOps, Seq: batch.seq, Origin: batch.origin, Ops: batch.ops})
+		}
+	}
+}
+
+// apply integrates a batch of ops and broadcasts the ones that took. It
+// stops at the first op that fails.
+func (r *collabRoom) apply(origin, editor string, ops []CRDTOp) error {
+	var (
+		applied []CRDTOp
+		err     error
+	)
+	for _, op := range ops {
+		if op.Kind == CRDTInsert && op.ID.Site != origin {
+			err = ErrInvalidCRDTOp
+			break
+		}
+		if err = r.doc.Apply(op); err != nil {
+			break
+		}
+		applied = append(applied, op)
+	}
+	if len(applied) == 0 {
+		return err
+	}
+
+	r.seq++
+	batch := collabBatch{seq: r.seq, origin: origin, ops: applied}
+	r.log = append(r.log, batch)
+	if len(r.log) > collabLogSize {
+		r.log = r.log[len(r.log)-collabLogSize:]
+	}
+	r.dirty = true
+	if editor != "" {
+		r.lastEditor = editor
+	}
+	r.broadcast(CollabMessage{Type: CollabOps, Seq: batch.seq, Origin: origin, Ops: applied}, nil)
+	return err
+}
+
+// merge folds a note update made outside the session into the document.
+// The update is diffed against the last saved content and applied to the
+// characters that content was made of, so edits made in the session since
+// then survive.
+func (r *collabRoom) merge(content string, version int32) {
+	ops, ids := r.doc.replaceOps(collabServerSite, r.baseIDs, r.baseText, content)
+	if err := r.apply(collabServerSite, "", ops); err != nil {
+		log.Printf("Failed to merge update into collaborative note %s: %v", r.noteID, err)
+		return
+	}
+	r.version = version
+	r.baseText, r.baseIDs = content, ids
+	r.dirty = r.doc.Text() != content
+}
+
+// CollabClient is one connection to a room. Messages for it arrive on
+// Outbound, which is closed when the client is disconnected.
+type CollabClient struct {
+	room     *collabRoom
+	send     chan CollabMessage
+	presence CollabPresenceState
+}
+
+func (c *CollabClient) Outbound() <-chan CollabMessage {
+	return c.send
+}
+
+// Handle processes a message from the client
+func (c *CollabClient) Handle(msg CollabMessage) {
+	r := c.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[c]; !ok {
+		return
+	}
+
+	switch msg.Type {
+	case CollabSync:
+		r.sync(c, msg.Epoch, msg.Since)
+	case CollabOps:
+		if err := r.apply(c.presence.ClientID, c.presence.UserID, msg.Ops); err != nil {
+			r.sendTo(c, CollabMessage{Type: CollabError, Error: err.Error()})
+		}
+	case CollabAwareness:
+		if len(msg.Presence) > 0 {
+			state := msg.Presence[0]
+			c.presence.Name = state.Name
+			c.presence.Color = state.Color
+			c.presence.Cursor = state.Cursor
+		}
+		r.broadcast(CollabMessage{
+			Type:     CollabPresence,
+			Event:    PresenceUpdate,
+			Presence: []CollabPresenceState{c.presence},
+		}, c)
+	default:
+		r.sendTo(c, CollabMessage{Type: CollabError, Error: "unknown message type"})
+	}
+}
+
+// Leave disconnects the client from its room
+func (c *CollabClient) Leave() {
+	c.room.leave(c)
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	ErrNoteCommentNotFound = errors.New("comment thread not found")
+	ErrInvalidNoteComment  = errors.New("invalid comment data")
+)
+
+// blockIDPattern matches an Obsidian-style ^block-id at the end of a line
+var blockIDPattern = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)\s*$`)
+
+// NoteCommentThread is a thread along with its comments, oldest first
+type NoteCommentThread struct {
+	models.NoteCommentThread
+	Comments []models.NoteComment `json:"comments"`
+}
+
+type CreateNoteCommentInput struct {
+	AuthorID string
+	Body     string
+	// Start and End anchor the thread to a range of the note's content,
+	// counted in characters with End exclusive. Otherwise BlockID anchors
+	// it to the line ending in that ^block-id.
+	Start   *int
+	End     *int
+	BlockID string
+	// ExpectedVersion, when set, is the note version the anchor refers to
+	ExpectedVersion *int32
+}
+
+// textEdit replaces the characters old[oldStart:oldEnd] with
+// new[newStart:newEnd]
+type textEdit struct {
+	oldStart, oldEnd int
+	newStart, newEnd int
+}
+
+// ListCommentThreads returns the note's comment threads, or only open or
+// resolved ones when resolved is set
+func (s *NoteService) ListCommentThreads(ctx context.Context, workspaceID, noteID, viewerID string, resolved *bool) ([]NoteCommentThread, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := models.ListNoteCommentThreadsParams{NoteID: note.ID}
+	if resolved != nil {
+		params.Resolved = pgtype.Bool{Bool: *resolved, Valid: true}
+	}
+	threads, err := s.s.Queries.ListNoteCommentThreads(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comment threads: %w", err)
+	}
+	comments, err := s.s.Queries.ListNoteComments(ctx, note.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	byThread := make(map[pgtype.UUID][]models.NoteComment, len(threads))
+	for _, comment := range comments {
+		byThread[comment.ThreadID] = append(byThread[comment.ThreadID], comment)
+	}
+	out := make([]NoteCommentThread, len(threads))
+	for i, thread := range threads {
+		out[i] = NoteCommentThread{NoteCommentThread: thread, Comments: byThread[thread.ID]}
+		if out[i].Comments == nil {
+			out[i].Comments = make([]models.NoteComment, 0)
+		}
+	}
+	return out, nil
+}
+
+// CreateCommentThread starts a thread on part of the note with its first
+// comment. Anyone who can see the note may comment on it.
+func (s *NoteService) CreateCommentThread(ctx context.Context, workspaceID, noteID string, input CreateNoteCommentInput) (NoteCommentThread, error) {
+	body := strings.TrimSpace(input.Body)
+	if input.AuthorID == "" || body == "" {
+		return NoteCommentThread{}, ErrMissingNoteFields
+	}
+	if (input.Start == nil) != (input.End == nil) || (input.Start == nil) == (input.BlockID == "") {
+		return NoteCommentThread{}, ErrInvalidNoteComment
+	}
+
+	note, err := s.GetNote(ctx, workspaceID, noteID, input.AuthorID)
+	if err != nil {
+		return NoteCommentThread{}, err
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return NoteCommentThread{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	// Keep the content from changing until the anchor is saved, so it
+	// can't miss being remapped
+	note, err = queries.LockWorkspaceNote(ctx, models.LockWorkspaceNoteParams{
+		WorkspaceID: note.WorkspaceID,
+		ID:          note.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NoteCommentThread{}, ErrNoteNotFound
+		}
+		return NoteCommentThread{}, fmt.Errorf("failed to get note: %w", err)
+	}
+	if input.ExpectedVersion != nil && *input.ExpectedVersion != note.Version {
+		return NoteCommentThread{}, &NoteConflictError{Current: note}
+	}
+
+	params := models.CreateNoteCommentThreadParams{
+		NoteID:    note.ID,
+		CreatedBy: pgtype.Text{String: input.AuthorID, Valid: true},
+	}
+	if input.Start != nil {
+		runes := []rune(note.Content)
+		start, end := *input.Start, *input.End
+		if start < 0 || start >= end || end > len(runes) {
+			return NoteCommentThread{}, ErrInvalidNoteComment
+		}
+		params.AnchorStart = pgtype.Int4{Int32: int32(start), Valid: true}
+		params.AnchorEnd = pgtype.Int4{Int32: int32(end), Valid: true}
+		params.Quote = string(runes[start:end])
+	} else {
+		text, ok := noteBlocks(note.Content)[input.BlockID]
+		if !ok {
+			return NoteCommentThread{}, ErrInvalidNoteComment
+		}
+		params.BlockID = pgtype.Text{String: input.BlockID, Valid: true}
+		params.Quote = text
+	}
+
+	thread, err := queries.CreateNoteCommentThread(ctx, params)
+	if err != nil {
+		return NoteCommentThread{}, fmt.Errorf("failed to create comment thread: %w", err)
+	}
+	comment, err := queries.CreateNoteComment(ctx, models.CreateNoteCommentParams{
+		ThreadID: thread.ID,
+		AuthorID: pgtype.Text{String: input.AuthorID, Valid: true},
+		Body:     body,
+	})
+	if err != nil {
+		return NoteCommentThread{}, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return NoteCommentThread{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return NoteCommentThread{NoteCommentThread: thread, Comments: []models.NoteComment{comment}}, nil
+}
+
+// ReplyToCommentThread adds a comment to an existing thread
+func (s *NoteService) ReplyToCommentThread(ctx context.Context, workspaceID, noteID, threadID, authorID, body string) (models.NoteComment, error) {
+	body = strings.TrimSpace(body)
+	if authorID == "" || body == "" {
+		return models.NoteComment{}, ErrMissingNoteFields
+	}
+	thread, err := s.getCommentThread(ctx, workspaceID, noteID, threadID, authorID)
+	if err != nil {
+		return models.NoteComment{}, err
+	}
+
+	comment, err := s.s.Queries.CreateNoteComment(ctx, models.CreateNoteCommentParams{
+		ThreadID: thread.ID,
+		AuthorID: pgtype.Text{String: authorID, Valid: true},
+		Body:     body,
+	})
+	if err != nil {
+		return models.NoteComment{}, fmt.Errorf("failed to create comment: %w", err)
+	}
+	return comment, nil
+}
+
+// ResolveCommentThread marks a thread resolved, or open again
+func (s *NoteService) ResolveCommentThread(ctx context.Context, workspaceID, noteID, threadID, userID string, resolved bool) (NoteCommentThread, error) {
+	thread, err := s.getCommentThread(ctx, workspaceID, noteID, threadID, userID)
+	if err != nil {
+		return NoteCommentThread{}, err
+	}
+
+	thread, err = s.s.Queries.SetNoteCommentThreadResolved(ctx, models.SetNoteCommentThreadResolvedParams{
+		Resolved:   resolved,
+		ResolvedBy: pgtype.Text{String: userID, Valid: userID != ""},
+		NoteID:     thread.NoteID,
+		ID:         thread.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NoteCommentThread{}, ErrNoteCommentNotFound
+		}
+		return NoteCommentThread{}, fmt.Errorf("failed to resolve comment thread: %w", err)
+	}
+	comments, err := s.s.Queries.ListNoteThreadComments(ctx, thread.ID)
+	if err != nil {
+		return NoteCommentThread{}, fmt.Errorf("failed to list comments: %w", err)
+	}
+	if comments == nil {
+		comments = make([]models.NoteComment, 0)
+	}
+	return NoteCommentThread{NoteCommentThread: thread, Comments: comments}, nil
+}
+
+func (s *NoteService) getCommentThread(ctx context.Context, workspaceID, noteID, threadID, viewerID string) (models.NoteCommentThread, error) {
+	tID, err := parseUUID(threadID)
+	if err != nil {
+		return models.NoteCommentThread{}, ErrInvalidNoteData
+	}
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return models.NoteCommentThread{}, err
+	}
+
+	thread, err := s.s.Queries.GetNoteCommentThread(ctx, models.GetNoteCommentThreadParams{
+		NoteID: note.ID,
+		ID:     tID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.NoteCommentThread{}, ErrNoteCommentNotFound
+		}
+		return models.NoteCommentThread{}, fmt.Errorf("failed to get comment thread: %w", err)
+	}
+	return thread, nil
+}
+
+// remapNoteComments moves the note's comment anchors from the old content
+// to the new. Threads whose text is gone are marked orphaned rather than
+// deleted, and come back if their text reappears.
+func remapNoteComments(ctx context.Context, q *models.Queries, noteID pgtype.UUID, from, to string) error {
+	if from == to {
+		return nil
+	}
+	threads, err := q.ListNoteCommentThreads(ctx, models.ListNoteCommentThreadsParams{NoteID: noteID})
+	if err != nil {
+		return fmt.Errorf("failed to list comment threads: %w", err)
+	}
+	if len(threads) == 0 {
+		return nil
+	}
+
+	edits := diffText(from, to)
+	runes := []rune(to)
+	var blocks map[string]string
+	for _, thread := range threads {
+		params := models.UpdateNoteCommentThreadAnchorParams{
+			ID:          thread.ID,
+			AnchorStart: thread.AnchorStart,
+			AnchorEnd:   thread.AnchorEnd,
+			Quote:       thread.Quote,
+		}
+		if thread.BlockID.Valid {
+			if blocks == nil {
+				blocks = noteBlocks(to)
+			}
+			text, ok := blocks[thread.BlockID.String]
+			params.Orphaned = !ok
+			if ok {
+				params.Quote = text
+			}
+		} else {
+			start, end, ok := remapAnchor(edits, to, int(thread.AnchorStart.Int32), int(thread.AnchorEnd.Int32), thread.Quote, thread.OrphanedAt.Valid)
+			params.AnchorStart = pgtype.Int4{Int32: int32(start), Valid: true}
+			params.AnchorEnd = pgtype.Int4{Int32: int32(end), Valid: true}
+			params.Orphaned = !ok
+			if ok {
+				params.Quote = string(runes[start:end])
+			}
+		}
+
+		if params.AnchorStart == thread.AnchorStart && params.AnchorEnd == thread.AnchorEnd &&
+			params.Quote == thread.Quote && params.Orphaned == thread.OrphanedAt.Valid {
+			continue
+		}
+		if err := q.UpdateNoteCommentThreadAnchor(ctx, params); err != nil {
+			return fmt.Errorf("failed to move comment anchor: %w", err)
+		}
+	}
+	return nil
+}
+
+// remapAnchor moves the range [start, end) through edits. The range keeps
+// whatever of its text survived, along with anything inserted inside it.
+// When nothing survived, or the thread was already orphaned, it looks for
+// the quoted text instead. It reports false when the anchor is lost, with
+// start and end collapsed to where the text used to be.
+func remapAnchor(edits []textEdit, content string, start, end int, quote string, orphaned bool) (int, int, bool) {
+	start, end = mapAnchorStart(edits, start), mapAnchorEnd(edits, end)
+	if !orphaned && start < end {
+		return start, end, true
+	}
+	if i, ok := uniqueIndex(content, quote); ok {
+		return i, i + utf8.RuneCountInString(quote), true
+	}
+	pos := min(start, end)
+	return pos, pos, false
+}
+
+// mapAnchorStart maps the start of a range. A start inside replaced text
+// moves past the replacement.
+func mapAnchorStart(edits []textEdit, pos int) int {
+	delta := 0
+	for _, e := range edits {
+		if pos < e.oldStart {
+			break
+		}
+		if pos < e.oldEnd {
+			return e.newEnd
+		}
+		delta = e.newEnd - e.oldEnd
+	}
+	return pos + delta
+}
+
+// mapAnchorEnd maps the exclusive end of a range. An end inside replaced
+// text moves back before the replacement.
+func mapAnchorEnd(edits []textEdit, pos int) int {
+	delta := 0
+	for _, e := range edits {
+		if pos <= e.oldStart {
+			break
+		}
+		if pos <= e.oldEnd {
+			return e.newStart
+		}
+		delta = e.newEnd - e.oldEnd
+	}
+	return pos + delta
+}
+
+// diffText finds the edits that turn from into to, in order. Lines are
+// compared first so that edits far apart stay separate, then each changed
+// run of lines is narrowed down to the characters that differ.
+func diffText(from, to string) []textEdit {
+	a, b := []rune(from), []rune(to)
+	var edits []textEdit
+	oldPos, newPos := 0, 0
+	hunkOld, hunkNew := -1, -1
+	closeHunk := func() {
+		if hunkOld < 0 {
+			return
+		}
+		old, cur := a[hunkOld:oldPos], b[hunkNew:newPos]
+		prefix := 0
+		for prefix < len(old) && prefix < len(cur) && old[prefix] == cur[prefix] {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(old)-prefix && suffix < len(cur)-prefix && old[len(old)-1-suffix] == cur[len(cur)-1-suffix] {
+			suffix++
+		}
+		if prefix+suffix < len(old) || prefix+suffix < len(cur) {
+			edits = append(edits, textEdit{
+				oldStart: hunkOld + prefix,
+				oldEnd:   oldPos - suffix,
+				newStart: hunkNew + prefix,
+				newEnd:   newPos - suffix,
+			})
+		}
+		hunkOld, hunkNew = -1, -1
+	}
+
+	for _, line := range diffLines(strings.SplitAfter(from, "\n"), strings.SplitAfter(to, "\n")) {
+		n := utf8.RuneCountInString(line.Text)
+		if line.Op == DiffEqual {
+			closeHunk()
+			oldPos += n
+			newPos += n
+			continue
+		}
+		if hunkOld < 0 {
+			hunkOld, hunkNew = oldPos, newPos
+		}
+		if line.Op == DiffDelete {
+			oldPos += n
+		} else {
+			newPos += n
+		}
+	}
+	closeHunk()
+	return edits
+}
+
+// noteBlocks maps each ^block-id in the content to the text of its line
+func noteBlocks(content string) map[string]string {
+	blocks := make(map[string]string)
+	mapProseLines(content, func(line string) string {
+		if m := blockIDPattern.FindStringSubmatchIndex(line); m != nil {
+			id := line[m[2]:m[3]]
+			if _, ok := blocks[id]; !ok {
+				blocks[id] = strings.TrimSpace(line[:m[0]])
+			}
+		}
+		return line
+	})
+	return blocks
+}
+
+// uniqueIndex returns the character offset of sub in s, if it occurs
+// exactly once
+func uniqueIndex(s, sub string) (int, bool) {
+	i := strings.Index(s, sub)
+	if sub == "" || i < 0 || strings.Contains(s[i+1:], sub) {
+		return 0, false
+	}
+	return utf8.RuneCountInString(s[:i]), true
+}
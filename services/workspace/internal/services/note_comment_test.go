@@ -0,0 +1,31 @@
+package services
+
+import "testing"
+
+func TestRemapAnchor(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   string
+		start, end int
+		quote      string
+		orphaned   bool
+		wantStart  int
+		wantEnd    int
+		wantFound  bool
+	}{
+		{"before the edit", "hello world", "hello there world", 0, 5, "hello", false, 0, 5, true},
+		{"after the edit", "hello world", "hello there world", 6, 11, "world", false, 12, 17, true},
+		{"grows with inserted text", "hello world", "hello there world", 4, 7, "o w", false, 4, 13, true},
+		{"shrinks to what survived", "hello cruel world", "hello world", 3, 14, "lo cruel wo", false, 3, 8, true},
+		{"text deleted", "hello cruel world", "hello world", 6, 12, "cruel ", false, 6, 6, false},
+		{"text moved", "cruel\nhello\n", "hello\ncruel\n", 0, 5, "cruel", false, 6, 11, true},
+		{"orphan found again", "hello", "say world again", 0, 0, "world", true, 4, 9, true},
+		{"orphan quoted twice", "hello", "world world", 0, 0, "world", true, 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, end, found := remapAnchor(diffText(tt.from, tt.to), tt.to, tt.start, tt.end, tt.quote, tt.orphaned)
+		if start != tt.wantStart || end != tt.wantEnd || found != tt.wantFound {
+			t.Errorf("%s: got %d, %d, %v want %d, %d, %v", tt.name, start, end, found, tt.wantStart, tt.wantEnd, tt.wantFound)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+const maxExportNameLength = 100
+
+// RenderedNote is a note with its content rendered to sanitized HTML
+type RenderedNote struct {
+	ID        pgtype.UUID        `json:"id"`
+	Title     string             `json:"title"`
+	Tags      []string           `json:"tags"`
+	HTML      string             `json:"html"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// noteFrontMatter is the YAML header of an exported Markdown note
+type noteFrontMatter struct {
+	ID      string    `yaml:"id"`
+	Title   string    `yaml:"title"`
+	Tags    []string  `yaml:"tags"`
+	Created time.Time `yaml:"created"`
+	Updated time.Time `yaml:"updated"`
+}
+
+// exportedNote is a note along with its path in the export, without an
+// extension
+type exportedNote struct {
+	note  models.Note
+	path  string
+	depth int
+}
+
+var exportPageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+{{- if .Tags}}
+<meta name="keywords" content="{{range $i, $t := .Tags}}{{if $i}}, {{end}}{{$t}}{{end}}">
+{{- end}}
+</head>
+<body>
+<nav><a href="{{.Index}}">All notes</a></nav>
+<article>
+<h1>{{.Title}}</h1>
+{{- if .Tags}}
+<p>{{range .Tags}}<span class="tag">#{{.}}</span> {{end}}</p>
+{{- end}}
+{{.Content}}
+</article>
+</body>
+</html>
+`))
+
+var exportIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Notes</title>
+</head>
+<body>
+<h1>Notes</h1>
+<ul>
+{{- range .}}
+<li style="margin-left: {{.Depth}}em"><a href="{{.Href}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+// RenderNote returns a note with its Markdown rendered to sanitized HTML,
+// with heading anchors, task list checkboxes and highlighted code
+func (s *NoteService) RenderNote(ctx context.Context, workspaceID, noteID, viewerID string) (RenderedNote, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return RenderedNote{}, err
+	}
+
+	html, err := RenderMarkdown(note.Content)
+	if err != nil {
+		return RenderedNote{}, err
+	}
+	return RenderedNote{
+		ID:        note.ID,
+		Title:     note.Title,
+		Tags:      note.Tags,
+		HTML:      html,
+		UpdatedAt: note.UpdatedAt,
+	}, nil
+}
+
+// ExportNotes writes every note the viewer can see to w as a zip archive. Each
+// note is saved as Markdown with YAML front-matter and as an HTML page, in
+// folders that follow the note tree, alongside an index.html linking them
+// all. Wiki links between notes become relative links in the HTML pages.
+func (s *NoteService) ExportNotes(ctx context.Context, workspaceID, viewerID string, w io.Writer) error {
+	notes, err := s.ListNotes(ctx, workspaceID, viewerID, NoteTagFilter{})
+	if err != nil {
+		return err
+	}
+	exported := exportNotePaths(notes)
+
+	byTitle := make(map[string]string, len(exported))
+	byID := make(map[string]string, len(exported))
+	for _, e := range exported {
+		byID[uuidString(e.note.ID)] = e.path
+		if key := strings.ToLower(e.note.Title); byTitle[key] == "" {
+			byTitle[key] = e.path
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	for _, e := range exported {
+		markdown, err := exportMarkdown(e.note)
+		if err != nil {
+			return err
+		}
+		if err := writeZipFile(archive, e.path+".md", markdown); err != nil {
+			return err
+		}
+
+		content := linkWikiLinks(e.note.Content, func(target string) (string, bool) {
+			path, ok := byID[strings.ToLower(target)]
+			if !ok {
+				path, ok = byTitle[strings.ToLower(target)]
+			}
+			if !ok {
+				return "", false
+			}
+			return relativeHref(e.depth, path+".html"), true
+		})
+		html, err := RenderMarkdown(content)
+		if err != nil {
+			return err
+		}
+
+		var page bytes.Buffer
+		if err := exportPageTemplate.Execute(&page, struct {
+			Title   string
+			Tags    []string
+			Index   string
+			Content template.HTML
+		}{
+			Title: e.note.Title,
+			Tags:  e.note.Tags,
+			Index: relativeHref(e.depth, "index.html"),
+			// Already sanitized by RenderMarkdown
+			Content: template.HTML(html),
+		}); err != nil {
+			return fmt.Errorf("failed to render exported note: %w", err)
+		}
+		if err := writeZipFile(archive, e.path+".html", page.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	type indexEntry struct {
+		Title string
+		Href  string
+		Depth int
+	}
+	entries := make([]indexEntry, len(exported))
+	for i, e := range exported {
+		entries[i] = indexEntry{Title: e.note.Title, Href: relativeHref(0, e.path+".html"), Depth: e.depth}
+	}
+	var index bytes.Buffer
+	if err := exportIndexTemplate.Execute(&index, entries); err != nil {
+		return fmt.Errorf("failed to render export index: %w", err)
+	}
+	if err := writeZipFile(archive, "index.html", index.Bytes()); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write export archive: %w", err)
+	}
+	return nil
+}
+
+// exportNotePaths lays notes out in tree order. A note's children go in a
+// folder named after it, and names are made unique among siblings.
+func exportNotePaths(notes []models.Note) []exportedNote {
+	byID := make(map[pgtype.UUID]models.Note, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+	}
+
+	var out []exportedNote
+	var walk func(nodes []*NoteTreeNode, dir string, depth int)
+	walk = func(nodes []*NoteTreeNode, dir string, depth int) {
+		used := make(map[string]bool)
+		if dir == "" {
+			used["index"] = true
+		}
+		for _, node := range nodes {
+			base := exportFileName(node.Title)
+			name := base
+			for i := 2; used[strings.ToLower(name)]; i++ {
+				name = fmt.Sprintf("%s (%d)", base, i)
+			}
+			used[strings.ToLower(name)] = true
+
+			out = append(out, exportedNote{note: byID[node.ID], path: dir + name, depth: depth})
+			walk(node.Children, dir+name+"/", depth+1)
+		}
+	}
+	walk(buildNoteTree(notes), "", 0)
+	return out
+}
+
+// exportFileName turns a note title into a name that is safe as a file or
+// folder name on common filesystems
+func exportFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|#[]`, r) {
+			return '-'
+		}
+		return r
+	}, title)
+	name = strings.Trim(name, " .")
+	if runes := []rune(name); len(runes) > maxExportNameLength {
+		name = strings.TrimRight(string(runes[:maxExportNameLength]), " .")
+	}
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+func exportMarkdown(note models.Note) ([]byte, error) {
+	header, err := yaml.Marshal(noteFrontMatter{
+		ID:      uuidString(note.ID),
+		Title:   note.Title,
+		Tags:    note.Tags,
+		Created: note.CreatedAt.Time,
+		Updated: note.UpdatedAt.Time,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write note front-matter: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(header)
+	buf.WriteString("---\n\n")
+	buf.WriteString(note.Content)
+	return buf.Bytes(), nil
+}
+
+// linkWikiLinks replaces [[links]] that resolve with Markdown links, and
+// the rest with their plain text
+func linkWikiLinks(content string, resolve func(target string) (string, bool)) string {
+	return mapProseLines(content, func(line string) string {
+		return wikiLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+			inner := link[2 : len(link)-2]
+			target := wikiLinkTarget(inner)
+			label := target
+			if _, alias, ok := strings.Cut(inner, "|"); ok && strings.TrimSpace(alias) != "" {
+				label = strings.TrimSpace(alias)
+			}
+			href, ok := resolve(target)
+			if !ok {
+				return label
+			}
+			return "[" + label + "](" + href + ")"
+		})
+	})
+}
+
+// relativeHref links to path, relative to the archive root, from a file
+// depth folders deep
+func relativeHref(depth int, path string) string {
+	href := (&url.URL{Path: path}).EscapedPath()
+	return strings.Repeat("../", depth) + href
+}
+
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidNoteImport  = errors.New("invalid note import archive")
+	ErrNoteImportTooLarge = errors.New("note import archive has too many files or is too large once extracted")
+)
+
+const (
+	maxImportFiles    = 5000
+	maxImportFileSize = 1 << 20
+	// maxImportTotalSize bounds the extracted size of an archive, which is
+	// held in memory
+	maxImportTotalSize = 100 << 20
+	// Notes with different parents are created concurrently; siblings are
+	// created in order so they keep their positions
+	importWorkers = 4
+)
+
+// Reasons a file in an import archive was skipped
+const (
+	importSkipUnsafePath  = "unsafe path"
+	importSkipHidden      = "hidden file"
+	importSkipNotMarkdown = "not a Markdown file"
+	importSkipTooLarge    = "file too large"
+	importSkipNotText     = "not UTF-8 text"
+	importSkipDuplicate   = "duplicate note path"
+	importSkipUnreadable  = "unreadable file"
+)
+
+var markdownLinkPattern = regexp.MustCompile(`\[([^\[\]\n]*)\]\(([^()\s]+)\)`)
+
+type NoteImportReport struct {
+	Imported int                 `json:"imported"`
+	Notes    []ImportedNote      `json:"notes"`
+	Skipped  []SkippedImportFile `json:"skipped"`
+	// Error is set when the import stopped partway; Notes lists the notes
+	// created before it did
+	Error string `json:"error,omitempty"`
+}
+
+type ImportedNote struct {
+	// Path is the note's path in the archive, without its extension. Folders
+	// without a matching Markdown file are imported as empty notes.
+	Path  string `json:"path"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type SkippedImportFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// importNode is a Markdown file or folder in an import archive. A file and
+// a folder with the same name, as in "Project.md" and "Project/", become a
+// single note with the folder's contents as children.
+type importNode struct {
+	path     string
+	title    string
+	tags     []string
+	content  string
+	file     bool
+	parent   *importNode
+	children []*importNode
+	id       string
+}
+
+// ImportNotes creates notes from a zip of Markdown files, such as a plain
+// folder of notes or an Obsidian vault. YAML front-matter provides titles
+// and tags, folders become parent notes, and [[links]] and Markdown links
+// between files are rewritten to point at the imported notes' titles.
+// Files that cannot be imported are listed in the report. When creating a
+// note fails, the report of the notes created so far comes with the error.
+func (s *NoteService) ImportNotes(ctx context.Context, workspaceID, authorID string, archive io.ReaderAt, size int64) (NoteImportReport, error) {
+	if authorID == "" {
+		return NoteImportReport{}, ErrMissingNoteFields
+	}
+	reader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return NoteImportReport{}, ErrInvalidNoteImport
+	}
+	if len(reader.File) > maxImportFiles {
+		return NoteImportReport{}, ErrNoteImportTooLarge
+	}
+
+	report := NoteImportReport{
+		Notes:   make([]ImportedNote, 0),
+		Skipped: make([]SkippedImportFile, 0),
+	}
+	skip := func(name, reason string) {
+		report.Skipped = append(report.Skipped, SkippedImportFile{Path: name, Reason: reason})
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, ok := cleanImportPath(f.Name)
+		switch {
+		case !ok:
+			skip(f.Name, importSkipUnsafePath)
+		case isHiddenImportPath(name):
+			skip(name, importSkipHidden)
+		case !isMarkdownFile(name):
+			skip(name, importSkipNotMarkdown)
+		case f.UncompressedSize64 > maxImportFileSize:
+			skip(name, importSkipTooLarge)
+		default:
+			files[name] = f
+		}
+	}
+	files = stripImportRoot(files)
+
+	root := &importNode{}
+	nodes := map[string]*importNode{"": root}
+	names := slices.Sorted(func(yield func(string) bool) {
+		for name := range files {
+			if !yield(name) {
+				return
+			}
+		}
+	})
+	extracted := 0
+	for _, name := range names {
+		data, err := readImportFile(files[name])
+		// Headers may understate sizes, so count what was actually read
+		if extracted += len(data); extracted > maxImportTotalSize {
+			return NoteImportReport{}, ErrNoteImportTooLarge
+		}
+		if err != nil {
+			skip(name, importSkipUnreadable)
+			continue
+		}
+		if len(data) > maxImportFileSize {
+			skip(name, importSkipTooLarge)
+			continue
+		}
+		if !utf8.Valid(data) {
+			skip(name, importSkipNotText)
+			continue
+		}
+
+		notePath := strings.TrimSuffix(name, path.Ext(name))
+		node := importTreeNode(nodes, notePath)
+		if node.file {
+			skip(name, importSkipDuplicate)
+			continue
+		}
+		node.file = true
+		node.title, node.tags, node.content = parseFrontMatter(string(data))
+		if node.title == "" {
+			node.title = node.path[strings.LastIndex(node.path, "/")+1:]
+		}
+	}
+
+	rewriteImportLinks(nodes)
+
+	err = s.createImportedNotes(ctx, workspaceID, authorID, root, &report)
+
+	slices.SortFunc(report.Notes, func(a, b ImportedNote) int { return strings.Compare(a.Path, b.Path) })
+	slices.SortFunc(report.Skipped, func(a, b SkippedImportFile) int { return strings.Compare(a.Path, b.Path) })
+	report.Imported = len(report.Notes)
+	return report, err
+}
+
+// createImportedNotes creates the tree one level at a time, so every
+// parent exists before its children
+func (s *NoteService) createImportedNotes(ctx context.Context, workspaceID, authorID string, root *importNode, report *NoteImportReport) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	level := []*importNode{root}
+	for len(level) > 0 {
+		var next []*importNode
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, importWorkers)
+		for _, parent := range level {
+			next = append(next, parent.children...)
+			if len(parent.children) == 0 {
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(parent *importNode) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				for _, node := range parent.children {
+					note, err := s.CreateNote(ctx, CreateNoteInput{
+						WorkspaceID: workspaceID,
+						AuthorID:    authorID,
+						Title:       node.title,
+						Content:     node.content,
+						Tags:        node.tags,
+						ParentID:    importParentID(node),
+					})
+					mu.Lock()
+					if err != nil {
+						if firstErr == nil {
+							firstErr = err
+							cancel()
+						}
+						mu.Unlock()
+						return
+					}
+					node.id = uuidString(note.ID)
+					report.Notes = append(report.Notes, ImportedNote{Path: node.path, ID: node.id, Title: note.Title})
+					mu.Unlock()
+				}
+			}(parent)
+		}
+		wg.Wait()
+		if firstErr != nil {
+			return fmt.Errorf("failed to import notes: %w", firstErr)
+		}
+		level = next
+	}
+	return nil
+}
+
+// importParentID returns the created note the node goes under, or "" for
+// the top level
+func importParentID(node *importNode) string {
+	if node.parent == nil {
+		return ""
+	}
+	return node.parent.id
+}
+
+// importTreeNode returns the node for notePath, adding it and any missing
+// folders above it to the tree
+func importTreeNode(nodes map[string]*importNode, notePath string) *importNode {
+	if node, ok := nodes[notePath]; ok {
+		return node
+	}
+	parentPath, name := "", notePath
+	if i := strings.LastIndex(notePath, "/"); i >= 0 {
+		parentPath, name = notePath[:i], notePath[i+1:]
+	}
+	parent := importTreeNode(nodes, parentPath)
+	node := &importNode{path: notePath, title: name}
+	if parent.path != "" {
+		node.parent = parent
+	}
+	parent.children = append(parent.children, node)
+	nodes[notePath] = node
+	return node
+}
+
+// rewriteImportLinks points [[links]] and relative Markdown links between
+// imported files at the titles of the notes they will become. Links are
+// looked up by path from the archive root, then by file name, the way
+// Obsidian resolves them.
+func rewriteImportLinks(nodes map[string]*importNode) {
+	byPath := make(map[string]*importNode, len(nodes))
+	byName := make(map[string]*importNode, len(nodes))
+	paths := slices.Sorted(func(yield func(string) bool) {
+		for p := range nodes {
+			if p != "" && !yield(p) {
+				return
+			}
+		}
+	})
+	for _, p := range paths {
+		node := nodes[p]
+		key := strings.ToLower(p)
+		byPath[key] = node
+		name := key[strings.LastIndex(key, "/")+1:]
+		if _, ok := byName[name]; !ok {
+			byName[name] = node
+		}
+	}
+	lookup := func(target string) *importNode {
+		key := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(target), "/"))
+		key = strings.TrimSuffix(key, ".md")
+		if node, ok := byPath[key]; ok {
+			return node
+		}
+		return byName[key[strings.LastIndex(key, "/")+1:]]
+	}
+
+	for _, node := range nodes {
+		if !node.file {
+			continue
+		}
+		dir := path.Dir(node.path)
+		node.content = mapProseLines(node.content, func(line string) string {
+			line = wikiLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+				inner := link[2 : len(link)-2]
+				target, rest := inner, ""
+				if i := strings.IndexAny(inner, "#|"); i >= 0 {
+					target, rest = inner[:i], inner[i:]
+				}
+				linked := lookup(target)
+				if linked == nil {
+					return link
+				}
+				return "[[" + linked.title + rest + "]]"
+			})
+			return markdownLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+				m := markdownLinkPattern.FindStringSubmatch(link)
+				href, heading, _ := strings.Cut(m[2], "#")
+				if strings.Contains(href, ":") || !isMarkdownFile(href) {
+					return link
+				}
+				if unescaped, err := url.PathUnescape(href); err == nil {
+					href = unescaped
+				}
+				linked := lookup(path.Join(dir, href))
+				if linked == nil {
+					return link
+				}
+				target := linked.title
+				if heading != "" {
+					target += "#" + heading
+				}
+				if m[1] == "" || m[1] == linked.title {
+					return "[[" + target + "]]"
+				}
+				return "[[" + target + "|" + m[1] + "]]"
+			})
+		})
+	}
+}
+
+// parseFrontMatter splits a YAML front-matter block from the start of a
+// Markdown file. Content without valid front-matter is returned unchanged.
+func parseFrontMatter(data string) (title string, tags []string, content string) {
+	data = strings.TrimPrefix(data, "\ufeff")
+	normalized := strings.ReplaceAll(data, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return "", nil, data
+	}
+	block, rest, ok := strings.Cut(normalized[4:], "\n---")
+	if !ok {
+		return "", nil, data
+	}
+	if newline := strings.IndexByte(rest, '\n'); newline >= 0 {
+		rest = rest[newline+1:]
+	} else if strings.TrimSpace(rest) == "" {
+		rest = ""
+	} else {
+		return "", nil, data
+	}
+
+	var meta struct {
+		Title string `yaml:"title"`
+		Tags  any    `yaml:"tags"`
+		Tag   any    `yaml:"tag"`
+	}
+	if err := yaml.Unmarshal([]byte(block), &meta); err != nil {
+		return "", nil, data
+	}
+	tags = append(frontMatterTags(meta.Tags), frontMatterTags(meta.Tag)...)
+	return strings.TrimSpace(meta.Title), tags, strings.TrimLeft(rest, "\n")
+}
+
+// frontMatterTags accepts tags as a YAML list or as a string separated by
+// commas or spaces, with or without leading #
+func frontMatterTags(value any) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// stripImportRoot removes a folder that wraps every file, as when a vault
+// folder itself was zipped
+func stripImportRoot(files map[string]*zip.File) map[string]*zip.File {
+	prefix := ""
+	for name := range files {
+		first, _, ok := strings.Cut(name, "/")
+		if !ok || (prefix != "" && first != prefix) {
+			return files
+		}
+		prefix = first
+	}
+	if prefix == "" {
+		return files
+	}
+	stripped := make(map[string]*zip.File, len(files))
+	for name, f := range files {
+		stripped[strings.TrimPrefix(name, prefix+"/")] = f
+	}
+	return stripped
+}
+
+// cleanImportPath normalizes an archive path, rejecting paths that escape
+// the archive root
+func cleanImportPath(name string) (string, bool) {
+	name = path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(name) || name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
+
+// isHiddenImportPath reports paths inside dot folders, such as .obsidian
+// and .trash, and macOS archive metadata
+func isHiddenImportPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+func isMarkdownFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+func readImportFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// The size in the header is not trusted, so read one byte past the limit
+	// to tell when a file is too large
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(rc, maxImportFileSize+1)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		data    string
+		title   string
+		tags    []string
+		content string
+	}{
+		{"---\ntitle: Plan\ntags: [a, '#b']\n---\nBody\n", "Plan", []string{"a", "b"}, "Body\n"},
+		{"---\r\ntitle: Plan\r\n---\r\nBody", "Plan", nil, "Body"},
+		{"\ufeff---\ntag: 'x, #y z'\n---\n\nBody", "", []string{"x", "y", "z"}, "Body"},
+		{"---\ntitle: '  Spaced  '\ntags: one\ntag: [two]\n---\n", "Spaced", []string{"one", "two"}, ""},
+		{"---\ntitle: Only\n---", "Only", nil, ""},
+		{"No front matter\n---\n", "", nil, "No front matter\n---\n"},
+		{"---\ntitle: [unclosed\n---\nBody", "", nil, "---\ntitle: [unclosed\n---\nBody"},
+		{"---\ntitle: Open\nBody", "", nil, "---\ntitle: Open\nBody"},
+		{"---\ntitle: Plan\n---trailing", "", nil, "---\ntitle: Plan\n---trailing"},
+	}
+	for _, tt := range tests {
+		title, tags, content := parseFrontMatter(tt.data)
+		if title != tt.title || fmt.Sprint(tags) != fmt.Sprint(tt.tags) || content != tt.content {
+			t.Errorf("parseFrontMatter(%q) got %q, %v, %q want %q, %v, %q", tt.data, title, tags, content, tt.title, tt.tags, tt.content)
+		}
+	}
+}
+
+func TestRewriteImportLinks(t *testing.T) {
+	nodes := map[string]*importNode{"": {}}
+	addFile := func(notePath, title string, lines ...string) *importNode {
+		node := importTreeNode(nodes, notePath)
+		node.file = true
+		node.title = title
+		node.content = strings.Join(lines, "\n")
+		return node
+	}
+	addFile("Archive/Budget", "Old Budget")
+	addFile("Projects/Budget", "Budget")
+	launch := addFile("Projects/Launch", "Launch Plan",
+		"[up](../Daily.md)",
+		"[b](Budget.md#Q1)",
+	)
+	daily := addFile("Daily", "Daily",
+		"[[Launch]]",
+		"[[Projects/Launch#Goals|the plan]]",
+		"[[launch.md]]",
+		// File names shared by several notes go to the first by path
+		"[[Budget]]",
+		"[[Missing]]",
+		"[see](Projects/Budget.md)",
+		"[Budget](Projects/Budget.md)",
+		"[site](https://example.com/a.md)",
+		"[image](image.png)",
+		"```",
+		"[[Launch]]",
+		"```",
+	)
+
+	rewriteImportLinks(nodes)
+
+	tests := []struct {
+		node *importNode
+		want []string
+	}{
+		{launch, []string{"[[Daily|up]]", "[[Budget#Q1|b]]"}},
+		{daily, []string{
+			"[[Launch Plan]]",
+			"[[Launch Plan#Goals|the plan]]",
+			"[[Launch Plan]]",
+			"[[Old Budget]]",
+			"[[Missing]]",
+			"[[Budget|see]]",
+			"[[Budget]]",
+			"[site](https://example.com/a.md)",
+			"[image](image.png)",
+			"```",
+			"[[Launch]]",
+			"```",
+		}},
+	}
+	for _, tt := range tests {
+		got := strings.Split(tt.node.content, "\n")
+		if len(got) != len(tt.want) {
+			t.Errorf("%s got %d lines want %d", tt.node.path, len(got), len(tt.want))
+			continue
+		}
+		for i, line := range got {
+			if line != tt.want[i] {
+				t.Errorf("%s line %d got %q want %q", tt.node.path, i+1, line, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+// Note link kinds
+const (
+	// NoteLinkWiki is a [[Title]] link, optionally with #heading or |alias
+	NoteLinkWiki = "wiki"
+	// NoteLinkID is a [[note-id]] link or a Markdown link to a note URL
+	NoteLinkID = "id"
+)
+
+const (
+	maxBacklinkContexts   = 3
+	maxBacklinkContextLen = 200
+)
+
+var (
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+	noteURLPattern  = regexp.MustCompile(`\]\([^)\s]*/notes/([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})\)`)
+	noteIDPattern   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+type NoteBacklink struct {
+	NoteID    pgtype.UUID        `json:"note_id"`
+	Title     string             `json:"title"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	// Context holds the lines of the linking note that contain the link
+	Context []string `json:"context"`
+}
+
+type NoteGraphNode struct {
+	ID        pgtype.UUID `json:"id"`
+	Title     string      `json:"title"`
+	Tags      []string    `json:"tags"`
+	Links     int         `json:"links"`
+	Backlinks int         `json:"backlinks"`
+}
+
+type NoteGraphEdge struct {
+	Source pgtype.UUID `json:"source"`
+	Target pgtype.UUID `json:"target"`
+	Count  int64       `json:"count"`
+}
+
+type NoteGraph struct {
+	Nodes []NoteGraphNode `json:"nodes"`
+	Edges []NoteGraphEdge `json:"edges"`
+}
+
+type noteLinkRef struct {
+	kind string
+	ref  string
+}
+
+func (s *NoteService) ListBacklinks(ctx context.Context, workspaceID, noteID, viewerID string) ([]NoteBacklink, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.s.Queries.ListNoteBacklinks(ctx, models.ListNoteBacklinksParams{
+		TargetID: note.ID,
+		ViewerID: pgtype.Text{String: viewerID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backlinks: %w", err)
+	}
+
+	backlinks := make([]NoteBacklink, 0, len(rows))
+	index := make(map[pgtype.UUID]int, len(rows))
+	for _, row := range rows {
+		i, ok := index[row.SourceID]
+		if !ok {
+			i = len(backlinks)
+			index[row.SourceID] = i
+			backlinks = append(backlinks, NoteBacklink{
+				NoteID:    row.SourceID,
+				Title:     row.Title,
+				UpdatedAt: row.UpdatedAt,
+				Context:   make([]string, 0),
+			})
+		}
+		backlinks[i].Context = appendLinkContext(backlinks[i].Context, row.Content, row.TargetRef)
+	}
+	return backlinks, nil
+}
+
+// ListBrokenLinks returns links whose target note doesn't exist, from notes
+// the viewer can see
+func (s *NoteService) ListBrokenLinks(ctx context.Context, workspaceID, viewerID string) ([]models.ListBrokenNoteLinksRow, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidNoteData
+	}
+
+	links, err := s.s.Queries.ListBrokenNoteLinks(ctx, models.ListBrokenNoteLinksParams{
+		WorkspaceID: wsID,
+		ViewerID:    viewerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broken links: %w", err)
+	}
+	if links == nil {
+		links = make([]models.ListBrokenNoteLinksRow, 0)
+	}
+	return links, nil
+}
+
+// GetNoteGraph returns every note the viewer can see and the links between
+// them.
+func (s *NoteService) GetNoteGraph(ctx context.Context, workspaceID, viewerID string) (NoteGraph, error) {
+	notes, err := s.ListNotes(ctx, workspaceID, viewerID, NoteTagFilter{})
+	if err != nil {
+		return NoteGraph{}, err
+	}
+	wsID, _ := parseUUID(workspaceID)
+	links, err := s.s.Queries.ListWorkspaceNoteLinks(ctx, wsID)
+	if err != nil {
+		return NoteGraph{}, fmt.Errorf("failed to list note links: %w", err)
+	}
+
+	graph := NoteGraph{
+		Nodes: make([]NoteGraphNode, len(notes)),
+		Edges: make([]NoteGraphEdge, 0, len(links)),
+	}
+	index := make(map[pgtype.UUID]int, len(notes))
+	for i, note := range notes {
+		index[note.ID] = i
+		graph.Nodes[i] = NoteGraphNode{ID: note.ID, Title: note.Title, Tags: note.Tags}
+	}
+	for _, link := range links {
+		// Skip links to or from notes the viewer cannot see
+		source, ok := index[link.SourceID]
+		if !ok {
+			continue
+		}
+		target, ok := index[link.TargetID]
+		if !ok {
+			continue
+		}
+		graph.Edges = append(graph.Edges, NoteGraphEdge{
+			Source: link.SourceID,
+			Target: link.TargetID,
+			Count:  link.LinkCount,
+		})
+		graph.Nodes[source].Links++
+		graph.Nodes[target].Backlinks++
+	}
+	return graph, nil
+}
+
+// syncNoteLinks replaces the note's outgoing links with the ones in its
+// current content.
+func syncNoteLinks(ctx context.Context, q *models.Queries, note models.Note) error {
+	if err := q.DeleteNoteLinks(ctx, note.ID); err != nil {
+		return fmt.Errorf("failed to clear note links: %w", err)
+	}
+	refs := parseNoteLinks(note.Content)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	var (
+		titles []string
+		ids    []pgtype.UUID
+	)
+	for _, ref := range refs {
+		if ref.kind == NoteLinkWiki {
+			titles = append(titles, strings.ToLower(ref.ref))
+		} else if id, err := parseUUID(ref.ref); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	byTitle := make(map[string]pgtype.UUID)
+	if len(titles) > 0 {
+		rows, err := q.ResolveNoteTitles(ctx, models.ResolveNoteTitlesParams{
+			WorkspaceID: note.WorkspaceID,
+			Titles:      titles,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve note links: %w", err)
+		}
+		for _, row := range rows {
+			byTitle[row.TitleKey] = row.ID
+		}
+	}
+	existing := make(map[pgtype.UUID]bool)
+	if len(ids) > 0 {
+		found, err := q.FilterWorkspaceNoteIDs(ctx, models.FilterWorkspaceNoteIDsParams{
+			WorkspaceID: note.WorkspaceID,
+			Ids:         ids,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve note links: %w", err)
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+
+	for _, ref := range refs {
+		var target pgtype.UUID
+		if ref.kind == NoteLinkWiki {
+			target = byTitle[strings.ToLower(ref.ref)]
+		} else if id, err := parseUUID(ref.ref); err == nil && existing[id] {
+			target = id
+		}
+		if err := q.CreateNoteLink(ctx, models.CreateNoteLinkParams{
+			WorkspaceID: note.WorkspaceID,
+			SourceID:    note.ID,
+			TargetID:    target,
+			TargetRef:   ref.ref,
+			Kind:        ref.kind,
+		}); err != nil {
+			return fmt.Errorf("failed to create note link: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveNoteLinks points dangling wiki links at a note that now carries
+// their title.
+func resolveNoteLinks(ctx context.Context, q *models.Queries, note models.Note) error {
+	if err := q.ResolveBrokenNoteLinks(ctx, models.ResolveBrokenNoteLinksParams{
+		TargetID:    note.ID,
+		WorkspaceID: note.WorkspaceID,
+		Title:       note.Title,
+	}); err != nil {
+		return fmt.Errorf("failed to resolve note links: %w", err)
+	}
+	return nil
+}
+
+// renameNoteLinks rewrites [[oldTitle]] links to a renamed note in the
+// notes that link to it. Each rewrite is saved as a new revision by editorID.
+func renameNoteLinks(ctx context.Context, q *models.Queries, note models.Note, oldTitle, editorID string) error {
+	// Rewrite links in every note, including those the editor cannot see
+	backlinks, err := q.ListNoteBacklinks(ctx, models.ListNoteBacklinksParams{TargetID: note.ID})
+	if err != nil {
+		return fmt.Errorf("failed to list backlinks: %w", err)
+	}
+
+	seen := make(map[pgtype.UUID]bool)
+	for _, link := range backlinks {
+		if link.Kind != NoteLinkWiki || link.SourceID == note.ID || seen[link.SourceID] {
+			continue
+		}
+		seen[link.SourceID] = true
+
+		source, err := q.GetWorkspaceNote(ctx, models.GetWorkspaceNoteParams{
+			WorkspaceID: note.WorkspaceID,
+			ID:          link.SourceID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get linking note: %w", err)
+		}
+		content := rewriteWikiLinks(source.Content, oldTitle, note.Title)
+		if content == source.Content {
+			continue
+		}
+
+		updated, err := q.UpdateNote(ctx, models.UpdateNoteParams{
+			WorkspaceID: source.WorkspaceID,
+			ID:          source.ID,
+			Title:       source.Title,
+			Content:     content,
+			Tags:        source.Tags,
+			Version:     source.Version,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Saved by someone else just now; leave their link as is
+				continue
+			}
+			return fmt.Errorf("failed to update linking note: %w", err)
+		}
+		if err := recordNoteRevision(ctx, q, updated, editorID); err != nil {
+			return err
+		}
+		if err := syncNoteLinks(ctx, q, updated); err != nil {
+			return err
+		}
+		if err := remapNoteComments(ctx, q, updated.ID, source.Content, updated.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseNoteLinks finds the distinct links in Markdown content, skipping
+// fenced code blocks.
+func parseNoteLinks(content string) []noteLinkRef {
+	var refs []noteLinkRef
+	seen := make(map[noteLinkRef]bool)
+	add := func(ref noteLinkRef) {
+		if ref.ref != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	mapProseLines(content, func(line string) string {
+		for _, m := range wikiLinkPattern.FindAllStringSubmatch(line, -1) {
+			target := wikiLinkTarget(m[1])
+			if noteIDPattern.MatchString(target) {
+				add(noteLinkRef{kind: NoteLinkID, ref: strings.ToLower(target)})
+			} else {
+				add(noteLinkRef{kind: NoteLinkWiki, ref: target})
+			}
+		}
+		for _, m := range noteURLPattern.FindAllStringSubmatch(line, -1) {
+			add(noteLinkRef{kind: NoteLinkID, ref: strings.ToLower(m[1])})
+		}
+		return line
+	})
+	return refs
+}
+
+// rewriteWikiLinks points [[oldTitle]] links at newTitle, keeping any
+// #heading or |alias.
+func rewriteWikiLinks(content, oldTitle, newTitle string) string {
+	return mapProseLines(content, func(line string) string {
+		return wikiLinkPattern.ReplaceAllStringFunc(line, func(link string) string {
+			inner := link[2 : len(link)-2]
+			target, rest := inner, ""
+			if i := strings.IndexAny(inner, "#|"); i >= 0 {
+				target, rest = inner[:i], inner[i:]
+			}
+			if !strings.EqualFold(strings.TrimSpace(target), oldTitle) {
+				return link
+			}
+			return "[[" + newTitle + rest + "]]"
+		})
+	})
+}
+
+// wikiLinkTarget strips the |alias and #heading from a wiki link
+func wikiLinkTarget(inner string) string {
+	target, _, _ := strings.Cut(inner, "|")
+	target, _, _ = strings.Cut(target, "#")
+	return strings.TrimSpace(target)
+}
+
+// mapProseLines applies fn to each line outside fenced code blocks
+func mapProseLines(content string, fn func(line string) string) string {
+	lines := strings.Split(content, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+			continue
+		}
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		lines[i] = fn(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func appendLinkContext(contexts []string, content, ref string) []string {
+	ref = strings.ToLower(ref)
+	for _, line := range strings.Split(content, "\n") {
+		if len(contexts) >= maxBacklinkContexts {
+			break
+		}
+		if !strings.Contains(strings.ToLower(line), ref) {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if runes := []rune(line); len(runes) > maxBacklinkContextLen {
+			line = string(runes[:maxBacklinkContextLen]) + "…"
+		}
+		if slices.Contains(contexts, line) {
+			continue
+		}
+		contexts = append(contexts, line)
+	}
+	return contexts
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseNoteLinks(t *testing.T) {
+	const id = "0a1b2c3d-0000-4000-8000-000000000001"
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"plain", "See [[Plan]] and [[ Budget ]]", []string{"wiki:Plan", "wiki:Budget"}},
+		{"heading and alias", "[[Plan#Goals|the goals]] [[Plan|plan]]", []string{"wiki:Plan"}},
+		{"repeated", "[[Plan]]\n[[Plan]]", []string{"wiki:Plan"}},
+		{"note id", "[[" + strings.ToUpper(id) + "]]", []string{"id:" + id}},
+		{"note url", "[link](https://app.example.com/workspaces/w/notes/" + id + ")", []string{"id:" + id}},
+		{"id once", "[[" + id + "]] [x](/notes/" + id + ")", []string{"id:" + id}},
+		{"code fence", "```\n[[Code]]\n```\n~~~go\n[[Tilde]]\n~~~\n[[After]]", []string{"wiki:After"}},
+		{"empty targets", "[[]] [[|alias]] [[#heading]] [[a\nb]]", nil},
+		{"nested brackets", "[[[Inner]]]", []string{"wiki:Inner"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, ref := range parseNoteLinks(tt.content) {
+			got = append(got, ref.kind+":"+ref.ref)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteWikiLinks(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"See [[Plan]].", "See [[Roadmap]]."},
+		{"[[plan#Goals|the goals]]", "[[Roadmap#Goals|the goals]]"},
+		{"[[ Plan ]]", "[[Roadmap]]"},
+		{"[[Planning]] [[Other|Plan]]", "[[Planning]] [[Other|Plan]]"},
+		{"```\n[[Plan]]\n```\n[[Plan]]", "```\n[[Plan]]\n```\n[[Roadmap]]"},
+	}
+	for _, tt := range tests {
+		if got := rewriteWikiLinks(tt.content, "Plan", "Roadmap"); got != tt.want {
+			t.Errorf("rewriteWikiLinks(%q) got %q want %q", tt.content, got, tt.want)
+		}
+	}
+}
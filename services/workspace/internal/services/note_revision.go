@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var ErrNoteRevisionNotFound = errors.New("note revision not found")
+
+// Diff line operations
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// NoteDiffLine is one line of a line-level diff. Line numbers are 1-based
+// and omitted on the side the line does not appear in.
+type NoteDiffLine struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+type NoteRevisionDiff struct {
+	From        int32          `json:"from"`
+	To          int32          `json:"to"`
+	FromTitle   string         `json:"from_title"`
+	ToTitle     string         `json:"to_title"`
+	AddedTags   []string       `json:"added_tags"`
+	RemovedTags []string       `json:"removed_tags"`
+	Added       int            `json:"added"`
+	Removed     int            `json:"removed"`
+	Lines       []NoteDiffLine `json:"lines"`
+}
+
+func (s *NoteService) ListRevisions(ctx context.Context, workspaceID, noteID, viewerID string) ([]models.ListNoteRevisionsRow, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.s.Queries.ListNoteRevisions(ctx, note.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list note revisions: %w", err)
+	}
+	if revisions == nil {
+		revisions = make([]models.ListNoteRevisionsRow, 0)
+	}
+	return revisions, nil
+}
+
+func (s *NoteService) GetRevision(ctx context.Context, workspaceID, noteID, viewerID string, revision int32) (models.NoteRevision, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return models.NoteRevision{}, err
+	}
+	return s.getRevision(ctx, note.ID, revision)
+}
+
+func (s *NoteService) DiffRevisions(ctx context.Context, workspaceID, noteID, viewerID string, from, to int32) (NoteRevisionDiff, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return NoteRevisionDiff{}, err
+	}
+	older, err := s.getRevision(ctx, note.ID, from)
+	if err != nil {
+		return NoteRevisionDiff{}, err
+	}
+	newer, err := s.getRevision(ctx, note.ID, to)
+	if err != nil {
+		return NoteRevisionDiff{}, err
+	}
+
+	diff := NoteRevisionDiff{
+		From:        from,
+		To:          to,
+		FromTitle:   older.Title,
+		ToTitle:     newer.Title,
+		AddedTags:   addedTags(older.Tags, newer.Tags),
+		RemovedTags: addedTags(newer.Tags, older.Tags),
+		Lines:       diffLines(splitLines(older.Content), splitLines(newer.Content)),
+	}
+	if diff.AddedTags == nil {
+		diff.AddedTags = make([]string, 0)
+	}
+	if diff.RemovedTags == nil {
+		diff.RemovedTags = make([]string, 0)
+	}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case DiffInsert:
+			diff.Added++
+		case DiffDelete:
+			diff.Removed++
+		}
+	}
+	return diff, nil
+}
+
+// RestoreRevision writes an old revision back as a new update, so the
+// restore itself shows up in the history. Revision numbers match the note
+// version they were saved at.
+func (s *NoteService) RestoreRevision(ctx context.Context, workspaceID, noteID string, revision int32, editorID string) (models.Note, error) {
+	note, err := s.getNoteAs(ctx, workspaceID, noteID, editorID, true)
+	if err != nil {
+		return models.Note{}, err
+	}
+	old, err := s.getRevision(ctx, note.ID, revision)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	return s.UpdateNote(ctx, workspaceID, noteID, UpdateNoteInput{
+		EditorID: editorID,
+		Title:    &old.Title,
+		Content:  &old.Content,
+		Tags:     &old.Tags,
+	})
+}
+
+func (s *NoteService) getRevision(ctx context.Context, noteID pgtype.UUID, revision int32) (models.NoteRevision, error) {
+	rev, err := s.s.Queries.GetNoteRevision(ctx, models.GetNoteRevisionParams{
+		NoteID:   noteID,
+		Revision: revision,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.NoteRevision{}, ErrNoteRevisionNotFound
+		}
+		return models.NoteRevision{}, fmt.Errorf("failed to get note revision: %w", err)
+	}
+	return rev, nil
+}
+
+func recordNoteRevision(ctx context.Context, q *models.Queries, note models.Note, authorID string) error {
+	_, err := q.CreateNoteRevision(ctx, models.CreateNoteRevisionParams{
+		NoteID:   note.ID,
+		Revision: note.Version,
+		AuthorID: pgtype.Text{String: authorID, Valid: authorID != ""},
+		Title:    note.Title,
+		Content:  note.Content,
+		Tags:     note.Tags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record note revision: %w", err)
+	}
+	return nil
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// diffLines computes a shortest line edit script with Myers' algorithm, in
+// its linear space variant: each middle snake splits the edit script in
+// two halves, which are diffed on their own.
+func diffLines(a, b []string) []NoteDiffLine {
+	size := (len(a)+len(b)+1)/2 + 1
+	d := lineDiff{
+		a:     a,
+		b:     b,
+		vf:    make([]int, 2*size+1),
+		vb:    make([]int, 2*size+1),
+		lines: make([]NoteDiffLine, 0, max(len(a), len(b))),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.lines
+}
+
+// lineDiff holds the state of diffLines. vf and vb, the furthest reaching
+// paths of the forward and backward searches by diagonal, are shared by
+// every middle snake search, which only read what they wrote.
+type lineDiff struct {
+	a, b             []string
+	vf, vb           []int
+	lines            []NoteDiffLine
+	oldLine, newLine int
+}
+
+// compare diffs a[a0:a1] against b[b0:b1]
+func (d *lineDiff) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.equal(a0)
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1-suffix > a0 && b1-suffix > b0 && d.a[a1-1-suffix] == d.b[b1-1-suffix] {
+		suffix++
+	}
+	a1, b1 = a1-suffix, b1-suffix
+
+	switch {
+	case a0 == a1:
+		for y := b0; y < b1; y++ {
+			d.newLine++
+			d.lines = append(d.lines, NoteDiffLine{Op: DiffInsert, Text: d.b[y], NewLine: d.newLine})
+		}
+	case b0 == b1:
+		for x := a0; x < a1; x++ {
+			d.oldLine++
+			d.lines = append(d.lines, NoteDiffLine{Op: DiffDelete, Text: d.a[x], OldLine: d.oldLine})
+		}
+	default:
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.compare(a0, x, b0, y)
+		for ; x < u; x++ {
+			d.equal(x)
+		}
+		d.compare(u, a1, v, b1)
+	}
+
+	for x := a1; x < a1+suffix; x++ {
+		d.equal(x)
+	}
+}
+
+func (d *lineDiff) equal(x int) {
+	d.oldLine++
+	d.newLine++
+	d.lines = append(d.lines, NoteDiffLine{Op: DiffEqual, Text: d.a[x], OldLine: d.oldLine, NewLine: d.newLine})
+}
+
+// middleSnake finds the snake, from (x, y) to (u, v), in the middle of a
+// shortest edit script of a[a0:a1] against b[b0:b1]. The backward search
+// runs on the reversed sequences, where diagonal kr is delta-k forwards.
+func (d *lineDiff) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	off := len(d.vf) / 2
+	d.vf[off+1], d.vb[off+1] = 0, 0
+
+	for D := 0; D <= (n+m+1)/2; D++ {
+		for k := -D; k <= D; k += 2 {
+			var fx int
+			if k == -D || (k != D && d.vf[off+k-1] < d.vf[off+k+1]) {
+				fx = d.vf[off+k+1]
+			} else {
+				fx = d.vf[off+k-1] + 1
+			}
+			fy := fx - k
+			sx, sy := fx, fy
+			for fx < n && fy < m && d.a[a0+fx] == d.b[b0+fy] {
+				fx++
+				fy++
+			}
+			d.vf[off+k] = fx
+			if kr := delta - k; odd && kr >= -(D-1) && kr <= D-1 && fx+d.vb[off+kr] >= n {
+				return a0 + sx, b0 + sy, a0 + fx, b0 + fy
+			}
+		}
+		for kr := -D; kr <= D; kr += 2 {
+			var rx int
+			if kr == -D || (kr != D && d.vb[off+kr-1] < d.vb[off+kr+1]) {
+				rx = d.vb[off+kr+1]
+			} else {
+				rx = d.vb[off+kr-1] + 1
+			}
+			ry := rx - kr
+			sx, sy := rx, ry
+			for rx < n && ry < m && d.a[a1-1-rx] == d.b[b1-1-ry] {
+				rx++
+				ry++
+			}
+			d.vb[off+kr] = rx
+			if k := delta - kr; !odd && k >= -D && k <= D && d.vf[off+k]+rx >= n {
+				return a1 - rx, b1 - ry, a1 - sx, b1 - sy
+			}
+		}
+	}
+	// Unreachable: the searches meet within (n+m+1)/2 steps
+	return a0, b0, a0, b0
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// formatDiff renders a diff as one line per edit, such as "-a +b =c"
+func formatDiff(lines []NoteDiffLine) string {
+	ops := map[string]string{DiffEqual: "=", DiffInsert: "+", DiffDelete: "-"}
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = ops[line.Op] + line.Text
+	}
+	return strings.Join(out, " ")
+}
+
+// checkDiff makes sure the diff turns a into b with consistent line
+// numbers, and returns its number of inserts and deletes
+func checkDiff(t *testing.T, a, b []string, lines []NoteDiffLine) int {
+	t.Helper()
+	var old, cur []string
+	edits := 0
+	for _, line := range lines {
+		switch line.Op {
+		case DiffEqual:
+			old, cur = append(old, line.Text), append(cur, line.Text)
+			if line.OldLine != len(old) || line.NewLine != len(cur) {
+				t.Fatalf("line %q numbered %d/%d, want %d/%d", line.Text, line.OldLine, line.NewLine, len(old), len(cur))
+			}
+		case DiffDelete:
+			old = append(old, line.Text)
+			edits++
+			if line.OldLine != len(old) || line.NewLine != 0 {
+				t.Fatalf("deleted line %q numbered %d/%d, want %d/0", line.Text, line.OldLine, line.NewLine, len(old))
+			}
+		case DiffInsert:
+			cur = append(cur, line.Text)
+			edits++
+			if line.NewLine != len(cur) || line.OldLine != 0 {
+				t.Fatalf("inserted line %q numbered %d/%d, want 0/%d", line.Text, line.OldLine, line.NewLine, len(cur))
+			}
+		}
+	}
+	if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(cur, "\n") != strings.Join(b, "\n") {
+		t.Fatalf("diff %s does not turn %q into %q", formatDiff(lines), a, b)
+	}
+	return edits
+}
+
+// lcsEdits returns the length of a shortest edit script, by dynamic
+// programming
+func lcsEdits(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", ""},
+		{"a", "", "-a"},
+		{"", "a", "+a"},
+		{"a b c", "a b c", "=a =b =c"},
+		{"a b c", "a x c", "=a -b +x =c"},
+		{"a b c", "a c", "=a -b =c"},
+		{"a c", "a b c", "=a +b =c"},
+		{"a b c a b b a", "c b a b a c", ""},
+		{"x a b c", "a b c x", ""},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		lines := diffLines(a, b)
+		edits := checkDiff(t, a, b, lines)
+		if want := lcsEdits(a, b); edits != want {
+			t.Errorf("diffLines(%q, %q) = %s, %d edits, want %d", tt.a, tt.b, formatDiff(lines), edits, want)
+		}
+		if tt.want != "" && formatDiff(lines) != tt.want {
+			t.Errorf("diffLines(%q, %q) = %s, want %s", tt.a, tt.b, formatDiff(lines), tt.want)
+		}
+	}
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		out := make([]string, rng.Intn(30))
+		for i := range out {
+			out[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return out
+	}
+	for i := 0; i < 500; i++ {
+		a, b := lines(), lines()
+		edits := checkDiff(t, a, b, diffLines(a, b))
+		if want := lcsEdits(a, b); edits != want {
+			t.Fatalf("diffLines(%q, %q) has %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffLinesRewriteMemory(t *testing.T) {
+	a, b := make([]string, 4000), make([]string, 4000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old line %d", i)
+		b[i] = fmt.Sprintf("new line %d", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	lines := diffLines(a, b)
+	runtime.ReadMemStats(&after)
+
+	if edits := checkDiff(t, a, b, lines); edits != 8000 {
+		t.Errorf("rewrite has %d edits, want 8000", edits)
+	}
+	// The edit script itself takes about 500 KB
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("diffLines allocated %d bytes for a 4000 line rewrite", allocated)
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     []textEdit
+	}{
+		{"same\ntext", "same\ntext", nil},
+		{"one\ntwo\nthree", "one\nTWO\nthree", []textEdit{{oldStart: 4, oldEnd: 7, newStart: 4, newEnd: 7}}},
+		{"hello world", "hello brave world", []textEdit{{oldStart: 6, oldEnd: 6, newStart: 6, newEnd: 12}}},
+		{"a\nb\nc\nd", "A\nb\nc\nD", []textEdit{
+			{oldStart: 0, oldEnd: 1, newStart: 0, newEnd: 1},
+			{oldStart: 6, oldEnd: 7, newStart: 6, newEnd: 7},
+		}},
+		{"héllo\nwörld", "héllo\nworld", []textEdit{{oldStart: 7, oldEnd: 8, newStart: 7, newEnd: 8}}},
+		{"hello cruel world", "hello world", []textEdit{{oldStart: 6, oldEnd: 12, newStart: 6, newEnd: 6}}},
+		{"a\nc\n", "a\nb\nc\n", []textEdit{{oldStart: 2, oldEnd: 2, newStart: 2, newEnd: 4}}},
+		{"", "new", []textEdit{{oldStart: 0, oldEnd: 0, newStart: 0, newEnd: 3}}},
+	}
+	for _, tt := range tests {
+		got := diffText(tt.from, tt.to)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("diffText(%q, %q) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+		}
+		if applied := applyEdits(tt.from, tt.to, got); applied != tt.to {
+			t.Errorf("diffText(%q, %q) edits give %q", tt.from, tt.to, applied)
+		}
+	}
+}
+
+// applyEdits replays edits found between from and to, copying replaced text
+// from to, so a correct diff gives back to
+func applyEdits(from, to string, edits []textEdit) string {
+	a, b := []rune(from), []rune(to)
+	var out []rune
+	pos := 0
+	for _, e := range edits {
+		out = append(out, a[pos:e.oldStart]...)
+		out = append(out, b[e.newStart:e.newEnd]...)
+		pos = e.oldEnd
+	}
+	return string(append(out, a[pos:]...))
+}
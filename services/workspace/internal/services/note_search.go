@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+const (
+	defaultNoteSearchLimit = 20
+	maxNoteSearchLimit     = 100
+)
+
+type NoteSearchInput struct {
+	// ViewerID only finds notes that user can see
+	ViewerID string
+	Query    string
+	Tags     []string
+	AuthorID string
+	Limit    int
+}
+
+// SearchNotes runs a ranked full-text search over note titles, tags and
+// content. See buildNoteSearchQuery for the supported query syntax.
+func (s *NoteService) SearchNotes(ctx context.Context, workspaceID string, input NoteSearchInput) ([]models.SearchWorkspaceNotesRow, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidNoteData
+	}
+	if strings.TrimSpace(input.Query) == "" {
+		return nil, ErrMissingNoteFields
+	}
+
+	query := buildNoteSearchQuery(input.Query)
+	if query == "" {
+		return make([]models.SearchWorkspaceNotesRow, 0), nil
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultNoteSearchLimit
+	}
+	limit = min(limit, maxNoteSearchLimit)
+
+	results, err := s.s.Queries.SearchWorkspaceNotes(ctx, models.SearchWorkspaceNotesParams{
+		Query:       query,
+		WorkspaceID: wsID,
+		Tags:        tagFilter(input.Tags),
+		AuthorID:    pgtype.Text{String: input.AuthorID, Valid: input.AuthorID != ""},
+		ViewerID:    input.ViewerID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+	if results == nil {
+		results = make([]models.SearchWorkspaceNotesRow, 0)
+	}
+	return results, nil
+}
+
+// buildNoteSearchQuery turns search box input into to_tsquery syntax.
+// "Quoted words" match as a phrase, a trailing * matches a prefix, a
+// leading - excludes a term and OR joins alternatives. Other terms must all
+// match. Punctuation is dropped, so the result is always valid syntax.
+func buildNoteSearchQuery(input string) string {
+	var (
+		out      strings.Builder
+		pendOr   bool
+		haveTerm bool
+	)
+	emit := func(term string) {
+		if haveTerm {
+			if pendOr {
+				out.WriteString(" | ")
+			} else {
+				out.WriteString(" & ")
+			}
+		}
+		out.WriteString(term)
+		haveTerm = true
+		pendOr = false
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsSpace(runes[i]):
+			i++
+		case runes[i] == '"' || (runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '"'):
+			negate := runes[i] == '-'
+			if negate {
+				i++
+			}
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if term := searchPhrase(string(runes[i+1:min(end, len(runes))]), false); term != "" {
+				if negate {
+					term = "!" + term
+				}
+				emit(term)
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+			if word == "OR" {
+				pendOr = haveTerm
+				continue
+			}
+			negate := strings.HasPrefix(word, "-")
+			prefix := strings.HasSuffix(word, "*")
+			term := searchPhrase(strings.Trim(word, "-*"), prefix)
+			if term == "" {
+				continue
+			}
+			if negate {
+				term = "!" + term
+			}
+			emit(term)
+		}
+	}
+	return out.String()
+}
+
+// searchPhrase joins the words of s with the followed-by operator. With
+// prefix set, the last word matches as a prefix.
+func searchPhrase(s string, prefix bool) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+var (
+	ErrShareNotFound         = errors.New("share link not found")
+	ErrInvalidShareData      = errors.New("invalid share link data")
+	ErrShareExpired          = errors.New("share link has expired")
+	ErrShareRevoked          = errors.New("share link has been revoked")
+	ErrSharePasswordRequired = errors.New("share link requires a password")
+	ErrShareWrongPassword    = errors.New("incorrect share link password")
+)
+
+// How a shared note is served
+const (
+	ShareFormatHTML     = "html"
+	ShareFormatMarkdown = "markdown"
+)
+
+// Outcomes recorded in a share link's access log
+const (
+	shareAccessOK               = "ok"
+	shareAccessPasswordRequired = "password_required"
+	shareAccessWrongPassword    = "wrong_password"
+	shareAccessExpired          = "expired"
+	shareAccessRevoked          = "revoked"
+)
+
+const maxSharePasswordLength = 72 // bcrypt ignores anything longer
+
+type NoteShareServicer interface {
+	CreateShare(ctx context.Context, workspaceID, noteID string, input CreateShareInput) (CreatedNoteShare, error)
+	ListShares(ctx context.Context, workspaceID, noteID, userID string) ([]models.ListNoteSharesRow, error)
+	RevokeShare(ctx context.Context, workspaceID, noteID, shareID, userID string) error
+	ListShareAccesses(ctx context.Context, workspaceID, noteID, shareID, userID string) ([]models.NoteShareAccess, error)
+	OpenShare(ctx context.Context, token, password string, access ShareAccess) (SharedNote, error)
+}
+
+type CreateShareInput struct {
+	// CreatedBy must be allowed to edit the note
+	CreatedBy string
+	// ExpiresAt, when set, must be in the future
+	ExpiresAt *time.Time
+	// Password, when set, must be given by anyone opening the link
+	Password string
+}
+
+// CreatedNoteShare is a new share link along with its token. The token is
+// only available here; afterwards the link is identified by its hint.
+type CreatedNoteShare struct {
+	models.NoteShare
+	Token       string `json:"token"`
+	HasPassword bool   `json:"has_password"`
+}
+
+// ShareAccess describes who opened a share link, for the access log
+type ShareAccess struct {
+	Format    string
+	IPAddress string
+	UserAgent string
+}
+
+// SharedNote is what a share link exposes of a note
+type SharedNote struct {
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	Tags      []string           `json:"tags"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type NoteShareService struct {
+	s     *store.Store
+	notes NoteServicer
+}
+
+// Compile time interface implementation check
+var _ NoteShareServicer = (*NoteShareService)(nil)
+
+func NewNoteShareService(store *store.Store, notes NoteServicer) *NoteShareService {
+	return &NoteShareService{s: store, notes: notes}
+}
+
+func (s *NoteShareService) CreateShare(ctx context.Context, workspaceID, noteID string, input CreateShareInput) (CreatedNoteShare, error) {
+	if input.CreatedBy == "" {
+		return CreatedNoteShare{}, ErrMissingNoteFields
+	}
+	var expiresAt pgtype.Timestamptz
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(time.Now()) {
+			return CreatedNoteShare{}, ErrInvalidShareData
+		}
+		expiresAt = pgtype.Timestamptz{Time: *input.ExpiresAt, Valid: true}
+	}
+	if len(input.Password) > maxSharePasswordLength {
+		return CreatedNoteShare{}, ErrInvalidShareData
+	}
+
+	note, err := s.editableNote(ctx, workspaceID, noteID, input.CreatedBy)
+	if err != nil {
+		return CreatedNoteShare{}, err
+	}
+
+	var passwordHash pgtype.Text
+	if input.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return CreatedNoteShare{}, fmt.Errorf("failed to hash share password: %w", err)
+		}
+		passwordHash = pgtype.Text{String: string(hash), Valid: true}
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return CreatedNoteShare{}, err
+	}
+
+	share, err := s.s.Queries.CreateNoteShare(ctx, models.CreateNoteShareParams{
+		WorkspaceID:  note.WorkspaceID,
+		NoteID:       note.ID,
+		TokenHash:    hashSecretToken(token),
+		TokenHint:    token[:6],
+		PasswordHash: passwordHash,
+		CreatedBy:    pgtype.Text{String: input.CreatedBy, Valid: true},
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return CreatedNoteShare{}, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return CreatedNoteShare{NoteShare: share, Token: token, HasPassword: passwordHash.Valid}, nil
+}
+
+func (s *NoteShareService) ListShares(ctx context.Context, workspaceID, noteID, userID string) ([]models.ListNoteSharesRow, error) {
+	note, err := s.notes.GetNote(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := s.s.Queries.ListNoteShares(ctx, note.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	if shares == nil {
+		shares = make([]models.ListNoteSharesRow, 0)
+	}
+	return shares, nil
+}
+
+// RevokeShare stops a share link from working. Revoking a link twice is
+// not an error.
+func (s *NoteShareService) RevokeShare(ctx context.Context, workspaceID, noteID, shareID, userID string) error {
+	sID, err := parseUUID(shareID)
+	if err != nil {
+		return ErrInvalidShareData
+	}
+	note, err := s.editableNote(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.s.Queries.RevokeNoteShare(ctx, models.RevokeNoteShareParams{
+		NoteID: note.ID,
+		ID:     sID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	if rows == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// ListShareAccesses returns the most recent attempts to open a share link
+func (s *NoteShareService) ListShareAccesses(ctx context.Context, workspaceID, noteID, shareID, userID string) ([]models.NoteShareAccess, error) {
+	sID, err := parseUUID(shareID)
+	if err != nil {
+		return nil, ErrInvalidShareData
+	}
+	note, err := s.notes.GetNote(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses, err := s.s.Queries.ListNoteShareAccesses(ctx, models.ListNoteShareAccessesParams{
+		NoteID:  note.ID,
+		ShareID: sID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share link accesses: %w", err)
+	}
+	if accesses == nil {
+		accesses = make([]models.NoteShareAccess, 0)
+	}
+	return accesses, nil
+}
+
+// OpenShare returns the note behind a share link, checking that the link is
+// still valid and that the password matches. Every attempt on a known link
+// is logged, whether or not it succeeds.
+func (s *NoteShareService) OpenShare(ctx context.Context, token, password string, access ShareAccess) (SharedNote, error) {
+	if token == "" {
+		return SharedNote{}, ErrShareNotFound
+	}
+	if access.Format != ShareFormatHTML && access.Format != ShareFormatMarkdown {
+		return SharedNote{}, ErrInvalidShareData
+	}
+
+	share, err := s.s.Queries.GetNoteShareByTokenHash(ctx, hashSecretToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SharedNote{}, ErrShareNotFound
+		}
+		return SharedNote{}, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	status, shareErr := checkShare(share, password)
+	if err := s.s.Queries.CreateNoteShareAccess(ctx, models.CreateNoteShareAccessParams{
+		ShareID:   share.ID,
+		Status:    status,
+		Format:    access.Format,
+		IpAddress: pgtype.Text{String: access.IPAddress, Valid: access.IPAddress != ""},
+		UserAgent: pgtype.Text{String: access.UserAgent, Valid: access.UserAgent != ""},
+	}); err != nil {
+		return SharedNote{}, fmt.Errorf("failed to log share link access: %w", err)
+	}
+	if shareErr != nil {
+		return SharedNote{}, shareErr
+	}
+
+	note, err := s.s.Queries.GetWorkspaceNote(ctx, models.GetWorkspaceNoteParams{
+		WorkspaceID: share.WorkspaceID,
+		ID:          share.NoteID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SharedNote{}, ErrShareNotFound
+		}
+		return SharedNote{}, fmt.Errorf("failed to get shared note: %w", err)
+	}
+
+	shared := SharedNote{
+		Title:     note.Title,
+		Content:   note.Content,
+		Tags:      note.Tags,
+		UpdatedAt: note.UpdatedAt,
+	}
+	if access.Format == ShareFormatHTML {
+		shared.Content, err = RenderMarkdown(note.Content)
+		if err != nil {
+			return SharedNote{}, err
+		}
+	}
+	return shared, nil
+}
+
+// editableNote returns the note if the user may edit it, which is needed to
+// create or revoke its share links
+func (s *NoteShareService) editableNote(ctx context.Context, workspaceID, noteID, userID string) (models.Note, error) {
+	note, err := s.notes.GetNote(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	access, err := s.notes.GetNoteAccess(ctx, workspaceID, noteID, userID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	if access.Role == NoteRoleViewer {
+		return models.Note{}, ErrNoteForbidden
+	}
+	return note, nil
+}
+
+// checkShare decides whether a share link may be opened, returning the
+// access log status along with the error to report
+func checkShare(share models.NoteShare, password string) (string, error) {
+	switch {
+	case share.RevokedAt.Valid:
+		return shareAccessRevoked, ErrShareRevoked
+	case share.ExpiresAt.Valid && !share.ExpiresAt.Time.After(time.Now()):
+		return shareAccessExpired, ErrShareExpired
+	case !share.PasswordHash.Valid:
+		return shareAccessOK, nil
+	case password == "":
+		return shareAccessPasswordRequired, ErrSharePasswordRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(share.PasswordHash.String), []byte(password)); err != nil {
+		return shareAccessWrongPassword, ErrShareWrongPassword
+	}
+	return shareAccessOK, nil
+}
+
+// newSecretToken returns a random URL-safe token for a share link or a
+// calendar feed
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Tokens carry 256 bits of randomness, so an unsalted hash is enough to
+// keep them out of the database
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
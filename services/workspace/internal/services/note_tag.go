@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var ErrTagExists = errors.New("tag already exists; merge the tags instead")
+
+type TagChangeResult struct {
+	Tag          string `json:"tag"`
+	NotesUpdated int    `json:"notes_updated"`
+}
+
+// ListTags returns the tags of the notes viewerID can see
+func (s *NoteService) ListTags(ctx context.Context, workspaceID, viewerID string) ([]models.ListWorkspaceTagsRow, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidNoteData
+	}
+
+	tags, err := s.s.Queries.ListWorkspaceTags(ctx, models.ListWorkspaceTagsParams{
+		WorkspaceID: wsID,
+		ViewerID:    viewerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if tags == nil {
+		tags = make([]models.ListWorkspaceTagsRow, 0)
+	}
+	return tags, nil
+}
+
+// RenameTag renames a tag on every note in the workspace that editorID can
+// edit. Renaming onto
+// another tag that is already in use fails with ErrTagExists; changing only
+// the case of a tag is allowed.
+func (s *NoteService) RenameTag(ctx context.Context, workspaceID, from, to, editorID string) (TagChangeResult, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return TagChangeResult{}, ErrMissingNoteFields
+	}
+	if !strings.EqualFold(from, to) {
+		tags, err := s.ListTags(ctx, workspaceID, editorID)
+		if err != nil {
+			return TagChangeResult{}, err
+		}
+		for _, tag := range tags {
+			if strings.EqualFold(tag.Tag, to) {
+				return TagChangeResult{}, ErrTagExists
+			}
+		}
+	}
+	return s.replaceTags(ctx, workspaceID, []string{from}, to, editorID)
+}
+
+// MergeTags replaces each of tags with into on every note in the workspace
+// that editorID can edit
+func (s *NoteService) MergeTags(ctx context.Context, workspaceID string, tags []string, into, editorID string) (TagChangeResult, error) {
+	into = strings.TrimSpace(into)
+	tags = normalizeTags(tags)
+	if into == "" || len(tags) == 0 {
+		return TagChangeResult{}, ErrMissingNoteFields
+	}
+	return s.replaceTags(ctx, workspaceID, tags, into, editorID)
+}
+
+// replaceTags swaps the tags in from for to on every note carrying them
+// that editorID can edit, in one transaction. Each changed note gets a new version and revision.
+func (s *NoteService) replaceTags(ctx context.Context, workspaceID string, from []string, to, editorID string) (TagChangeResult, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return TagChangeResult{}, ErrInvalidNoteData
+	}
+
+	// Notes already tagged with another spelling of to are rewritten too,
+	// so every note ends up with the same spelling
+	lowered := []string{strings.ToLower(to)}
+	for _, tag := range from {
+		lowered = append(lowered, strings.ToLower(tag))
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return TagChangeResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	notes, err := queries.LockNotesWithTags(ctx, models.LockNotesWithTagsParams{
+		WorkspaceID: wsID,
+		Tags:        lowered,
+		EditorID:    editorID,
+	})
+	if err != nil {
+		return TagChangeResult{}, fmt.Errorf("failed to get tagged notes: %w", err)
+	}
+
+	type change struct {
+		note  models.Note
+		added []string
+	}
+	var changes []change
+	for _, note := range notes {
+		tags := make([]string, len(note.Tags))
+		for i, tag := range note.Tags {
+			tags[i] = tag
+			for _, lower := range lowered {
+				if strings.ToLower(tag) == lower {
+					tags[i] = to
+					break
+				}
+			}
+		}
+		tags = normalizeTags(tags)
+		if slices.Equal(tags, note.Tags) {
+			continue
+		}
+
+		updated, err := queries.UpdateNote(ctx, models.UpdateNoteParams{
+			WorkspaceID: note.WorkspaceID,
+			ID:          note.ID,
+			Title:       note.Title,
+			Content:     note.Content,
+			Tags:        tags,
+			Version:     note.Version,
+		})
+		if err != nil {
+			return TagChangeResult{}, fmt.Errorf("failed to update note tags: %w", err)
+		}
+		if err := recordNoteRevision(ctx, queries, updated, editorID); err != nil {
+			return TagChangeResult{}, err
+		}
+		changes = append(changes, change{note: updated, added: addedTags(note.Tags, updated.Tags)})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return TagChangeResult{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, c := range changes {
+		s.emitTagged(ctx, c.note, c.added)
+	}
+	return TagChangeResult{Tag: to, NotesUpdated: len(changes)}, nil
+}
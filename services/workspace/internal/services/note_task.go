@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	// checklistItemPattern matches a Markdown task list item such as
+	// "- [ ] Send the agenda @alice due:2026-11-01"
+	checklistItemPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*)$`)
+	taskMentionPattern   = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9_.-]+)`)
+	taskDuePattern       = regexp.MustCompile(`(?:^|\s)due:(\d{4}-\d{2}-\d{2})\b`)
+)
+
+// noteTaskBlockPrefix starts the block ids given to extracted items
+const noteTaskBlockPrefix = "task-"
+
+// checklistItem is one task list item of a note
+type checklistItem struct {
+	checked bool
+	// text is the item without its checkbox or block id
+	text    string
+	blockID string
+}
+
+// noteTaskLink is a task created from a note that still has to be linked,
+// since the note may not exist yet
+type noteTaskLink struct {
+	taskID  pgtype.UUID
+	blockID string
+}
+
+func parseChecklistItem(line string) (checklistItem, bool) {
+	m := checklistItemPattern.FindStringSubmatch(line)
+	if m == nil {
+		return checklistItem{}, false
+	}
+	item := checklistItem{checked: m[2] != " ", text: m[4]}
+	if b := blockIDPattern.FindStringSubmatchIndex(item.text); b != nil {
+		item.blockID = item.text[b[2]:b[3]]
+		item.text = item.text[:b[0]]
+	}
+	item.text = strings.TrimSpace(item.text)
+	return item, true
+}
+
+// checklistStates returns whether each block id'd item is checked
+func checklistStates(content string) map[string]bool {
+	states := make(map[string]bool)
+	mapProseLines(content, func(line string) string {
+		if item, ok := parseChecklistItem(line); ok && item.blockID != "" {
+			if _, seen := states[item.blockID]; !seen {
+				states[item.blockID] = item.checked
+			}
+		}
+		return line
+	})
+	return states
+}
+
+// setChecklistItem ticks or unticks the item carrying the block id
+func setChecklistItem(content, blockID string, checked bool) string {
+	mark := " "
+	if checked {
+		mark = "x"
+	}
+	done := false
+	return mapProseLines(content, func(line string) string {
+		if done {
+			return line
+		}
+		item, ok := parseChecklistItem(line)
+		if !ok || item.blockID != blockID {
+			return line
+		}
+		done = true
+		m := checklistItemPattern.FindStringSubmatchIndex(line)
+		return line[:m[4]] + mark + line[m[5]:]
+	})
+}
+
+// extractNoteTasks creates a task for every unchecked item of the content
+// that isn't linked to one yet. The assignee comes from the first @username
+// naming a workspace member, and the due date from due:YYYY-MM-DD. Items
+// are given a block id when they lack one, and the returned content carries
+// those. noteID is invalid for a note that is still being created.
+func extractNoteTasks(ctx context.Context, q *models.Queries, workspaceID, noteID pgtype.UUID, content string) (string, []models.Task, []noteTaskLink, error) {
+	linked := make(map[string]bool)
+	if noteID.Valid {
+		links, err := q.ListNoteTasks(ctx, noteID)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to list note tasks: %w", err)
+		}
+		for _, link := range links {
+			linked[link.BlockID] = true
+		}
+	}
+
+	var usernames []string
+	mapProseLines(content, func(line string) string {
+		if item, ok := parseChecklistItem(line); ok && !item.checked && !linked[item.blockID] {
+			for _, m := range taskMentionPattern.FindAllStringSubmatch(item.text, -1) {
+				usernames = append(usernames, m[1])
+			}
+		}
+		return line
+	})
+	members := make(map[string]string)
+	if len(usernames) > 0 {
+		users, err := q.ListWorkspaceUsersByUsername(ctx, models.ListWorkspaceUsersByUsernameParams{
+			WorkspaceID: workspaceID,
+			Usernames:   usernames,
+		})
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to resolve mentions: %w", err)
+		}
+		for _, user := range users {
+			members[user.Username] = user.ID
+		}
+	}
+
+	blocks := noteBlocks(content)
+	var (
+		tasks    []models.Task
+		newLinks []noteTaskLink
+		err      error
+	)
+	content = mapProseLines(content, func(line string) string {
+		if err != nil {
+			return line
+		}
+		item, ok := parseChecklistItem(line)
+		if !ok || item.checked || linked[item.blockID] {
+			return line
+		}
+		title, assigneeID, dueDate := parseTaskItem(item.text, members)
+		if title == "" {
+			return line
+		}
+
+		blockID := item.blockID
+		if blockID == "" {
+			if blockID, err = newNoteTaskBlockID(blocks); err != nil {
+				return line
+			}
+			blocks[blockID] = item.text
+			line = strings.TrimRight(line, " \t") + " ^" + blockID
+		}
+
+		var task models.Task
+		task, err = q.CreateNewTask(ctx, models.CreateNewTaskParams{
+			WorkspaceID: workspaceID,
+			Title:       title,
+			AssigneeID:  pgtype.Text{String: assigneeID, Valid: assigneeID != ""},
+			Status:      models.TaskStatusToDo,
+			Priority:    models.TaskPriorityMedium,
+			DueDate:     toTimestamptz(dueDate),
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to create task: %w", err)
+			return line
+		}
+		linked[blockID] = true
+		tasks = append(tasks, task)
+		newLinks = append(newLinks, noteTaskLink{taskID: task.ID, blockID: blockID})
+		return line
+	})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return content, tasks, newLinks, nil
+}
+
+// parseTaskItem splits an item into the task's title, assignee and due
+// date. Mentions of non-members and malformed dates stay in the title.
+func parseTaskItem(text string, members map[string]string) (string, string, *time.Time) {
+	var assigneeID string
+	text = taskMentionPattern.ReplaceAllStringFunc(text, func(match string) string {
+		username := strings.TrimPrefix(strings.TrimSpace(match), "@")
+		id, ok := members[username]
+		if !ok || assigneeID != "" {
+			return match
+		}
+		assigneeID = id
+		return ""
+	})
+
+	var dueDate *time.Time
+	text = taskDuePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if dueDate != nil {
+			return match
+		}
+		day, err := time.Parse(time.DateOnly, strings.TrimPrefix(strings.TrimSpace(match), "due:"))
+		if err != nil {
+			return match
+		}
+		dueDate = &day
+		return ""
+	})
+
+	return strings.Join(strings.Fields(text), " "), assigneeID, dueDate
+}
+
+func newNoteTaskBlockID(taken map[string]string) (string, error) {
+	for {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate block id: %w", err)
+		}
+		id := noteTaskBlockPrefix + hex.EncodeToString(b)
+		if _, ok := taken[id]; !ok {
+			return id, nil
+		}
+	}
+}
+
+func linkNoteTasks(ctx context.Context, q *models.Queries, noteID pgtype.UUID, links []noteTaskLink) error {
+	for _, link := range links {
+		if err := q.CreateNoteTask(ctx, models.CreateNoteTaskParams{
+			TaskID:  link.taskID,
+			NoteID:  noteID,
+			BlockID: link.blockID,
+		}); err != nil {
+			return fmt.Errorf("failed to link note task: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncNoteTaskStatus completes the tasks whose items were ticked between
+// two versions of a note, and reopens those that were unticked. It returns
+// the tasks it changed.
+func syncNoteTaskStatus(ctx context.Context, q *models.Queries, noteID pgtype.UUID, from, to string) ([]models.Task, error) {
+	links, err := q.ListNoteTasks(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list note tasks: %w", err)
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	before, after := checklistStates(from), checklistStates(to)
+	var changed []models.Task
+	for _, link := range links {
+		checked, ok := after[link.BlockID]
+		if !ok {
+			continue
+		}
+		if was, ok := before[link.BlockID]; ok && was == checked {
+			continue
+		}
+		done := link.Status == models.TaskStatusDone
+		if checked == done {
+			continue
+		}
+		status := models.TaskStatusToDo
+		if checked {
+			status = models.TaskStatusDone
+		}
+
+		task, err := setTaskStatus(ctx, q, link.TaskID, status)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, task)
+	}
+	return changed, nil
+}
+
+func setTaskStatus(ctx context.Context, q *models.Queries, taskID pgtype.UUID, status models.TaskStatus) (models.Task, error) {
+	previous, err := q.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to get task: %w", err)
+	}
+	task, err := q.UpdateTask(ctx, models.UpdateTaskParams{
+		ID:          previous.TaskID,
+		Title:       previous.Title,
+		Description: previous.Description,
+		AssigneeID:  previous.AssigneeID,
+		Status:      status,
+		Priority:    previous.Priority,
+		DueDate:     previous.DueDate,
+		StartDate:   previous.StartDate,
+	})
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to update task: %w", err)
+	}
+	if err := recordCycleStatusChange(ctx, q, task, previous.Status); err != nil {
+		return models.Task{}, err
+	}
+	return task, nil
+}
+
+// syncTaskChecklistItem ticks the item a task was extracted from once the
+// task is done, and unticks it when the task is reopened
+func syncTaskChecklistItem(ctx context.Context, q *models.Queries, task models.Task, previous models.TaskStatus) error {
+	done := task.Status == models.TaskStatusDone
+	if done == (previous == models.TaskStatusDone) {
+		return nil
+	}
+
+	link, err := q.GetTaskNote(ctx, task.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get task note: %w", err)
+	}
+	note, err := q.LockWorkspaceNoteForUpdate(ctx, models.LockWorkspaceNoteForUpdateParams{
+		WorkspaceID: link.WorkspaceID,
+		ID:          link.NoteID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get task note: %w", err)
+	}
+
+	content := setChecklistItem(note.Content, link.BlockID, done)
+	if content == note.Content {
+		return nil
+	}
+	updated, err := q.UpdateNote(ctx, models.UpdateNoteParams{
+		WorkspaceID: note.WorkspaceID,
+		ID:          note.ID,
+		Title:       note.Title,
+		Content:     content,
+		Tags:        note.Tags,
+		Version:     note.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update task note: %w", err)
+	}
+	if err := recordNoteRevision(ctx, q, updated, ""); err != nil {
+		return err
+	}
+	return remapNoteComments(ctx, q, updated.ID, note.Content, updated.Content)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTaskItem(t *testing.T) {
+	members := map[string]string{"alice": "user-alice", "bob": "user-bob"}
+	tests := []struct {
+		text     string
+		title    string
+		assignee string
+		due      string
+	}{
+		{"Send the agenda @alice due:2026-11-01", "Send the agenda", "user-alice", "2026-11-01"},
+		{"@bob review the draft", "review the draft", "user-bob", ""},
+		{"Ask @carol about it", "Ask @carol about it", "", ""},
+		// Only the first member is assigned
+		{"Pair @alice @bob", "Pair @bob", "user-alice", ""},
+		{"Mail bob@example.com", "Mail bob@example.com", "", ""},
+		{"Ship due:2026-13-01", "Ship due:2026-13-01", "", ""},
+		{"Ship due:2026-11-01 due:2026-12-01", "Ship due:2026-12-01", "", "2026-11-01"},
+		{"Plan  the   offsite", "Plan the offsite", "", ""},
+	}
+	for _, tt := range tests {
+		title, assignee, due := parseTaskItem(tt.text, members)
+		gotDue := ""
+		if due != nil {
+			gotDue = due.Format(time.DateOnly)
+		}
+		if title != tt.title || assignee != tt.assignee || gotDue != tt.due {
+			t.Errorf("parseTaskItem(%q) got %q, %q, %q want %q, %q, %q", tt.text, title, assignee, gotDue, tt.title, tt.assignee, tt.due)
+		}
+	}
+}
+
+func TestSetChecklistItem(t *testing.T) {
+	content := strings.Join([]string{
+		"- [ ] One ^task-1",
+		"- [x] Two ^task-2",
+		"```",
+		"- [ ] Code ^task-3",
+		"```",
+		"  * [ ] Nested ^task-4",
+		"- [ ] Again ^task-1",
+	}, "\n")
+	tests := []struct {
+		blockID string
+		checked bool
+		line    int
+		want    string
+	}{
+		{"task-1", true, 0, "- [x] One ^task-1"},
+		{"task-2", false, 1, "- [ ] Two ^task-2"},
+		{"task-3", true, 3, "- [ ] Code ^task-3"},
+		{"task-4", true, 5, "  * [x] Nested ^task-4"},
+		// Only the first item with the id changes
+		{"task-1", true, 6, "- [ ] Again ^task-1"},
+	}
+	for _, tt := range tests {
+		got := strings.Split(setChecklistItem(content, tt.blockID, tt.checked), "\n")[tt.line]
+		if got != tt.want {
+			t.Errorf("setChecklistItem(%s, %v) line %d got %q want %q", tt.blockID, tt.checked, tt.line+1, got, tt.want)
+		}
+	}
+	if got := setChecklistItem(content, "task-9", true); got != content {
+		t.Errorf("setChecklistItem(task-9) got %q want the content unchanged", got)
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var (
+	ErrParentNoteNotFound = errors.New("parent note not found")
+	ErrNoteCycle          = errors.New("a note cannot be moved under itself or its descendants")
+)
+
+// What happens to a note's children when it is deleted
+const (
+	NoteDeleteReparent = "reparent"
+	NoteDeleteCascade  = "cascade"
+)
+
+type MoveNoteInput struct {
+	// EditorID must be allowed to edit the note
+	EditorID string
+	// ParentID is the new parent; empty moves the note to the top level
+	ParentID string
+	// Position is the index among the new siblings the editor can see; nil
+	// appends
+	Position *int
+}
+
+type DeleteNoteInput struct {
+	// EditorID must be allowed to edit the note
+	EditorID string
+	// ExpectedVersion, when set, makes the delete conditional on the note
+	// still being at that version
+	ExpectedVersion *int32
+	// Children is NoteDeleteReparent (the default), which moves children up
+	// to the deleted note's parent, or NoteDeleteCascade, which deletes the
+	// whole subtree
+	Children string
+}
+
+// NoteTreeNode is a note without its content, nested under its parent
+type NoteTreeNode struct {
+	ID        pgtype.UUID        `json:"id"`
+	ParentID  pgtype.UUID        `json:"parent_id"`
+	Title     string             `json:"title"`
+	Tags      []string           `json:"tags"`
+	Position  int32              `json:"position"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Children  []*NoteTreeNode    `json:"children"`
+}
+
+// ListNoteTree returns the notes the viewer can see as a tree of top-level
+// notes. Notes under a parent the viewer cannot see are shown at the top
+// level.
+func (s *NoteService) ListNoteTree(ctx context.Context, workspaceID, viewerID string) ([]*NoteTreeNode, error) {
+	notes, err := s.ListNotes(ctx, workspaceID, viewerID, NoteTagFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return buildNoteTree(notes), nil
+}
+
+// ListNoteChildren returns a note's children in order, or the top-level
+// notes when parentID is empty.
+func (s *NoteService) ListNoteChildren(ctx context.Context, workspaceID, parentID, viewerID string) ([]models.ListNoteChildrenRow, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidNoteData
+	}
+	var parent pgtype.UUID
+	if parentID != "" {
+		note, err := s.GetNote(ctx, workspaceID, parentID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		parent = note.ID
+	}
+	return listNoteChildren(ctx, s.s.Queries, wsID, parent, viewerID)
+}
+
+// GetBreadcrumbs returns the path from the top level down to the note,
+// skipping ancestors the viewer cannot see
+func (s *NoteService) GetBreadcrumbs(ctx context.Context, workspaceID, noteID, viewerID string) ([]models.ListNoteAncestorsRow, error) {
+	note, err := s.GetNote(ctx, workspaceID, noteID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	crumbs, err := s.s.Queries.ListNoteAncestors(ctx, models.ListNoteAncestorsParams{
+		WorkspaceID: note.WorkspaceID,
+		ID:          note.ID,
+		ViewerID:    viewerID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note ancestors: %w", err)
+	}
+	if crumbs == nil {
+		crumbs = make([]models.ListNoteAncestorsRow, 0)
+	}
+	return crumbs, nil
+}
+
+// MoveNote moves a note, along with its subtree, under a new parent
+func (s *NoteService) MoveNote(ctx context.Context, workspaceID, noteID string, input MoveNoteInput) (models.Note, error) {
+	note, err := s.getNoteAs(ctx, workspaceID, noteID, input.EditorID, true)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Note{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	// The cycle check only holds while no other move runs
+	if err := queries.LockNoteTree(ctx, note.WorkspaceID); err != nil {
+		return models.Note{}, fmt.Errorf("failed to lock note tree: %w", err)
+	}
+	parent, err := resolveParentNote(ctx, queries, note.WorkspaceID, input.ParentID, input.EditorID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	if parent.Valid {
+		cyclic, err := queries.IsNoteInSubtree(ctx, models.IsNoteInSubtreeParams{
+			CandidateID: parent,
+			RootID:      note.ID,
+		})
+		if err != nil {
+			return models.Note{}, fmt.Errorf("failed to check note hierarchy: %w", err)
+		}
+		if cyclic {
+			return models.Note{}, ErrNoteCycle
+		}
+	}
+
+	siblings, err := listNoteChildren(ctx, queries, note.WorkspaceID, parent, "")
+	if err != nil {
+		return models.Note{}, err
+	}
+	visible, err := listNoteChildren(ctx, queries, note.WorkspaceID, parent, input.EditorID)
+	if err != nil {
+		return models.Note{}, err
+	}
+	order := make([]pgtype.UUID, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		if sibling.ID != note.ID {
+			order = append(order, sibling.ID)
+		}
+	}
+	// Position counts the siblings the editor can see, so the note goes in
+	// front of the visible sibling currently at that index
+	position := len(order)
+	if input.Position != nil {
+		index := max(*input.Position, 0)
+		for _, sibling := range visible {
+			if sibling.ID == note.ID {
+				continue
+			}
+			if index == 0 {
+				position = slices.Index(order, sibling.ID)
+				break
+			}
+			index--
+		}
+	}
+	order = slices.Insert(order, position, note.ID)
+
+	if err := queries.SetNoteParent(ctx, models.SetNoteParentParams{
+		ParentID:    parent,
+		WorkspaceID: note.WorkspaceID,
+		ID:          note.ID,
+	}); err != nil {
+		return models.Note{}, fmt.Errorf("failed to move note: %w", err)
+	}
+	if _, err := queries.ReorderNotes(ctx, models.ReorderNotesParams{
+		Ids:         order,
+		WorkspaceID: note.WorkspaceID,
+	}); err != nil {
+		return models.Note{}, fmt.Errorf("failed to reorder notes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Note{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getNote(ctx, workspaceID, noteID)
+}
+
+// ReorderNotes sets the order of a note's children, or of the top-level
+// notes when parentID is empty. noteIDs must list every sibling the editor
+// can see once; siblings hidden from the editor keep their places.
+func (s *NoteService) ReorderNotes(ctx context.Context, workspaceID, parentID, editorID string, noteIDs []string) ([]models.ListNoteChildrenRow, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidNoteData
+	}
+
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	parent, err := resolveParentNote(ctx, queries, wsID, parentID, editorID)
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := listNoteChildren(ctx, queries, wsID, parent, "")
+	if err != nil {
+		return nil, err
+	}
+	visible, err := listNoteChildren(ctx, queries, wsID, parent, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if len(noteIDs) != len(visible) {
+		return nil, ErrInvalidNoteData
+	}
+	current := make(map[pgtype.UUID]bool, len(visible))
+	for _, sibling := range visible {
+		current[sibling.ID] = true
+	}
+	requested := make([]pgtype.UUID, 0, len(noteIDs))
+	for _, id := range noteIDs {
+		nID, err := parseUUID(id)
+		if err != nil || !current[nID] {
+			return nil, ErrInvalidNoteData
+		}
+		// Each sibling may only appear once
+		current[nID] = false
+		requested = append(requested, nID)
+	}
+	// Fill the slots of the visible siblings in the requested order
+	isVisible := make(map[pgtype.UUID]bool, len(visible))
+	for _, sibling := range visible {
+		isVisible[sibling.ID] = true
+	}
+	order := make([]pgtype.UUID, 0, len(siblings))
+	for _, sibling := range siblings {
+		if isVisible[sibling.ID] {
+			sibling.ID, requested = requested[0], requested[1:]
+		}
+		order = append(order, sibling.ID)
+	}
+
+	if _, err := queries.ReorderNotes(ctx, models.ReorderNotesParams{
+		Ids:         order,
+		WorkspaceID: wsID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reorder notes: %w", err)
+	}
+	children, err := listNoteChildren(ctx, queries, wsID, parent, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return children, nil
+}
+
+// resolveParentNote checks that parentID is a note in the workspace that
+// userID can see. An empty parentID means the top level and resolves to a
+// null UUID.
+func resolveParentNote(ctx context.Context, q *models.Queries, workspaceID pgtype.UUID, parentID, userID string) (pgtype.UUID, error) {
+	if parentID == "" {
+		return pgtype.UUID{}, nil
+	}
+	pID, err := parseUUID(parentID)
+	if err != nil {
+		return pgtype.UUID{}, ErrInvalidNoteData
+	}
+	parent, err := q.GetWorkspaceNote(ctx, models.GetWorkspaceNoteParams{
+		WorkspaceID: workspaceID,
+		ID:          pID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, ErrParentNoteNotFound
+		}
+		return pgtype.UUID{}, fmt.Errorf("failed to get parent note: %w", err)
+	}
+	role, err := noteRole(ctx, q, parent, userID)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if role == "" {
+		return pgtype.UUID{}, ErrParentNoteNotFound
+	}
+	return parent.ID, nil
+}
+
+// listNoteChildren lists the children viewerID can see, or all of them when
+// viewerID is empty
+func listNoteChildren(ctx context.Context, q *models.Queries, workspaceID, parentID pgtype.UUID, viewerID string) ([]models.ListNoteChildrenRow, error) {
+	children, err := q.ListNoteChildren(ctx, models.ListNoteChildrenParams{
+		WorkspaceID: workspaceID,
+		ParentID:    parentID,
+		ViewerID:    pgtype.Text{String: viewerID, Valid: viewerID != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list note children: %w", err)
+	}
+	if children == nil {
+		children = make([]models.ListNoteChildrenRow, 0)
+	}
+	return children, nil
+}
+
+// buildNoteTree nests notes under their parents, ordering siblings by
+// position. Notes whose parent is missing from the list, or whose parents
+// lead back to them, are kept at the top level.
+func buildNoteTree(notes []models.Note) []*NoteTreeNode {
+	nodes := make(map[pgtype.UUID]*NoteTreeNode, len(notes))
+	parents := make(map[pgtype.UUID]pgtype.UUID, len(notes))
+	for _, note := range notes {
+		if note.ParentID.Valid {
+			parents[note.ID] = note.ParentID
+		}
+		nodes[note.ID] = &NoteTreeNode{
+			ID:        note.ID,
+			ParentID:  note.ParentID,
+			Title:     note.Title,
+			Tags:      note.Tags,
+			Position:  note.Position,
+			UpdatedAt: note.UpdatedAt,
+			Children:  make([]*NoteTreeNode, 0),
+		}
+	}
+
+	roots := make([]*NoteTreeNode, 0)
+	for _, note := range notes {
+		node := nodes[note.ID]
+		if parent, ok := nodes[note.ParentID]; ok && note.ParentID.Valid && !inParentCycle(parents, note.ID) {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	byPosition := func(a, b *NoteTreeNode) int {
+		return int(a.Position) - int(b.Position)
+	}
+	slices.SortStableFunc(roots, byPosition)
+	for _, node := range nodes {
+		slices.SortStableFunc(node.Children, byPosition)
+	}
+	return roots
+}
+
+// inParentCycle reports whether following parents up from the note leads
+// back to it
+func inParentCycle(parents map[pgtype.UUID]pgtype.UUID, id pgtype.UUID) bool {
+	next, ok := parents[id]
+	for range len(parents) {
+		if !ok {
+			return false
+		}
+		if next == id {
+			return true
+		}
+		next, ok = parents[next]
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+// treeNote returns note n under parent at position; parent 0 is the top
+// level
+func treeNote(n, parent byte, position int32) models.Note {
+	note := models.Note{ID: testUUID(n), Title: fmt.Sprintf("note %d", n), Position: position}
+	if parent != 0 {
+		note.ParentID = testUUID(parent)
+	}
+	return note
+}
+
+// formatNoteTree renders a tree as "1(2 3) 4"
+func formatNoteTree(nodes []*NoteTreeNode) string {
+	out := make([]string, len(nodes))
+	for i, node := range nodes {
+		out[i] = fmt.Sprint(node.ID.Bytes[15])
+		if len(node.Children) > 0 {
+			out[i] += "(" + formatNoteTree(node.Children) + ")"
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func TestBuildNoteTree(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes []models.Note
+		want  string
+	}{
+		{"empty", nil, ""},
+		{"nested", []models.Note{treeNote(1, 0, 0), treeNote(2, 1, 0), treeNote(3, 2, 0), treeNote(4, 0, 1)}, "1(2(3)) 4"},
+		{"by position", []models.Note{treeNote(1, 0, 1), treeNote(2, 0, 0), treeNote(3, 1, 2), treeNote(4, 1, 1)}, "2 1(4 3)"},
+		{"children before parents", []models.Note{treeNote(3, 2, 0), treeNote(2, 1, 0), treeNote(1, 0, 0)}, "1(2(3))"},
+		{"missing parent", []models.Note{treeNote(1, 0, 0), treeNote(2, 9, 1)}, "1 2"},
+		{"own parent", []models.Note{treeNote(1, 1, 0)}, "1"},
+		{"parent cycle", []models.Note{treeNote(1, 3, 0), treeNote(2, 1, 1), treeNote(3, 2, 2), treeNote(4, 3, 0)}, "1 2 3(4)"},
+	}
+	for _, tt := range tests {
+		if got := formatNoteTree(buildNoteTree(tt.notes)); got != tt.want {
+			t.Errorf("%s: got %q want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInParentCycle(t *testing.T) {
+	parents := map[pgtype.UUID]pgtype.UUID{
+		testUUID(1): testUUID(2),
+		testUUID(2): testUUID(3),
+		testUUID(3): testUUID(1),
+		testUUID(4): testUUID(1),
+		testUUID(5): testUUID(9),
+	}
+	tests := []struct {
+		note byte
+		want bool
+	}{
+		{1, true},
+		{3, true},
+		// Under a cycle but not part of it
+		{4, false},
+		{5, false},
+		{6, false},
+	}
+	for _, tt := range tests {
+		if got := inParentCycle(parents, testUUID(tt.note)); got != tt.want {
+			t.Errorf("inParentCycle(%d) got %v want %v", tt.note, got, tt.want)
+		}
+	}
+}
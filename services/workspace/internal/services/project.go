@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+// Project errors
+var (
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrMilestoneNotFound  = errors.New("milestone not found")
+	ErrInvalidProjectData = errors.New("invalid project data")
+)
+
+var projectStatuses = []models.ProjectStatus{
+	models.ProjectStatusPlanned,
+	models.ProjectStatusActive,
+	models.ProjectStatusPaused,
+	models.ProjectStatusCompleted,
+	models.ProjectStatusCancelled,
+}
+
+type ProjectInput struct {
+	Name        string
+	Description string
+	Status      string
+	LeadID      string
+	TargetDate  *time.Time
+}
+
+type UpdateProjectInput struct {
+	Name        *string
+	Description *string
+	Status      *string
+	LeadID      *string
+	TargetDate  *time.Time
+	// ClearTargetDate removes the target date; TargetDate is ignored
+	ClearTargetDate bool
+}
+
+type MilestoneInput struct {
+	Name        string
+	Description string
+	TargetDate  *time.Time
+}
+
+type UpdateMilestoneInput struct {
+	Name            *string
+	Description     *string
+	TargetDate      *time.Time
+	ClearTargetDate bool
+}
+
+// ProjectProgress is a rollup of task statuses. Percent is the share of
+// tasks that are done.
+type ProjectProgress struct {
+	Total     int64            `json:"total"`
+	Completed int64            `json:"completed"`
+	Percent   float64          `json:"percent"`
+	ByStatus  map[string]int64 `json:"by_status"`
+}
+
+type ProjectSummary struct {
+	Project  models.Project  `json:"project"`
+	Progress ProjectProgress `json:"progress"`
+}
+
+type MilestoneSummary struct {
+	Milestone models.Milestone `json:"milestone"`
+	Progress  ProjectProgress  `json:"progress"`
+}
+
+type ProjectDetail struct {
+	Project    models.Project     `json:"project"`
+	Progress   ProjectProgress    `json:"progress"`
+	Milestones []MilestoneSummary `json:"milestones"`
+}
+
+type ProjectServicer interface {
+	CreateProject(ctx context.Context, workspaceID string, input ProjectInput) (models.Project, error)
+	ListProjects(ctx context.Context, workspaceID string) ([]ProjectSummary, error)
+	GetProject(ctx context.Context, workspaceID, projectID string) (ProjectDetail, error)
+	UpdateProject(ctx context.Context, workspaceID, projectID string, input UpdateProjectInput) (models.Project, error)
+	DeleteProject(ctx context.Context, workspaceID, projectID string) error
+	CreateMilestone(ctx context.Context, workspaceID, projectID string, input MilestoneInput) (models.Milestone, error)
+	UpdateMilestone(ctx context.Context, workspaceID, projectID, milestoneID string, input UpdateMilestoneInput) (models.Milestone, error)
+	DeleteMilestone(ctx context.Context, workspaceID, projectID, milestoneID string) error
+	ListProjectTasks(ctx context.Context, workspaceID, projectID string) ([]models.GetTasksByProjectRow, error)
+	AssignTask(ctx context.Context, taskID, projectID, milestoneID string) (models.Task, error)
+}
+
+type ProjectService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ ProjectServicer = (*ProjectService)(nil)
+
+func NewProjectService(store *store.Store) *ProjectService {
+	return &ProjectService{s: store}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, workspaceID string, input ProjectInput) (models.Project, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Project{}, ErrInvalidProjectData
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return models.Project{}, ErrInvalidProjectData
+	}
+	status := models.ProjectStatusPlanned
+	if input.Status != "" {
+		status = models.ProjectStatus(input.Status)
+	}
+	if !slices.Contains(projectStatuses, status) {
+		return models.Project{}, ErrInvalidProjectData
+	}
+	if err := s.checkLead(ctx, wsID, input.LeadID); err != nil {
+		return models.Project{}, err
+	}
+
+	project, err := s.s.Queries.CreateProject(ctx, models.CreateProjectParams{
+		WorkspaceID: wsID,
+		Name:        name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		Status:      status,
+		LeadID:      pgtype.Text{String: input.LeadID, Valid: input.LeadID != ""},
+		TargetDate:  toDate(input.TargetDate),
+	})
+	if err != nil {
+		return models.Project{}, fmt.Errorf("failed to create project: %w", err)
+	}
+	return project, nil
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, workspaceID string) ([]ProjectSummary, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidProjectData
+	}
+
+	projects, err := s.s.Queries.ListWorkspaceProjects(ctx, wsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	counts, err := s.s.Queries.GetWorkspaceProjectTaskStatusCounts(ctx, wsID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count project tasks: %w", err)
+	}
+
+	progress := make(map[pgtype.UUID]*ProjectProgress)
+	for _, row := range counts {
+		p, ok := progress[row.ProjectID]
+		if !ok {
+			p = newProjectProgress()
+			progress[row.ProjectID] = p
+		}
+		p.add(row.Status, row.TaskCount)
+	}
+
+	summaries := make([]ProjectSummary, 0, len(projects))
+	for _, project := range projects {
+		p, ok := progress[project.ID]
+		if !ok {
+			p = newProjectProgress()
+		}
+		summaries = append(summaries, ProjectSummary{Project: project, Progress: *p})
+	}
+	return summaries, nil
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, workspaceID, projectID string) (ProjectDetail, error) {
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return ProjectDetail{}, err
+	}
+
+	milestones, err := s.s.Queries.ListProjectMilestones(ctx, project.ID)
+	if err != nil {
+		return ProjectDetail{}, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	counts, err := s.s.Queries.GetProjectTaskStatusCounts(ctx, project.ID)
+	if err != nil {
+		return ProjectDetail{}, fmt.Errorf("failed to count project tasks: %w", err)
+	}
+
+	total := newProjectProgress()
+	byMilestone := make(map[pgtype.UUID]*ProjectProgress)
+	for _, row := range counts {
+		total.add(row.Status, row.TaskCount)
+		if !row.MilestoneID.Valid {
+			continue
+		}
+		p, ok := byMilestone[row.MilestoneID]
+		if !ok {
+			p = newProjectProgress()
+			byMilestone[row.MilestoneID] = p
+		}
+		p.add(row.Status, row.TaskCount)
+	}
+
+	detail := ProjectDetail{
+		Project:    project,
+		Progress:   *total,
+		Milestones: make([]MilestoneSummary, 0, len(milestones)),
+	}
+	for _, milestone := range milestones {
+		p, ok := byMilestone[milestone.ID]
+		if !ok {
+			p = newProjectProgress()
+		}
+		detail.Milestones = append(detail.Milestones, MilestoneSummary{Milestone: milestone, Progress: *p})
+	}
+	return detail, nil
+}
+
+func (s *ProjectService) UpdateProject(ctx context.Context, workspaceID, projectID string, input UpdateProjectInput) (models.Project, error) {
+	current, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return models.Project{}, err
+	}
+
+	params := models.UpdateProjectParams{
+		WorkspaceID: current.WorkspaceID,
+		ID:          current.ID,
+		Name:        current.Name,
+		Description: current.Description,
+		Status:      current.Status,
+		LeadID:      current.LeadID,
+		TargetDate:  current.TargetDate,
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return models.Project{}, ErrInvalidProjectData
+		}
+		params.Name = name
+	}
+	if input.Description != nil {
+		params.Description = pgtype.Text{String: *input.Description, Valid: *input.Description != ""}
+	}
+	if input.Status != nil {
+		params.Status = models.ProjectStatus(*input.Status)
+		if !slices.Contains(projectStatuses, params.Status) {
+			return models.Project{}, ErrInvalidProjectData
+		}
+	}
+	if input.LeadID != nil {
+		if err := s.checkLead(ctx, current.WorkspaceID, *input.LeadID); err != nil {
+			return models.Project{}, err
+		}
+		params.LeadID = pgtype.Text{String: *input.LeadID, Valid: *input.LeadID != ""}
+	}
+	if input.ClearTargetDate {
+		params.TargetDate = pgtype.Date{}
+	} else if input.TargetDate != nil {
+		params.TargetDate = toDate(input.TargetDate)
+	}
+
+	project, err := s.s.Queries.UpdateProject(ctx, params)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("failed to update project: %w", err)
+	}
+	return project, nil
+}
+
+func (s *ProjectService) DeleteProject(ctx context.Context, workspaceID, projectID string) error {
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return err
+	}
+
+	// Tasks are kept; their project and milestone are cleared by the FKs
+	err = s.s.Queries.DeleteProject(ctx, models.DeleteProjectParams{
+		WorkspaceID: project.WorkspaceID,
+		ID:          project.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	return nil
+}
+
+func (s *ProjectService) CreateMilestone(ctx context.Context, workspaceID, projectID string, input MilestoneInput) (models.Milestone, error) {
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return models.Milestone{}, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return models.Milestone{}, ErrInvalidProjectData
+	}
+
+	milestone, err := s.s.Queries.CreateMilestone(ctx, models.CreateMilestoneParams{
+		ProjectID:   project.ID,
+		Name:        name,
+		Description: pgtype.Text{String: input.Description, Valid: input.Description != ""},
+		TargetDate:  toDate(input.TargetDate),
+	})
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("failed to create milestone: %w", err)
+	}
+	return milestone, nil
+}
+
+func (s *ProjectService) UpdateMilestone(ctx context.Context, workspaceID, projectID, milestoneID string, input UpdateMilestoneInput) (models.Milestone, error) {
+	current, err := s.getMilestone(ctx, workspaceID, projectID, milestoneID)
+	if err != nil {
+		return models.Milestone{}, err
+	}
+
+	params := models.UpdateMilestoneParams{
+		ProjectID:   current.ProjectID,
+		ID:          current.ID,
+		Name:        current.Name,
+		Description: current.Description,
+		TargetDate:  current.TargetDate,
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return models.Milestone{}, ErrInvalidProjectData
+		}
+		params.Name = name
+	}
+	if input.Description != nil {
+		params.Description = pgtype.Text{String: *input.Description, Valid: *input.Description != ""}
+	}
+	if input.ClearTargetDate {
+		params.TargetDate = pgtype.Date{}
+	} else if input.TargetDate != nil {
+		params.TargetDate = toDate(input.TargetDate)
+	}
+
+	milestone, err := s.s.Queries.UpdateMilestone(ctx, params)
+	if err != nil {
+		return models.Milestone{}, fmt.Errorf("failed to update milestone: %w", err)
+	}
+	return milestone, nil
+}
+
+func (s *ProjectService) DeleteMilestone(ctx context.Context, workspaceID, projectID, milestoneID string) error {
+	milestone, err := s.getMilestone(ctx, workspaceID, projectID, milestoneID)
+	if err != nil {
+		return err
+	}
+
+	err = s.s.Queries.DeleteMilestone(ctx, models.DeleteMilestoneParams{
+		ProjectID: milestone.ProjectID,
+		ID:        milestone.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	return nil
+}
+
+func (s *ProjectService) ListProjectTasks(ctx context.Context, workspaceID, projectID string) ([]models.GetTasksByProjectRow, error) {
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.s.Queries.GetTasksByProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project tasks: %w", err)
+	}
+	if tasks == nil {
+		tasks = make([]models.GetTasksByProjectRow, 0)
+	}
+	return tasks, nil
+}
+
+// AssignTask moves a task into a project and optionally one of its
+// milestones. An empty projectID removes the task from its project.
+func (s *ProjectService) AssignTask(ctx context.Context, taskID, projectID, milestoneID string) (models.Task, error) {
+	tID, err := parseUUID(taskID)
+	if err != nil {
+		return models.Task{}, ErrInvalidTaskData
+	}
+
+	task, err := s.s.Queries.GetTaskByID(ctx, tID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Task{}, ErrTaskNotFound
+		}
+		return models.Task{}, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	params := models.SetTaskProjectParams{ID: task.TaskID}
+	if projectID != "" {
+		project, err := s.getProject(ctx, uuidString(task.WorkspaceID), projectID)
+		if err != nil {
+			return models.Task{}, err
+		}
+		params.ProjectID = project.ID
+
+		if milestoneID != "" {
+			milestone, err := s.getMilestone(ctx, uuidString(task.WorkspaceID), projectID, milestoneID)
+			if err != nil {
+				return models.Task{}, err
+			}
+			params.MilestoneID = milestone.ID
+		}
+	} else if milestoneID != "" {
+		return models.Task{}, ErrInvalidProjectData
+	}
+
+	updated, err := s.s.Queries.SetTaskProject(ctx, params)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to assign task: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *ProjectService) getProject(ctx context.Context, workspaceID, projectID string) (models.Project, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Project{}, ErrInvalidProjectData
+	}
+	pID, err := parseUUID(projectID)
+	if err != nil {
+		return models.Project{}, ErrInvalidProjectData
+	}
+
+	project, err := s.s.Queries.GetProject(ctx, models.GetProjectParams{
+		WorkspaceID: wsID,
+		ID:          pID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Project{}, ErrProjectNotFound
+		}
+		return models.Project{}, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, nil
+}
+
+func (s *ProjectService) getMilestone(ctx context.Context, workspaceID, projectID, milestoneID string) (models.Milestone, error) {
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return models.Milestone{}, err
+	}
+	mID, err := parseUUID(milestoneID)
+	if err != nil {
+		return models.Milestone{}, ErrInvalidProjectData
+	}
+
+	milestone, err := s.s.Queries.GetMilestone(ctx, models.GetMilestoneParams{
+		ProjectID: project.ID,
+		ID:        mID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Milestone{}, ErrMilestoneNotFound
+		}
+		return models.Milestone{}, fmt.Errorf("failed to get milestone: %w", err)
+	}
+	return milestone, nil
+}
+
+// checkLead ensures a project lead is a member of the project's workspace.
+func (s *ProjectService) checkLead(ctx context.Context, workspaceID pgtype.UUID, leadID string) error {
+	if leadID == "" {
+		return nil
+	}
+	isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+		UserID:      leadID,
+		WorkspaceID: workspaceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return ErrInvalidProjectData
+	}
+	return nil
+}
+
+func newProjectProgress() *ProjectProgress {
+	p := &ProjectProgress{ByStatus: make(map[string]int64, len(taskStatusOrder))}
+	for _, status := range taskStatusOrder {
+		p.ByStatus[string(status)] = 0
+	}
+	return p
+}
+
+func (p *ProjectProgress) add(status models.TaskStatus, count int64) {
+	p.ByStatus[string(status)] += count
+	p.Total += count
+	if status == models.TaskStatusDone {
+		p.Completed += count
+	}
+	if p.Total > 0 {
+		p.Percent = math.Round(float64(p.Completed)/float64(p.Total)*1000) / 10
+	}
+}
+
+func toDate(t *time.Time) pgtype.Date {
+	if t == nil {
+		return pgtype.Date{}
+	}
+	return pgtype.Date{Time: *t, Valid: true}
+}
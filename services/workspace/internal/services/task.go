@@ -14,35 +14,46 @@ import (
 
 // Domain errors
 var (
-	ErrTaskNotFound    = errors.New("task not found")
-	ErrInvalidTaskData = errors.New("invalid task data")
+	ErrTaskNotFound           = errors.New("task not found")
+	ErrInvalidTaskData        = errors.New("invalid task data")
+	ErrTaskDependencyNotFound = errors.New("task dependency not found")
+	ErrTaskDependencyCycle    = errors.New("task dependency would create a cycle")
 )
 
 type TaskServicer interface {
 	CreateNewTask(ctx context.Context, workspaceID, title, description,
-		assigneeId, status, priority string, dueDate time.Time) (models.Task, error)
+		assigneeId, status, priority string, dueDate time.Time, startDate *time.Time) (models.Task, error)
 	GetWorkspaceTasks(ctx context.Context, workspaceID string) ([]models.GetTasksByWorkspaceRow, error)
 	GetTask(ctx context.Context, taskID string) (models.GetTaskByIDRow, error)
-	UpdateTask(ctx context.Context, taskID, title, description, assigneeId, status, priority string, dueDate time.Time) (models.Task, error)
+	UpdateTask(ctx context.Context, taskID, title, description, assigneeId, status, priority string, dueDate time.Time, startDate *time.Time) (models.Task, error)
 	DeleteTask(ctx context.Context, taskID string) error
+	ListTaskComments(ctx context.Context, taskID string) ([]models.TaskComment, error)
+	AddTaskComment(ctx context.Context, taskID, authorID, body string) (models.TaskComment, error)
+	ListTaskDependencies(ctx context.Context, taskID string) ([]models.TaskDependency, error)
+	AddTaskDependency(ctx context.Context, taskID, dependsOnID string) error
+	RemoveTaskDependency(ctx context.Context, taskID, dependsOnID string) error
 }
 
 type TaskService struct {
-	s *store.Store
+	s      *store.Store
+	events AutomationEmitter
 }
 
 // Compile time interface implementation check
 var _ TaskServicer = (*TaskService)(nil)
 
-func NewTaskService(store *store.Store) *TaskService {
-	return &TaskService{s: store}
+func NewTaskService(store *store.Store, events AutomationEmitter) *TaskService {
+	return &TaskService{s: store, events: events}
 }
 
 func (s *TaskService) CreateNewTask(ctx context.Context, workspaceID, title,
-	description, assigneeId, status, priority string, dueDate time.Time) (models.Task, error) {
+	description, assigneeId, status, priority string, dueDate time.Time, startDate *time.Time) (models.Task, error) {
 	if workspaceID == "" || title == "" {
 		return models.Task{}, ErrInvalidTaskData
 	}
+	if startDate != nil && !dueDate.IsZero() && startDate.After(dueDate) {
+		return models.Task{}, ErrInvalidTaskData
+	}
 
 	var wid pgtype.UUID
 	if err := wid.Scan(workspaceID); err != nil {
@@ -56,7 +67,8 @@ func (s *TaskService) CreateNewTask(ctx context.Context, workspaceID, title,
 		AssigneeID:  pgtype.Text{String: assigneeId, Valid: assigneeId != ""},
 		Status:      models.TaskStatus(status),
 		Priority:    models.TaskPriority(priority),
-		DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: true},
+		DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: !dueDate.IsZero()},
+		StartDate:   toTimestamptz(startDate),
 	}
 
 	task, err := s.s.Queries.CreateNewTask(ctx, params)
@@ -64,6 +76,12 @@ func (s *TaskService) CreateNewTask(ctx context.Context, workspaceID, title,
 		return models.Task{}, fmt.Errorf("failed to create task: %w", err)
 	}
 
+	s.emit(ctx, AutomationEvent{
+		Trigger:     TriggerTaskCreated,
+		WorkspaceID: task.WorkspaceID,
+		SubjectID:   task.ID,
+	})
+
 	return task, nil
 }
 
@@ -110,16 +128,27 @@ func (s *TaskService) GetTask(ctx context.Context, taskID string) (models.GetTas
 	return task, nil
 }
 
-func (s *TaskService) UpdateTask(ctx context.Context, taskID, title, description, assigneeId, status, priority string, dueDate time.Time) (models.Task, error) {
+func (s *TaskService) UpdateTask(ctx context.Context, taskID, title, description, assigneeId, status, priority string, dueDate time.Time, startDate *time.Time) (models.Task, error) {
 	if taskID == "" {
 		return models.Task{}, ErrInvalidTaskData
 	}
+	if startDate != nil && !dueDate.IsZero() && startDate.After(dueDate) {
+		return models.Task{}, ErrInvalidTaskData
+	}
 
 	var tid pgtype.UUID
 	if err := tid.Scan(taskID); err != nil {
 		return models.Task{}, ErrInvalidTaskData
 	}
 
+	previous, err := s.s.Queries.GetTaskByID(ctx, tid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Task{}, ErrTaskNotFound
+		}
+		return models.Task{}, fmt.Errorf("failed to get task: %w", err)
+	}
+
 	params := models.UpdateTaskParams{
 		ID:          tid,
 		Title:       title,
@@ -127,10 +156,18 @@ func (s *TaskService) UpdateTask(ctx context.Context, taskID, title, description
 		AssigneeID:  pgtype.Text{String: assigneeId, Valid: assigneeId != ""},
 		Status:      models.TaskStatus(status),
 		Priority:    models.TaskPriority(priority),
-		DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: true},
+		DueDate:     pgtype.Timestamptz{Time: dueDate, Valid: !dueDate.IsZero()},
+		StartDate:   toTimestamptz(startDate),
 	}
 
-	task, err := s.s.Queries.UpdateTask(ctx, params)
+	tx, err := s.s.Pool.Begin(ctx)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.s.Queries.WithTx(tx)
+
+	task, err := queries.UpdateTask(ctx, params)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.Task{}, ErrTaskNotFound
@@ -138,6 +175,26 @@ func (s *TaskService) UpdateTask(ctx context.Context, taskID, title, description
 		return models.Task{}, fmt.Errorf("failed to update task: %w", err)
 	}
 
+	if err := recordCycleStatusChange(ctx, queries, task, previous.Status); err != nil {
+		return models.Task{}, err
+	}
+	if err := syncTaskChecklistItem(ctx, queries, task, previous.Status); err != nil {
+		return models.Task{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Task{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if task.Status != previous.Status {
+		s.emit(ctx, AutomationEvent{
+			Trigger:     TriggerTaskStatusChanged,
+			WorkspaceID: task.WorkspaceID,
+			SubjectID:   task.ID,
+			Status:      task.Status,
+		})
+	}
+
 	return task, nil
 }
 
@@ -161,3 +218,129 @@ func (s *TaskService) DeleteTask(ctx context.Context, taskID string) error {
 
 	return nil
 }
+
+func (s *TaskService) ListTaskComments(ctx context.Context, taskID string) ([]models.TaskComment, error) {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.s.Queries.ListTaskComments(ctx, task.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task comments: %w", err)
+	}
+	if comments == nil {
+		comments = make([]models.TaskComment, 0)
+	}
+	return comments, nil
+}
+
+func (s *TaskService) AddTaskComment(ctx context.Context, taskID, authorID, body string) (models.TaskComment, error) {
+	if authorID == "" || body == "" {
+		return models.TaskComment{}, ErrInvalidTaskData
+	}
+
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return models.TaskComment{}, err
+	}
+
+	comment, err := s.s.Queries.CreateTaskComment(ctx, models.CreateTaskCommentParams{
+		TaskID:   task.TaskID,
+		AuthorID: pgtype.Text{String: authorID, Valid: true},
+		Body:     body,
+	})
+	if err != nil {
+		return models.TaskComment{}, fmt.Errorf("failed to add task comment: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *TaskService) ListTaskDependencies(ctx context.Context, taskID string) ([]models.TaskDependency, error) {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := s.s.Queries.ListTaskDependencies(ctx, task.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task dependencies: %w", err)
+	}
+	if dependencies == nil {
+		dependencies = make([]models.TaskDependency, 0)
+	}
+	return dependencies, nil
+}
+
+// AddTaskDependency marks taskID as blocked by dependsOnID. Both tasks must
+// belong to the same workspace and the dependency graph must stay acyclic.
+func (s *TaskService) AddTaskDependency(ctx context.Context, taskID, dependsOnID string) error {
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	blocker, err := s.GetTask(ctx, dependsOnID)
+	if err != nil {
+		return err
+	}
+	if task.TaskID == blocker.TaskID || task.WorkspaceID != blocker.WorkspaceID {
+		return ErrInvalidTaskData
+	}
+
+	createsCycle, err := s.s.Queries.DependencyCreatesCycle(ctx, models.DependencyCreatesCycleParams{
+		BlockerID: blocker.TaskID,
+		TaskID:    task.TaskID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check task dependencies: %w", err)
+	}
+	if createsCycle {
+		return ErrTaskDependencyCycle
+	}
+
+	err = s.s.Queries.CreateTaskDependency(ctx, models.CreateTaskDependencyParams{
+		TaskID:      task.TaskID,
+		DependsOnID: blocker.TaskID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add task dependency: %w", err)
+	}
+	return nil
+}
+
+func (s *TaskService) RemoveTaskDependency(ctx context.Context, taskID, dependsOnID string) error {
+	tID, err := parseUUID(taskID)
+	if err != nil {
+		return ErrInvalidTaskData
+	}
+	dID, err := parseUUID(dependsOnID)
+	if err != nil {
+		return ErrInvalidTaskData
+	}
+
+	removed, err := s.s.Queries.DeleteTaskDependency(ctx, models.DeleteTaskDependencyParams{
+		TaskID:      tID,
+		DependsOnID: dID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove task dependency: %w", err)
+	}
+	if removed == 0 {
+		return ErrTaskDependencyNotFound
+	}
+	return nil
+}
+
+// emit hands a committed change to the automation engine, if one is wired up.
+func (s *TaskService) emit(ctx context.Context, event AutomationEvent) {
+	if s.events != nil {
+		s.events.Emit(ctx, event)
+	}
+}
+
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
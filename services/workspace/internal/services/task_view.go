@@ -0,0 +1,546 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+// Task view errors
+var (
+	ErrTaskViewNotFound     = errors.New("task view not found")
+	ErrInvalidTaskViewData  = errors.New("invalid task view data")
+	ErrTaskViewAccessDenied = errors.New("task view access denied")
+)
+
+const (
+	TaskViewPrivate   = "private"
+	TaskViewWorkspace = "workspace"
+)
+
+// AssigneeMe can be used in TaskViewFilters.AssigneeIDs to match the user
+// executing the view, so one shared view works for every member.
+const AssigneeMe = "me"
+
+var (
+	taskStatusOrder   = []models.TaskStatus{models.TaskStatusToDo, models.TaskStatusInProgress, models.TaskStatusReview, models.TaskStatusDone}
+	taskPriorityOrder = []models.TaskPriority{models.TaskPriorityHigh, models.TaskPriorityMedium, models.TaskPriorityLow}
+
+	taskViewGroupings = []string{"status", "assignee", "priority"}
+	taskViewColumns   = []string{"title", "description", "status", "priority", "assignee", "due_date", "created_at", "updated_at"}
+	taskViewSortKeys  = []string{"title", "status", "priority", "assignee", "due_date", "created_at", "updated_at"}
+
+	defaultTaskViewColumns = []string{"title", "status", "priority", "assignee", "due_date"}
+)
+
+type TaskViewFilters struct {
+	Statuses    []string   `json:"statuses,omitempty"`
+	Priorities  []string   `json:"priorities,omitempty"`
+	AssigneeIDs []string   `json:"assignee_ids,omitempty"`
+	Unassigned  bool       `json:"unassigned,omitempty"`
+	DueBefore   *time.Time `json:"due_before,omitempty"`
+	DueAfter    *time.Time `json:"due_after,omitempty"`
+	Search      string     `json:"search,omitempty"`
+}
+
+type TaskViewSort struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"` // "asc" or "desc"
+}
+
+type TaskViewInput struct {
+	Name       string
+	Visibility string
+	Filters    TaskViewFilters
+	Sort       []TaskViewSort
+	GroupBy    string
+	Columns    []string
+}
+
+type UpdateTaskViewInput struct {
+	Name       *string
+	Visibility *string
+	Filters    *TaskViewFilters
+	Sort       *[]TaskViewSort
+	GroupBy    *string
+	Columns    *[]string
+}
+
+type TaskViewGroup struct {
+	Key   string                          `json:"key"`
+	Tasks []models.GetTasksByWorkspaceRow `json:"tasks"`
+}
+
+// TaskViewResult is a saved view evaluated against the workspace's current
+// tasks. Groups is only populated when the view has a grouping.
+type TaskViewResult struct {
+	View    models.TaskView                 `json:"view"`
+	Columns []string                        `json:"columns"`
+	Tasks   []models.GetTasksByWorkspaceRow `json:"tasks"`
+	Groups  []TaskViewGroup                 `json:"groups,omitempty"`
+}
+
+type TaskViewServicer interface {
+	CreateTaskView(ctx context.Context, workspaceID, userID string, input TaskViewInput) (models.TaskView, error)
+	ListTaskViews(ctx context.Context, workspaceID, userID string) ([]models.TaskView, error)
+	GetTaskView(ctx context.Context, workspaceID, viewID, userID string) (models.TaskView, error)
+	UpdateTaskView(ctx context.Context, workspaceID, viewID, userID string, input UpdateTaskViewInput) (models.TaskView, error)
+	DeleteTaskView(ctx context.Context, workspaceID, viewID, userID string) error
+	ExecuteTaskView(ctx context.Context, workspaceID, viewID, userID string) (TaskViewResult, error)
+}
+
+type TaskViewService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ TaskViewServicer = (*TaskViewService)(nil)
+
+func NewTaskViewService(store *store.Store) *TaskViewService {
+	return &TaskViewService{s: store}
+}
+
+func (s *TaskViewService) CreateTaskView(ctx context.Context, workspaceID, userID string, input TaskViewInput) (models.TaskView, error) {
+	wsID, err := s.authorize(ctx, workspaceID, userID)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+
+	params, err := encodeTaskView(input)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+	params.WorkspaceID = wsID
+	params.OwnerID = userID
+
+	view, err := s.s.Queries.CreateTaskView(ctx, params)
+	if err != nil {
+		return models.TaskView{}, fmt.Errorf("failed to create task view: %w", err)
+	}
+	return view, nil
+}
+
+func (s *TaskViewService) ListTaskViews(ctx context.Context, workspaceID, userID string) ([]models.TaskView, error) {
+	wsID, err := s.authorize(ctx, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := s.s.Queries.ListVisibleTaskViews(ctx, models.ListVisibleTaskViewsParams{
+		WorkspaceID: wsID,
+		OwnerID:     userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task views: %w", err)
+	}
+	if views == nil {
+		views = make([]models.TaskView, 0)
+	}
+	return views, nil
+}
+
+func (s *TaskViewService) GetTaskView(ctx context.Context, workspaceID, viewID, userID string) (models.TaskView, error) {
+	wsID, err := s.authorize(ctx, workspaceID, userID)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+
+	var vID pgtype.UUID
+	if err := vID.Scan(viewID); err != nil {
+		return models.TaskView{}, ErrInvalidTaskViewData
+	}
+
+	view, err := s.s.Queries.GetTaskView(ctx, models.GetTaskViewParams{
+		WorkspaceID: wsID,
+		ID:          vID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TaskView{}, ErrTaskViewNotFound
+		}
+		return models.TaskView{}, fmt.Errorf("failed to get task view: %w", err)
+	}
+
+	// Private views are invisible to everyone but their owner
+	if view.Visibility == TaskViewPrivate && view.OwnerID != userID {
+		return models.TaskView{}, ErrTaskViewNotFound
+	}
+	return view, nil
+}
+
+func (s *TaskViewService) UpdateTaskView(ctx context.Context, workspaceID, viewID, userID string, input UpdateTaskViewInput) (models.TaskView, error) {
+	current, err := s.GetTaskView(ctx, workspaceID, viewID, userID)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+	if current.OwnerID != userID {
+		return models.TaskView{}, ErrTaskViewAccessDenied
+	}
+
+	merged, err := decodeTaskView(current)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+	if input.Name != nil {
+		merged.Name = *input.Name
+	}
+	if input.Visibility != nil {
+		merged.Visibility = *input.Visibility
+	}
+	if input.Filters != nil {
+		merged.Filters = *input.Filters
+	}
+	if input.Sort != nil {
+		merged.Sort = *input.Sort
+	}
+	if input.GroupBy != nil {
+		merged.GroupBy = *input.GroupBy
+	}
+	if input.Columns != nil {
+		merged.Columns = *input.Columns
+	}
+
+	encoded, err := encodeTaskView(merged)
+	if err != nil {
+		return models.TaskView{}, err
+	}
+
+	updated, err := s.s.Queries.UpdateTaskView(ctx, models.UpdateTaskViewParams{
+		WorkspaceID: current.WorkspaceID,
+		ID:          current.ID,
+		Name:        encoded.Name,
+		Visibility:  encoded.Visibility,
+		Filters:     encoded.Filters,
+		Sort:        encoded.Sort,
+		GroupBy:     encoded.GroupBy,
+		Columns:     encoded.Columns,
+	})
+	if err != nil {
+		return models.TaskView{}, fmt.Errorf("failed to update task view: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *TaskViewService) DeleteTaskView(ctx context.Context, workspaceID, viewID, userID string) error {
+	view, err := s.GetTaskView(ctx, workspaceID, viewID, userID)
+	if err != nil {
+		return err
+	}
+	if view.OwnerID != userID {
+		return ErrTaskViewAccessDenied
+	}
+
+	err = s.s.Queries.DeleteTaskView(ctx, models.DeleteTaskViewParams{
+		WorkspaceID: view.WorkspaceID,
+		ID:          view.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete task view: %w", err)
+	}
+	return nil
+}
+
+func (s *TaskViewService) ExecuteTaskView(ctx context.Context, workspaceID, viewID, userID string) (TaskViewResult, error) {
+	view, err := s.GetTaskView(ctx, workspaceID, viewID, userID)
+	if err != nil {
+		return TaskViewResult{}, err
+	}
+	def, err := decodeTaskView(view)
+	if err != nil {
+		return TaskViewResult{}, err
+	}
+
+	tasks, err := s.s.Queries.GetTasksByWorkspace(ctx, view.WorkspaceID)
+	if err != nil {
+		return TaskViewResult{}, fmt.Errorf("failed to get workspace tasks: %w", err)
+	}
+
+	filtered := make([]models.GetTasksByWorkspaceRow, 0, len(tasks))
+	for _, task := range tasks {
+		if matchesTaskViewFilters(task, def.Filters, userID) {
+			filtered = append(filtered, task)
+		}
+	}
+	sortTaskViewRows(filtered, def.Sort)
+
+	columns := def.Columns
+	if len(columns) == 0 {
+		columns = defaultTaskViewColumns
+	}
+
+	result := TaskViewResult{
+		View:    view,
+		Columns: columns,
+		Tasks:   filtered,
+	}
+	if def.GroupBy != "" {
+		result.Groups = groupTaskViewRows(filtered, def.GroupBy)
+	}
+	return result, nil
+}
+
+func (s *TaskViewService) authorize(ctx context.Context, workspaceID, userID string) (pgtype.UUID, error) {
+	if workspaceID == "" || userID == "" {
+		return pgtype.UUID{}, ErrInvalidTaskViewData
+	}
+
+	var wsID pgtype.UUID
+	if err := wsID.Scan(workspaceID); err != nil {
+		return pgtype.UUID{}, ErrInvalidTaskViewData
+	}
+
+	isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+		UserID:      userID,
+		WorkspaceID: wsID,
+	})
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if !isMember {
+		return pgtype.UUID{}, ErrTaskViewAccessDenied
+	}
+	return wsID, nil
+}
+
+// encodeTaskView validates a view definition and converts it to its column
+// representation. The caller fills in the workspace and owner.
+func encodeTaskView(input TaskViewInput) (models.CreateTaskViewParams, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+	}
+
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = TaskViewPrivate
+	}
+	if visibility != TaskViewPrivate && visibility != TaskViewWorkspace {
+		return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+	}
+
+	for _, status := range input.Filters.Statuses {
+		if !slices.Contains(taskStatusOrder, models.TaskStatus(status)) {
+			return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+		}
+	}
+	for _, priority := range input.Filters.Priorities {
+		if !slices.Contains(taskPriorityOrder, models.TaskPriority(priority)) {
+			return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+		}
+	}
+	for _, key := range input.Sort {
+		if !slices.Contains(taskViewSortKeys, key.Field) {
+			return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+		}
+		if key.Direction != "" && key.Direction != "asc" && key.Direction != "desc" {
+			return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+		}
+	}
+	if input.GroupBy != "" && !slices.Contains(taskViewGroupings, input.GroupBy) {
+		return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+	}
+
+	columns := make([]string, 0, len(input.Columns))
+	for _, column := range input.Columns {
+		if !slices.Contains(taskViewColumns, column) {
+			return models.CreateTaskViewParams{}, ErrInvalidTaskViewData
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	filters, err := json.Marshal(input.Filters)
+	if err != nil {
+		return models.CreateTaskViewParams{}, fmt.Errorf("failed to encode filters: %w", err)
+	}
+	sortKeys := input.Sort
+	if sortKeys == nil {
+		sortKeys = []TaskViewSort{}
+	}
+	sort, err := json.Marshal(sortKeys)
+	if err != nil {
+		return models.CreateTaskViewParams{}, fmt.Errorf("failed to encode sort: %w", err)
+	}
+
+	return models.CreateTaskViewParams{
+		Name:       name,
+		Visibility: visibility,
+		Filters:    filters,
+		Sort:       sort,
+		GroupBy:    pgtype.Text{String: input.GroupBy, Valid: input.GroupBy != ""},
+		Columns:    columns,
+	}, nil
+}
+
+func decodeTaskView(view models.TaskView) (TaskViewInput, error) {
+	def := TaskViewInput{
+		Name:       view.Name,
+		Visibility: view.Visibility,
+		GroupBy:    view.GroupBy.String,
+		Columns:    view.Columns,
+	}
+	if err := json.Unmarshal(view.Filters, &def.Filters); err != nil {
+		return TaskViewInput{}, fmt.Errorf("failed to decode filters: %w", err)
+	}
+	if err := json.Unmarshal(view.Sort, &def.Sort); err != nil {
+		return TaskViewInput{}, fmt.Errorf("failed to decode sort: %w", err)
+	}
+	return def, nil
+}
+
+func matchesTaskViewFilters(task models.GetTasksByWorkspaceRow, f TaskViewFilters, userID string) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, string(task.Status)) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, string(task.Priority)) {
+		return false
+	}
+	if len(f.AssigneeIDs) > 0 || f.Unassigned {
+		matched := f.Unassigned && !task.AssigneeID.Valid
+		for _, id := range f.AssigneeIDs {
+			if id == AssigneeMe {
+				id = userID
+			}
+			if task.AssigneeID.Valid && task.AssigneeID.String == id {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.DueBefore != nil && (!task.DueDate.Valid || !task.DueDate.Time.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (!task.DueDate.Valid || !task.DueDate.Time.After(*f.DueAfter)) {
+		return false
+	}
+	if f.Search != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(f.Search)) {
+		return false
+	}
+	return true
+}
+
+func sortTaskViewRows(tasks []models.GetTasksByWorkspaceRow, keys []TaskViewSort) {
+	if len(keys) == 0 {
+		// GetTasksByWorkspace already orders by created_at DESC
+		return
+	}
+	slices.SortStableFunc(tasks, func(a, b models.GetTasksByWorkspaceRow) int {
+		for _, key := range keys {
+			c := compareTaskViewRows(a, b, key.Field)
+			if key.Direction == "desc" {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+func compareTaskViewRows(a, b models.GetTasksByWorkspaceRow, field string) int {
+	switch field {
+	case "title":
+		return cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "status":
+		return cmp.Compare(slices.Index(taskStatusOrder, a.Status), slices.Index(taskStatusOrder, b.Status))
+	case "priority":
+		// Higher priority sorts first when ascending
+		return cmp.Compare(slices.Index(taskPriorityOrder, a.Priority), slices.Index(taskPriorityOrder, b.Priority))
+	case "assignee":
+		return cmp.Compare(assigneeDisplayName(a), assigneeDisplayName(b))
+	case "due_date":
+		return compareTimestamptz(a.DueDate, b.DueDate)
+	case "created_at":
+		return compareTimestamptz(a.CreatedAt, b.CreatedAt)
+	case "updated_at":
+		return compareTimestamptz(a.UpdatedAt, b.UpdatedAt)
+	}
+	return 0
+}
+
+// compareTimestamptz orders null timestamps after all valid ones.
+func compareTimestamptz(a, b pgtype.Timestamptz) int {
+	switch {
+	case !a.Valid && !b.Valid:
+		return 0
+	case !a.Valid:
+		return 1
+	case !b.Valid:
+		return -1
+	}
+	return a.Time.Compare(b.Time)
+}
+
+func assigneeDisplayName(task models.GetTasksByWorkspaceRow) string {
+	if !task.AssigneeID.Valid {
+		return ""
+	}
+	name := strings.TrimSpace(task.AssigneeFirstName.String + " " + task.AssigneeLastName.String)
+	if name == "" {
+		name = task.AssigneeUsername.String
+	}
+	return strings.ToLower(name)
+}
+
+func groupTaskViewRows(tasks []models.GetTasksByWorkspaceRow, groupBy string) []TaskViewGroup {
+	var keys []string
+	switch groupBy {
+	case "status":
+		for _, status := range taskStatusOrder {
+			keys = append(keys, string(status))
+		}
+	case "priority":
+		for _, priority := range taskPriorityOrder {
+			keys = append(keys, string(priority))
+		}
+	}
+
+	buckets := make(map[string][]models.GetTasksByWorkspaceRow)
+	for _, task := range tasks {
+		var key string
+		switch groupBy {
+		case "status":
+			key = string(task.Status)
+		case "priority":
+			key = string(task.Priority)
+		case "assignee":
+			key = task.AssigneeID.String
+		}
+		if _, seen := buckets[key]; !seen && groupBy == "assignee" {
+			keys = append(keys, key)
+		}
+		buckets[key] = append(buckets[key], task)
+	}
+
+	if groupBy == "assignee" {
+		// Unassigned tasks (empty key) go last
+		if i := slices.Index(keys, ""); i >= 0 {
+			keys = append(slices.Delete(keys, i, i+1), "")
+		}
+	}
+
+	groups := make([]TaskViewGroup, 0, len(keys))
+	for _, key := range keys {
+		rows := buckets[key]
+		if rows == nil {
+			rows = make([]models.GetTasksByWorkspaceRow, 0)
+		}
+		groups = append(groups, TaskViewGroup{Key: key, Tasks: rows})
+	}
+	return groups
+}
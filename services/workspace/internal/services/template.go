@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+var (
+	ErrTemplateNotFound    = errors.New("template not found")
+	ErrInvalidTemplateData = errors.New("invalid template data")
+)
+
+// What a template creates
+const (
+	TemplateKindNote = "note"
+	TemplateKindTask = "task"
+)
+
+// placeholderPattern matches {{name}}, allowing spaces inside the braces
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+type TemplateInput struct {
+	Kind string
+	Name string
+	// Title and Content become the note's title and content, or the task's
+	// title and description
+	Title   string
+	Content string
+	// Tags only apply to note templates
+	Tags []string
+	// Checklist, Priority, AssigneeID and DueInDays only apply to task
+	// templates. The checklist is appended to the description as a task
+	// list.
+	Checklist  []string
+	Priority   string
+	AssigneeID string
+	DueInDays  *int
+}
+
+type UpdateTemplateInput struct {
+	Name       *string
+	Title      *string
+	Content    *string
+	Tags       *[]string
+	Checklist  *[]string
+	Priority   *string
+	AssigneeID *string
+	DueInDays  *int
+	// ClearDueInDays removes the due date offset; DueInDays is ignored
+	ClearDueInDays bool
+}
+
+type InstantiateTemplateInput struct {
+	UserID string
+	// Timezone is the IANA name used for date placeholders and due dates,
+	// UTC when empty
+	Timezone string
+	// Values fill in custom placeholders, and override built-in ones
+	Values map[string]string
+	// ParentID nests a note under another
+	ParentID string
+	// AssigneeID overrides a task template's default assignee
+	AssigneeID string
+}
+
+// TemplateInstance is the note or task created from a template
+type TemplateInstance struct {
+	Kind string       `json:"kind"`
+	Note *models.Note `json:"note,omitempty"`
+	Task *models.Task `json:"task,omitempty"`
+}
+
+type TemplateServicer interface {
+	CreateTemplate(ctx context.Context, workspaceID, userID string, input TemplateInput) (models.Template, error)
+	ListTemplates(ctx context.Context, workspaceID, kind string) ([]models.Template, error)
+	GetTemplate(ctx context.Context, workspaceID, templateID string) (models.Template, error)
+	UpdateTemplate(ctx context.Context, workspaceID, templateID string, input UpdateTemplateInput) (models.Template, error)
+	DeleteTemplate(ctx context.Context, workspaceID, templateID string) error
+	InstantiateTemplate(ctx context.Context, workspaceID, templateID string, input InstantiateTemplateInput) (TemplateInstance, error)
+}
+
+// TemplateService stores templates and creates notes and tasks from them
+// through the note and task services.
+type TemplateService struct {
+	s     *store.Store
+	notes NoteServicer
+	tasks TaskServicer
+}
+
+// Compile time interface implementation check
+var _ TemplateServicer = (*TemplateService)(nil)
+
+func NewTemplateService(store *store.Store, notes NoteServicer, tasks TaskServicer) *TemplateService {
+	return &TemplateService{s: store, notes: notes, tasks: tasks}
+}
+
+func (s *TemplateService) CreateTemplate(ctx context.Context, workspaceID, userID string, input TemplateInput) (models.Template, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Template{}, ErrInvalidTemplateData
+	}
+	input, err = s.checkTemplate(ctx, wsID, input)
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	template, err := s.s.Queries.CreateTemplate(ctx, models.CreateTemplateParams{
+		WorkspaceID: wsID,
+		Kind:        input.Kind,
+		Name:        input.Name,
+		Title:       input.Title,
+		Content:     input.Content,
+		Tags:        input.Tags,
+		Checklist:   input.Checklist,
+		Priority:    models.TaskPriority(input.Priority),
+		AssigneeID:  pgtype.Text{String: input.AssigneeID, Valid: input.AssigneeID != ""},
+		DueInDays:   toInt4(input.DueInDays),
+		CreatedBy:   pgtype.Text{String: userID, Valid: userID != ""},
+	})
+	if err != nil {
+		return models.Template{}, fmt.Errorf("failed to create template: %w", err)
+	}
+	return template, nil
+}
+
+// ListTemplates returns the workspace's templates, or only those of one
+// kind
+func (s *TemplateService) ListTemplates(ctx context.Context, workspaceID, kind string) ([]models.Template, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return nil, ErrInvalidTemplateData
+	}
+	if kind != "" && kind != TemplateKindNote && kind != TemplateKindTask {
+		return nil, ErrInvalidTemplateData
+	}
+
+	templates, err := s.s.Queries.ListTemplates(ctx, models.ListTemplatesParams{
+		WorkspaceID: wsID,
+		Kind:        pgtype.Text{String: kind, Valid: kind != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	if templates == nil {
+		templates = make([]models.Template, 0)
+	}
+	return templates, nil
+}
+
+func (s *TemplateService) GetTemplate(ctx context.Context, workspaceID, templateID string) (models.Template, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return models.Template{}, ErrInvalidTemplateData
+	}
+	tID, err := parseUUID(templateID)
+	if err != nil {
+		return models.Template{}, ErrInvalidTemplateData
+	}
+
+	template, err := s.s.Queries.GetTemplate(ctx, models.GetTemplateParams{
+		WorkspaceID: wsID,
+		ID:          tID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Template{}, ErrTemplateNotFound
+		}
+		return models.Template{}, fmt.Errorf("failed to get template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *TemplateService) UpdateTemplate(ctx context.Context, workspaceID, templateID string, input UpdateTemplateInput) (models.Template, error) {
+	current, err := s.GetTemplate(ctx, workspaceID, templateID)
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	merged := TemplateInput{
+		Kind:       current.Kind,
+		Name:       current.Name,
+		Title:      current.Title,
+		Content:    current.Content,
+		Tags:       current.Tags,
+		Checklist:  current.Checklist,
+		Priority:   string(current.Priority),
+		AssigneeID: current.AssigneeID.String,
+	}
+	if current.DueInDays.Valid {
+		days := int(current.DueInDays.Int32)
+		merged.DueInDays = &days
+	}
+	if input.Name != nil {
+		merged.Name = *input.Name
+	}
+	if input.Title != nil {
+		merged.Title = *input.Title
+	}
+	if input.Content != nil {
+		merged.Content = *input.Content
+	}
+	if input.Tags != nil {
+		merged.Tags = *input.Tags
+	}
+	if input.Checklist != nil {
+		merged.Checklist = *input.Checklist
+	}
+	if input.Priority != nil {
+		merged.Priority = *input.Priority
+	}
+	if input.AssigneeID != nil {
+		merged.AssigneeID = *input.AssigneeID
+	}
+	if input.ClearDueInDays {
+		merged.DueInDays = nil
+	} else if input.DueInDays != nil {
+		merged.DueInDays = input.DueInDays
+	}
+
+	merged, err = s.checkTemplate(ctx, current.WorkspaceID, merged)
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	updated, err := s.s.Queries.UpdateTemplate(ctx, models.UpdateTemplateParams{
+		WorkspaceID: current.WorkspaceID,
+		ID:          current.ID,
+		Name:        merged.Name,
+		Title:       merged.Title,
+		Content:     merged.Content,
+		Tags:        merged.Tags,
+		Checklist:   merged.Checklist,
+		Priority:    models.TaskPriority(merged.Priority),
+		AssigneeID:  pgtype.Text{String: merged.AssigneeID, Valid: merged.AssigneeID != ""},
+		DueInDays:   toInt4(merged.DueInDays),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Template{}, ErrTemplateNotFound
+		}
+		return models.Template{}, fmt.Errorf("failed to update template: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *TemplateService) DeleteTemplate(ctx context.Context, workspaceID, templateID string) error {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return ErrInvalidTemplateData
+	}
+	tID, err := parseUUID(templateID)
+	if err != nil {
+		return ErrInvalidTemplateData
+	}
+
+	rows, err := s.s.Queries.DeleteTemplate(ctx, models.DeleteTemplateParams{
+		WorkspaceID: wsID,
+		ID:          tID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	if rows == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// InstantiateTemplate fills in the template's placeholders and creates the
+// note or task it describes
+func (s *TemplateService) InstantiateTemplate(ctx context.Context, workspaceID, templateID string, input InstantiateTemplateInput) (TemplateInstance, error) {
+	if input.UserID == "" {
+		return TemplateInstance{}, ErrInvalidTemplateData
+	}
+	loc := time.UTC
+	if input.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(input.Timezone); err != nil {
+			return TemplateInstance{}, ErrInvalidTemplateData
+		}
+	}
+	template, err := s.GetTemplate(ctx, workspaceID, templateID)
+	if err != nil {
+		return TemplateInstance{}, err
+	}
+
+	now := time.Now().In(loc)
+	values, err := s.placeholderValues(ctx, template.WorkspaceID, input.UserID, now)
+	if err != nil {
+		return TemplateInstance{}, err
+	}
+	for name, value := range input.Values {
+		values[name] = value
+	}
+	fill := func(text string) string {
+		return fillPlaceholders(text, values)
+	}
+
+	if template.Kind == TemplateKindNote {
+		tags := make([]string, len(template.Tags))
+		for i, tag := range template.Tags {
+			tags[i] = fill(tag)
+		}
+		note, err := s.notes.CreateNote(ctx, CreateNoteInput{
+			WorkspaceID: workspaceID,
+			AuthorID:    input.UserID,
+			Title:       fill(template.Title),
+			Content:     fill(template.Content),
+			Tags:        tags,
+			ParentID:    input.ParentID,
+		})
+		if err != nil {
+			return TemplateInstance{}, err
+		}
+		return TemplateInstance{Kind: TemplateKindNote, Note: &note}, nil
+	}
+
+	description := fill(template.Content)
+	if len(template.Checklist) > 0 {
+		var b strings.Builder
+		b.WriteString(strings.TrimRight(description, "\n"))
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		for _, item := range template.Checklist {
+			b.WriteString("- [ ] " + fill(item) + "\n")
+		}
+		description = b.String()
+	}
+	assigneeID := input.AssigneeID
+	if assigneeID == "" {
+		assigneeID = template.AssigneeID.String
+	}
+	// Tasks created without a due date get the zero time, as through the API
+	var dueDate time.Time
+	if template.DueInDays.Valid {
+		y, m, d := now.Date()
+		dueDate = time.Date(y, m, d+int(template.DueInDays.Int32), 0, 0, 0, 0, loc)
+	}
+
+	task, err := s.tasks.CreateNewTask(ctx, workspaceID, fill(template.Title), description, assigneeID,
+		string(models.TaskStatusToDo), string(template.Priority), dueDate, nil)
+	if err != nil {
+		return TemplateInstance{}, err
+	}
+	return TemplateInstance{Kind: TemplateKindTask, Task: &task}, nil
+}
+
+// checkTemplate validates a template and tidies up its fields
+func (s *TemplateService) checkTemplate(ctx context.Context, workspaceID pgtype.UUID, input TemplateInput) (TemplateInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Name == "" {
+		return TemplateInput{}, ErrInvalidTemplateData
+	}
+	if input.Priority == "" {
+		input.Priority = string(models.TaskPriorityMedium)
+	}
+	input.Tags = normalizeTags(input.Tags)
+	checklist := make([]string, 0, len(input.Checklist))
+	for _, item := range input.Checklist {
+		if item = strings.TrimSpace(item); item != "" {
+			checklist = append(checklist, item)
+		}
+	}
+	input.Checklist = checklist
+
+	switch input.Kind {
+	case TemplateKindNote:
+		if len(input.Checklist) > 0 || input.AssigneeID != "" || input.DueInDays != nil ||
+			input.Priority != string(models.TaskPriorityMedium) {
+			return TemplateInput{}, ErrInvalidTemplateData
+		}
+	case TemplateKindTask:
+		if input.Title == "" || len(input.Tags) > 0 {
+			return TemplateInput{}, ErrInvalidTemplateData
+		}
+		if !slices.Contains(taskPriorityOrder, models.TaskPriority(input.Priority)) {
+			return TemplateInput{}, ErrInvalidTemplateData
+		}
+		if input.DueInDays != nil && *input.DueInDays < 0 {
+			return TemplateInput{}, ErrInvalidTemplateData
+		}
+		if input.AssigneeID != "" {
+			isMember, err := s.s.Queries.IsWorkspaceUser(ctx, models.IsWorkspaceUserParams{
+				UserID:      input.AssigneeID,
+				WorkspaceID: workspaceID,
+			})
+			if err != nil {
+				return TemplateInput{}, fmt.Errorf("failed to check membership: %w", err)
+			}
+			if !isMember {
+				return TemplateInput{}, ErrInvalidTemplateData
+			}
+		}
+	default:
+		return TemplateInput{}, ErrInvalidTemplateData
+	}
+	return input, nil
+}
+
+// placeholderValues returns the built-in placeholders: the date and time,
+// the user's name and the workspace's name
+func (s *TemplateService) placeholderValues(ctx context.Context, workspaceID pgtype.UUID, userID string, now time.Time) (map[string]string, error) {
+	values := map[string]string{
+		"date":     now.Format(time.DateOnly),
+		"time":     now.Format("15:04"),
+		"datetime": now.Format("2006-01-02 15:04"),
+		"weekday":  now.Weekday().String(),
+		"user":     userID,
+	}
+
+	user, err := s.s.Queries.GetUserByID(ctx, userID)
+	switch {
+	case err == nil:
+		values["user"] = userDisplayName(user)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	workspace, err := s.s.Queries.GetWorkspaceById(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	values["workspace"] = workspace.Name
+	return values, nil
+}
+
+// fillPlaceholders replaces {{name}} with its value, leaving unknown
+// placeholders as they are
+func fillPlaceholders(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func userDisplayName(user models.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	if user.Username != "" {
+		return user.Username
+	}
+	return user.Email
+}
+
+func toInt4(v *int) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(*v), Valid: true}
+}
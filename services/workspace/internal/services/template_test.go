@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+func TestFillPlaceholders(t *testing.T) {
+	values := map[string]string{
+		"date":         "2026-10-19",
+		"user":         "Ada {{date}}",
+		"project.name": "Launch",
+		"empty":        "",
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Notes for {{date}}", "Notes for 2026-10-19"},
+		{"{{ date }} and {{date}}", "2026-10-19 and 2026-10-19"},
+		{"{{project.name}} plan", "Launch plan"},
+		{"[{{empty}}]", "[]"},
+		// Values are not filled in again
+		{"By {{user}}", "By Ada {{date}}"},
+		{"{{unknown}} stays", "{{unknown}} stays"},
+		{"{{1date}} {date} {{ }}", "{{1date}} {date} {{ }}"},
+		{"{{{date}}}", "{2026-10-19}"},
+	}
+	for _, tt := range tests {
+		if got := fillPlaceholders(tt.text, values); got != tt.want {
+			t.Errorf("fillPlaceholders(%q) got %q want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestUserDisplayName(t *testing.T) {
+	tests := []struct {
+		user models.User
+		want string
+	}{
+		{models.User{FirstName: "Ada", LastName: "Lovelace", Username: "ada"}, "Ada Lovelace"},
+		{models.User{FirstName: "Ada", Username: "ada"}, "Ada"},
+		{models.User{Username: "ada", Email: "ada@example.com"}, "ada"},
+		{models.User{Email: "ada@example.com"}, "ada@example.com"},
+	}
+	for _, tt := range tests {
+		if got := userDisplayName(tt.user); got != tt.want {
+			t.Errorf("userDisplayName(%+v) got %q want %q", tt.user, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+	"github.com/tomasohchom/motion/services/workspace/internal/store"
+)
+
+// Timeline conflict types
+const (
+	ConflictStartAfterDue       = "start_after_due"
+	ConflictStartsBeforeBlocker = "starts_before_blocker_due"
+	ConflictDueBeforeBlocker    = "due_before_blocker_due"
+	ConflictStartedWhileBlocked = "started_while_blocked"
+	ConflictDependencyCycle     = "dependency_cycle"
+)
+
+// criticalSlackTolerance absorbs rounding in stored timestamps
+const criticalSlackTolerance = time.Minute
+
+type TimelineTask struct {
+	Task      models.GetTasksByWorkspaceRow `json:"task"`
+	Start     *time.Time                    `json:"start"`
+	End       *time.Time                    `json:"end"`
+	DependsOn []string                      `json:"depends_on"`
+	Critical  bool                          `json:"critical"`
+	// SlackHours is how far the task can slip without delaying the timeline
+	SlackHours float64 `json:"slack_hours"`
+}
+
+type TimelineConflict struct {
+	Type        string `json:"type"`
+	TaskID      string `json:"task_id"`
+	DependsOnID string `json:"depends_on_id,omitempty"`
+	Message     string `json:"message"`
+}
+
+// Timeline is a Gantt-ready view of a workspace or project. Tasks without a
+// start date are drawn as points on their due date.
+type Timeline struct {
+	Start        *time.Time              `json:"start"`
+	End          *time.Time              `json:"end"`
+	Tasks        []TimelineTask          `json:"tasks"`
+	Dependencies []models.TaskDependency `json:"dependencies"`
+	CriticalPath []string                `json:"critical_path"`
+	Conflicts    []TimelineConflict      `json:"conflicts"`
+}
+
+type TimelineServicer interface {
+	GetTimeline(ctx context.Context, workspaceID, projectID string) (Timeline, error)
+}
+
+type TimelineService struct {
+	s *store.Store
+}
+
+// Compile time interface implementation check
+var _ TimelineServicer = (*TimelineService)(nil)
+
+func NewTimelineService(store *store.Store) *TimelineService {
+	return &TimelineService{s: store}
+}
+
+// GetTimeline builds the timeline for a workspace, or for one of its
+// projects when projectID is set.
+func (s *TimelineService) GetTimeline(ctx context.Context, workspaceID, projectID string) (Timeline, error) {
+	wsID, err := parseUUID(workspaceID)
+	if err != nil {
+		return Timeline{}, ErrInvalidTaskData
+	}
+
+	var tasks []models.GetTasksByWorkspaceRow
+	if projectID == "" {
+		tasks, err = s.s.Queries.GetTasksByWorkspace(ctx, wsID)
+		if err != nil {
+			return Timeline{}, fmt.Errorf("failed to get workspace tasks: %w", err)
+		}
+	} else {
+		pID, err := parseUUID(projectID)
+		if err != nil {
+			return Timeline{}, ErrInvalidProjectData
+		}
+		project, err := s.s.Queries.GetProject(ctx, models.GetProjectParams{WorkspaceID: wsID, ID: pID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Timeline{}, ErrProjectNotFound
+			}
+			return Timeline{}, fmt.Errorf("failed to get project: %w", err)
+		}
+		rows, err := s.s.Queries.GetTasksByProject(ctx, project.ID)
+		if err != nil {
+			return Timeline{}, fmt.Errorf("failed to get project tasks: %w", err)
+		}
+		for _, row := range rows {
+			tasks = append(tasks, models.GetTasksByWorkspaceRow(row))
+		}
+	}
+
+	dependencies, err := s.s.Queries.ListWorkspaceTaskDependencies(ctx, wsID)
+	if err != nil {
+		return Timeline{}, fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+
+	return buildTimeline(tasks, dependencies), nil
+}
+
+// buildTimeline lays out tasks, flags scheduling conflicts and runs a
+// critical path analysis over task durations. Dependencies on tasks outside
+// the set are dropped.
+func buildTimeline(tasks []models.GetTasksByWorkspaceRow, dependencies []models.TaskDependency) Timeline {
+	timeline := Timeline{
+		Tasks:        make([]TimelineTask, len(tasks)),
+		Dependencies: make([]models.TaskDependency, 0),
+		CriticalPath: make([]string, 0),
+		Conflicts:    make([]TimelineConflict, 0),
+	}
+
+	index := make(map[pgtype.UUID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.TaskID] = i
+		item := TimelineTask{Task: task, DependsOn: make([]string, 0)}
+		if scheduled(task.DueDate) {
+			end := task.DueDate.Time
+			item.End = &end
+			item.Start = &end
+		}
+		if scheduled(task.StartDate) {
+			start := task.StartDate.Time
+			item.Start = &start
+			if item.End == nil {
+				item.End = &start
+			}
+		}
+		if item.Start != nil && (timeline.Start == nil || item.Start.Before(*timeline.Start)) {
+			timeline.Start = item.Start
+		}
+		if item.End != nil && (timeline.End == nil || item.End.After(*timeline.End)) {
+			timeline.End = item.End
+		}
+		if scheduled(task.StartDate) && scheduled(task.DueDate) && task.StartDate.Time.After(task.DueDate.Time) {
+			timeline.Conflicts = append(timeline.Conflicts, TimelineConflict{
+				Type:    ConflictStartAfterDue,
+				TaskID:  uuidString(task.TaskID),
+				Message: fmt.Sprintf("%q starts after it is due", task.Title),
+			})
+		}
+		timeline.Tasks[i] = item
+	}
+
+	preds := make([][]int, len(tasks))
+	succs := make([][]int, len(tasks))
+	for _, dep := range dependencies {
+		ti, ok := index[dep.TaskID]
+		if !ok {
+			continue
+		}
+		bi, ok := index[dep.DependsOnID]
+		if !ok {
+			continue
+		}
+		timeline.Dependencies = append(timeline.Dependencies, dep)
+		timeline.Tasks[ti].DependsOn = append(timeline.Tasks[ti].DependsOn, uuidString(dep.DependsOnID))
+		preds[ti] = append(preds[ti], bi)
+		succs[bi] = append(succs[bi], ti)
+
+		if conflict, ok := dependencyConflict(tasks[ti], tasks[bi]); ok {
+			timeline.Conflicts = append(timeline.Conflicts, conflict)
+		}
+	}
+
+	order, cyclic := topoSort(preds, succs)
+	for _, i := range cyclic {
+		timeline.Conflicts = append(timeline.Conflicts, TimelineConflict{
+			Type:    ConflictDependencyCycle,
+			TaskID:  uuidString(tasks[i].TaskID),
+			Message: fmt.Sprintf("%q is part of a dependency cycle", tasks[i].Title),
+		})
+	}
+
+	timeline.CriticalPath = criticalPath(&timeline, order, preds, succs)
+	return timeline
+}
+
+// scheduled reports whether a task date is set. Tasks created without a due
+// date used to store the zero time, which counts as unset.
+func scheduled(t pgtype.Timestamptz) bool {
+	return t.Valid && !t.Time.IsZero()
+}
+
+func dependencyConflict(task, blocker models.GetTasksByWorkspaceRow) (TimelineConflict, bool) {
+	conflict := TimelineConflict{
+		TaskID:      uuidString(task.TaskID),
+		DependsOnID: uuidString(blocker.TaskID),
+	}
+	switch {
+	case task.Status != models.TaskStatusToDo && blocker.Status != models.TaskStatusDone:
+		conflict.Type = ConflictStartedWhileBlocked
+		conflict.Message = fmt.Sprintf("%q is underway but its blocker %q is not done", task.Title, blocker.Title)
+	case !scheduled(blocker.DueDate):
+		return TimelineConflict{}, false
+	case scheduled(task.StartDate) && task.StartDate.Time.Before(blocker.DueDate.Time):
+		conflict.Type = ConflictStartsBeforeBlocker
+		conflict.Message = fmt.Sprintf("%q starts before its blocker %q is due", task.Title, blocker.Title)
+	case !scheduled(task.StartDate) && scheduled(task.DueDate) && task.DueDate.Time.Before(blocker.DueDate.Time):
+		conflict.Type = ConflictDueBeforeBlocker
+		conflict.Message = fmt.Sprintf("%q is due before its blocker %q", task.Title, blocker.Title)
+	default:
+		return TimelineConflict{}, false
+	}
+	return conflict, true
+}
+
+// topoSort orders tasks so blockers come first. Tasks caught in a cycle are
+// returned separately.
+func topoSort(preds, succs [][]int) (order, cyclic []int) {
+	remaining := make([]int, len(preds))
+	queue := make([]int, 0, len(preds))
+	for i := range preds {
+		remaining[i] = len(preds[i])
+		if remaining[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		order = append(order, i)
+		for _, s := range succs[i] {
+			remaining[s]--
+			if remaining[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+	for i, n := range remaining {
+		if n > 0 {
+			cyclic = append(cyclic, i)
+		}
+	}
+	return order, cyclic
+}
+
+// criticalPath runs a forward and backward pass over task durations and
+// marks tasks with no slack. It returns the longest chain of critical
+// tasks, blockers first.
+func criticalPath(timeline *Timeline, order []int, preds, succs [][]int) []string {
+	n := len(timeline.Tasks)
+	duration := make([]time.Duration, n)
+	for i, task := range timeline.Tasks {
+		if task.Start != nil && task.End != nil && task.End.After(*task.Start) {
+			duration[i] = task.End.Sub(*task.Start)
+		}
+	}
+
+	inOrder := make([]bool, n)
+	earlyStart := make([]time.Duration, n)
+	earlyFinish := make([]time.Duration, n)
+	var length time.Duration
+	for _, i := range order {
+		inOrder[i] = true
+		for _, p := range preds[i] {
+			earlyStart[i] = max(earlyStart[i], earlyFinish[p])
+		}
+		earlyFinish[i] = earlyStart[i] + duration[i]
+		length = max(length, earlyFinish[i])
+	}
+	if length == 0 {
+		return make([]string, 0)
+	}
+
+	lateFinish := make([]time.Duration, n)
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		lateFinish[i] = length
+		for _, s := range succs[i] {
+			if inOrder[s] {
+				lateFinish[i] = min(lateFinish[i], lateFinish[s]-duration[s])
+			}
+		}
+		slack := lateFinish[i] - earlyFinish[i]
+		timeline.Tasks[i].SlackHours = slack.Hours()
+		timeline.Tasks[i].Critical = slack < criticalSlackTolerance
+	}
+
+	// Walk back from the task that finishes last
+	last := -1
+	for _, i := range order {
+		if timeline.Tasks[i].Critical && earlyFinish[i] == length && (last == -1 || duration[i] > duration[last]) {
+			last = i
+		}
+	}
+	var path []string
+	for last != -1 {
+		path = append(path, uuidString(timeline.Tasks[last].Task.TaskID))
+		next := -1
+		for _, p := range preds[last] {
+			if inOrder[p] && timeline.Tasks[p].Critical && earlyFinish[p] == earlyStart[last] {
+				next = p
+				break
+			}
+		}
+		last = next
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/tomasohchom/motion/services/workspace/internal/models"
+)
+
+var timelineDay = time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+func testUUID(n byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{15: n}, Valid: true}
+}
+
+// timelineTask returns a to-do task starting and due the given number of
+// days after timelineDay. Negative days leave the date unset.
+func timelineTask(n byte, start, due int) models.GetTasksByWorkspaceRow {
+	task := models.GetTasksByWorkspaceRow{
+		TaskID: testUUID(n),
+		Title:  fmt.Sprintf("task %d", n),
+		Status: models.TaskStatusToDo,
+	}
+	if start >= 0 {
+		task.StartDate = pgtype.Timestamptz{Time: timelineDay.AddDate(0, 0, start), Valid: true}
+	}
+	if due >= 0 {
+		task.DueDate = pgtype.Timestamptz{Time: timelineDay.AddDate(0, 0, due), Valid: true}
+	}
+	return task
+}
+
+func dependency(task, dependsOn byte) models.TaskDependency {
+	return models.TaskDependency{TaskID: testUUID(task), DependsOnID: testUUID(dependsOn)}
+}
+
+func TestBuildTimelineCriticalPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		tasks        []models.GetTasksByWorkspaceRow
+		dependencies []models.TaskDependency
+		critical     []byte
+		slack        map[byte]float64
+	}{
+		{
+			name:     "no tasks",
+			critical: []byte{},
+		},
+		{
+			name:         "chain beside a short task",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 2), timelineTask(2, 2, 5), timelineTask(3, 5, 6), timelineTask(4, 0, 1)},
+			dependencies: []models.TaskDependency{dependency(2, 1), dependency(3, 2)},
+			critical:     []byte{1, 2, 3},
+			slack:        map[byte]float64{1: 0, 2: 0, 3: 0, 4: 5 * 24},
+		},
+		{
+			name:  "longest of two branches",
+			tasks: []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 1), timelineTask(2, 1, 2), timelineTask(3, 1, 4), timelineTask(4, 4, 5)},
+			dependencies: []models.TaskDependency{
+				dependency(2, 1), dependency(3, 1), dependency(4, 2), dependency(4, 3),
+			},
+			critical: []byte{1, 3, 4},
+			slack:    map[byte]float64{2: 2 * 24},
+		},
+		{
+			name:         "points only",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, -1, 1), timelineTask(2, -1, 2)},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+			critical:     []byte{},
+		},
+		{
+			name:         "dependencies outside the set",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 3)},
+			dependencies: []models.TaskDependency{dependency(1, 9), dependency(9, 1)},
+			critical:     []byte{1},
+		},
+		{
+			name:         "cycle",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 1), timelineTask(2, 1, 2), timelineTask(3, 0, 3)},
+			dependencies: []models.TaskDependency{dependency(1, 2), dependency(2, 1)},
+			critical:     []byte{3},
+		},
+	}
+	for _, tt := range tests {
+		timeline := buildTimeline(tt.tasks, tt.dependencies)
+
+		want := make([]string, len(tt.critical))
+		for i, n := range tt.critical {
+			want[i] = uuidString(testUUID(n))
+		}
+		if fmt.Sprint(timeline.CriticalPath) != fmt.Sprint(want) {
+			t.Errorf("%s: critical path got %v want %v", tt.name, timeline.CriticalPath, want)
+		}
+		for _, task := range timeline.Tasks {
+			n := task.Task.TaskID.Bytes[15]
+			if slack, ok := tt.slack[n]; ok && task.SlackHours != slack {
+				t.Errorf("%s: task %d slack got %v want %v", tt.name, n, task.SlackHours, slack)
+			}
+		}
+		for _, dep := range timeline.Dependencies {
+			if dep.TaskID.Bytes[15] == 9 || dep.DependsOnID.Bytes[15] == 9 {
+				t.Errorf("%s: kept dependency on a task outside the set", tt.name)
+			}
+		}
+	}
+}
+
+func TestBuildTimelineDates(t *testing.T) {
+	zero := timelineTask(3, -1, -1)
+	zero.DueDate = pgtype.Timestamptz{Valid: true}
+	tasks := []models.GetTasksByWorkspaceRow{
+		timelineTask(1, 1, 3),
+		timelineTask(2, -1, 4),
+		zero,
+		timelineTask(4, -1, -1),
+		timelineTask(5, 2, -1),
+	}
+	timeline := buildTimeline(tasks, []models.TaskDependency{dependency(1, 3)})
+
+	day := func(n int) string { return timelineDay.AddDate(0, 0, n).Format(time.DateOnly) }
+	format := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.DateOnly)
+	}
+	tests := []struct {
+		task       int
+		start, end string
+	}{
+		{0, day(1), day(3)},
+		// Tasks without a start are points on their due date
+		{1, day(4), day(4)},
+		// The zero time counts as no due date
+		{2, "-", "-"},
+		{3, "-", "-"},
+		{4, day(2), day(2)},
+	}
+	for _, tt := range tests {
+		item := timeline.Tasks[tt.task]
+		if got := format(item.Start); got != tt.start {
+			t.Errorf("task %d start got %s want %s", tt.task+1, got, tt.start)
+		}
+		if got := format(item.End); got != tt.end {
+			t.Errorf("task %d end got %s want %s", tt.task+1, got, tt.end)
+		}
+	}
+	if format(timeline.Start) != day(1) || format(timeline.End) != day(4) {
+		t.Errorf("timeline got %s to %s want %s to %s", format(timeline.Start), format(timeline.End), day(1), day(4))
+	}
+	if len(timeline.Conflicts) != 0 {
+		t.Errorf("got conflicts %+v want none", timeline.Conflicts)
+	}
+}
+
+func TestBuildTimelineConflicts(t *testing.T) {
+	started := timelineTask(2, 5, 6)
+	started.Status = models.TaskStatusInProgress
+	done := timelineTask(1, 0, 3)
+	done.Status = models.TaskStatusDone
+
+	tests := []struct {
+		name         string
+		tasks        []models.GetTasksByWorkspaceRow
+		dependencies []models.TaskDependency
+		want         []string
+	}{
+		{
+			name:  "start after due",
+			tasks: []models.GetTasksByWorkspaceRow{timelineTask(1, 3, 2)},
+			want:  []string{ConflictStartAfterDue + " 1"},
+		},
+		{
+			name:         "starts before blocker is due",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 3), timelineTask(2, 2, 5)},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+			want:         []string{ConflictStartsBeforeBlocker + " 2 on 1"},
+		},
+		{
+			name:         "due before blocker",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 3), timelineTask(2, -1, 2)},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+			want:         []string{ConflictDueBeforeBlocker + " 2 on 1"},
+		},
+		{
+			name:         "started while blocked",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 3), started},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+			want:         []string{ConflictStartedWhileBlocked + " 2 on 1"},
+		},
+		{
+			name:         "started once blocker is done",
+			tasks:        []models.GetTasksByWorkspaceRow{done, started},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+		},
+		{
+			name:         "blocker without due date",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, -1, -1), timelineTask(2, 0, 1)},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+		},
+		{
+			name:         "in order",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, 0, 3), timelineTask(2, 3, 5)},
+			dependencies: []models.TaskDependency{dependency(2, 1)},
+		},
+		{
+			name:         "cycle",
+			tasks:        []models.GetTasksByWorkspaceRow{timelineTask(1, -1, -1), timelineTask(2, -1, -1), timelineTask(3, -1, -1)},
+			dependencies: []models.TaskDependency{dependency(1, 2), dependency(2, 1), dependency(3, 1)},
+			want:         []string{ConflictDependencyCycle + " 1", ConflictDependencyCycle + " 2", ConflictDependencyCycle + " 3"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, conflict := range buildTimeline(tt.tasks, tt.dependencies).Conflicts {
+			entry := fmt.Sprintf("%s %d", conflict.Type, testUUIDNumber(t, conflict.TaskID))
+			if conflict.DependsOnID != "" {
+				entry += fmt.Sprintf(" on %d", testUUIDNumber(t, conflict.DependsOnID))
+			}
+			got = append(got, entry)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %q want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testUUIDNumber reverses testUUID
+func testUUIDNumber(t *testing.T, id string) byte {
+	t.Helper()
+	parsed, err := parseUUID(id)
+	if err != nil {
+		t.Fatalf("parseUUID(%q) = %v", id, err)
+	}
+	return parsed.Bytes[15]
+}
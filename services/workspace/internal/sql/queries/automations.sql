@@ -0,0 +1,217 @@
+-- name: CreateAutomationRule :one
+INSERT INTO automation_rules (
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at;
+
+-- name: GetAutomationRule :one
+SELECT
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at
+FROM automation_rules
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: ListAutomationRules :many
+SELECT
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at
+FROM automation_rules
+WHERE workspace_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListEnabledAutomationRules :many
+SELECT
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at
+FROM automation_rules
+WHERE
+    workspace_id = $1
+    AND trigger_type = $2
+    AND enabled
+ORDER BY created_at ASC;
+
+-- name: ListEnabledAutomationRulesByTrigger :many
+SELECT
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at
+FROM automation_rules
+WHERE
+    trigger_type = $1
+    AND enabled
+ORDER BY created_at ASC;
+
+-- name: UpdateAutomationRule :one
+UPDATE automation_rules
+SET
+    name = $3,
+    enabled = $4,
+    trigger_type = $5,
+    trigger_config = $6,
+    conditions = $7,
+    actions = $8,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING
+    id,
+    workspace_id,
+    name,
+    enabled,
+    trigger_type,
+    trigger_config,
+    conditions,
+    actions,
+    created_by,
+    created_at,
+    updated_at;
+
+-- name: DeleteAutomationRule :exec
+DELETE FROM automation_rules
+WHERE workspace_id = $1 AND id = $2;
+
+-- name: CreateAutomationRun :one
+INSERT INTO automation_runs (
+    rule_id,
+    workspace_id,
+    trigger_type,
+    subject_id,
+    depth,
+    status,
+    error,
+    started_at
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING
+    id,
+    rule_id,
+    workspace_id,
+    trigger_type,
+    subject_id,
+    depth,
+    status,
+    error,
+    started_at,
+    finished_at;
+
+-- name: ListAutomationRuns :many
+SELECT
+    id,
+    rule_id,
+    workspace_id,
+    trigger_type,
+    subject_id,
+    depth,
+    status,
+    error,
+    started_at,
+    finished_at
+FROM automation_runs
+WHERE rule_id = $1
+ORDER BY started_at DESC
+LIMIT $2;
+
+-- name: ListOverdueTasksForRule :many
+-- Overdue, unfinished tasks the rule has not yet run against.
+SELECT
+    t.id,
+    t.workspace_id,
+    t.title,
+    t.description,
+    t.assignee_id,
+    t.status,
+    t.priority,
+    t.due_date,
+    t.created_at,
+    t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date
+FROM tasks AS t
+WHERE
+    t.workspace_id = $1
+    AND t.due_date < now()
+    AND t.status <> 'Done'
+    AND NOT EXISTS (
+        SELECT 1
+        FROM automation_runs AS r
+        WHERE
+            r.rule_id = $2
+            AND r.subject_id = t.id
+    );
@@ -0,0 +1,83 @@
+-- name: UpsertCalendarFeed :one
+-- Creating a member's feed again replaces its token
+INSERT INTO calendar_feeds (
+    workspace_id,
+    user_id,
+    token_hash,
+    token_hint,
+    tasks
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (workspace_id, user_id) DO UPDATE
+SET
+    token_hash = excluded.token_hash,
+    token_hint = excluded.token_hint,
+    tasks = excluded.tasks,
+    updated_at = now()
+RETURNING
+    id,
+    workspace_id,
+    user_id,
+    token_hash,
+    token_hint,
+    tasks,
+    created_at,
+    updated_at;
+
+-- name: GetCalendarFeed :one
+SELECT
+    id,
+    workspace_id,
+    user_id,
+    token_hash,
+    token_hint,
+    tasks,
+    created_at,
+    updated_at
+FROM calendar_feeds
+WHERE
+    workspace_id = $1
+    AND user_id = $2;
+
+-- name: GetCalendarFeedByTokenHash :one
+SELECT
+    id,
+    workspace_id,
+    user_id,
+    token_hash,
+    token_hint,
+    tasks,
+    created_at,
+    updated_at
+FROM calendar_feeds
+WHERE token_hash = $1;
+
+-- name: UpdateCalendarFeedTasks :one
+UPDATE calendar_feeds
+SET
+    tasks = $3,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND user_id = $2
+RETURNING
+    id,
+    workspace_id,
+    user_id,
+    token_hash,
+    token_hint,
+    tasks,
+    created_at,
+    updated_at;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE
+    workspace_id = $1
+    AND user_id = $2;
@@ -0,0 +1,118 @@
+-- name: CreateCycle :one
+INSERT INTO cycles (
+    workspace_id,
+    name,
+    start_date,
+    end_date
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: GetCycle :one
+SELECT *
+FROM cycles
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: ListWorkspaceCycles :many
+SELECT *
+FROM cycles
+WHERE workspace_id = $1
+ORDER BY start_date ASC;
+
+-- name: UpdateCycle :one
+UPDATE cycles
+SET
+    name = $3,
+    start_date = $4,
+    end_date = $5,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING *;
+
+-- name: DeleteCycle :exec
+DELETE FROM cycles
+WHERE workspace_id = $1 AND id = $2;
+
+-- name: CountOverlappingCycles :one
+-- Cycles in the workspace, other than the given one, sharing any day with
+-- the range.
+SELECT COUNT(*) AS overlapping
+FROM cycles
+WHERE
+    workspace_id = $1
+    AND id IS DISTINCT FROM $2
+    AND start_date <= sqlc.arg('range_end')::date
+    AND end_date >= sqlc.arg('range_start')::date;
+
+-- name: GetNextCycle :one
+-- The first open cycle starting after start_date.
+SELECT *
+FROM cycles
+WHERE
+    workspace_id = $1
+    AND start_date > $2
+    AND closed_at IS NULL
+ORDER BY start_date ASC
+LIMIT 1;
+
+-- name: ListCyclesToClose :many
+-- Open cycles whose last day has passed.
+SELECT *
+FROM cycles
+WHERE
+    closed_at IS NULL
+    AND end_date < current_date
+ORDER BY start_date ASC;
+
+-- name: CloseCycle :one
+UPDATE cycles
+SET
+    closed_at = now(),
+    updated_at = now()
+WHERE
+    id = $1
+    AND closed_at IS NULL
+RETURNING *;
+
+-- name: CarryOverCycleTasks :many
+-- Moves unfinished tasks into the next cycle, returning the moved task ids.
+UPDATE tasks
+SET
+    cycle_id = sqlc.arg('next_cycle_id'),
+    updated_at = now()
+WHERE
+    cycle_id = sqlc.arg('cycle_id')
+    AND status <> 'Done'
+RETURNING id;
+
+-- name: CountUnfinishedCycleTasks :one
+SELECT COUNT(*) AS unfinished
+FROM tasks
+WHERE cycle_id = $1 AND status <> 'Done';
+
+-- name: CreateTaskCycleEvent :exec
+INSERT INTO task_cycle_events (
+    cycle_id,
+    task_id,
+    event
+)
+VALUES (
+    $1,
+    $2,
+    $3
+);
+
+-- name: ListCycleTaskEvents :many
+SELECT *
+FROM task_cycle_events
+WHERE cycle_id = $1
+ORDER BY id ASC;
@@ -0,0 +1,110 @@
+-- name: ListEventAttendees :many
+-- The attendees of the given events, members with their names
+SELECT
+    a.id,
+    a.event_id,
+    a.user_id,
+    a.email,
+    a.status,
+    a.created_at,
+    a.updated_at,
+    u.email AS user_email,
+    u.first_name,
+    u.last_name,
+    u.username
+FROM event_attendees AS a
+LEFT JOIN users AS u ON a.user_id = u.id
+WHERE a.event_id = ANY(sqlc.arg('event_ids')::UUID [])
+ORDER BY a.created_at ASC, a.id ASC;
+
+-- name: GetEventAttendee :one
+SELECT
+    id,
+    event_id,
+    user_id,
+    email,
+    status,
+    rsvp_token_hash,
+    created_at,
+    updated_at
+FROM event_attendees
+WHERE
+    event_id = $1
+    AND id = $2;
+
+-- name: GetEventAttendeeByUser :one
+SELECT
+    id,
+    event_id,
+    user_id,
+    email,
+    status,
+    rsvp_token_hash,
+    created_at,
+    updated_at
+FROM event_attendees
+WHERE
+    event_id = $1
+    AND user_id = $2;
+
+-- name: GetEventAttendeeByEmail :one
+SELECT
+    id,
+    event_id,
+    user_id,
+    email,
+    status,
+    rsvp_token_hash,
+    created_at,
+    updated_at
+FROM event_attendees
+WHERE
+    event_id = $1
+    AND email = $2;
+
+-- name: GetEventAttendeeByTokenHash :one
+-- The attendee an RSVP link was made for, and the workspace of the event
+SELECT
+    a.id,
+    a.event_id,
+    a.user_id,
+    a.email,
+    a.status,
+    e.workspace_id
+FROM event_attendees AS a
+INNER JOIN events AS e ON a.event_id = e.id
+WHERE a.rsvp_token_hash = $1;
+
+-- name: CreateEventAttendee :exec
+INSERT INTO event_attendees (event_id, user_id, email, status)
+VALUES ($1, $2, $3, $4);
+
+-- name: CopyEventAttendees :exec
+-- Gives an event the attendees of another, with their answers. RSVP links
+-- stay with the original.
+INSERT INTO event_attendees (event_id, user_id, email, status)
+SELECT
+    sqlc.arg('to_event_id')::UUID,
+    user_id,
+    email,
+    status
+FROM event_attendees
+WHERE event_id = sqlc.arg('from_event_id');
+
+-- name: SetEventAttendeeStatus :exec
+UPDATE event_attendees
+SET
+    status = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: SetEventAttendeeTokenHash :exec
+UPDATE event_attendees
+SET
+    rsvp_token_hash = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: DeleteEventAttendee :exec
+DELETE FROM event_attendees
+WHERE id = $1;
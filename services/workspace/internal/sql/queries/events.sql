@@ -0,0 +1,292 @@
+-- name: CreateEvent :one
+INSERT INTO events (
+    workspace_id,
+    name,
+    color,
+    starts_at,
+    timezone,
+    all_day,
+    duration_minutes,
+    attendees_count,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    ical_uid
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14
+)
+RETURNING
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid;
+
+-- name: GetWorkspaceEvent :one
+SELECT
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid
+FROM events
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: GetEventOverride :one
+-- The event replacing one occurrence of a recurring event
+SELECT
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid
+FROM events
+WHERE
+    recurring_event_id = $1
+    AND recurrence_id = $2;
+
+-- name: GetEventByICalUID :one
+-- The imported event, or series, with the given UID
+SELECT
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid
+FROM events
+WHERE
+    workspace_id = $1
+    AND ical_uid = $2
+    AND recurring_event_id IS NULL;
+
+-- name: ListWorkspaceEvents :many
+-- Events overlapping the window, recurring ones through any of their
+-- occurrences, optionally of the given colors or with the given attendee,
+-- a member's ID or an email. Either end of the window may be left open.
+SELECT
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid
+FROM events
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND (
+        sqlc.narg('window_end')::TIMESTAMPTZ IS NULL
+        OR starts_at < sqlc.narg('window_end')
+    )
+    AND (
+        sqlc.narg('window_start')::TIMESTAMPTZ IS NULL
+        OR ends_at IS NULL
+        OR ends_at > sqlc.narg('window_start')
+    )
+    AND (
+        sqlc.narg('colors')::TEXT [] IS NULL
+        OR color = ANY(sqlc.narg('colors')::TEXT [])
+    )
+    AND (
+        sqlc.narg('attendee')::TEXT IS NULL
+        OR EXISTS (
+            SELECT 1
+            FROM event_attendees AS a
+            WHERE
+                a.event_id = events.id
+                AND (
+                    a.user_id = sqlc.narg('attendee')
+                    OR a.email = lower(sqlc.narg('attendee'))
+                )
+        )
+    )
+ORDER BY starts_at ASC, name ASC;
+
+-- name: ListEventRecurrenceIDs :many
+-- The occurrences of the given recurring events that were edited on their
+-- own
+SELECT
+    recurring_event_id,
+    recurrence_id
+FROM events
+WHERE recurring_event_id = ANY(sqlc.arg('event_ids')::UUID []);
+
+-- name: UpdateEvent :one
+UPDATE events
+SET
+    name = $3,
+    color = $4,
+    starts_at = $5,
+    timezone = $6,
+    all_day = $7,
+    duration_minutes = $8,
+    rrule = $9,
+    exdates = $10,
+    ends_at = $11,
+    sequence = sequence + 1,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid;
+
+-- name: ListEventOverrides :many
+-- The occurrences of a recurring event edited on their own, from since on
+SELECT
+    id,
+    workspace_id,
+    name,
+    color,
+    duration_minutes,
+    attendees_count,
+    created_at,
+    updated_at,
+    starts_at,
+    timezone,
+    all_day,
+    rrule,
+    exdates,
+    recurring_event_id,
+    recurrence_id,
+    ends_at,
+    sequence,
+    ical_uid
+FROM events
+WHERE
+    recurring_event_id = $1
+    AND recurrence_id >= $2
+ORDER BY recurrence_id ASC;
+
+-- name: SetEventRecurrence :exec
+UPDATE events
+SET
+    recurring_event_id = $2,
+    recurrence_id = $3,
+    sequence = sequence + 1,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: RefreshEventAttendeesCount :exec
+UPDATE events
+SET attendees_count = (
+    SELECT count(*)
+    FROM event_attendees
+    WHERE event_id = events.id
+)
+WHERE id = $1;
+
+-- name: DeleteEventOverrides :exec
+-- Drops the edited occurrences of a recurring event, from since on when
+-- given
+DELETE FROM events
+WHERE
+    recurring_event_id = sqlc.arg('event_id')
+    AND (
+        sqlc.narg('since')::TIMESTAMPTZ IS NULL
+        OR recurrence_id >= sqlc.narg('since')
+    );
+
+-- name: DeleteEvent :execrows
+DELETE FROM events
+WHERE
+    workspace_id = $1
+    AND id = $2;
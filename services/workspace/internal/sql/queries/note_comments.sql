@@ -0,0 +1,160 @@
+-- name: CreateNoteCommentThread :one
+INSERT INTO note_comment_threads (
+    note_id,
+    created_by,
+    anchor_start,
+    anchor_end,
+    block_id,
+    quote
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING
+    id,
+    note_id,
+    created_by,
+    anchor_start,
+    anchor_end,
+    block_id,
+    quote,
+    resolved_at,
+    resolved_by,
+    orphaned_at,
+    created_at,
+    updated_at;
+
+-- name: GetNoteCommentThread :one
+SELECT
+    id,
+    note_id,
+    created_by,
+    anchor_start,
+    anchor_end,
+    block_id,
+    quote,
+    resolved_at,
+    resolved_by,
+    orphaned_at,
+    created_at,
+    updated_at
+FROM note_comment_threads
+WHERE
+    note_id = $1
+    AND id = $2;
+
+-- name: ListNoteCommentThreads :many
+-- Threads in the order they appear in the note, orphaned ones last. A null
+-- resolved lists both open and resolved threads.
+SELECT
+    id,
+    note_id,
+    created_by,
+    anchor_start,
+    anchor_end,
+    block_id,
+    quote,
+    resolved_at,
+    resolved_by,
+    orphaned_at,
+    created_at,
+    updated_at
+FROM note_comment_threads
+WHERE
+    note_id = sqlc.arg('note_id')
+    AND (
+        sqlc.narg('resolved')::BOOLEAN IS NULL
+        OR (resolved_at IS NOT NULL) = sqlc.narg('resolved')::BOOLEAN
+    )
+ORDER BY
+    orphaned_at IS NOT NULL ASC,
+    anchor_start ASC NULLS LAST,
+    created_at ASC;
+
+-- name: UpdateNoteCommentThreadAnchor :exec
+-- Moves a thread's anchor after the note changed. orphaned_at keeps the
+-- time the anchored text was first found missing.
+UPDATE note_comment_threads
+SET
+    anchor_start = sqlc.narg('anchor_start'),
+    anchor_end = sqlc.narg('anchor_end'),
+    quote = sqlc.arg('quote'),
+    orphaned_at = CASE
+        WHEN sqlc.arg('orphaned')::BOOLEAN THEN coalesce(orphaned_at, now())
+    END,
+    updated_at = now()
+WHERE id = sqlc.arg('id');
+
+-- name: SetNoteCommentThreadResolved :one
+UPDATE note_comment_threads
+SET
+    resolved_at = CASE
+        WHEN sqlc.arg('resolved')::BOOLEAN THEN coalesce(resolved_at, now())
+    END,
+    resolved_by = CASE
+        WHEN sqlc.arg('resolved')::BOOLEAN THEN coalesce(resolved_by, sqlc.narg('resolved_by'))
+    END,
+    updated_at = now()
+WHERE
+    note_id = sqlc.arg('note_id')
+    AND id = sqlc.arg('id')
+RETURNING
+    id,
+    note_id,
+    created_by,
+    anchor_start,
+    anchor_end,
+    block_id,
+    quote,
+    resolved_at,
+    resolved_by,
+    orphaned_at,
+    created_at,
+    updated_at;
+
+-- name: CreateNoteComment :one
+INSERT INTO note_comments (
+    thread_id,
+    author_id,
+    body
+)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+RETURNING
+    id,
+    thread_id,
+    author_id,
+    body,
+    created_at;
+
+-- name: ListNoteComments :many
+-- Comments on every thread of the note, oldest first within each thread.
+SELECT
+    c.id,
+    c.thread_id,
+    c.author_id,
+    c.body,
+    c.created_at
+FROM note_comments AS c
+INNER JOIN note_comment_threads AS t ON c.thread_id = t.id
+WHERE t.note_id = $1
+ORDER BY c.thread_id ASC, c.created_at ASC;
+
+-- name: ListNoteThreadComments :many
+SELECT
+    id,
+    thread_id,
+    author_id,
+    body,
+    created_at
+FROM note_comments
+WHERE thread_id = $1
+ORDER BY created_at ASC;
@@ -0,0 +1,113 @@
+-- name: CreateNoteLink :exec
+INSERT INTO note_links (
+    workspace_id,
+    source_id,
+    target_id,
+    target_ref,
+    kind
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (source_id, kind, target_ref) DO NOTHING;
+
+-- name: DeleteNoteLinks :exec
+DELETE FROM note_links
+WHERE source_id = $1;
+
+-- name: ResolveNoteTitles :many
+-- Maps lowercased titles to notes. The oldest note wins when titles repeat.
+SELECT DISTINCT ON (lower(title))
+    id,
+    lower(title) AS title_key
+FROM notes
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND lower(title) = ANY(sqlc.arg('titles')::TEXT [])
+ORDER BY lower(title) ASC, created_at ASC;
+
+-- name: FilterWorkspaceNoteIDs :many
+SELECT id
+FROM notes
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND id = ANY(sqlc.arg('ids')::UUID []);
+
+-- name: ResolveBrokenNoteLinks :exec
+-- Points dangling wiki links at a note that now has their title.
+UPDATE note_links
+SET target_id = sqlc.arg('target_id')
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND target_id IS NULL
+    AND kind = 'wiki'
+    AND lower(target_ref) = lower(sqlc.arg('title'));
+
+-- name: ListNoteBacklinks :many
+-- Links to target_id from notes viewer_id can see, or from every note when
+-- viewer_id is null.
+SELECT
+    l.source_id,
+    l.target_ref,
+    l.kind,
+    n.title,
+    n.content,
+    n.updated_at
+FROM note_links AS l
+INNER JOIN notes AS n ON l.source_id = n.id
+WHERE
+    l.target_id = sqlc.arg('target_id')
+    AND (
+        sqlc.narg('viewer_id')::TEXT IS NULL
+        OR (
+            n.visibility = 'workspace'
+            OR n.author_id = sqlc.narg('viewer_id')::TEXT
+            OR (
+                n.visibility = 'restricted'
+                AND EXISTS (
+                    SELECT 1
+                    FROM note_members AS m
+                    WHERE m.note_id = n.id AND m.user_id = sqlc.narg('viewer_id')::TEXT
+                )
+            )
+        )
+    )
+ORDER BY n.updated_at DESC;
+
+-- name: ListBrokenNoteLinks :many
+SELECT
+    l.source_id,
+    n.title AS source_title,
+    l.target_ref,
+    l.kind
+FROM note_links AS l
+INNER JOIN notes AS n ON l.source_id = n.id
+WHERE
+    l.workspace_id = sqlc.arg('workspace_id')
+    AND l.target_id IS NULL
+    AND (
+        n.visibility = 'workspace'
+        OR n.author_id = sqlc.arg('viewer_id')::TEXT
+        OR (
+            n.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = n.id AND m.user_id = sqlc.arg('viewer_id')::TEXT
+            )
+        )
+    )
+ORDER BY n.title ASC, l.target_ref ASC;
+
+-- name: ListWorkspaceNoteLinks :many
+SELECT
+    source_id,
+    target_id,
+    count(*) AS link_count
+FROM note_links
+WHERE workspace_id = $1 AND target_id IS NOT NULL
+GROUP BY source_id, target_id;
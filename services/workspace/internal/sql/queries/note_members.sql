@@ -0,0 +1,34 @@
+-- name: GetNoteMemberRole :one
+SELECT role
+FROM note_members
+WHERE note_id = $1 AND user_id = $2;
+
+-- name: ListNoteMembers :many
+SELECT
+    m.user_id,
+    m.role,
+    u.email,
+    u.first_name,
+    u.last_name,
+    u.username,
+    m.created_at
+FROM note_members AS m
+INNER JOIN users AS u ON m.user_id = u.id
+WHERE m.note_id = $1
+ORDER BY m.created_at ASC, u.username ASC;
+
+-- name: CreateNoteMember :exec
+INSERT INTO note_members (note_id, user_id, role)
+VALUES ($1, $2, $3);
+
+-- name: DeleteNoteMembers :exec
+DELETE FROM note_members
+WHERE note_id = $1;
+
+-- name: CountWorkspaceMembers :one
+-- How many of user_ids belong to the workspace.
+SELECT count(*) AS member_count
+FROM workspace_users
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND user_id = ANY(sqlc.arg('user_ids')::TEXT []);
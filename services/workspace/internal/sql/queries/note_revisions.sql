@@ -0,0 +1,38 @@
+-- name: CreateNoteRevision :one
+INSERT INTO note_revisions (
+    note_id,
+    revision,
+    author_id,
+    title,
+    content,
+    tags
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: ListNoteRevisions :many
+SELECT
+    id,
+    note_id,
+    revision,
+    author_id,
+    title,
+    tags,
+    created_at
+FROM note_revisions
+WHERE note_id = $1
+ORDER BY revision DESC;
+
+-- name: GetNoteRevision :one
+SELECT *
+FROM note_revisions
+WHERE
+    note_id = $1
+    AND revision = $2;
@@ -0,0 +1,100 @@
+-- name: CreateNoteShare :one
+INSERT INTO note_shares (
+    workspace_id,
+    note_id,
+    token_hash,
+    token_hint,
+    password_hash,
+    created_by,
+    expires_at
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING
+    id,
+    workspace_id,
+    note_id,
+    token_hash,
+    token_hint,
+    password_hash,
+    created_by,
+    expires_at,
+    revoked_at,
+    created_at;
+
+-- name: GetNoteShareByTokenHash :one
+SELECT
+    id,
+    workspace_id,
+    note_id,
+    token_hash,
+    token_hint,
+    password_hash,
+    created_by,
+    expires_at,
+    revoked_at,
+    created_at
+FROM note_shares
+WHERE token_hash = $1;
+
+-- name: ListNoteShares :many
+SELECT
+    s.id,
+    s.note_id,
+    s.token_hint,
+    s.password_hash IS NOT NULL AS has_password,
+    s.created_by,
+    s.expires_at,
+    s.revoked_at,
+    s.created_at,
+    count(a.id) AS access_count,
+    max(a.accessed_at)::TIMESTAMPTZ AS last_accessed_at
+FROM note_shares AS s
+LEFT JOIN note_share_accesses AS a
+    ON s.id = a.share_id AND a.status = 'ok'
+WHERE s.note_id = $1
+GROUP BY s.id
+ORDER BY s.created_at DESC;
+
+-- name: RevokeNoteShare :execrows
+UPDATE note_shares
+SET revoked_at = COALESCE(revoked_at, now())
+WHERE note_id = $1 AND id = $2;
+
+-- name: CreateNoteShareAccess :exec
+INSERT INTO note_share_accesses (
+    share_id,
+    status,
+    format,
+    ip_address,
+    user_agent
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: ListNoteShareAccesses :many
+SELECT
+    a.id,
+    a.share_id,
+    a.status,
+    a.format,
+    a.ip_address,
+    a.user_agent,
+    a.accessed_at
+FROM note_share_accesses AS a
+INNER JOIN note_shares AS s ON a.share_id = s.id
+WHERE s.note_id = $1 AND a.share_id = $2
+ORDER BY a.accessed_at DESC
+LIMIT 500;
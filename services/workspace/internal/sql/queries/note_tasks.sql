@@ -0,0 +1,32 @@
+-- name: CreateNoteTask :exec
+INSERT INTO note_tasks (task_id, note_id, block_id)
+VALUES ($1, $2, $3);
+
+-- name: ListNoteTasks :many
+SELECT
+    nt.task_id,
+    nt.block_id,
+    t.status
+FROM note_tasks AS nt
+INNER JOIN tasks AS t ON nt.task_id = t.id
+WHERE nt.note_id = $1;
+
+-- name: GetTaskNote :one
+SELECT
+    nt.note_id,
+    nt.block_id,
+    n.workspace_id
+FROM note_tasks AS nt
+INNER JOIN notes AS n ON nt.note_id = n.id
+WHERE nt.task_id = $1;
+
+-- name: ListWorkspaceUsersByUsername :many
+SELECT
+    u.id,
+    u.username
+FROM workspace_users AS wu
+INNER JOIN users AS u ON wu.user_id = u.id
+WHERE
+    wu.workspace_id = $1
+    AND u.username = ANY(sqlc.arg('usernames')::TEXT [])
+ORDER BY u.username;
@@ -4,14 +4,20 @@ INSERT INTO notes (
     author_id,
     title,
     content,
-    tags
+    tags,
+    parent_id,
+    position,
+    visibility
 )
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8
 )
 RETURNING
     id,
@@ -21,7 +27,11 @@ RETURNING
     content,
     tags,
     created_at,
-    updated_at;
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility;
 
 -- name: GetWorkspaceNote :one
 SELECT
@@ -32,13 +42,63 @@ SELECT
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 FROM notes
 WHERE
     workspace_id = $1
     AND id = $2;
 
+-- name: LockWorkspaceNote :one
+-- Holds the note's content steady until the transaction ends.
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = $1
+    AND id = $2
+FOR SHARE;
+
+-- name: LockWorkspaceNoteForUpdate :one
+-- Holds the note for a write that doesn't come from an editor, such as
+-- ticking a checklist item when its task is completed.
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = $1
+    AND id = $2
+FOR UPDATE;
+
 -- name: ListWorkspaceNotes :many
+-- Notes viewer_id can see that carry every tag in all_tags and, unless
+-- any_tags is empty, at least one of any_tags. Tags are compared in lower
+-- case.
 SELECT
     id,
     workspace_id,
@@ -47,21 +107,261 @@ SELECT
     content,
     tags,
     created_at,
-    updated_at
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
 FROM notes
-WHERE workspace_id = $1
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND notes_lower_tags(tags) @> sqlc.arg('all_tags')::TEXT []
+    AND (
+        cardinality(sqlc.arg('any_tags')::TEXT []) = 0
+        OR notes_lower_tags(tags) && sqlc.arg('any_tags')::TEXT []
+    )
+    AND (
+        notes.visibility = 'workspace'
+        OR notes.author_id = sqlc.arg('viewer_id')::TEXT
+        OR (
+            notes.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = notes.id AND m.user_id = sqlc.arg('viewer_id')::TEXT
+            )
+        )
+    )
 ORDER BY updated_at DESC;
 
+-- name: ListWorkspaceTags :many
+-- Tags in use on the notes viewer_id can see, grouped case-insensitively
+-- under their most common spelling.
+SELECT
+    mode() WITHIN GROUP (ORDER BY t.tag)::TEXT AS tag,
+    count(DISTINCT n.id) AS note_count
+FROM notes AS n
+CROSS JOIN LATERAL unnest(n.tags) AS t (tag)
+WHERE
+    n.workspace_id = sqlc.arg('workspace_id')
+    AND (
+        n.visibility = 'workspace'
+        OR n.author_id = sqlc.arg('viewer_id')::TEXT
+        OR (
+            n.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = n.id AND m.user_id = sqlc.arg('viewer_id')::TEXT
+            )
+        )
+    )
+GROUP BY lower(t.tag)
+ORDER BY note_count DESC, tag ASC;
+
+-- name: LockNotesWithTags :many
+-- Notes editor_id can edit carrying any of tags, compared
+-- case-insensitively, locked for update.
+SELECT
+    id,
+    workspace_id,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at,
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility
+FROM notes
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND EXISTS (
+        SELECT 1
+        FROM unnest(notes.tags) AS t (tag)
+        WHERE lower(t.tag) = ANY(sqlc.arg('tags')::TEXT [])
+    )
+    AND (
+        notes.visibility = 'workspace'
+        OR notes.author_id = sqlc.arg('editor_id')::TEXT
+        OR (
+            notes.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE
+                    m.note_id = notes.id
+                    AND m.user_id = sqlc.arg('editor_id')::TEXT
+                    AND m.role <> 'viewer'
+            )
+        )
+    )
+ORDER BY id ASC
+FOR UPDATE;
+
+-- name: ListNoteChildren :many
+-- Children of a note in sibling order, or top-level notes when parent_id is
+-- null. child_count lets clients load deeper levels lazily. A null viewer_id
+-- includes notes regardless of visibility.
+SELECT
+    n.id,
+    n.workspace_id,
+    n.author_id,
+    n.title,
+    n.content,
+    n.tags,
+    n.created_at,
+    n.updated_at,
+    n.version,
+    n.parent_id,
+    n.position,
+    n.visibility,
+    (
+        SELECT count(*)
+        FROM notes AS c
+        WHERE c.parent_id = n.id
+    ) AS child_count
+FROM notes AS n
+WHERE
+    n.workspace_id = sqlc.arg('workspace_id')
+    AND n.parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::UUID
+    AND (
+        sqlc.narg('viewer_id')::TEXT IS NULL
+        OR (
+            n.visibility = 'workspace'
+            OR n.author_id = sqlc.narg('viewer_id')::TEXT
+            OR (
+                n.visibility = 'restricted'
+                AND EXISTS (
+                    SELECT 1
+                    FROM note_members AS m
+                    WHERE m.note_id = n.id AND m.user_id = sqlc.narg('viewer_id')::TEXT
+                )
+            )
+        )
+    )
+ORDER BY n.position ASC, n.created_at ASC;
+
+-- name: ListNoteAncestors :many
+-- The chain from the root down to the note itself, leaving out notes
+-- viewer_id cannot see. path stops the walk should the parents ever loop.
+WITH RECURSIVE chain AS (
+    SELECT
+        id,
+        parent_id,
+        title,
+        0 AS depth,
+        ARRAY[id] AS path
+    FROM notes
+    WHERE workspace_id = sqlc.arg('workspace_id') AND id = sqlc.arg('id')
+
+    UNION ALL
+
+    SELECT
+        n.id,
+        n.parent_id,
+        n.title,
+        c.depth + 1 AS depth,
+        c.path || n.id AS path
+    FROM notes AS n
+    INNER JOIN chain AS c ON n.id = c.parent_id
+    WHERE NOT n.id = ANY(c.path)
+)
+
+SELECT
+    c.id,
+    c.parent_id,
+    c.title
+FROM chain AS c
+INNER JOIN notes AS n ON c.id = n.id
+WHERE (
+    n.visibility = 'workspace'
+    OR n.author_id = sqlc.arg('viewer_id')::TEXT
+    OR (
+        n.visibility = 'restricted'
+        AND EXISTS (
+            SELECT 1
+            FROM note_members AS m
+            WHERE m.note_id = n.id AND m.user_id = sqlc.arg('viewer_id')::TEXT
+        )
+    )
+)
+ORDER BY c.depth DESC;
+
+-- name: LockNoteTree :exec
+-- Serializes changes to a workspace's note hierarchy until the transaction
+-- ends, so concurrent moves cannot together create a cycle.
+SELECT pg_advisory_xact_lock(hashtextextended('notes:' || sqlc.arg('workspace_id')::UUID::TEXT, 0));
+
+-- name: IsNoteInSubtree :one
+-- Whether candidate_id is root_id or one of its descendants, found by
+-- walking up from candidate_id.
+WITH RECURSIVE chain AS (
+    SELECT
+        id,
+        parent_id
+    FROM notes
+    WHERE id = sqlc.arg('candidate_id')
+
+    UNION
+
+    SELECT
+        n.id,
+        n.parent_id
+    FROM notes AS n
+    INNER JOIN chain AS c ON n.id = c.parent_id
+)
+
+SELECT EXISTS (
+    SELECT 1
+    FROM chain
+    WHERE id = sqlc.arg('root_id')
+) AS in_subtree;
+
+-- name: NextNotePosition :one
+SELECT COALESCE(MAX(position) + 1, 0)::INTEGER AS position
+FROM notes
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::UUID;
+
+-- name: SetNoteParent :exec
+UPDATE notes
+SET parent_id = sqlc.narg('parent_id')
+WHERE workspace_id = sqlc.arg('workspace_id') AND id = sqlc.arg('id');
+
+-- name: ReorderNotes :execrows
+-- Sets positions to match the order of ids.
+UPDATE notes AS n
+SET position = o.ord - 1
+FROM unnest(sqlc.arg('ids')::UUID []) WITH ORDINALITY AS o (id, ord)
+WHERE n.workspace_id = sqlc.arg('workspace_id') AND n.id = o.id;
+
+-- name: ReparentNoteChildren :execrows
+-- Moves a note's children under new_parent_id, after the notes already
+-- there.
+UPDATE notes
+SET
+    parent_id = sqlc.narg('new_parent_id'),
+    position = sqlc.arg('base_position') + position
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND parent_id = sqlc.arg('parent_id');
+
 -- name: UpdateNote :one
 UPDATE notes
 SET
     title = $3,
     content = $4,
     tags = $5,
+    version = version + 1,
     updated_at = now()
 WHERE
     workspace_id = $1
     AND id = $2
+    AND version = $6
 RETURNING
     id,
     workspace_id,
@@ -70,8 +370,73 @@ RETURNING
     content,
     tags,
     created_at,
-    updated_at;
+    updated_at,
+    version,
+    parent_id,
+    position,
+    visibility;
 
--- name: DeleteNote :exec
-DELETE FROM notes
+-- name: SetNoteVisibility :exec
+UPDATE notes
+SET visibility = $3
 WHERE workspace_id = $1 AND id = $2;
+
+-- name: DeleteNote :execrows
+-- A null version deletes the note whatever its version.
+DELETE FROM notes
+WHERE
+    workspace_id = $1
+    AND id = $2
+    AND (sqlc.narg('version')::INTEGER IS NULL OR version = sqlc.narg('version'));
+
+-- name: SearchWorkspaceNotes :many
+-- Ranked full-text search over the notes viewer_id can see. An empty tags
+-- array or null author matches all notes; tags are compared in lower case.
+-- Highlights are HTML with matches in <mark>; the text is escaped first so
+-- notes cannot add markup of their own.
+WITH search AS (
+    SELECT to_tsquery('english', sqlc.arg('query')) AS query
+)
+
+SELECT
+    n.id,
+    n.workspace_id,
+    n.author_id,
+    n.title,
+    n.tags,
+    n.created_at,
+    n.updated_at,
+    n.version,
+    ts_rank_cd(notes_search_vector(n.title, n.tags, n.content), s.query) AS rank,
+    ts_headline(
+        'english', notes_html_escape(n.title), s.query,
+        'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'
+    ) AS title_highlight,
+    ts_headline(
+        'english', notes_html_escape(n.content), s.query,
+        'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>'
+    ) AS snippet
+FROM notes AS n
+CROSS JOIN search AS s
+WHERE
+    n.workspace_id = sqlc.arg('workspace_id')
+    AND notes_search_vector(n.title, n.tags, n.content) @@ s.query
+    AND notes_lower_tags(n.tags) @> sqlc.arg('tags')::TEXT []
+    AND (
+        sqlc.narg('author_id')::TEXT IS NULL
+        OR n.author_id = sqlc.narg('author_id')::TEXT
+    )
+    AND (
+        n.visibility = 'workspace'
+        OR n.author_id = sqlc.arg('viewer_id')::TEXT
+        OR (
+            n.visibility = 'restricted'
+            AND EXISTS (
+                SELECT 1
+                FROM note_members AS m
+                WHERE m.note_id = n.id AND m.user_id = sqlc.arg('viewer_id')::TEXT
+            )
+        )
+    )
+ORDER BY rank DESC, n.updated_at DESC
+LIMIT sqlc.arg('result_limit');
@@ -0,0 +1,93 @@
+-- name: CreateProject :one
+INSERT INTO projects (
+    workspace_id,
+    name,
+    description,
+    status,
+    lead_id,
+    target_date
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetProject :one
+SELECT *
+FROM projects
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: ListWorkspaceProjects :many
+SELECT *
+FROM projects
+WHERE workspace_id = $1
+ORDER BY created_at ASC;
+
+-- name: UpdateProject :one
+UPDATE projects
+SET
+    name = $3,
+    description = $4,
+    status = $5,
+    lead_id = $6,
+    target_date = $7,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING *;
+
+-- name: DeleteProject :exec
+DELETE FROM projects
+WHERE workspace_id = $1 AND id = $2;
+
+-- name: CreateMilestone :one
+INSERT INTO milestones (
+    project_id,
+    name,
+    description,
+    target_date
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: GetMilestone :one
+SELECT *
+FROM milestones
+WHERE
+    project_id = $1
+    AND id = $2;
+
+-- name: ListProjectMilestones :many
+SELECT *
+FROM milestones
+WHERE project_id = $1
+ORDER BY target_date ASC NULLS LAST, created_at ASC;
+
+-- name: UpdateMilestone :one
+UPDATE milestones
+SET
+    name = $3,
+    description = $4,
+    target_date = $5,
+    updated_at = now()
+WHERE
+    project_id = $1
+    AND id = $2
+RETURNING *;
+
+-- name: DeleteMilestone :exec
+DELETE FROM milestones
+WHERE project_id = $1 AND id = $2;
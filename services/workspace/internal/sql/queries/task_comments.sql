@@ -0,0 +1,28 @@
+-- name: CreateTaskComment :one
+INSERT INTO task_comments (
+    task_id,
+    author_id,
+    body
+)
+VALUES (
+    $1,
+    $2,
+    $3
+)
+RETURNING
+    id,
+    task_id,
+    author_id,
+    body,
+    created_at;
+
+-- name: ListTaskComments :many
+SELECT
+    id,
+    task_id,
+    author_id,
+    body,
+    created_at
+FROM task_comments
+WHERE task_id = $1
+ORDER BY created_at ASC;
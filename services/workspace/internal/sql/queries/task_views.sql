@@ -0,0 +1,100 @@
+-- name: CreateTaskView :one
+INSERT INTO task_views (
+    workspace_id,
+    owner_id,
+    name,
+    visibility,
+    filters,
+    sort,
+    group_by,
+    columns
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING
+    id,
+    workspace_id,
+    owner_id,
+    name,
+    visibility,
+    filters,
+    sort,
+    group_by,
+    columns,
+    created_at,
+    updated_at;
+
+-- name: GetTaskView :one
+SELECT
+    id,
+    workspace_id,
+    owner_id,
+    name,
+    visibility,
+    filters,
+    sort,
+    group_by,
+    columns,
+    created_at,
+    updated_at
+FROM task_views
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: ListVisibleTaskViews :many
+SELECT
+    id,
+    workspace_id,
+    owner_id,
+    name,
+    visibility,
+    filters,
+    sort,
+    group_by,
+    columns,
+    created_at,
+    updated_at
+FROM task_views
+WHERE
+    workspace_id = $1
+    AND (owner_id = $2 OR visibility = 'workspace')
+ORDER BY name ASC;
+
+-- name: UpdateTaskView :one
+UPDATE task_views
+SET
+    name = $3,
+    visibility = $4,
+    filters = $5,
+    sort = $6,
+    group_by = $7,
+    columns = $8,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING
+    id,
+    workspace_id,
+    owner_id,
+    name,
+    visibility,
+    filters,
+    sort,
+    group_by,
+    columns,
+    created_at,
+    updated_at;
+
+-- name: DeleteTaskView :exec
+DELETE FROM task_views
+WHERE workspace_id = $1 AND id = $2;
@@ -6,7 +6,8 @@ INSERT INTO tasks (
     assignee_id,
     status,
     priority,
-    due_date
+    due_date,
+    start_date
 ) VALUES (
     $1, -- workspace_id
     $2, -- title
@@ -14,7 +15,8 @@ INSERT INTO tasks (
     $4, -- assignee_id
     $5, -- status
     $6, -- priority
-    $7  -- due_date
+    $7, -- due_date
+    $8  -- start_date
 )
 RETURNING *;
 
@@ -29,6 +31,10 @@ SELECT
     t.due_date,
     t.created_at,
     t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
 
     -- Assignee info
     u.id AS assignee_id,
@@ -52,6 +58,10 @@ SELECT
     t.due_date,
     t.created_at,
     t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
 
     -- Assignee info
     u.id AS assignee_id,
@@ -71,10 +81,151 @@ SET
     assignee_id = $4, -- assignee_id
     status = $5, -- status
     priority = $6, -- priority
-    due_date = $7  -- due_date
+    due_date = $7, -- due_date
+    start_date = $8  -- start_date
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteTask :exec
 DELETE FROM tasks
 WHERE id = $1;
+
+-- name: SetTaskProject :one
+UPDATE tasks
+SET
+    project_id = $2,
+    milestone_id = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetTasksByProject :many
+SELECT
+    t.id AS task_id,
+    t.workspace_id,
+    t.title,
+    t.description,
+    t.status,
+    t.priority,
+    t.due_date,
+    t.created_at,
+    t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
+
+    -- Assignee info
+    u.id AS assignee_id,
+    u.first_name AS assignee_first_name,
+    u.last_name AS assignee_last_name,
+    u.username AS assignee_username,
+    u.email AS assignee_email
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
+WHERE t.project_id = $1
+ORDER BY t.created_at DESC;
+
+-- name: GetProjectTaskStatusCounts :many
+SELECT
+    milestone_id,
+    status,
+    COUNT(*) AS task_count
+FROM tasks
+WHERE project_id = $1
+GROUP BY milestone_id, status;
+
+-- name: GetWorkspaceProjectTaskStatusCounts :many
+SELECT
+    project_id,
+    status,
+    COUNT(*) AS task_count
+FROM tasks
+WHERE
+    workspace_id = $1
+    AND project_id IS NOT NULL
+GROUP BY project_id, status;
+
+-- name: SetTaskCycle :one
+UPDATE tasks
+SET
+    cycle_id = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetTasksByCycle :many
+SELECT
+    t.id AS task_id,
+    t.workspace_id,
+    t.title,
+    t.description,
+    t.status,
+    t.priority,
+    t.due_date,
+    t.created_at,
+    t.updated_at,
+    t.project_id,
+    t.milestone_id,
+    t.cycle_id,
+    t.start_date,
+
+    -- Assignee info
+    u.id AS assignee_id,
+    u.first_name AS assignee_first_name,
+    u.last_name AS assignee_last_name,
+    u.username AS assignee_username,
+    u.email AS assignee_email
+FROM tasks AS t
+LEFT JOIN users AS u ON t.assignee_id = u.id
+WHERE t.cycle_id = $1
+ORDER BY t.created_at DESC;
+
+-- name: ListTaskDependencies :many
+SELECT *
+FROM task_dependencies
+WHERE task_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListWorkspaceTaskDependencies :many
+SELECT
+    d.task_id,
+    d.depends_on_id,
+    d.created_at
+FROM task_dependencies AS d
+INNER JOIN tasks AS t ON d.task_id = t.id
+WHERE t.workspace_id = $1;
+
+-- name: CreateTaskDependency :exec
+INSERT INTO task_dependencies (
+    task_id,
+    depends_on_id
+)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTaskDependency :execrows
+DELETE FROM task_dependencies
+WHERE task_id = $1 AND depends_on_id = $2;
+
+-- name: DependencyCreatesCycle :one
+-- Reports whether the blocker already depends, directly or transitively, on
+-- the task.
+WITH RECURSIVE chain AS (
+    SELECT depends_on_id
+    FROM task_dependencies
+    WHERE task_id = sqlc.arg('blocker_id')
+    UNION
+    SELECT d.depends_on_id
+    FROM task_dependencies AS d
+    INNER JOIN chain AS c ON d.task_id = c.depends_on_id
+)
+
+SELECT EXISTS (
+    SELECT 1
+    FROM chain
+    WHERE depends_on_id = sqlc.arg('task_id')
+) AS creates_cycle;
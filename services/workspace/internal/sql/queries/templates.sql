@@ -0,0 +1,123 @@
+-- name: CreateTemplate :one
+INSERT INTO templates (
+    workspace_id,
+    kind,
+    name,
+    title,
+    content,
+    tags,
+    checklist,
+    priority,
+    assignee_id,
+    due_in_days,
+    created_by
+)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+)
+RETURNING
+    id,
+    workspace_id,
+    kind,
+    name,
+    title,
+    content,
+    tags,
+    checklist,
+    priority,
+    assignee_id,
+    due_in_days,
+    created_by,
+    created_at,
+    updated_at;
+
+-- name: GetTemplate :one
+SELECT
+    id,
+    workspace_id,
+    kind,
+    name,
+    title,
+    content,
+    tags,
+    checklist,
+    priority,
+    assignee_id,
+    due_in_days,
+    created_by,
+    created_at,
+    updated_at
+FROM templates
+WHERE
+    workspace_id = $1
+    AND id = $2;
+
+-- name: ListTemplates :many
+-- A null kind lists both note and task templates.
+SELECT
+    id,
+    workspace_id,
+    kind,
+    name,
+    title,
+    content,
+    tags,
+    checklist,
+    priority,
+    assignee_id,
+    due_in_days,
+    created_by,
+    created_at,
+    updated_at
+FROM templates
+WHERE
+    workspace_id = sqlc.arg('workspace_id')
+    AND kind = coalesce(sqlc.narg('kind'), kind)
+ORDER BY kind ASC, lower(name) ASC;
+
+-- name: UpdateTemplate :one
+UPDATE templates
+SET
+    name = $3,
+    title = $4,
+    content = $5,
+    tags = $6,
+    checklist = $7,
+    priority = $8,
+    assignee_id = $9,
+    due_in_days = $10,
+    updated_at = now()
+WHERE
+    workspace_id = $1
+    AND id = $2
+RETURNING
+    id,
+    workspace_id,
+    kind,
+    name,
+    title,
+    content,
+    tags,
+    checklist,
+    priority,
+    assignee_id,
+    due_in_days,
+    created_by,
+    created_at,
+    updated_at;
+
+-- name: DeleteTemplate :execrows
+DELETE FROM templates
+WHERE
+    workspace_id = $1
+    AND id = $2;
@@ -0,0 +1,21 @@
+CREATE TABLE event_attendees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    -- A workspace member, or someone outside the workspace by email
+    user_id TEXT REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT,
+    status TEXT NOT NULL DEFAULT 'needs-action' CHECK (
+        status IN ('needs-action', 'accepted', 'declined', 'tentative')
+    ),
+    -- Hash of the token in the RSVP link of an attendee without an account
+    rsvp_token_hash TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT event_attendees_identity_check CHECK (
+        (user_id IS NULL) != (email IS NULL)
+    ),
+    CONSTRAINT event_attendees_user_unique UNIQUE (event_id, user_id),
+    CONSTRAINT event_attendees_email_unique UNIQUE (event_id, email)
+);
+
+CREATE INDEX idx_event_attendees_user_id ON event_attendees (user_id);
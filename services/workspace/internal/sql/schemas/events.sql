@@ -0,0 +1,46 @@
+CREATE TABLE events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL,
+    duration_minutes INT NOT NULL,
+    -- Number of event_attendees rows
+    attendees_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    starts_at TIMESTAMPTZ NOT NULL,
+    -- IANA name, such as Europe/Paris
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    -- All-day events start at midnight in their timezone and last whole
+    -- days
+    all_day BOOLEAN NOT NULL DEFAULT false,
+    -- RFC 5545 recurrence rule, and the starts of removed occurrences
+    rrule TEXT,
+    exdates TIMESTAMPTZ [] NOT NULL DEFAULT '{}',
+    -- An occurrence edited on its own points at its series, recurrence_id
+    -- being the occurrence's original start
+    recurring_event_id UUID REFERENCES events (id) ON DELETE CASCADE,
+    recurrence_id TIMESTAMPTZ,
+    -- End of the event, or of its last occurrence; NULL for series without
+    -- end
+    ends_at TIMESTAMPTZ,
+    -- Revision count, the iCalendar SEQUENCE
+    sequence INT NOT NULL DEFAULT 0,
+    -- UID of an event imported from an iCalendar file
+    ical_uid TEXT,
+    CONSTRAINT events_recurrence_id_check CHECK (
+        (recurring_event_id IS NULL) = (recurrence_id IS NULL)
+    ),
+    CONSTRAINT events_override_rrule_check CHECK (
+        rrule IS NULL OR recurring_event_id IS NULL
+    ),
+    CONSTRAINT events_recurrence_unique UNIQUE (
+        recurring_event_id, recurrence_id
+    ) DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX idx_events_workspace_id ON events (workspace_id);
+CREATE INDEX idx_events_starts_at ON events (workspace_id, starts_at);
+CREATE INDEX idx_events_ends_at ON events (workspace_id, ends_at);
+CREATE UNIQUE INDEX idx_events_ical_uid ON events (workspace_id, ical_uid)
+WHERE recurring_event_id IS NULL;
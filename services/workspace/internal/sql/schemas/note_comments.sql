@@ -0,0 +1,29 @@
+CREATE TABLE note_comment_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    created_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    anchor_start INTEGER,
+    anchor_end INTEGER,
+    block_id TEXT,
+    quote TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMPTZ,
+    resolved_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    orphaned_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((anchor_start IS NULL) = (anchor_end IS NULL)),
+    CHECK ((anchor_start IS NULL) <> (block_id IS NULL)),
+    CHECK (anchor_start >= 0 AND anchor_start <= anchor_end)
+);
+
+CREATE INDEX idx_note_comment_threads_note_id ON note_comment_threads (note_id);
+
+CREATE TABLE note_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    thread_id UUID NOT NULL REFERENCES note_comment_threads (id) ON DELETE CASCADE,
+    author_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_note_comments_thread_id ON note_comments (thread_id, created_at);
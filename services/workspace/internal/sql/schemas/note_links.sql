@@ -0,0 +1,15 @@
+CREATE TABLE note_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    target_id UUID REFERENCES notes (id) ON DELETE SET NULL,
+    target_ref TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('wiki', 'id')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (source_id, kind, target_ref)
+);
+
+CREATE INDEX idx_note_links_target_id ON note_links (target_id);
+CREATE INDEX idx_note_links_unresolved ON note_links (
+    workspace_id, lower(target_ref)
+) WHERE target_id IS NULL;
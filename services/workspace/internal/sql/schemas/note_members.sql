@@ -0,0 +1,9 @@
+CREATE TABLE note_members (
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (note_id, user_id)
+);
+
+CREATE INDEX idx_note_members_user_id ON note_members (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE note_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    author_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL, -- noqa
+    tags TEXT [] NOT NULL DEFAULT '{}'::TEXT [],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (note_id, revision)
+);
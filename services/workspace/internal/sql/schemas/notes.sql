@@ -1,3 +1,27 @@
+CREATE FUNCTION notes_tags_text(tags TEXT []) RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT array_to_string(tags, ' ')
+$$;
+
+CREATE FUNCTION notes_search_vector(title TEXT, tags TEXT [], content TEXT)
+RETURNS TSVECTOR
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('english', title), 'A')
+        || setweight(to_tsvector('english', notes_tags_text(tags)), 'B')
+        || setweight(to_tsvector('english', content), 'C')
+$$;
+
+CREATE FUNCTION notes_html_escape(value TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT replace(replace(replace(replace(replace(
+        value, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')
+$$;
+
+CREATE FUNCTION notes_lower_tags(tags TEXT []) RETURNS TEXT []
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT ARRAY(SELECT lower(t) FROM unnest(tags) AS t)
+$$;
+
 CREATE TABLE notes (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
@@ -6,7 +30,19 @@ CREATE TABLE notes (
     content TEXT NOT NULL DEFAULT '', -- noqa
     tags TEXT [] NOT NULL DEFAULT '{}'::TEXT [],
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    version INTEGER NOT NULL DEFAULT 1,
+    parent_id UUID REFERENCES notes (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    visibility TEXT NOT NULL DEFAULT 'workspace' CHECK (
+        visibility IN ('workspace', 'private', 'restricted')
+    ),
+    CONSTRAINT notes_parent_not_self CHECK (parent_id <> id)
 );
 
 CREATE INDEX idx_notes_workspace_id ON notes (workspace_id);
+CREATE INDEX idx_notes_search ON notes USING gin (
+    notes_search_vector(title, tags, content)
+);
+CREATE INDEX idx_notes_lower_tags ON notes USING gin (notes_lower_tags(tags));
+CREATE INDEX idx_notes_parent_id ON notes (workspace_id, parent_id, position);
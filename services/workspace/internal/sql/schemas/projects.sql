@@ -0,0 +1,29 @@
+CREATE TYPE project_status AS ENUM (
+    'planned', 'active', 'paused', 'completed', 'cancelled'
+);
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    status PROJECT_STATUS NOT NULL DEFAULT 'planned',
+    lead_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    target_date DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_projects_workspace_id ON projects (workspace_id);
+
+CREATE TABLE milestones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    target_date DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_milestones_project_id ON milestones (project_id);
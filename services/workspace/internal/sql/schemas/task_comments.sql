@@ -0,0 +1,10 @@
+CREATE TABLE task_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    -- NULL when the comment was posted by an automation rule
+    author_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_comments_task_id ON task_comments (task_id);
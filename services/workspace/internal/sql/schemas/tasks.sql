@@ -11,9 +11,28 @@ CREATE TABLE tasks (
     priority TASK_PRIORITY NOT NULL DEFAULT 'medium',
     due_date TIMESTAMPTZ,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    project_id UUID REFERENCES projects (id) ON DELETE SET NULL,
+    milestone_id UUID REFERENCES milestones (id) ON DELETE SET NULL,
+    cycle_id UUID REFERENCES cycles (id) ON DELETE SET NULL,
+    start_date TIMESTAMPTZ
 );
 
 CREATE INDEX idx_tasks_workspace_id ON tasks (workspace_id);
 CREATE INDEX idx_tasks_assignee_id ON tasks (assignee_id);
 CREATE INDEX idx_tasks_status ON tasks (status);
+CREATE INDEX idx_tasks_project_id ON tasks (project_id);
+CREATE INDEX idx_tasks_milestone_id ON tasks (milestone_id);
+CREATE INDEX idx_tasks_cycle_id ON tasks (cycle_id);
+
+CREATE TABLE task_dependencies (
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    depends_on_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, depends_on_id),
+    CHECK (task_id <> depends_on_id)
+);
+
+CREATE INDEX idx_task_dependencies_depends_on_id ON task_dependencies (
+    depends_on_id
+);
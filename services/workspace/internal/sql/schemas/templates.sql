@@ -0,0 +1,18 @@
+CREATE TABLE templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('note', 'task')),
+    name TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    tags TEXT [] NOT NULL DEFAULT '{}',
+    checklist TEXT [] NOT NULL DEFAULT '{}',
+    priority TASK_PRIORITY NOT NULL DEFAULT 'medium',
+    assignee_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    due_in_days INTEGER CHECK (due_in_days >= 0),
+    created_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_templates_workspace_id ON templates (workspace_id, kind);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_task_views_workspace_id;
+DROP TABLE IF EXISTS task_views;
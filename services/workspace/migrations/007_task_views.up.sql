@@ -0,0 +1,19 @@
+CREATE TABLE task_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    owner_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    -- Values: 'private', 'workspace'
+    visibility TEXT NOT NULL DEFAULT 'private',
+    filters JSONB NOT NULL DEFAULT '{}'::JSONB,
+    sort JSONB NOT NULL DEFAULT '[]'::JSONB,
+    -- Values: 'status', 'assignee', 'priority'
+    group_by TEXT,
+    columns TEXT [] NOT NULL DEFAULT '{}'::TEXT [],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (visibility IN ('private', 'workspace')),
+    CHECK (group_by IN ('status', 'assignee', 'priority'))
+);
+
+CREATE INDEX idx_task_views_workspace_id ON task_views (workspace_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS automation_runs;
+DROP TABLE IF EXISTS automation_rules;
+DROP TABLE IF EXISTS task_comments;
@@ -0,0 +1,55 @@
+CREATE TABLE task_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    -- NULL when the comment was posted by an automation rule
+    author_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_comments_task_id ON task_comments (task_id);
+
+CREATE TABLE automation_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Values: 'task_created', 'task_status_changed', 'task_due_date_passed', 'note_tagged'
+    trigger_type TEXT NOT NULL,
+    trigger_config JSONB NOT NULL DEFAULT '{}'::JSONB,
+    conditions JSONB NOT NULL DEFAULT '[]'::JSONB,
+    actions JSONB NOT NULL DEFAULT '[]'::JSONB,
+    created_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (
+        trigger_type IN (
+            'task_created',
+            'task_status_changed',
+            'task_due_date_passed',
+            'note_tagged'
+        )
+    )
+);
+
+CREATE INDEX idx_automation_rules_workspace_trigger ON automation_rules (
+    workspace_id, trigger_type
+);
+
+CREATE TABLE automation_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id UUID NOT NULL REFERENCES automation_rules (id) ON DELETE CASCADE,
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    trigger_type TEXT NOT NULL,
+    -- The task or note that fired the trigger
+    subject_id UUID NOT NULL,
+    depth INT NOT NULL DEFAULT 0,
+    -- Values: 'succeeded', 'failed', 'skipped'
+    status TEXT NOT NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_automation_runs_rule_id ON automation_runs (rule_id, started_at);
+CREATE INDEX idx_automation_runs_rule_subject ON automation_runs (rule_id, subject_id);
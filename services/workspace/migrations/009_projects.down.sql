@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_tasks_milestone_id;
+DROP INDEX IF EXISTS idx_tasks_project_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS milestone_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS milestones;
+DROP TABLE IF EXISTS projects;
+DROP TYPE IF EXISTS project_status;
@@ -0,0 +1,36 @@
+CREATE TYPE project_status AS ENUM (
+    'planned', 'active', 'paused', 'completed', 'cancelled'
+);
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    status PROJECT_STATUS NOT NULL DEFAULT 'planned',
+    lead_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    target_date DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_projects_workspace_id ON projects (workspace_id);
+
+CREATE TABLE milestones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT,
+    target_date DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_milestones_project_id ON milestones (project_id);
+
+ALTER TABLE tasks
+ADD COLUMN project_id UUID REFERENCES projects (id) ON DELETE SET NULL,
+ADD COLUMN milestone_id UUID REFERENCES milestones (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_project_id ON tasks (project_id);
+CREATE INDEX idx_tasks_milestone_id ON tasks (milestone_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_cycle_events;
+DROP INDEX IF EXISTS idx_tasks_cycle_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS cycle_id;
+DROP TABLE IF EXISTS cycles;
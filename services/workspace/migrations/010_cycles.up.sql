@@ -0,0 +1,36 @@
+CREATE TABLE cycles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_cycles_workspace_id ON cycles (workspace_id, start_date);
+
+ALTER TABLE tasks
+ADD COLUMN cycle_id UUID REFERENCES cycles (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_cycle_id ON tasks (cycle_id);
+
+-- History of task membership in cycles, used to compute cycle summaries.
+-- Events of one transaction share created_at, so id keeps their order.
+CREATE TABLE task_cycle_events (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    cycle_id UUID NOT NULL REFERENCES cycles (id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    event TEXT NOT NULL CHECK (
+        event IN (
+            'added', 'removed', 'completed', 'reopened', 'carried_in', 'carried_over'
+        )
+    ),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_cycle_events_cycle_id ON task_cycle_events (
+    cycle_id, id
+);
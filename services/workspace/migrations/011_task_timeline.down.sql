@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_dependencies;
+ALTER TABLE tasks DROP COLUMN IF EXISTS start_date;
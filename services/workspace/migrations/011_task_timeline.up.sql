@@ -0,0 +1,14 @@
+ALTER TABLE tasks ADD COLUMN start_date TIMESTAMPTZ;
+
+-- task_id cannot start until depends_on_id is finished
+CREATE TABLE task_dependencies (
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    depends_on_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, depends_on_id),
+    CHECK (task_id <> depends_on_id)
+);
+
+CREATE INDEX idx_task_dependencies_depends_on_id ON task_dependencies (
+    depends_on_id
+);
@@ -0,0 +1,32 @@
+-- Snapshot of a note after each save; revision numbers count up per note
+CREATE TABLE note_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    author_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    tags TEXT [] NOT NULL DEFAULT '{}'::TEXT [],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (note_id, revision)
+);
+
+-- Existing notes start their history from their current state
+INSERT INTO note_revisions (
+    note_id,
+    revision,
+    author_id,
+    title,
+    content,
+    tags,
+    created_at
+)
+SELECT
+    id,
+    1,
+    author_id,
+    title,
+    content,
+    tags,
+    updated_at
+FROM notes;
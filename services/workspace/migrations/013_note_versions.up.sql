@@ -0,0 +1,2 @@
+-- Bumped on every update; exposed as the note's ETag
+ALTER TABLE notes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notes_search;
+DROP FUNCTION IF EXISTS notes_html_escape;
+DROP FUNCTION IF EXISTS notes_search_vector;
+DROP FUNCTION IF EXISTS notes_tags_text;
@@ -0,0 +1,27 @@
+-- array_to_string is only STABLE, so wrap it for use in an index
+CREATE FUNCTION notes_tags_text(tags TEXT []) RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT array_to_string(tags, ' ')
+$$;
+
+-- The search vector is generated by an expression index rather than a
+-- stored column: every note query returns whole rows, which would then
+-- carry the vector along with each note.
+CREATE FUNCTION notes_search_vector(title TEXT, tags TEXT [], content TEXT)
+RETURNS TSVECTOR
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT setweight(to_tsvector('english', title), 'A')
+        || setweight(to_tsvector('english', notes_tags_text(tags)), 'B')
+        || setweight(to_tsvector('english', content), 'C')
+$$;
+
+-- Escapes text for HTML, so search highlights can only add their own tags
+CREATE FUNCTION notes_html_escape(value TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT replace(replace(replace(replace(replace(
+        value, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')
+$$;
+
+CREATE INDEX idx_notes_search ON notes USING gin (
+    notes_search_vector(title, tags, content)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notes_parent_id;
+ALTER TABLE notes DROP CONSTRAINT IF EXISTS notes_parent_not_self;
+ALTER TABLE notes DROP COLUMN IF EXISTS position;
+ALTER TABLE notes DROP COLUMN IF EXISTS parent_id;
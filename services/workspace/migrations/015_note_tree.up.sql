@@ -0,0 +1,20 @@
+-- Notes form a tree per workspace; position orders siblings
+ALTER TABLE notes
+ADD COLUMN parent_id UUID REFERENCES notes (id) ON DELETE CASCADE,
+ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+ADD CONSTRAINT notes_parent_not_self CHECK (parent_id <> id);
+
+-- Keep the current most-recent-first order for existing notes
+UPDATE notes AS n
+SET position = o.position
+FROM (
+    SELECT
+        id,
+        row_number() OVER (
+            PARTITION BY workspace_id ORDER BY updated_at DESC
+        ) - 1 AS position
+    FROM notes
+) AS o
+WHERE n.id = o.id;
+
+CREATE INDEX idx_notes_parent_id ON notes (workspace_id, parent_id, position);
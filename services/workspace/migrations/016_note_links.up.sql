@@ -0,0 +1,73 @@
+-- Links found in note content. target_ref is the link as written: a title
+-- for [[wiki links]], a note id otherwise. Links whose target doesn't exist
+-- (yet) have a null target_id.
+CREATE TABLE note_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    source_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    target_id UUID REFERENCES notes (id) ON DELETE SET NULL,
+    target_ref TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('wiki', 'id')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (source_id, kind, target_ref)
+);
+
+CREATE INDEX idx_note_links_target_id ON note_links (target_id);
+CREATE INDEX idx_note_links_unresolved ON note_links (
+    workspace_id, lower(target_ref)
+) WHERE target_id IS NULL;
+
+-- Index [[links]] already in notes. Links in code blocks and Markdown links
+-- to note URLs are picked up the next time a note is saved.
+INSERT INTO note_links (
+    workspace_id,
+    source_id,
+    target_ref,
+    kind
+)
+SELECT DISTINCT
+    l.workspace_id,
+    l.source_id,
+    CASE
+        WHEN l.target_ref ~* '^[0-9a-f]{8}-([0-9a-f]{4}-){3}[0-9a-f]{12}$'
+            THEN lower(l.target_ref)
+        ELSE l.target_ref
+    END AS target_ref,
+    CASE
+        WHEN l.target_ref ~* '^[0-9a-f]{8}-([0-9a-f]{4}-){3}[0-9a-f]{12}$'
+            THEN 'id'
+        ELSE 'wiki'
+    END AS kind
+FROM (
+    SELECT
+        n.workspace_id,
+        n.id AS source_id,
+        trim(split_part(split_part(m.link[1], '|', 1), '#', 1)) AS target_ref
+    FROM notes AS n
+    CROSS JOIN LATERAL regexp_matches(n.content, '\[\[([^][\n]+)\]\]', 'g') AS m (link)
+) AS l
+WHERE l.target_ref <> ''
+ON CONFLICT (source_id, kind, target_ref) DO NOTHING;
+
+UPDATE note_links AS l
+SET target_id = t.id
+FROM (
+    SELECT DISTINCT ON (workspace_id, lower(title))
+        id,
+        workspace_id,
+        lower(title) AS title_key
+    FROM notes
+    ORDER BY workspace_id ASC, lower(title) ASC, created_at ASC
+) AS t
+WHERE
+    l.kind = 'wiki'
+    AND l.workspace_id = t.workspace_id
+    AND lower(l.target_ref) = t.title_key;
+
+UPDATE note_links AS l
+SET target_id = n.id
+FROM notes AS n
+WHERE
+    l.kind = 'id'
+    AND l.workspace_id = n.workspace_id
+    AND l.target_ref = n.id::TEXT;
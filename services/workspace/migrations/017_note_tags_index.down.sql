@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_notes_lower_tags;
+DROP FUNCTION IF EXISTS notes_lower_tags;
@@ -0,0 +1,8 @@
+-- Tag filters compare tags in lower case, the way tag listings group them
+CREATE FUNCTION notes_lower_tags(tags TEXT []) RETURNS TEXT []
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT ARRAY(SELECT lower(t) FROM unnest(tags) AS t)
+$$;
+
+-- Backs tag filters (&& and @>) on notes
+CREATE INDEX idx_notes_lower_tags ON notes USING gin (notes_lower_tags(tags));
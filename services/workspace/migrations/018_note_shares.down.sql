@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS note_share_accesses;
+DROP TABLE IF EXISTS note_shares;
@@ -0,0 +1,35 @@
+-- Read-only links to a note for people outside the workspace. Only a hash
+-- of the token is stored; token_hint helps editors tell links apart.
+CREATE TABLE note_shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_hint TEXT NOT NULL,
+    password_hash TEXT,
+    created_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_note_shares_note_id ON note_shares (note_id);
+
+-- Every attempt to open a share link, including refused ones
+CREATE TABLE note_share_accesses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    share_id UUID NOT NULL REFERENCES note_shares (id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (
+        status IN (
+            'ok', 'password_required', 'wrong_password', 'expired', 'revoked'
+        )
+    ),
+    format TEXT NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_note_share_accesses_share_id ON note_share_accesses (
+    share_id, accessed_at DESC
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS note_members;
+
+ALTER TABLE notes
+DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,24 @@
+-- Reusable starting points for notes and tasks. Text fields may contain
+-- placeholders such as {{date}} and {{user}}, filled in when the template
+-- is used.
+CREATE TABLE templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('note', 'task')),
+    name TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    -- The note's content, or the task's description
+    content TEXT NOT NULL DEFAULT '',
+    -- Note templates only
+    tags TEXT [] NOT NULL DEFAULT '{}',
+    -- Task templates only
+    checklist TEXT [] NOT NULL DEFAULT '{}',
+    priority TASK_PRIORITY NOT NULL DEFAULT 'medium',
+    assignee_id TEXT REFERENCES users (id) ON DELETE SET NULL,
+    due_in_days INTEGER CHECK (due_in_days >= 0),
+    created_by TEXT REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_templates_workspace_id ON templates (workspace_id, kind);
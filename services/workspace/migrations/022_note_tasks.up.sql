@@ -0,0 +1,9 @@
+-- Tasks extracted from checklist items in a note. The item's line carries
+-- a ^block-id marker so the link survives edits around it.
+CREATE TABLE note_tasks (
+    task_id UUID PRIMARY KEY REFERENCES tasks (id) ON DELETE CASCADE,
+    note_id UUID NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    block_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (note_id, block_id)
+);
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS idx_events_starts_at;
+
+ALTER TABLE events
+ADD COLUMN event_date DATE,
+ADD COLUMN event_time TIME;
+
+UPDATE events
+SET
+    event_date = (starts_at AT TIME ZONE timezone)::DATE,
+    event_time = (starts_at AT TIME ZONE timezone)::TIME;
+
+ALTER TABLE events
+ALTER COLUMN event_date SET NOT NULL,
+ALTER COLUMN event_time SET NOT NULL,
+DROP COLUMN starts_at,
+DROP COLUMN timezone,
+DROP COLUMN all_day;
+
+CREATE INDEX idx_events_date ON events (event_date);
@@ -0,0 +1,16 @@
+-- Events start at an instant and carry the IANA timezone they were
+-- planned in. Existing events were entered without a zone and are taken
+-- to be UTC.
+ALTER TABLE events
+ADD COLUMN starts_at TIMESTAMPTZ,
+ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC',
+ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE events SET starts_at = (event_date + event_time) AT TIME ZONE 'UTC';
+
+ALTER TABLE events
+ALTER COLUMN starts_at SET NOT NULL,
+DROP COLUMN event_date,
+DROP COLUMN event_time;
+
+CREATE INDEX idx_events_starts_at ON events (workspace_id, starts_at);
@@ -0,0 +1,7 @@
+DELETE FROM events WHERE recurring_event_id IS NOT NULL;
+
+ALTER TABLE events
+DROP COLUMN recurrence_id,
+DROP COLUMN recurring_event_id,
+DROP COLUMN exdates,
+DROP COLUMN rrule;
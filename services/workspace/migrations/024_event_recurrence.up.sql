@@ -0,0 +1,19 @@
+-- Recurring events carry an RFC 5545 RRULE and the starts of the
+-- occurrences removed from them. An occurrence edited on its own is stored
+-- as a separate event pointing at its series, with recurrence_id holding
+-- the occurrence's original start. Shifting a series moves its edited
+-- occurrences one by one, hence the deferred uniqueness check.
+ALTER TABLE events
+ADD COLUMN rrule TEXT,
+ADD COLUMN exdates TIMESTAMPTZ [] NOT NULL DEFAULT '{}',
+ADD COLUMN recurring_event_id UUID REFERENCES events (id) ON DELETE CASCADE,
+ADD COLUMN recurrence_id TIMESTAMPTZ,
+ADD CONSTRAINT events_recurrence_id_check CHECK (
+    (recurring_event_id IS NULL) = (recurrence_id IS NULL)
+),
+ADD CONSTRAINT events_override_rrule_check CHECK (
+    rrule IS NULL OR recurring_event_id IS NULL
+),
+ADD CONSTRAINT events_recurrence_unique UNIQUE (
+    recurring_event_id, recurrence_id
+) DEFERRABLE INITIALLY DEFERRED;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_events_ends_at;
+
+ALTER TABLE events
+DROP COLUMN IF EXISTS ends_at;
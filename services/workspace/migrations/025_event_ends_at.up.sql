@@ -0,0 +1,12 @@
+-- ends_at is when an event ends, or when the last occurrence of a
+-- recurring one does; NULL for series without end. Window queries bound
+-- both starts_at and ends_at so they can use an index for either end.
+-- Existing series are left open ended.
+ALTER TABLE events
+ADD COLUMN ends_at TIMESTAMPTZ;
+
+UPDATE events
+SET ends_at = starts_at + make_interval(mins => duration_minutes)
+WHERE rrule IS NULL;
+
+CREATE INDEX idx_events_ends_at ON events (workspace_id, ends_at);
@@ -0,0 +1,20 @@
+-- Events count their revisions, so calendar apps can tell an update from
+-- the copy they already have (the iCalendar SEQUENCE)
+ALTER TABLE events
+ADD COLUMN sequence INT NOT NULL DEFAULT 0;
+
+-- A calendar feed serves a workspace's calendar to one member's calendar
+-- apps. Those cannot sign in, so the feed URL carries a secret token, of
+-- which only the hash is kept.
+CREATE TABLE calendar_feeds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_hint TEXT NOT NULL,
+    -- How task due dates appear: not at all, as to-dos or as all-day events
+    tasks TEXT NOT NULL DEFAULT 'none' CHECK (tasks IN ('none', 'todo', 'event')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (workspace_id, user_id)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_events_ical_uid;
+
+ALTER TABLE events
+DROP COLUMN IF EXISTS ical_uid;
@@ -0,0 +1,8 @@
+-- Events imported from iCalendar files keep their UID, so importing the
+-- same file again updates them instead of creating duplicates. Occurrences
+-- edited on their own carry the UID of their series.
+ALTER TABLE events
+ADD COLUMN ical_uid TEXT;
+
+CREATE UNIQUE INDEX idx_events_ical_uid ON events (workspace_id, ical_uid)
+WHERE recurring_event_id IS NULL;
@@ -0,0 +1,25 @@
+-- Attendees are workspace members, or people outside the workspace by
+-- email, each answering the invitation on their own. An event's
+-- attendees_count follows its attendees once they are set, and keeps the
+-- count given so far until then.
+CREATE TABLE event_attendees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES users (id) ON DELETE CASCADE,
+    email TEXT,
+    status TEXT NOT NULL DEFAULT 'needs-action' CHECK (
+        status IN ('needs-action', 'accepted', 'declined', 'tentative')
+    ),
+    -- Attendees without an account answer through a link carrying a
+    -- secret token, of which only the hash is kept
+    rsvp_token_hash TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT event_attendees_identity_check CHECK (
+        (user_id IS NULL) != (email IS NULL)
+    ),
+    CONSTRAINT event_attendees_user_unique UNIQUE (event_id, user_id),
+    CONSTRAINT event_attendees_email_unique UNIQUE (event_id, email)
+);
+
+CREATE INDEX idx_event_attendees_user_id ON event_attendees (user_id);
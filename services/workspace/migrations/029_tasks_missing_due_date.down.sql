@@ -0,0 +1 @@
+-- Missing due dates stay NULL; the zero time was never a real date
@@ -0,0 +1,3 @@
+-- Tasks created without a due date were stored as the zero time, which
+-- calendar feeds and the timeline took for a date in year 1
+UPDATE tasks SET due_date = NULL WHERE due_date = '0001-01-01 00:00:00+00';
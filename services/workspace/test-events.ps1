@@ -95,8 +95,8 @@ if (-not $workspaceLookup) {
 $createEventBody = @{
     name             = "Team Standup"
     color            = "#3B82F6"
-    event_date       = "2025-11-15"
-    event_time       = "09:30:00"
+    starts_at        = "2025-11-15T09:30:00+01:00"
+    timezone         = "Europe/Paris"
     duration_minutes = 30
     attendees_count  = 5
 }
@@ -104,7 +104,7 @@ fi
 
 # Create Event
 create_event_body=$(cat <<EOF
-{"name":"Team Standup","color":"#3B82F6","event_date":"2025-11-15","event_time":"09:30:00","duration_minutes":30,"attendees_count":5}
+{"name":"Team Standup","color":"#3B82F6","starts_at":"2025-11-15T09:30:00+01:00","timezone":"Europe/Paris","duration_minutes":30,"attendees_count":5}
 EOF
 )
 http_request POST "/workspaces/${workspaceId}/events" "$create_event_body" "Create Event"